@@ -13,14 +13,18 @@ import (
 )
 
 type CalendarCmd struct {
-	Calendars       CalendarCalendarsCmd       `cmd:"" name:"calendars" help:"List calendars"`
-	ACL             CalendarAclCmd             `cmd:"" name:"acl" aliases:"permissions,perms" help:"List calendar ACL"`
+	Calendars       CalendarCalendarsCmd       `cmd:"" name:"calendars" help:"List and manage calendars"`
+	ACL             CalendarAclCmd             `cmd:"" name:"acl" aliases:"permissions,perms" help:"List and manage calendar sharing (ACL)"`
 	Events          CalendarEventsCmd          `cmd:"" name:"events" aliases:"list,ls" help:"List events from a calendar or all calendars"`
 	Event           CalendarEventCmd           `cmd:"" name:"event" aliases:"get,info,show" help:"Get event"`
 	Create          CalendarCreateCmd          `cmd:"" name:"create" aliases:"add,new" help:"Create an event"`
 	Update          CalendarUpdateCmd          `cmd:"" name:"update" aliases:"edit,set" help:"Update an event"`
 	Delete          CalendarDeleteCmd          `cmd:"" name:"delete" aliases:"rm,del,remove" help:"Delete an event"`
 	FreeBusy        CalendarFreeBusyCmd        `cmd:"" name:"freebusy" help:"Get free/busy"`
+	Export          CalendarExportCmd          `cmd:"" name:"export" help:"Export events as iCalendar (.ics)"`
+	Import          CalendarImportCmd          `cmd:"" name:"import" help:"Import events from an iCalendar (.ics) file"`
+	Mirror          CalendarMirrorCmd          `cmd:"" name:"mirror" help:"Mirror events one-way into another account's calendar"`
+	FindTime        CalendarFindTimeCmd        `cmd:"" name:"find-time" aliases:"findtime,slots" help:"Find meeting slots where all attendees are free"`
 	Respond         CalendarRespondCmd         `cmd:"" name:"respond" aliases:"rsvp,reply" help:"Respond to an event invitation"`
 	ProposeTime     CalendarProposeTimeCmd     `cmd:"" name:"propose-time" help:"Generate URL to propose a new meeting time (browser-only feature)"`
 	Colors          CalendarColorsCmd          `cmd:"" name:"colors" help:"Show calendar colors"`
@@ -34,14 +38,14 @@ type CalendarCmd struct {
 	WorkingLocation CalendarWorkingLocationCmd `cmd:"" name:"working-location" aliases:"wl" help:"Set working location (home/office/custom)"`
 }
 
-type CalendarCalendarsCmd struct {
+type CalendarCalendarsListCmd struct {
 	Max       int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
 	Page      string `name:"page" aliases:"cursor" help:"Page token"`
 	All       bool   `name:"all" aliases:"all-pages,allpages" help:"Fetch all pages"`
 	FailEmpty bool   `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
 }
 
-func (c *CalendarCalendarsCmd) Run(ctx context.Context, flags *RootFlags) error {
+func (c *CalendarCalendarsListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
@@ -107,7 +111,7 @@ func (c *CalendarCalendarsCmd) Run(ctx context.Context, flags *RootFlags) error
 	return nil
 }
 
-type CalendarAclCmd struct {
+type CalendarAclListCmd struct {
 	CalendarID string `arg:"" name:"calendarId" help:"Calendar ID"`
 	Max        int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
 	Page       string `name:"page" aliases:"cursor" help:"Page token"`
@@ -115,7 +119,7 @@ type CalendarAclCmd struct {
 	FailEmpty  bool   `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
 }
 
-func (c *CalendarAclCmd) Run(ctx context.Context, flags *RootFlags) error {
+func (c *CalendarAclListCmd) Run(ctx context.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
 	account, err := requireAccount(flags)
 	if err != nil {
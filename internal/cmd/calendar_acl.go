@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	calendarAclScopeUser    = "user"
+	calendarAclScopeGroup   = "group"
+	calendarAclScopeDomain  = "domain"
+	calendarAclScopeDefault = "default"
+)
+
+var calendarAclRoles = []string{"freeBusyReader", "reader", "writer", "owner"}
+
+type CalendarAclCmd struct {
+	List   CalendarAclListCmd   `cmd:"" default:"withargs" aliases:"ls" help:"List calendar ACL"`
+	Add    CalendarAclAddCmd    `cmd:"" name:"add" aliases:"share,grant,create" help:"Share a calendar with a user, group, domain, or the public"`
+	Update CalendarAclUpdateCmd `cmd:"" name:"update" aliases:"set,edit" help:"Change the role of an ACL rule"`
+	Remove CalendarAclRemoveCmd `cmd:"" name:"remove" aliases:"rm,delete,unshare,revoke" help:"Remove an ACL rule"`
+}
+
+type CalendarAclAddCmd struct {
+	CalendarID        string `arg:"" name:"calendarId" help:"Calendar ID"`
+	To                string `name:"to" help:"Share target: user|group|domain|public (inferred from --email/--domain)"`
+	Email             string `name:"email" help:"User or group email (for --to=user|group)"`
+	Domain            string `name:"domain" help:"Domain (for --to=domain; e.g. example.com)"`
+	Role              string `name:"role" help:"Role: freeBusyReader|reader|writer|owner" default:"reader"`
+	SendNotifications bool   `name:"send-notifications" help:"Email the grantee about the share (default: true; use --no-send-notifications to skip)" default:"true" negatable:"_"`
+}
+
+func (c *CalendarAclAddCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("calendarId required")
+	}
+	scope, err := c.scope()
+	if err != nil {
+		return err
+	}
+	role, err := normalizeCalendarAclRole(c.Role)
+	if err != nil {
+		return err
+	}
+	if scope.Type == calendarAclScopeDefault && (role == "writer" || role == "owner") {
+		return usagef("--to=public only supports --role freeBusyReader|reader")
+	}
+
+	rule := &calendar.AclRule{Role: role, Scope: scope}
+	if err := dryRunExit(ctx, flags, "calendar.acl.add", map[string]any{
+		"calendar_id":        calendarID,
+		"rule_id":            calendarAclRuleID(scope),
+		"scope_type":         scope.Type,
+		"scope_value":        scope.Value,
+		"role":               role,
+		"send_notifications": c.SendNotifications,
+	}); err != nil {
+		return err
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+
+	created, err := svc.Acl.Insert(calendarID, rule).
+		SendNotifications(c.SendNotifications).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+	return writeCalendarAclRule(ctx, u, calendarID, created)
+}
+
+func (c *CalendarAclAddCmd) scope() (*calendar.AclRuleScope, error) {
+	to := strings.ToLower(strings.TrimSpace(c.To))
+	email := strings.TrimSpace(c.Email)
+	domain := strings.TrimSpace(c.Domain)
+
+	if to == "" {
+		switch {
+		case email != "" && domain == "":
+			to = calendarAclScopeUser
+		case email == "" && domain != "":
+			to = calendarAclScopeDomain
+		case email == "" && domain == "":
+			return nil, usage("must specify --to (user|group|domain|public)")
+		default:
+			return nil, usage("ambiguous share target (use --to=user|group|domain|public)")
+		}
+	}
+
+	switch to {
+	case calendarAclScopeUser, calendarAclScopeGroup:
+		if email == "" {
+			return nil, usagef("missing --email for --to=%s", to)
+		}
+		if domain != "" {
+			return nil, usagef("--to=%s cannot be combined with --domain", to)
+		}
+		return &calendar.AclRuleScope{Type: to, Value: email}, nil
+	case calendarAclScopeDomain:
+		if domain == "" {
+			return nil, usage("missing --domain for --to=domain")
+		}
+		if email != "" {
+			return nil, usage("--to=domain cannot be combined with --email")
+		}
+		return &calendar.AclRuleScope{Type: calendarAclScopeDomain, Value: domain}, nil
+	case "public", "anyone", calendarAclScopeDefault:
+		if email != "" || domain != "" {
+			return nil, usage("--to=public cannot be combined with --email or --domain")
+		}
+		return &calendar.AclRuleScope{Type: calendarAclScopeDefault}, nil
+	default:
+		return nil, usage("invalid --to (expected user|group|domain|public)")
+	}
+}
+
+type CalendarAclUpdateCmd struct {
+	CalendarID        string `arg:"" name:"calendarId" help:"Calendar ID"`
+	RuleID            string `arg:"" name:"ruleId" help:"ACL rule ID (e.g. user:a@b.com, group:team@b.com, domain:b.com, default); a bare email means user:<email>, public means default"`
+	Role              string `name:"role" help:"Role: freeBusyReader|reader|writer|owner" required:""`
+	SendNotifications bool   `name:"send-notifications" help:"Email the grantee about the change (default: true; use --no-send-notifications to skip)" default:"true" negatable:"_"`
+}
+
+func (c *CalendarAclUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("calendarId required")
+	}
+	ruleID, err := normalizeCalendarAclRuleID(c.RuleID)
+	if err != nil {
+		return err
+	}
+	role, err := normalizeCalendarAclRole(c.Role)
+	if err != nil {
+		return err
+	}
+
+	if err := dryRunExit(ctx, flags, "calendar.acl.update", map[string]any{
+		"calendar_id":        calendarID,
+		"rule_id":            ruleID,
+		"role":               role,
+		"send_notifications": c.SendNotifications,
+	}); err != nil {
+		return err
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+
+	updated, err := svc.Acl.Patch(calendarID, ruleID, &calendar.AclRule{Role: role}).
+		SendNotifications(c.SendNotifications).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+	return writeCalendarAclRule(ctx, u, calendarID, updated)
+}
+
+type CalendarAclRemoveCmd struct {
+	CalendarID string `arg:"" name:"calendarId" help:"Calendar ID"`
+	RuleID     string `arg:"" name:"ruleId" help:"ACL rule ID (e.g. user:a@b.com, group:team@b.com, domain:b.com, default); a bare email means user:<email>, public means default"`
+}
+
+func (c *CalendarAclRemoveCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("calendarId required")
+	}
+	ruleID, err := normalizeCalendarAclRuleID(c.RuleID)
+	if err != nil {
+		return err
+	}
+
+	if confirmErr := confirmDestructive(ctx, flags, fmt.Sprintf("remove ACL rule %s from calendar %s", ruleID, calendarID)); confirmErr != nil {
+		return confirmErr
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.Acl.Delete(calendarID, ruleID).Context(ctx).Do(); err != nil {
+		return err
+	}
+	return writeResult(ctx, u,
+		kv("removed", true),
+		kv("calendarId", calendarID),
+		kv("ruleId", ruleID),
+	)
+}
+
+func normalizeCalendarAclRole(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "reader", nil
+	}
+	switch strings.ToLower(raw) {
+	case "freebusy", "free-busy", "freebusyreader":
+		return "freeBusyReader", nil
+	}
+	for _, role := range calendarAclRoles {
+		if strings.EqualFold(raw, role) {
+			return role, nil
+		}
+	}
+	return "", usagef("invalid --role (expected %s)", strings.Join(calendarAclRoles, "|"))
+}
+
+// normalizeCalendarAclRuleID maps user-friendly rule references to ACL rule IDs.
+// Group rules must be given explicitly as group:<email>.
+func normalizeCalendarAclRuleID(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case raw == "":
+		return "", usage("ruleId required")
+	case strings.EqualFold(raw, "public") || strings.EqualFold(raw, calendarAclScopeDefault):
+		return calendarAclScopeDefault, nil
+	case strings.Contains(raw, ":"):
+		return raw, nil
+	case strings.Contains(raw, "@"):
+		return calendarAclScopeUser + ":" + raw, nil
+	default:
+		return "", usagef("invalid ruleId %q (expected e.g. user:a@b.com, group:team@b.com, domain:b.com, default)", raw)
+	}
+}
+
+func calendarAclRuleID(scope *calendar.AclRuleScope) string {
+	if scope.Type == calendarAclScopeDefault {
+		return calendarAclScopeDefault
+	}
+	return scope.Type + ":" + scope.Value
+}
+
+func writeCalendarAclRule(ctx context.Context, u *ui.UI, calendarID string, rule *calendar.AclRule) error {
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"calendarId": calendarID,
+			"rule":       rule,
+		})
+	}
+	u.Out().Printf("id\t%s", rule.Id)
+	if rule.Scope != nil {
+		u.Out().Printf("scope_type\t%s", rule.Scope.Type)
+		if rule.Scope.Value != "" {
+			u.Out().Printf("scope_value\t%s", rule.Scope.Value)
+		}
+	}
+	u.Out().Printf("role\t%s", rule.Role)
+	return nil
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestNormalizeCalendarAclRuleID(t *testing.T) {
+	tests := map[string]string{
+		"a@b.com":          "user:a@b.com",
+		"group:eng@b.com":  "group:eng@b.com",
+		"domain:b.com":     "domain:b.com",
+		"public":           "default",
+		"Default":          "default",
+		" user:a@b.com \t": "user:a@b.com",
+	}
+	for in, want := range tests {
+		got, err := normalizeCalendarAclRuleID(in)
+		if err != nil || got != want {
+			t.Fatalf("%q: got %q (%v), want %q", in, got, err, want)
+		}
+	}
+	if _, err := normalizeCalendarAclRuleID("bob"); err == nil {
+		t.Fatalf("expected error for bare name")
+	}
+}
+
+func TestNormalizeCalendarAclRole(t *testing.T) {
+	for in, want := range map[string]string{"freebusy": "freeBusyReader", "WRITER": "writer", "": "reader"} {
+		got, err := normalizeCalendarAclRole(in)
+		if err != nil || got != want {
+			t.Fatalf("%q: got %q (%v), want %q", in, got, err, want)
+		}
+	}
+	if _, err := normalizeCalendarAclRole("admin"); err == nil {
+		t.Fatalf("expected error for unknown role")
+	}
+}
+
+func TestCalendarAclAddScope(t *testing.T) {
+	scope, err := (&CalendarAclAddCmd{Email: "a@b.com"}).scope()
+	if err != nil || scope.Type != "user" || scope.Value != "a@b.com" {
+		t.Fatalf("unexpected user scope: %+v (%v)", scope, err)
+	}
+	scope, err = (&CalendarAclAddCmd{To: "group", Email: "eng@b.com"}).scope()
+	if err != nil || scope.Type != "group" {
+		t.Fatalf("unexpected group scope: %+v (%v)", scope, err)
+	}
+	scope, err = (&CalendarAclAddCmd{To: "public"}).scope()
+	if err != nil || scope.Type != "default" || scope.Value != "" {
+		t.Fatalf("unexpected public scope: %+v (%v)", scope, err)
+	}
+	for _, bad := range []CalendarAclAddCmd{
+		{},
+		{Email: "a@b.com", Domain: "b.com"},
+		{To: "domain"},
+		{To: "public", Email: "a@b.com"},
+		{To: "everyone"},
+	} {
+		if _, err := bad.scope(); err == nil {
+			t.Fatalf("expected error for %+v", bad)
+		}
+	}
+}
+
+func TestExecute_CalendarAclManage_JSON(t *testing.T) {
+	origNew := newCalendarService
+	t.Cleanup(func() { newCalendarService = origNew })
+
+	var (
+		inserted      calendar.AclRule
+		patched       calendar.AclRule
+		notifications []string
+		deletedPath   string
+	)
+	svc, closeSrv := newCalendarTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/calendar/v3")
+		switch {
+		case r.Method == http.MethodPost && path == "/calendars/team@example.com/acl":
+			_ = json.NewDecoder(r.Body).Decode(&inserted)
+			notifications = append(notifications, r.URL.Query().Get("sendNotifications"))
+			inserted.Id = inserted.Scope.Type + ":" + inserted.Scope.Value
+			_ = json.NewEncoder(w).Encode(inserted)
+		case r.Method == http.MethodPatch && path == "/calendars/team@example.com/acl/user:a@b.com":
+			_ = json.NewDecoder(r.Body).Decode(&patched)
+			notifications = append(notifications, r.URL.Query().Get("sendNotifications"))
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":    "user:a@b.com",
+				"role":  patched.Role,
+				"scope": map[string]any{"type": "user", "value": "a@b.com"},
+			})
+		case r.Method == http.MethodDelete && strings.HasPrefix(path, "/calendars/team@example.com/acl/"):
+			deletedPath = path
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer closeSrv()
+	newCalendarService = stubCalendarService(svc)
+
+	run := func(args ...string) string {
+		return captureStdout(t, func() {
+			_ = captureStderr(t, func() {
+				if err := Execute(append([]string{"--json", "--force", "--account", "a@b.com", "calendar", "acl"}, args...)); err != nil {
+					t.Fatalf("Execute %v: %v", args, err)
+				}
+			})
+		})
+	}
+
+	out := run("add", "team@example.com", "--email", "a@b.com", "--role", "freebusy", "--no-send-notifications")
+	if inserted.Role != "freeBusyReader" || inserted.Scope == nil || inserted.Scope.Type != "user" || inserted.Scope.Value != "a@b.com" {
+		t.Fatalf("unexpected inserted rule: %+v", inserted)
+	}
+	var parsed struct {
+		Rule calendar.AclRule `json:"rule"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil || parsed.Rule.Id != "user:a@b.com" {
+		t.Fatalf("unexpected add output: %q (%v)", out, err)
+	}
+
+	_ = run("update", "team@example.com", "a@b.com", "--role", "writer")
+	if patched.Role != "writer" {
+		t.Fatalf("unexpected patched rule: %+v", patched)
+	}
+	if len(notifications) != 2 || notifications[0] != "false" || notifications[1] != "true" {
+		t.Fatalf("unexpected sendNotifications: %v", notifications)
+	}
+
+	_ = run("remove", "team@example.com", "public")
+	if deletedPath != "/calendars/team@example.com/acl/default" {
+		t.Fatalf("unexpected delete path: %q", deletedPath)
+	}
+}
+
+func TestExecute_CalendarAclAdd_DryRun(t *testing.T) {
+	origNew := newCalendarService
+	t.Cleanup(func() { newCalendarService = origNew })
+	newCalendarService = func(context.Context, string) (*calendar.Service, error) {
+		t.Fatalf("dry-run must not create a calendar service")
+		return nil, nil
+	}
+
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			_ = Execute([]string{"--json", "--dry-run", "--account", "a@b.com", "calendar", "acl", "add", "primary", "--to", "public"})
+		})
+	})
+	var parsed struct {
+		DryRun  bool           `json:"dry_run"`
+		Request map[string]any `json:"request"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if !parsed.DryRun || parsed.Request["rule_id"] != "default" || parsed.Request["role"] != "reader" {
+		t.Fatalf("unexpected dry-run output: %+v", parsed)
+	}
+}
@@ -0,0 +1,452 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var calendarNotificationTypes = []string{"eventCreation", "eventChange", "eventCancellation", "eventResponse", "agenda"}
+
+type CalendarCalendarsCmd struct {
+	List        CalendarCalendarsListCmd        `cmd:"" default:"withargs" aliases:"ls" help:"List calendars"`
+	Create      CalendarCalendarsCreateCmd      `cmd:"" name:"create" aliases:"add,new" help:"Create a secondary calendar"`
+	Update      CalendarCalendarsUpdateCmd      `cmd:"" name:"update" aliases:"edit,set" help:"Rename, recolor, hide, or change defaults of a calendar"`
+	Delete      CalendarCalendarsDeleteCmd      `cmd:"" name:"delete" aliases:"rm,del,remove" help:"Delete a secondary calendar"`
+	Subscribe   CalendarCalendarsSubscribeCmd   `cmd:"" name:"subscribe" aliases:"sub" help:"Add an existing calendar to your calendar list"`
+	Unsubscribe CalendarCalendarsUnsubscribeCmd `cmd:"" name:"unsubscribe" aliases:"unsub" help:"Remove a calendar from your calendar list"`
+}
+
+// CalendarListSettingsFlags are the per-user calendar list settings shared by
+// create, update, and subscribe.
+type CalendarListSettingsFlags struct {
+	Color           string   `name:"calendar-color" help:"Calendar color: color ID (see 'gog calendar colors') or hex like #0088aa"`
+	SummaryOverride string   `name:"summary-override" help:"Name shown only in your calendar list (set empty to clear)"`
+	Hidden          *bool    `name:"hidden" help:"Hide the calendar from your calendar list"`
+	Selected        *bool    `name:"selected" help:"Show the calendar's events in the calendar UI"`
+	Reminders       []string `name:"reminder" help:"Default event reminders as method:duration (e.g., popup:30m, email:1d). Can be repeated (max 5). Set empty to clear."`
+	Notifications   []string `name:"notify" help:"Email notifications as type (eventCreation|eventChange|eventCancellation|eventResponse|agenda). Can be repeated or comma-separated. Set empty to clear."`
+}
+
+// apply copies the provided settings into entry and reports whether any were
+// set and whether the color is a hex value (colorRgbFormat).
+func (f *CalendarListSettingsFlags) apply(kctx *kong.Context, entry *calendar.CalendarListEntry) (changed, rgb bool, err error) {
+	if color := strings.TrimSpace(f.Color); color != "" {
+		if strings.HasPrefix(color, "#") {
+			bg, fg, colorErr := calendarHexColors(color)
+			if colorErr != nil {
+				return false, false, colorErr
+			}
+			entry.BackgroundColor = bg
+			entry.ForegroundColor = fg
+			rgb = true
+		} else {
+			if id, convErr := strconv.Atoi(color); convErr != nil || id < 1 || id > 24 {
+				return false, false, usagef("invalid --calendar-color %q (expected color ID 1-24 or hex like #0088aa)", color)
+			}
+			entry.ColorId = color
+		}
+		changed = true
+	}
+	if flagProvided(kctx, "summary-override") || strings.TrimSpace(f.SummaryOverride) != "" {
+		entry.SummaryOverride = strings.TrimSpace(f.SummaryOverride)
+		entry.ForceSendFields = append(entry.ForceSendFields, "SummaryOverride")
+		changed = true
+	}
+	if f.Hidden != nil {
+		entry.Hidden = *f.Hidden
+		entry.ForceSendFields = append(entry.ForceSendFields, "Hidden")
+		changed = true
+	}
+	if f.Selected != nil {
+		entry.Selected = *f.Selected
+		entry.ForceSendFields = append(entry.ForceSendFields, "Selected")
+		changed = true
+	}
+	if flagProvided(kctx, "reminder") || len(f.Reminders) > 0 {
+		reminders, remErr := buildReminders(f.Reminders)
+		if remErr != nil {
+			return false, false, remErr
+		}
+		entry.DefaultReminders = []*calendar.EventReminder{}
+		if reminders != nil {
+			entry.DefaultReminders = reminders.Overrides
+		}
+		entry.ForceSendFields = append(entry.ForceSendFields, "DefaultReminders")
+		changed = true
+	}
+	if flagProvided(kctx, "notify") || len(f.Notifications) > 0 {
+		settings, notifyErr := buildCalendarNotificationSettings(f.Notifications)
+		if notifyErr != nil {
+			return false, false, notifyErr
+		}
+		entry.NotificationSettings = settings
+		changed = true
+	}
+	return changed, rgb, nil
+}
+
+type CalendarCalendarsCreateCmd struct {
+	Summary     string                    `arg:"" name:"summary" help:"Calendar name"`
+	Description string                    `name:"description" help:"Calendar description"`
+	Location    string                    `name:"location" help:"Geographic location of the calendar"`
+	TimeZone    string                    `name:"timezone" aliases:"tz" help:"Calendar time zone (IANA, e.g. Europe/Berlin; default: your calendar's time zone)"`
+	Settings    CalendarListSettingsFlags `embed:""`
+}
+
+func (c *CalendarCalendarsCreateCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	summary := strings.TrimSpace(c.Summary)
+	if summary == "" {
+		return usage("empty summary")
+	}
+	cal := &calendar.Calendar{
+		Summary:     summary,
+		Description: strings.TrimSpace(c.Description),
+		Location:    strings.TrimSpace(c.Location),
+		TimeZone:    strings.TrimSpace(c.TimeZone),
+	}
+	entry := &calendar.CalendarListEntry{}
+	settingsChanged, rgb, err := c.Settings.apply(kctx, entry)
+	if err != nil {
+		return err
+	}
+
+	if err := dryRunExit(ctx, flags, "calendar.calendars.create", map[string]any{
+		"calendar":      cal,
+		"list_settings": calendarListSettingsRequest(entry, settingsChanged),
+	}); err != nil {
+		return err
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	if cal.TimeZone == "" {
+		loc, tzErr := getUserTimezone(ctx, svc)
+		if tzErr != nil {
+			return tzErr
+		}
+		cal.TimeZone = loc.String()
+	}
+
+	created, err := svc.Calendars.Insert(cal).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	var listEntry *calendar.CalendarListEntry
+	if settingsChanged {
+		listEntry, err = svc.CalendarList.Patch(created.Id, entry).ColorRgbFormat(rgb).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("calendar %s created, but applying list settings failed: %w", created.Id, err)
+		}
+	}
+	return writeCalendarCalendar(ctx, u, created, listEntry)
+}
+
+type CalendarCalendarsUpdateCmd struct {
+	CalendarID  string                    `arg:"" name:"calendarId" help:"Calendar ID or name"`
+	Summary     string                    `name:"summary" aliases:"name" help:"New calendar name (owners only)"`
+	Description string                    `name:"description" help:"New description (set empty to clear)"`
+	Location    string                    `name:"location" help:"New location (set empty to clear)"`
+	TimeZone    string                    `name:"timezone" aliases:"tz" help:"New time zone (IANA, e.g. Europe/Berlin)"`
+	Settings    CalendarListSettingsFlags `embed:""`
+}
+
+func (c *CalendarCalendarsUpdateCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("calendarId required")
+	}
+
+	patch := &calendar.Calendar{}
+	calendarChanged := false
+	if flagProvided(kctx, "summary") {
+		patch.Summary = strings.TrimSpace(c.Summary)
+		if patch.Summary == "" {
+			return usage("--summary cannot be empty")
+		}
+		calendarChanged = true
+	}
+	if flagProvided(kctx, "description") {
+		patch.Description = strings.TrimSpace(c.Description)
+		patch.ForceSendFields = append(patch.ForceSendFields, "Description")
+		calendarChanged = true
+	}
+	if flagProvided(kctx, "location") {
+		patch.Location = strings.TrimSpace(c.Location)
+		patch.ForceSendFields = append(patch.ForceSendFields, "Location")
+		calendarChanged = true
+	}
+	if flagProvided(kctx, "timezone") {
+		patch.TimeZone = strings.TrimSpace(c.TimeZone)
+		if patch.TimeZone == "" {
+			return usage("--timezone cannot be empty")
+		}
+		calendarChanged = true
+	}
+	entry := &calendar.CalendarListEntry{}
+	settingsChanged, rgb, err := c.Settings.apply(kctx, entry)
+	if err != nil {
+		return err
+	}
+	if !calendarChanged && !settingsChanged {
+		return usage("no updates provided")
+	}
+
+	request := map[string]any{"calendar_id": calendarID}
+	if calendarChanged {
+		request["calendar"] = patch
+	}
+	if settingsChanged {
+		request["list_settings"] = entry
+	}
+	if err := dryRunExit(ctx, flags, "calendar.calendars.update", request); err != nil {
+		return err
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+
+	var cal *calendar.Calendar
+	if calendarChanged {
+		if cal, err = svc.Calendars.Patch(calendarID, patch).Context(ctx).Do(); err != nil {
+			return err
+		}
+	}
+	var listEntry *calendar.CalendarListEntry
+	if settingsChanged {
+		if listEntry, err = svc.CalendarList.Patch(calendarID, entry).ColorRgbFormat(rgb).Context(ctx).Do(); err != nil {
+			return err
+		}
+	}
+	if cal == nil {
+		cal = &calendar.Calendar{Id: listEntry.Id, Summary: listEntry.Summary, TimeZone: listEntry.TimeZone}
+	}
+	return writeCalendarCalendar(ctx, u, cal, listEntry)
+}
+
+type CalendarCalendarsDeleteCmd struct {
+	CalendarID string `arg:"" name:"calendarId" help:"Calendar ID or name"`
+}
+
+func (c *CalendarCalendarsDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("calendarId required")
+	}
+	if strings.EqualFold(calendarID, primaryCalendarID) {
+		return usage("cannot delete the primary calendar")
+	}
+
+	if confirmErr := confirmDestructive(ctx, flags, fmt.Sprintf("delete calendar %s and all its events", calendarID)); confirmErr != nil {
+		return confirmErr
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.Calendars.Delete(calendarID).Context(ctx).Do(); err != nil {
+		return err
+	}
+	return writeResult(ctx, u,
+		kv("deleted", true),
+		kv("calendarId", calendarID),
+	)
+}
+
+type CalendarCalendarsSubscribeCmd struct {
+	CalendarID string                    `arg:"" name:"calendarId" help:"Calendar ID (e.g. team@group.calendar.google.com or a public calendar ID)"`
+	Settings   CalendarListSettingsFlags `embed:""`
+}
+
+func (c *CalendarCalendarsSubscribeCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("calendarId required")
+	}
+	entry := &calendar.CalendarListEntry{Id: calendarID}
+	settingsChanged, rgb, err := c.Settings.apply(kctx, entry)
+	if err != nil {
+		return err
+	}
+
+	if err := dryRunExit(ctx, flags, "calendar.calendars.subscribe", map[string]any{
+		"calendar_id":   calendarID,
+		"list_settings": calendarListSettingsRequest(entry, settingsChanged),
+	}); err != nil {
+		return err
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := svc.CalendarList.Insert(entry).ColorRgbFormat(rgb).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	return writeCalendarCalendar(ctx, u, &calendar.Calendar{Id: inserted.Id, Summary: inserted.Summary, TimeZone: inserted.TimeZone}, inserted)
+}
+
+type CalendarCalendarsUnsubscribeCmd struct {
+	CalendarID string `arg:"" name:"calendarId" help:"Calendar ID or name"`
+}
+
+func (c *CalendarCalendarsUnsubscribeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("calendarId required")
+	}
+	if strings.EqualFold(calendarID, primaryCalendarID) {
+		return usage("cannot unsubscribe from the primary calendar")
+	}
+
+	if err := dryRunExit(ctx, flags, "calendar.calendars.unsubscribe", map[string]any{
+		"calendar_id": calendarID,
+	}); err != nil {
+		return err
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.CalendarList.Delete(calendarID).Context(ctx).Do(); err != nil {
+		return err
+	}
+	return writeResult(ctx, u,
+		kv("unsubscribed", true),
+		kv("calendarId", calendarID),
+	)
+}
+
+func buildCalendarNotificationSettings(values []string) (*calendar.CalendarListEntryNotificationSettings, error) {
+	var kinds []string
+	for _, v := range values {
+		kinds = append(kinds, splitCSV(v)...)
+	}
+	notifications := []*calendar.CalendarNotification{}
+	for _, raw := range kinds {
+		// Accept type:email for symmetry with --reminder; email is the only method.
+		kind, method, hasMethod := strings.Cut(raw, ":")
+		if hasMethod && !strings.EqualFold(strings.TrimSpace(method), "email") {
+			return nil, usagef("invalid --notify method %q (only email is supported)", method)
+		}
+		matched := ""
+		for _, t := range calendarNotificationTypes {
+			if strings.EqualFold(strings.TrimSpace(kind), t) {
+				matched = t
+			}
+		}
+		if matched == "" {
+			return nil, usagef("invalid --notify %q (expected %s)", raw, strings.Join(calendarNotificationTypes, "|"))
+		}
+		notifications = append(notifications, &calendar.CalendarNotification{Type: matched, Method: "email"})
+	}
+	return &calendar.CalendarListEntryNotificationSettings{
+		Notifications:   notifications,
+		ForceSendFields: []string{"Notifications"},
+	}, nil
+}
+
+// calendarHexColors validates a #rrggbb background and picks a readable
+// foreground color for it.
+func calendarHexColors(value string) (string, string, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(value), "#")
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return "", "", usagef("invalid --calendar-color %q (expected hex like #0088aa)", value)
+	}
+	r, g, b := (rgb>>16)&0xff, (rgb>>8)&0xff, rgb&0xff
+	fg := "#ffffff"
+	if 299*r+587*g+114*b > 128000 {
+		fg = "#000000"
+	}
+	return "#" + strings.ToLower(hex), fg, nil
+}
+
+func calendarListSettingsRequest(entry *calendar.CalendarListEntry, changed bool) any {
+	if !changed {
+		return nil
+	}
+	return entry
+}
+
+func writeCalendarCalendar(ctx context.Context, u *ui.UI, cal *calendar.Calendar, entry *calendar.CalendarListEntry) error {
+	if outfmt.IsJSON(ctx) {
+		out := map[string]any{"calendar": cal}
+		if entry != nil {
+			out["listEntry"] = entry
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, out)
+	}
+	u.Out().Printf("id\t%s", cal.Id)
+	u.Out().Printf("summary\t%s", cal.Summary)
+	if cal.TimeZone != "" {
+		u.Out().Printf("timezone\t%s", cal.TimeZone)
+	}
+	if entry != nil {
+		if entry.SummaryOverride != "" {
+			u.Out().Printf("summary_override\t%s", entry.SummaryOverride)
+		}
+		if entry.BackgroundColor != "" {
+			u.Out().Printf("color\t%s", entry.BackgroundColor)
+		}
+		u.Out().Printf("hidden\t%t", entry.Hidden)
+		u.Out().Printf("selected\t%t", entry.Selected)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestCalendarHexColors(t *testing.T) {
+	bg, fg, err := calendarHexColors("#FFCC00")
+	if err != nil || bg != "#ffcc00" || fg != "#000000" {
+		t.Fatalf("unexpected light color: %q %q (%v)", bg, fg, err)
+	}
+	if _, fg, _ = calendarHexColors("#003366"); fg != "#ffffff" {
+		t.Fatalf("expected white foreground on dark background, got %q", fg)
+	}
+	for _, bad := range []string{"#fff", "#gggggg", "#1234567"} {
+		if _, _, err := calendarHexColors(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestBuildCalendarNotificationSettings(t *testing.T) {
+	settings, err := buildCalendarNotificationSettings([]string{"eventcreation, agenda", "eventChange:email"})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if len(settings.Notifications) != 3 || settings.Notifications[0].Type != "eventCreation" || settings.Notifications[2].Method != "email" {
+		t.Fatalf("unexpected notifications: %+v", settings.Notifications)
+	}
+	if settings, err = buildCalendarNotificationSettings([]string{""}); err != nil || len(settings.Notifications) != 0 {
+		t.Fatalf("expected empty notifications to clear, got %+v (%v)", settings, err)
+	}
+	for _, bad := range []string{"reminder", "agenda:sms"} {
+		if _, err := buildCalendarNotificationSettings([]string{bad}); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+type calendarsTestRequest struct {
+	method string
+	path   string
+	query  string
+	body   map[string]any
+}
+
+func newCalendarsTestService(t *testing.T) *[]calendarsTestRequest {
+	t.Helper()
+
+	origNew := newCalendarService
+	t.Cleanup(func() { newCalendarService = origNew })
+
+	var requests []calendarsTestRequest
+	svc, closeSrv := newCalendarTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/calendar/v3")
+		req := calendarsTestRequest{method: r.Method, path: path, query: r.URL.RawQuery}
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			_ = json.Unmarshal(data, &req.body)
+		}
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && path == "/calendars":
+			req.body["id"] = "proj@group.calendar.google.com"
+			_ = json.NewEncoder(w).Encode(req.body)
+		case r.Method == http.MethodPatch && path == "/calendars/proj@group.calendar.google.com":
+			req.body["id"] = "proj@group.calendar.google.com"
+			_ = json.NewEncoder(w).Encode(req.body)
+		case (r.Method == http.MethodPatch && path == "/users/me/calendarList/proj@group.calendar.google.com") ||
+			(r.Method == http.MethodPost && path == "/users/me/calendarList"):
+			req.body["id"] = "proj@group.calendar.google.com"
+			req.body["summary"] = "Project"
+			_ = json.NewEncoder(w).Encode(req.body)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(closeSrv)
+	newCalendarService = stubCalendarService(svc)
+	return &requests
+}
+
+func runCalendarsCmd(t *testing.T, args ...string) string {
+	t.Helper()
+	return captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute(append([]string{"--json", "--force", "--account", "a@b.com", "calendar", "calendars"}, args...)); err != nil {
+				t.Fatalf("Execute %v: %v", args, err)
+			}
+		})
+	})
+}
+
+func TestExecute_CalendarCalendarsCreate_JSON(t *testing.T) {
+	requests := newCalendarsTestService(t)
+
+	out := runCalendarsCmd(t, "create", "Project", "--description", "Launch", "--calendar-color", "#003366", "--reminder", "popup:15m")
+
+	// Calendar insert (in the primary calendar's time zone), then list settings patch.
+	reqs := *requests
+	if len(reqs) != 2 {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+	if reqs[0].path != "/calendars" || reqs[0].body["summary"] != "Project" || reqs[0].body["timeZone"] != "UTC" {
+		t.Fatalf("unexpected insert: %+v", reqs[0])
+	}
+	patch := reqs[1]
+	if !strings.Contains(patch.query, "colorRgbFormat=true") || patch.body["backgroundColor"] != "#003366" || patch.body["foregroundColor"] != "#ffffff" {
+		t.Fatalf("unexpected list patch: %+v", patch)
+	}
+	reminders, _ := patch.body["defaultReminders"].([]any)
+	if len(reminders) != 1 {
+		t.Fatalf("expected one default reminder, got %+v", patch.body)
+	}
+
+	var parsed struct {
+		Calendar  calendar.Calendar          `json:"calendar"`
+		ListEntry calendar.CalendarListEntry `json:"listEntry"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if parsed.Calendar.Id != "proj@group.calendar.google.com" || parsed.ListEntry.BackgroundColor != "#003366" {
+		t.Fatalf("unexpected output: %+v", parsed)
+	}
+}
+
+func TestExecute_CalendarCalendarsUpdate_JSON(t *testing.T) {
+	requests := newCalendarsTestService(t)
+
+	_ = runCalendarsCmd(t, "update", "proj@group.calendar.google.com", "--summary", "Renamed", "--description", "", "--hidden=false", "--notify", "")
+
+	reqs := *requests
+	if len(reqs) != 2 {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+	if reqs[0].body["summary"] != "Renamed" || reqs[0].body["description"] != "" {
+		t.Fatalf("unexpected calendar patch: %+v", reqs[0].body)
+	}
+	if hidden, ok := reqs[1].body["hidden"]; !ok || hidden != false {
+		t.Fatalf("expected hidden=false to be sent, got %+v", reqs[1].body)
+	}
+	settings, _ := reqs[1].body["notificationSettings"].(map[string]any)
+	if notifications, ok := settings["notifications"].([]any); !ok || len(notifications) != 0 {
+		t.Fatalf("expected notifications to be cleared, got %+v", reqs[1].body)
+	}
+	if _, ok := reqs[1].body["defaultReminders"]; ok {
+		t.Fatalf("reminders must not change unless requested: %+v", reqs[1].body)
+	}
+}
+
+func TestExecute_CalendarCalendarsSubscribeUnsubscribeDelete(t *testing.T) {
+	requests := newCalendarsTestService(t)
+
+	_ = runCalendarsCmd(t, "subscribe", "proj@group.calendar.google.com", "--calendar-color", "7", "--selected")
+	_ = runCalendarsCmd(t, "unsubscribe", "proj@group.calendar.google.com")
+	_ = runCalendarsCmd(t, "delete", "proj@group.calendar.google.com")
+
+	reqs := *requests
+	if len(reqs) != 3 {
+		t.Fatalf("unexpected requests: %+v", reqs)
+	}
+	if reqs[0].method != http.MethodPost || reqs[0].body["id"] != "proj@group.calendar.google.com" || reqs[0].body["colorId"] != "7" || reqs[0].body["selected"] != true {
+		t.Fatalf("unexpected subscribe: %+v", reqs[0])
+	}
+	if reqs[1].method != http.MethodDelete || reqs[1].path != "/users/me/calendarList/proj@group.calendar.google.com" {
+		t.Fatalf("unexpected unsubscribe: %+v", reqs[1])
+	}
+	if reqs[2].method != http.MethodDelete || reqs[2].path != "/calendars/proj@group.calendar.google.com" {
+		t.Fatalf("unexpected delete: %+v", reqs[2])
+	}
+}
+
+func TestExecute_CalendarCalendars_Validation(t *testing.T) {
+	for _, args := range [][]string{
+		{"delete", "primary"},
+		{"unsubscribe", "primary"},
+		{"update", "proj@group.calendar.google.com"},
+		{"create", "Project", "--calendar-color", "99"},
+	} {
+		var err error
+		_ = captureStderr(t, func() {
+			err = Execute(append([]string{"--force", "--account", "a@b.com", "calendar", "calendars"}, args...))
+		})
+		if err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
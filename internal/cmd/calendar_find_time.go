@@ -0,0 +1,611 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/cloudidentity/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// freeBusyBatchSize is the maximum number of calendars per FreeBusy query.
+const freeBusyBatchSize = 50
+
+type CalendarFindTimeCmd struct {
+	Attendees    string `name:"attendees" help:"Comma-separated attendee or Google Group emails"`
+	Duration     string `name:"duration" help:"Meeting length (e.g., 30m, 1h, 1h30m)" default:"30m"`
+	Within       string `name:"within" help:"Search window: today, tomorrow, this week, next week, next N days, a day, or FROM..TO" default:"next 7 days"`
+	WorkingHours string `name:"working-hours" help:"Working hours applied in each attendee's timezone (HH:MM-HH:MM)" default:"09:00-17:00"`
+	Weekends     bool   `name:"weekends" help:"Allow slots on Saturday and Sunday"`
+	Step         string `name:"step" help:"Granularity of candidate start times" default:"30m"`
+	Max          int    `name:"max" aliases:"limit" help:"Max number of slots to return" default:"5"`
+	NoSelf       bool   `name:"no-self" help:"Do not include your own calendar"`
+	WeekStart    string `name:"week-start" help:"Week start day for 'this week'/'next week' (sun, mon, ...)" default:""`
+	Create       bool   `name:"create" help:"Create an event in the best slot"`
+	Summary      string `name:"summary" help:"Event summary (required with --create)"`
+	Description  string `name:"description" help:"Event description (with --create)"`
+	Location     string `name:"location" help:"Event location (with --create)"`
+	WithMeet     bool   `name:"with-meet" help:"Add a Google Meet link (with --create)"`
+	SendUpdates  string `name:"send-updates" help:"Notification mode with --create: all, externalOnly, none (default: none)"`
+	CalendarID   string `name:"calendar" help:"Calendar to create the event in (with --create)" default:"primary"`
+}
+
+// findTimeSpec holds the parsed slot constraints.
+type findTimeSpec struct {
+	duration  time.Duration
+	step      time.Duration
+	workStart time.Duration
+	workEnd   time.Duration
+	weekends  bool
+	max       int
+}
+
+// findTimeParticipant is a single person whose availability constrains the slot.
+type findTimeParticipant struct {
+	Email    string `json:"email"`
+	Via      string `json:"via,omitempty"`
+	Timezone string `json:"timezone"`
+	Error    string `json:"error,omitempty"`
+	loc      *time.Location
+	tzErr    string
+	busy     []findTimeInterval
+}
+
+type findTimeInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+type findTimeSlot struct {
+	Start          string `json:"start"`
+	End            string `json:"end"`
+	StartDayOfWeek string `json:"startDayOfWeek"`
+	Score          int    `json:"score"`
+	start          time.Time
+	end            time.Time
+}
+
+func (c *CalendarFindTimeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	attendees := splitCSV(c.Attendees)
+	if len(attendees) == 0 {
+		return usage("required: --attendees")
+	}
+	spec, err := c.parseSpec()
+	if err != nil {
+		return err
+	}
+	if c.Create && strings.TrimSpace(c.Summary) == "" {
+		return usage("--create requires --summary")
+	}
+	weekStart, err := resolveWeekStart(c.WeekStart)
+	if err != nil {
+		return usage(err.Error())
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	loc, err := getUserTimezone(ctx, svc)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().In(loc)
+	from, to, err := parseFindTimeWindow(c.Within, now, loc, weekStart)
+	if err != nil {
+		return usage(fmt.Sprintf("invalid --within: %v", err))
+	}
+	if from.Before(now) {
+		from = now
+	}
+	if !to.After(from.Add(spec.duration)) {
+		return usage("--within window is shorter than --duration (or already over)")
+	}
+
+	ids := attendees
+	if !c.NoSelf && !containsFold(ids, account) {
+		ids = append([]string{account}, ids...)
+	}
+
+	participants, err := collectFindTimeParticipants(ctx, svc, account, ids, from, to)
+	if err != nil {
+		return err
+	}
+	resolveParticipantTimezones(ctx, u, svc, account, participants, loc)
+
+	active := make([]*findTimeParticipant, 0, len(participants))
+	for _, p := range participants {
+		if p.Error != "" {
+			u.Err().Printf("Warning: no free/busy for %s (%s); ignoring", p.Email, p.Error)
+			continue
+		}
+		active = append(active, p)
+	}
+	if len(active) == 0 {
+		return fmt.Errorf("no free/busy information available for any attendee")
+	}
+
+	slots := spec.findSlots(active, from, to, loc)
+
+	if c.Create {
+		if len(slots) == 0 {
+			return fmt.Errorf("no free slot found for --create")
+		}
+		return c.createEvent(ctx, flags, u, account, attendees, slots[0])
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"timeMin":   from.Format(time.RFC3339),
+			"timeMax":   to.Format(time.RFC3339),
+			"timezone":  loc.String(),
+			"duration":  spec.duration.String(),
+			"attendees": participants,
+			"slots":     slots,
+		})
+	}
+
+	if len(slots) == 0 {
+		u.Err().Printf("No free %s slot for %d attendees in %s", spec.duration, len(active), (&TimeRange{From: from, To: to}).FormatHuman())
+		return nil
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "START\tEND\tSCORE")
+	for _, s := range slots {
+		fmt.Fprintf(w, "%s\t%s\t%d\n",
+			s.start.Format("Mon Jan 2 15:04"),
+			s.end.Format("15:04 MST"),
+			s.Score,
+		)
+	}
+	return nil
+}
+
+func (c *CalendarFindTimeCmd) parseSpec() (*findTimeSpec, error) {
+	spec := &findTimeSpec{weekends: c.Weekends, max: c.Max}
+	if spec.max <= 0 {
+		return nil, usage("--max must be > 0")
+	}
+	var err error
+	spec.duration, err = time.ParseDuration(strings.TrimSpace(c.Duration))
+	if err != nil || spec.duration <= 0 {
+		return nil, usagef("invalid --duration %q (use e.g. 30m, 1h)", c.Duration)
+	}
+	spec.step, err = time.ParseDuration(strings.TrimSpace(c.Step))
+	if err != nil || spec.step < time.Minute {
+		return nil, usagef("invalid --step %q (use e.g. 15m, 30m)", c.Step)
+	}
+	spec.workStart, spec.workEnd, err = parseWorkingHours(c.WorkingHours)
+	if err != nil {
+		return nil, usage(err.Error())
+	}
+	if spec.workEnd-spec.workStart < spec.duration {
+		return nil, usage("--duration does not fit into --working-hours")
+	}
+	return spec, nil
+}
+
+// collectFindTimeParticipants resolves attendees into individual calendars with their
+// busy intervals. Groups are expanded by the FreeBusy API when possible and
+// via Cloud Identity (like `calendar team`) otherwise.
+func collectFindTimeParticipants(ctx context.Context, svc *calendar.Service, account string, ids []string, from, to time.Time) ([]*findTimeParticipant, error) {
+	resp, err := queryFreeBusy(ctx, svc, ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		participants []*findTimeParticipant
+		seen         = make(map[string]bool)
+		pending      []*findTimeParticipant
+		cloudSvc     *cloudidentity.Service
+		cloudErr     error
+	)
+	add := func(email, via string) *findTimeParticipant {
+		key := strings.ToLower(email)
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		p := &findTimeParticipant{Email: email, Via: via}
+		participants = append(participants, p)
+		return p
+	}
+
+	for _, id := range ids {
+		if group, ok := resp.Groups[id]; ok && len(group.Calendars) > 0 && len(group.Errors) == 0 {
+			for _, member := range group.Calendars {
+				if p := add(member, id); p != nil {
+					fillFreeBusy(p, resp.Calendars[member])
+				}
+			}
+			continue
+		}
+
+		cal, ok := resp.Calendars[id]
+		_, isGroup := resp.Groups[id]
+		if !isGroup && ok && len(cal.Errors) == 0 {
+			if p := add(id, ""); p != nil {
+				fillFreeBusy(p, cal)
+			}
+			continue
+		}
+		if strings.EqualFold(id, account) {
+			if p := add(id, ""); p != nil {
+				fillFreeBusy(p, cal)
+			}
+			continue
+		}
+
+		// Not directly queryable: try to expand it as a Google Group.
+		if cloudSvc == nil && cloudErr == nil {
+			cloudSvc, cloudErr = newCloudIdentityService(ctx, account)
+		}
+		var members []string
+		if cloudErr == nil {
+			members, err = collectGroupMemberEmails(ctx, cloudSvc, id)
+		}
+		if cloudErr != nil || err != nil || len(members) == 0 {
+			if isGroup {
+				if cloudErr != nil {
+					return nil, wrapCloudIdentityError(cloudErr, account)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to list group members: %w", err)
+				}
+			}
+			if p := add(id, ""); p != nil {
+				fillFreeBusy(p, cal)
+				if p.Error == "" {
+					p.Error = "notFound"
+				}
+			}
+			continue
+		}
+		for _, member := range members {
+			if p := add(member, id); p != nil {
+				pending = append(pending, p)
+			}
+		}
+	}
+
+	if len(pending) > 0 {
+		emails := make([]string, len(pending))
+		for i, p := range pending {
+			emails[i] = p.Email
+		}
+		memberResp, err := queryFreeBusy(ctx, svc, emails, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pending {
+			fillFreeBusy(p, memberResp.Calendars[p.Email])
+			if _, ok := memberResp.Calendars[p.Email]; !ok {
+				p.Error = "notFound"
+			}
+		}
+	}
+
+	return participants, nil
+}
+
+// queryFreeBusy runs FreeBusy queries in batches and merges the responses.
+func queryFreeBusy(ctx context.Context, svc *calendar.Service, ids []string, from, to time.Time) (*calendar.FreeBusyResponse, error) {
+	merged := &calendar.FreeBusyResponse{
+		Calendars: make(map[string]calendar.FreeBusyCalendar),
+		Groups:    make(map[string]calendar.FreeBusyGroup),
+	}
+	for i := 0; i < len(ids); i += freeBusyBatchSize {
+		end := min(i+freeBusyBatchSize, len(ids))
+		items := make([]*calendar.FreeBusyRequestItem, 0, end-i)
+		for _, id := range ids[i:end] {
+			items = append(items, &calendar.FreeBusyRequestItem{Id: id})
+		}
+		resp, err := svc.Freebusy.Query(&calendar.FreeBusyRequest{
+			TimeMin: from.Format(time.RFC3339),
+			TimeMax: to.Format(time.RFC3339),
+			Items:   items,
+		}).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("freebusy query: %w", err)
+		}
+		for id, cal := range resp.Calendars {
+			merged.Calendars[id] = cal
+		}
+		for id, group := range resp.Groups {
+			merged.Groups[id] = group
+		}
+	}
+	return merged, nil
+}
+
+func fillFreeBusy(p *findTimeParticipant, cal calendar.FreeBusyCalendar) {
+	if len(cal.Errors) > 0 {
+		reasons := make([]string, 0, len(cal.Errors))
+		for _, e := range cal.Errors {
+			reasons = append(reasons, e.Reason)
+		}
+		p.Error = strings.Join(reasons, ", ")
+		return
+	}
+	for _, b := range cal.Busy {
+		start, err := time.Parse(time.RFC3339, b.Start)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse(time.RFC3339, b.End)
+		if err != nil {
+			continue
+		}
+		p.busy = append(p.busy, findTimeInterval{start: start, end: end})
+	}
+}
+
+// resolveParticipantTimezones looks up each attendee's calendar timezone so
+// working hours apply locally. Unreadable calendars fall back to yours, with
+// a warning so the working hours used for them are not a surprise.
+func resolveParticipantTimezones(ctx context.Context, u *ui.UI, svc *calendar.Service, account string, participants []*findTimeParticipant, fallback *time.Location) {
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, 10) // max 10 concurrent requests
+	)
+	for _, p := range participants {
+		p.loc = fallback
+		if p.Error != "" || strings.EqualFold(p.Email, account) {
+			continue
+		}
+		wg.Add(1)
+		go func(p *findTimeParticipant) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			cal, err := svc.Calendars.Get(p.Email).Context(ctx).Do()
+			switch {
+			case err != nil:
+				p.tzErr = err.Error()
+			case cal.TimeZone == "":
+				p.tzErr = "calendar has no timezone"
+			default:
+				loc, err := time.LoadLocation(cal.TimeZone)
+				if err != nil {
+					p.tzErr = err.Error()
+					return
+				}
+				p.loc = loc
+			}
+		}(p)
+	}
+	wg.Wait()
+	for _, p := range participants {
+		p.Timezone = p.loc.String()
+		if p.tzErr != "" {
+			u.Err().Printf("Warning: could not read the timezone of %s (%s); using %s", p.Email, p.tzErr, p.Timezone)
+		}
+	}
+}
+
+// findSlots returns the best non-overlapping free slots, ranked by score.
+func (spec *findTimeSpec) findSlots(participants []*findTimeParticipant, from, to time.Time, loc *time.Location) []findTimeSlot {
+	start := from.In(loc).Truncate(time.Minute)
+	dayAligned := startOfDay(start)
+	if offset := start.Sub(dayAligned) % spec.step; offset != 0 {
+		start = start.Add(spec.step - offset)
+	}
+
+	window := to.Sub(from)
+	var candidates []findTimeSlot
+	for s := start; !s.Add(spec.duration).After(to); s = s.Add(spec.step) {
+		e := s.Add(spec.duration)
+		comfort, ok := spec.slotFits(participants, s, e)
+		if !ok {
+			continue
+		}
+		buffer := slotBuffer(participants, s, e)
+
+		// Score (0-100): distance from meetings, distance from the edges of
+		// working hours, and earlier slots first.
+		score := 40*min(buffer, time.Hour)/time.Hour +
+			30*min(comfort, 2*time.Hour)/(2*time.Hour)
+		earliness := 30 - int(30*s.Sub(from)/window)
+		candidates = append(candidates, findTimeSlot{
+			Start:          s.Format(time.RFC3339),
+			End:            e.Format(time.RFC3339),
+			StartDayOfWeek: s.Weekday().String(),
+			Score:          int(score) + earliness,
+			start:          s,
+			end:            e,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].start.Before(candidates[j].start)
+	})
+
+	picked := make([]findTimeSlot, 0, spec.max)
+	for _, cand := range candidates {
+		overlaps := false
+		for _, p := range picked {
+			if cand.start.Before(p.end) && p.start.Before(cand.end) {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		picked = append(picked, cand)
+		if len(picked) == spec.max {
+			break
+		}
+	}
+	return picked
+}
+
+// slotFits reports whether every participant is free and within working hours
+// for [s, e). It also returns how far the slot sits from the nearest edge of
+// anyone's working day.
+func (spec *findTimeSpec) slotFits(participants []*findTimeParticipant, s, e time.Time) (time.Duration, bool) {
+	comfort := 24 * time.Hour
+	for _, p := range participants {
+		local := s.In(p.loc)
+		if !spec.weekends && (local.Weekday() == time.Saturday || local.Weekday() == time.Sunday) {
+			return 0, false
+		}
+		dayStart := atDayOffset(local, spec.workStart)
+		dayEnd := atDayOffset(local, spec.workEnd)
+		if s.Before(dayStart) || e.After(dayEnd) {
+			return 0, false
+		}
+		comfort = min(comfort, s.Sub(dayStart), dayEnd.Sub(e))
+		for _, b := range p.busy {
+			if s.Before(b.end) && b.start.Before(e) {
+				return 0, false
+			}
+		}
+	}
+	return comfort, true
+}
+
+// slotBuffer returns the smallest gap between the slot and any adjacent busy
+// block across participants.
+func slotBuffer(participants []*findTimeParticipant, s, e time.Time) time.Duration {
+	buffer := 24 * time.Hour
+	for _, p := range participants {
+		for _, b := range p.busy {
+			switch {
+			case !b.end.After(s):
+				buffer = min(buffer, s.Sub(b.end))
+			case !b.start.Before(e):
+				buffer = min(buffer, b.start.Sub(e))
+			}
+		}
+	}
+	return buffer
+}
+
+func (c *CalendarFindTimeCmd) createEvent(ctx context.Context, flags *RootFlags, u *ui.UI, account string, attendees []string, slot findTimeSlot) error {
+	invitees := make([]string, 0, len(attendees))
+	for _, a := range attendees {
+		if !strings.EqualFold(a, account) {
+			invitees = append(invitees, a)
+		}
+	}
+	if !outfmt.IsJSON(ctx) {
+		u.Err().Printf("Best slot: %s - %s", slot.start.Format("Mon Jan 2 15:04"), slot.end.Format("15:04 MST"))
+	}
+	create := &CalendarCreateCmd{
+		CalendarID:  c.CalendarID,
+		Summary:     c.Summary,
+		From:        slot.Start,
+		To:          slot.End,
+		Description: c.Description,
+		Location:    c.Location,
+		Attendees:   strings.Join(invitees, ","),
+		WithMeet:    c.WithMeet,
+		SendUpdates: c.SendUpdates,
+	}
+	return create.Run(ctx, flags)
+}
+
+var findTimeDaysPattern = regexp.MustCompile(`^(?:next\s+)?(\d+)\s*(?:d|days?)$`)
+
+// parseFindTimeWindow turns a --within expression into a time window.
+func parseFindTimeWindow(expr string, now time.Time, loc *time.Location, weekStart time.Weekday) (time.Time, time.Time, error) {
+	lower := strings.ToLower(strings.Join(strings.Fields(expr), " "))
+	switch lower {
+	case "", "week", "this week":
+		return now, endOfWeek(now, weekStart), nil
+	case "next week":
+		next := startOfWeek(now, weekStart).AddDate(0, 0, 7)
+		return next, endOfWeek(next, weekStart), nil
+	}
+	if m := findTimeDaysPattern.FindStringSubmatch(lower); m != nil {
+		days, err := strconv.Atoi(m[1])
+		if err != nil || days <= 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid day count %q", m[1])
+		}
+		return now, endOfDay(now.AddDate(0, 0, days-1)), nil
+	}
+
+	fromExpr, toExpr, isRange := strings.Cut(expr, "..")
+	if !isRange {
+		fromExpr, toExpr, isRange = strings.Cut(lower, " to ")
+	}
+	if isRange {
+		from, err := parseTimeExpr(strings.TrimSpace(fromExpr), now, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to, err := parseTimeExprEndOfDay(strings.TrimSpace(toExpr), now, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		return from, to, nil
+	}
+
+	if !isDateOnlyOrRelative(expr) {
+		return time.Time{}, time.Time{}, fmt.Errorf("unsupported window %q", expr)
+	}
+	day, err := parseTimeExpr(strings.TrimSpace(expr), now, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return startOfDay(day), endOfDay(day), nil
+}
+
+// parseWorkingHours parses "HH:MM-HH:MM" into offsets from midnight.
+func parseWorkingHours(value string) (time.Duration, time.Duration, error) {
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(value), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid --working-hours %q (use HH:MM-HH:MM)", value)
+	}
+	start, err := parseWorkingHoursClock(startStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid --working-hours %q: %w", value, err)
+	}
+	end, err := parseWorkingHoursClock(endStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid --working-hours %q: %w", value, err)
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("invalid --working-hours %q: end must be after start", value)
+	}
+	return start, end, nil
+}
+
+// parseWorkingHoursClock parses "HH:MM" into an offset from midnight.
+func parseWorkingHoursClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// atDayOffset returns the given wall-clock offset on t's day, in t's location.
+func atDayOffset(t time.Time, offset time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, t.Location())
+}
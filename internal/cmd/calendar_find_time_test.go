@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+func TestParseFindTimeWindow(t *testing.T) {
+	loc := time.UTC
+	now := time.Date(2030, 1, 9, 14, 30, 0, 0, loc) // Wednesday
+
+	tests := []struct {
+		expr     string
+		from, to string
+	}{
+		{"this week", "2030-01-09T14:30:00Z", "2030-01-13T23:59:59Z"},
+		{"next week", "2030-01-14T00:00:00Z", "2030-01-20T23:59:59Z"},
+		{"next 3 days", "2030-01-09T14:30:00Z", "2030-01-11T23:59:59Z"},
+		{"2d", "2030-01-09T14:30:00Z", "2030-01-10T23:59:59Z"},
+		{"tomorrow", "2030-01-10T00:00:00Z", "2030-01-10T23:59:59Z"},
+		{"friday", "2030-01-11T00:00:00Z", "2030-01-11T23:59:59Z"},
+		{"2030-01-14..2030-01-15", "2030-01-14T00:00:00Z", "2030-01-15T23:59:59Z"},
+		{"monday to tuesday", "2030-01-14T00:00:00Z", "2030-01-15T23:59:59Z"},
+	}
+	for _, tc := range tests {
+		from, to, err := parseFindTimeWindow(tc.expr, now, loc, time.Monday)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.expr, err)
+		}
+		if got := from.Format(time.RFC3339); got != tc.from {
+			t.Fatalf("%q: from = %s, want %s", tc.expr, got, tc.from)
+		}
+		if got := to.Format(time.RFC3339); got != tc.to {
+			t.Fatalf("%q: to = %s, want %s", tc.expr, got, tc.to)
+		}
+	}
+
+	if _, _, err := parseFindTimeWindow("sometime soon", now, loc, time.Monday); err == nil {
+		t.Fatalf("expected error for unsupported window")
+	}
+}
+
+func TestParseWorkingHours(t *testing.T) {
+	start, end, err := parseWorkingHours("08:30-17:15")
+	if err != nil {
+		t.Fatalf("parseWorkingHours: %v", err)
+	}
+	if start != 8*time.Hour+30*time.Minute || end != 17*time.Hour+15*time.Minute {
+		t.Fatalf("unexpected hours: %s-%s", start, end)
+	}
+	for _, bad := range []string{"9-5", "17:00-09:00", "09:00"} {
+		if _, _, err := parseWorkingHours(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestFindSlots_RespectsTimezonesAndBusy(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	day := time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC) // Monday
+	spec := &findTimeSpec{
+		duration:  time.Hour,
+		step:      30 * time.Minute,
+		workStart: 9 * time.Hour,
+		workEnd:   12 * time.Hour,
+		max:       5,
+	}
+	participants := []*findTimeParticipant{
+		{Email: "me@example.com", loc: time.UTC},
+		// 09:00-12:00 in Berlin is 08:00-11:00 UTC in January.
+		{Email: "bob@example.com", loc: berlin},
+		{Email: "alice@example.com", loc: time.UTC, busy: []findTimeInterval{
+			{start: day.Add(9 * time.Hour), end: day.Add(9*time.Hour + 30*time.Minute)},
+		}},
+	}
+
+	slots := spec.findSlots(participants, day, endOfDay(day), time.UTC)
+	if len(slots) != 1 {
+		t.Fatalf("expected 1 non-overlapping slot, got %+v", slots)
+	}
+	if slots[0].Start != "2030-01-07T10:00:00Z" || slots[0].End != "2030-01-07T11:00:00Z" {
+		t.Fatalf("unexpected slot: %+v", slots[0])
+	}
+
+	// Weekends are skipped unless allowed.
+	saturday := day.AddDate(0, 0, 5)
+	if got := spec.findSlots(participants[:1], saturday, endOfDay(saturday), time.UTC); len(got) != 0 {
+		t.Fatalf("expected no weekend slots, got %+v", got)
+	}
+	spec.weekends = true
+	if got := spec.findSlots(participants[:1], saturday, endOfDay(saturday), time.UTC); len(got) == 0 {
+		t.Fatalf("expected weekend slots with weekends allowed")
+	}
+}
+
+func newFindTimeTestServices(t *testing.T, onInsert func(*calendar.Event)) {
+	t.Helper()
+
+	origCalSvc := newCalendarService
+	origCloudSvc := newCloudIdentityService
+	t.Cleanup(func() {
+		newCalendarService = origCalSvc
+		newCloudIdentityService = origCloudSvc
+	})
+
+	cloudSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "groups:lookup"):
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "groups/eng"})
+		case strings.Contains(r.URL.Path, "groups/eng/memberships"):
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"memberships": []map[string]any{
+					{"preferredMemberKey": map[string]any{"id": "alice@example.com"}, "type": "USER"},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(cloudSrv.Close)
+
+	cloudSvc, err := cloudidentity.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(cloudSrv.Client()),
+		option.WithEndpoint(cloudSrv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService (cloud): %v", err)
+	}
+	newCloudIdentityService = func(context.Context, string) (*cloudidentity.Service, error) { return cloudSvc, nil }
+
+	calSvc, closeCal := newCalendarTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "freeBusy"):
+			var req calendar.FreeBusyRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			calendars := map[string]any{}
+			for _, item := range req.Items {
+				switch item.Id {
+				case "eng@example.com":
+					calendars[item.Id] = map[string]any{"errors": []map[string]any{{"domain": "global", "reason": "notFound"}}}
+				case "alice@example.com":
+					calendars[item.Id] = map[string]any{"busy": []map[string]any{
+						{"start": "2030-01-07T09:00:00Z", "end": "2030-01-07T09:30:00Z"},
+					}}
+				default:
+					calendars[item.Id] = map[string]any{"busy": []map[string]any{}}
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"calendars": calendars})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/calendars/bob@example.com"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "bob@example.com", "timeZone": "Europe/Berlin"})
+		case r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/calendars/primary/events"):
+			body, _ := io.ReadAll(r.Body)
+			var ev calendar.Event
+			_ = json.Unmarshal(body, &ev)
+			if onInsert != nil {
+				onInsert(&ev)
+			}
+			ev.Id = "ev1"
+			_ = json.NewEncoder(w).Encode(ev)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(closeCal)
+	newCalendarService = stubCalendarService(calSvc)
+}
+
+func TestExecute_CalendarFindTime_JSON(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	newFindTimeTestServices(t, nil)
+
+	var stderr string
+	out := captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := Execute([]string{
+				"--json",
+				"--account", "a@b.com",
+				"calendar", "find-time",
+				"--attendees", "bob@example.com,eng@example.com",
+				"--duration", "1h",
+				"--within", "2030-01-07..2030-01-07",
+				"--working-hours", "09:00-12:00",
+			}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	var parsed struct {
+		Duration  string `json:"duration"`
+		Attendees []struct {
+			Email    string `json:"email"`
+			Via      string `json:"via"`
+			Timezone string `json:"timezone"`
+		} `json:"attendees"`
+		Slots []struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+		} `json:"slots"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if parsed.Duration != "1h0m0s" {
+		t.Fatalf("unexpected duration: %q", parsed.Duration)
+	}
+	if len(parsed.Attendees) != 3 {
+		t.Fatalf("expected self, bob and alice, got %+v", parsed.Attendees)
+	}
+	if parsed.Attendees[1].Timezone != "Europe/Berlin" {
+		t.Fatalf("expected bob in Europe/Berlin, got %+v", parsed.Attendees[1])
+	}
+	if parsed.Attendees[2].Email != "alice@example.com" || parsed.Attendees[2].Via != "eng@example.com" {
+		t.Fatalf("expected alice via group, got %+v", parsed.Attendees[2])
+	}
+	if len(parsed.Slots) != 1 || parsed.Slots[0].Start != "2030-01-07T10:00:00Z" {
+		t.Fatalf("unexpected slots: %+v", parsed.Slots)
+	}
+	if !strings.Contains(stderr, "could not read the timezone of alice@example.com") || !strings.Contains(stderr, "using UTC") {
+		t.Fatalf("expected timezone fallback warning for alice, got %q", stderr)
+	}
+}
+
+func TestExecute_CalendarFindTime_Create(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	var inserted *calendar.Event
+	newFindTimeTestServices(t, func(ev *calendar.Event) { inserted = ev })
+
+	_ = captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{
+				"--account", "a@b.com",
+				"calendar", "find-time",
+				"--attendees", "bob@example.com,eng@example.com",
+				"--duration", "1h",
+				"--within", "2030-01-07..2030-01-07",
+				"--working-hours", "09:00-12:00",
+				"--create", "--summary", "Planning",
+			}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+
+	if inserted == nil {
+		t.Fatalf("expected an event to be created")
+	}
+	if inserted.Summary != "Planning" || inserted.Start == nil || inserted.Start.DateTime != "2030-01-07T10:00:00Z" {
+		t.Fatalf("unexpected event: %+v", inserted)
+	}
+	if len(inserted.Attendees) != 2 || inserted.Attendees[1].Email != "eng@example.com" {
+		t.Fatalf("expected the group to be invited as-is, got %+v", inserted.Attendees)
+	}
+}
@@ -0,0 +1,951 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/ics"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const icsProdID = "-//gogcli//gog calendar export//EN"
+
+type CalendarExportCmd struct {
+	CalendarID string `arg:"" name:"calendarId" help:"Calendar ID"`
+	Out        string `name:"out" aliases:"output" help:"Write to this file instead of stdout"`
+	Query      string `name:"query" short:"q" help:"Only export events matching this text"`
+	TimeRangeFlags
+}
+
+func (c *CalendarExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("empty calendarId")
+	}
+	outPath := strings.TrimSpace(c.Out)
+	if outPath != "" {
+		if outPath, err = config.ExpandPath(outPath); err != nil {
+			return err
+		}
+	}
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+	tr, err := ResolveTimeRange(ctx, svc, c.TimeRangeFlags)
+	if err != nil {
+		return err
+	}
+	from, to := tr.FormatRFC3339()
+
+	// Recurring events are exported as their series (RRULE) rather than
+	// expanded instances; deleted instances are needed to emit EXDATEs.
+	fetch := func(pageToken string) ([]*calendar.Event, string, error) {
+		call := svc.Events.List(calendarID).
+			TimeMin(from).
+			TimeMax(to).
+			SingleEvents(false).
+			ShowDeleted(true).
+			MaxResults(2500)
+		if strings.TrimSpace(pageToken) != "" {
+			call = call.PageToken(pageToken)
+		}
+		if strings.TrimSpace(c.Query) != "" {
+			call = call.Q(c.Query)
+		}
+		resp, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Items, resp.NextPageToken, nil
+	}
+	events, err := collectAllPages("", fetch)
+	if err != nil {
+		return err
+	}
+
+	cal := ics.NewComponent("VCALENDAR")
+	cal.Add("PRODID", icsProdID)
+	cal.Add("VERSION", "2.0")
+	cal.Add("CALSCALE", "GREGORIAN")
+	cal.Add("METHOD", "PUBLISH")
+	if entry, listErr := svc.CalendarList.Get(calendarID).Context(ctx).Do(); listErr == nil {
+		cal.AddText("X-WR-CALNAME", entry.Summary)
+		cal.AddText("X-WR-TIMEZONE", entry.TimeZone)
+	}
+	count := appendVEvents(cal, events, time.Now())
+
+	var buf bytes.Buffer
+	if err := ics.Encode(&buf, cal); err != nil {
+		return err
+	}
+	if outPath == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	tmp := outPath + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, outPath); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"path": outPath, "events": count})
+	}
+	u.Out().Printf("path\t%s", outPath)
+	u.Out().Printf("events\t%d", count)
+	return nil
+}
+
+// appendVEvents converts events to VEVENTs. Cancelled instances of a series
+// become EXDATEs on the series; other cancelled events are skipped.
+func appendVEvents(cal *ics.Component, events []*calendar.Event, now time.Time) int {
+	exdates := map[string][]*calendar.EventDateTime{}
+	for _, ev := range events {
+		if ev != nil && ev.Status == "cancelled" && ev.RecurringEventId != "" && ev.OriginalStartTime != nil {
+			exdates[ev.RecurringEventId] = append(exdates[ev.RecurringEventId], ev.OriginalStartTime)
+		}
+	}
+
+	count := 0
+	for _, ev := range events {
+		if ev == nil || ev.Status == "cancelled" {
+			continue
+		}
+		cal.Children = append(cal.Children, eventToVEvent(ev, exdates[ev.Id], now))
+		count++
+	}
+	return count
+}
+
+func eventToVEvent(ev *calendar.Event, exdates []*calendar.EventDateTime, now time.Time) *ics.Component {
+	vev := ics.NewComponent("VEVENT")
+	uid := ev.ICalUID
+	if uid == "" {
+		uid = ev.Id + "@google.com"
+	}
+	vev.AddText("UID", uid)
+
+	stamp := now
+	if updated, err := time.Parse(time.RFC3339, ev.Updated); err == nil {
+		stamp = updated
+	}
+	vev.Add("DTSTAMP", ics.FormatUTC(stamp))
+	if created, err := time.Parse(time.RFC3339, ev.Created); err == nil {
+		vev.Add("CREATED", ics.FormatUTC(created))
+	}
+	if ev.Updated != "" {
+		vev.Add("LAST-MODIFIED", ics.FormatUTC(stamp))
+	}
+	if ev.Sequence > 0 {
+		vev.Add("SEQUENCE", fmt.Sprint(ev.Sequence))
+	}
+
+	addICSDateTime(vev, "DTSTART", ev.Start)
+	addICSDateTime(vev, "DTEND", ev.End)
+	if ev.RecurringEventId != "" {
+		addICSDateTime(vev, "RECURRENCE-ID", ev.OriginalStartTime)
+	}
+	for _, rule := range ev.Recurrence {
+		if prop, err := ics.ParseProperty(strings.TrimSpace(rule)); err == nil {
+			vev.Properties = append(vev.Properties, prop)
+		}
+	}
+	for _, ex := range exdates {
+		addICSDateTime(vev, "EXDATE", ex)
+	}
+
+	vev.AddText("SUMMARY", ev.Summary)
+	vev.AddText("DESCRIPTION", ev.Description)
+	vev.AddText("LOCATION", ev.Location)
+	if ev.Status != "" {
+		vev.Add("STATUS", strings.ToUpper(ev.Status))
+	}
+	if ev.Transparency == "transparent" {
+		vev.Add("TRANSP", "TRANSPARENT")
+	} else {
+		vev.Add("TRANSP", "OPAQUE")
+	}
+	switch ev.Visibility {
+	case "private", "public", "confidential":
+		vev.Add("CLASS", strings.ToUpper(ev.Visibility))
+	}
+	if ev.Source != nil && ev.Source.Url != "" {
+		vev.Add("URL", ev.Source.Url)
+	}
+
+	if ev.Organizer != nil && ev.Organizer.Email != "" {
+		var params []ics.Param
+		if ev.Organizer.DisplayName != "" {
+			params = append(params, ics.Param{Name: "CN", Value: ev.Organizer.DisplayName})
+		}
+		vev.Add("ORGANIZER", "mailto:"+ev.Organizer.Email, params...)
+	}
+	for _, a := range ev.Attendees {
+		if a == nil || a.Email == "" {
+			continue
+		}
+		vev.Properties = append(vev.Properties, attendeeToICS(a))
+	}
+
+	if link := eventConferenceLink(ev); link != "" {
+		label := "Google Meet"
+		if ev.ConferenceData != nil && ev.ConferenceData.ConferenceSolution != nil && ev.ConferenceData.ConferenceSolution.Name != "" {
+			label = ev.ConferenceData.ConferenceSolution.Name
+		}
+		vev.Add("CONFERENCE", link,
+			ics.Param{Name: "VALUE", Value: "URI"},
+			ics.Param{Name: "FEATURE", Value: "VIDEO"},
+			ics.Param{Name: "LABEL", Value: label},
+		)
+		vev.Add("X-GOOGLE-CONFERENCE", link)
+	}
+	if ev.ConferenceData != nil {
+		for _, ep := range ev.ConferenceData.EntryPoints {
+			if ep != nil && ep.EntryPointType == "phone" && ep.Uri != "" {
+				vev.Add("CONFERENCE", ep.Uri,
+					ics.Param{Name: "VALUE", Value: "URI"},
+					ics.Param{Name: "FEATURE", Value: "PHONE"},
+				)
+			}
+		}
+	}
+
+	for _, att := range ev.Attachments {
+		if att == nil || att.FileUrl == "" {
+			continue
+		}
+		var params []ics.Param
+		if att.MimeType != "" {
+			params = append(params, ics.Param{Name: "FMTTYPE", Value: att.MimeType})
+		}
+		vev.Add("ATTACH", att.FileUrl, params...)
+	}
+
+	if ev.Reminders != nil && !ev.Reminders.UseDefault {
+		for _, r := range ev.Reminders.Overrides {
+			if r == nil {
+				continue
+			}
+			alarm := ics.NewComponent("VALARM")
+			if r.Method == "email" {
+				alarm.Add("ACTION", "EMAIL")
+				alarm.AddText("SUMMARY", ev.Summary)
+			} else {
+				alarm.Add("ACTION", "DISPLAY")
+			}
+			alarm.Add("TRIGGER", ics.FormatDuration(-time.Duration(r.Minutes)*time.Minute))
+			alarm.AddText("DESCRIPTION", "Reminder")
+			vev.Children = append(vev.Children, alarm)
+		}
+	}
+	return vev
+}
+
+// addICSDateTime writes a DATE for all-day values, a local time with TZID
+// when the event has a known IANA timezone (needed for recurrence across
+// DST changes), and UTC otherwise.
+func addICSDateTime(vev *ics.Component, name string, edt *calendar.EventDateTime) {
+	if edt == nil {
+		return
+	}
+	if edt.Date != "" {
+		if d, err := time.Parse("2006-01-02", edt.Date); err == nil {
+			vev.Add(name, ics.FormatDate(d), ics.Param{Name: "VALUE", Value: "DATE"})
+		}
+		return
+	}
+	t, err := time.Parse(time.RFC3339, edt.DateTime)
+	if err != nil {
+		return
+	}
+	if edt.TimeZone != "" && edt.TimeZone != tzUTC {
+		if loc, err := time.LoadLocation(edt.TimeZone); err == nil {
+			vev.Add(name, ics.FormatLocal(t.In(loc)), ics.Param{Name: "TZID", Value: edt.TimeZone})
+			return
+		}
+	}
+	vev.Add(name, ics.FormatUTC(t))
+}
+
+func attendeeToICS(a *calendar.EventAttendee) *ics.Property {
+	var params []ics.Param
+	if a.DisplayName != "" {
+		params = append(params, ics.Param{Name: "CN", Value: a.DisplayName})
+	}
+	if a.Resource {
+		params = append(params, ics.Param{Name: "CUTYPE", Value: "RESOURCE"})
+	}
+	role := "REQ-PARTICIPANT"
+	if a.Optional {
+		role = "OPT-PARTICIPANT"
+	}
+	params = append(params, ics.Param{Name: "ROLE", Value: role})
+	params = append(params, ics.Param{Name: "PARTSTAT", Value: icsPartStat(a.ResponseStatus)})
+	if a.ResponseStatus == "" || a.ResponseStatus == "needsAction" {
+		params = append(params, ics.Param{Name: "RSVP", Value: "TRUE"})
+	}
+	return &ics.Property{Name: "ATTENDEE", Params: params, Value: "mailto:" + a.Email}
+}
+
+func icsPartStat(status string) string {
+	switch status {
+	case "accepted":
+		return "ACCEPTED"
+	case "declined":
+		return "DECLINED"
+	case "tentative":
+		return "TENTATIVE"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+func responseStatusFromPartStat(partStat string) string {
+	switch strings.ToUpper(partStat) {
+	case "ACCEPTED":
+		return "accepted"
+	case "DECLINED":
+		return "declined"
+	case "TENTATIVE":
+		return "tentative"
+	default:
+		return "needsAction"
+	}
+}
+
+// eventConferenceLink returns the video link of the event's conference.
+func eventConferenceLink(ev *calendar.Event) string {
+	if ev.ConferenceData != nil {
+		for _, ep := range ev.ConferenceData.EntryPoints {
+			if ep != nil && ep.EntryPointType == "video" && ep.Uri != "" {
+				return ep.Uri
+			}
+		}
+	}
+	return ev.HangoutLink
+}
+
+type CalendarImportCmd struct {
+	CalendarID string `arg:"" name:"calendarId" help:"Calendar ID"`
+	File       string `arg:"" name:"file" help:"iCalendar (.ics) file ('-' for stdin)"`
+}
+
+type calendarImportItem struct {
+	Action  string `json:"action"`
+	UID     string `json:"uid"`
+	Start   string `json:"start,omitempty"`
+	Summary string `json:"summary,omitempty"`
+	EventID string `json:"eventId,omitempty"`
+	Error   string `json:"error,omitempty"`
+	event   *calendar.Event
+	master  *calendarImportItem
+}
+
+const (
+	calendarImportCreate    = "create"
+	calendarImportUpdate    = "update"
+	calendarImportUnchanged = "unchanged"
+	calendarImportCancel    = "cancel"
+)
+
+func (c *CalendarImportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	calendarID := strings.TrimSpace(c.CalendarID)
+	if calendarID == "" {
+		return usage("empty calendarId")
+	}
+	roots, err := readICSFile(c.File)
+	if err != nil {
+		return err
+	}
+	dryRun := flags != nil && flags.DryRun
+
+	svc, err := newCalendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err = resolveCalendarID(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+	loc, err := getUserTimezone(ctx, svc)
+	if err != nil {
+		return err
+	}
+
+	items, err := icsImportItems(roots, loc)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return usage("no VEVENT components in " + c.File)
+	}
+
+	masters := map[string]*calendarImportItem{}
+	for _, item := range items {
+		if item.event.OriginalStartTime == nil {
+			masters[item.UID] = item
+		}
+	}
+	for _, item := range items {
+		if item.event.OriginalStartTime != nil {
+			item.master = masters[item.UID]
+		}
+	}
+
+	// Series first, so that modified instances can find them.
+	for _, item := range items {
+		if item.event.OriginalStartTime != nil {
+			continue
+		}
+		if err := importICSEvent(ctx, svc, calendarID, item, dryRun); err != nil {
+			item.Error = err.Error()
+		}
+	}
+	for _, item := range items {
+		if item.event.OriginalStartTime == nil {
+			continue
+		}
+		if err := importICSInstance(ctx, svc, calendarID, item, dryRun); err != nil {
+			item.Error = err.Error()
+		}
+	}
+
+	return writeCalendarImportPlan(ctx, u, dryRun, items)
+}
+
+func readICSFile(path string) ([]*ics.Component, error) {
+	path = strings.TrimSpace(path)
+	var (
+		r   io.Reader
+		err error
+	)
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		if path, err = config.ExpandPath(path); err != nil {
+			return nil, err
+		}
+		f, openErr := os.Open(path) //nolint:gosec // user-provided path
+		if openErr != nil {
+			return nil, openErr
+		}
+		defer f.Close()
+		r = f
+	}
+	roots, err := ics.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return roots, nil
+}
+
+// icsImportItems converts every VEVENT into an event to import. Floating
+// times use the calendar's X-WR-TIMEZONE, falling back to loc.
+func icsImportItems(roots []*ics.Component, loc *time.Location) ([]*calendarImportItem, error) {
+	var items []*calendarImportItem
+	for _, root := range roots {
+		calLoc := loc
+		if tz := root.Text("X-WR-TIMEZONE"); tz != "" {
+			if l, err := time.LoadLocation(tz); err == nil {
+				calLoc = l
+			}
+		}
+		for _, vev := range root.Components("VEVENT") {
+			ev, err := vEventToEvent(vev, calLoc)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, &calendarImportItem{
+				UID:     ev.ICalUID,
+				Start:   eventDateTimeString(ev.Start),
+				Summary: ev.Summary,
+				event:   ev,
+			})
+		}
+	}
+	return items, nil
+}
+
+func vEventToEvent(vev *ics.Component, loc *time.Location) (*calendar.Event, error) {
+	uid := vev.Text("UID")
+	if uid == "" {
+		return nil, fmt.Errorf("VEVENT %q has no UID", vev.Text("SUMMARY"))
+	}
+	ev := &calendar.Event{
+		ICalUID:     uid,
+		Sequence:    icsSequence(vev),
+		Summary:     vev.Text("SUMMARY"),
+		Description: vev.Text("DESCRIPTION"),
+		Location:    vev.Text("LOCATION"),
+	}
+
+	startProp := vev.Get("DTSTART")
+	if startProp == nil {
+		return nil, fmt.Errorf("VEVENT %s has no DTSTART", uid)
+	}
+	start, startTZ, allDay, err := icsEventDateTime(startProp, loc)
+	if err != nil {
+		return nil, fmt.Errorf("VEVENT %s: DTSTART: %w", uid, err)
+	}
+	ev.Start = icsToEventDateTime(start, startTZ, allDay)
+
+	var end time.Time
+	switch {
+	case vev.Get("DTEND") != nil:
+		var endTZ string
+		end, endTZ, _, err = icsEventDateTime(vev.Get("DTEND"), loc)
+		if err != nil {
+			return nil, fmt.Errorf("VEVENT %s: DTEND: %w", uid, err)
+		}
+		ev.End = icsToEventDateTime(end, endTZ, allDay)
+	case vev.Get("DURATION") != nil:
+		d, durErr := ics.ParseDuration(vev.Get("DURATION").Value)
+		if durErr != nil {
+			return nil, fmt.Errorf("VEVENT %s: %w", uid, durErr)
+		}
+		ev.End = icsToEventDateTime(start.Add(d), startTZ, allDay)
+	case allDay:
+		ev.End = icsToEventDateTime(start.AddDate(0, 0, 1), startTZ, true)
+	default:
+		ev.End = icsToEventDateTime(start, startTZ, false)
+	}
+
+	if rid := vev.Get("RECURRENCE-ID"); rid != nil {
+		t, tz, dateOnly, ridErr := icsEventDateTime(rid, loc)
+		if ridErr != nil {
+			return nil, fmt.Errorf("VEVENT %s: RECURRENCE-ID: %w", uid, ridErr)
+		}
+		ev.OriginalStartTime = icsToEventDateTime(t, tz, dateOnly)
+	}
+	var recurrence []string
+	for _, p := range vev.Properties {
+		switch p.Name {
+		case "RRULE", "EXRULE", "RDATE", "EXDATE":
+			recurrence = append(recurrence, p.String())
+		}
+	}
+	ev.Recurrence = buildRecurrence(recurrence)
+
+	switch status := strings.ToLower(vev.Text("STATUS")); status {
+	case "confirmed", "tentative", "cancelled":
+		ev.Status = status
+	}
+	if strings.EqualFold(vev.Text("TRANSP"), "TRANSPARENT") {
+		ev.Transparency = "transparent"
+	}
+	switch class := strings.ToLower(vev.Text("CLASS")); class {
+	case "private", "public", "confidential":
+		ev.Visibility = class
+	}
+
+	if org := vev.Get("ORGANIZER"); org != nil {
+		ev.Organizer = &calendar.EventOrganizer{
+			Email:       icsMailto(org.Value),
+			DisplayName: org.Param("CN"),
+		}
+	}
+	for _, p := range vev.GetAll("ATTENDEE") {
+		email := icsMailto(p.Value)
+		if email == "" {
+			continue
+		}
+		cutype := strings.ToUpper(p.Param("CUTYPE"))
+		ev.Attendees = append(ev.Attendees, &calendar.EventAttendee{
+			Email:          email,
+			DisplayName:    p.Param("CN"),
+			Optional:       strings.EqualFold(p.Param("ROLE"), "OPT-PARTICIPANT"),
+			Resource:       cutype == "RESOURCE" || cutype == "ROOM",
+			ResponseStatus: responseStatusFromPartStat(p.Param("PARTSTAT")),
+		})
+	}
+
+	// A conference link cannot be attached to an imported event, so keep it
+	// reachable from the description.
+	link := vev.Text("X-GOOGLE-CONFERENCE")
+	if link == "" {
+		for _, p := range vev.GetAll("CONFERENCE") {
+			if strings.HasPrefix(strings.ToLower(p.Value), "http") {
+				link = p.Value
+				break
+			}
+		}
+	}
+	if link != "" && !strings.Contains(ev.Description, link) && !strings.Contains(ev.Location, link) {
+		ev.Description = strings.TrimSpace(ev.Description + "\n\nJoin: " + link)
+	}
+
+	if ev.Reminders, err = buildReminders(icsReminders(vev)); err != nil {
+		return nil, fmt.Errorf("VEVENT %s: %w", uid, err)
+	}
+	return ev, nil
+}
+
+// icsEventDateTime parses a DATE/DATE-TIME property and returns the IANA
+// timezone to store with it.
+func icsEventDateTime(p *ics.Property, loc *time.Location) (time.Time, string, bool, error) {
+	t, dateOnly, err := p.Time(loc)
+	if err != nil {
+		return t, "", dateOnly, err
+	}
+	if dateOnly {
+		return t, "", true, nil
+	}
+	return t, t.Location().String(), false, nil
+}
+
+// icsToEventDateTime builds the start or end like --from/--to do, keeping
+// the TZID from the file rather than a zone guessed from the offset.
+func icsToEventDateTime(t time.Time, tz string, allDay bool) *calendar.EventDateTime {
+	if allDay {
+		return buildEventDateTime(t.Format("2006-01-02"), true)
+	}
+	if tz == "" || tz == "Local" {
+		t = t.UTC()
+	}
+	edt := buildEventDateTime(t.Format(time.RFC3339), false)
+	if tz != "" && tz != "Local" {
+		edt.TimeZone = tz
+	}
+	return edt
+}
+
+func icsSequence(vev *ics.Component) int64 {
+	seq, err := strconv.ParseInt(strings.TrimSpace(vev.Text("SEQUENCE")), 10, 64)
+	if err != nil || seq < 0 {
+		return 0
+	}
+	return seq
+}
+
+func icsMailto(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > 7 && strings.EqualFold(value[:7], "mailto:") {
+		value = value[7:]
+	}
+	if !strings.Contains(value, "@") {
+		return ""
+	}
+	return value
+}
+
+// icsReminders converts VALARMs with a relative trigger into --reminder
+// specs (method:duration). Absolute triggers, alarms after the start and
+// alarms further ahead than Calendar allows are ignored.
+func icsReminders(vev *ics.Component) []string {
+	var reminders []string
+	for _, alarm := range vev.Components("VALARM") {
+		trigger := alarm.Get("TRIGGER")
+		if trigger == nil || strings.EqualFold(trigger.Param("VALUE"), "DATE-TIME") ||
+			strings.EqualFold(trigger.Param("RELATED"), "END") {
+			continue
+		}
+		d, err := ics.ParseDuration(trigger.Value)
+		minutes := int64(-d / time.Minute)
+		if err != nil || d > 0 || minutes > 40320 {
+			continue
+		}
+		method := "popup"
+		if strings.EqualFold(alarm.Text("ACTION"), "EMAIL") {
+			method = "email"
+		}
+		reminders = append(reminders, fmt.Sprintf("%s:%dm", method, minutes))
+		if len(reminders) == 5 {
+			break
+		}
+	}
+	return reminders
+}
+
+// importICSEvent creates or updates a single event or series, matched by
+// iCalUID so re-importing the same file does not duplicate it.
+func importICSEvent(ctx context.Context, svc *calendar.Service, calendarID string, item *calendarImportItem, dryRun bool) error {
+	existing, err := findEventByICalUID(ctx, svc, calendarID, item.UID)
+	if err != nil {
+		return err
+	}
+
+	if item.event.Status == "cancelled" {
+		if existing == nil || existing.Status == "cancelled" {
+			item.Action = calendarImportUnchanged
+			return nil
+		}
+		item.Action = calendarImportCancel
+		item.EventID = existing.Id
+		if dryRun {
+			return nil
+		}
+		return svc.Events.Delete(calendarID, existing.Id).Context(ctx).Do()
+	}
+
+	switch {
+	case existing == nil:
+		item.Action = calendarImportCreate
+	case existing.Status != "cancelled" && icsEventUnchanged(existing, item.event):
+		item.Action = calendarImportUnchanged
+		item.EventID = existing.Id
+		return nil
+	default:
+		item.Action = calendarImportUpdate
+		item.EventID = existing.Id
+	}
+	if dryRun {
+		return nil
+	}
+
+	if existing == nil {
+		created, err := svc.Events.Import(calendarID, item.event).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		item.EventID = created.Id
+		return nil
+	}
+
+	item.event.Id = existing.Id
+	// The live sequence may be ahead of the file's; let the API bump it.
+	item.event.Sequence = 0
+	if item.event.Status == "" {
+		item.event.Status = "confirmed"
+	}
+	_, err = svc.Events.Update(calendarID, existing.Id, item.event).Context(ctx).Do()
+	return err
+}
+
+// importICSInstance applies a modified (RECURRENCE-ID) occurrence to the
+// matching instance of its series.
+func importICSInstance(ctx context.Context, svc *calendar.Service, calendarID string, item *calendarImportItem, dryRun bool) error {
+	cancel := item.event.Status == "cancelled"
+	item.Action = calendarImportUpdate
+	if cancel {
+		item.Action = calendarImportCancel
+	}
+
+	masterID := ""
+	if item.master != nil {
+		masterID = item.master.EventID
+	}
+	if masterID == "" {
+		if dryRun && item.master != nil && item.master.Action == calendarImportCreate {
+			return nil
+		}
+		master, err := findEventByICalUID(ctx, svc, calendarID, item.UID)
+		if err != nil {
+			return err
+		}
+		if master == nil {
+			return fmt.Errorf("recurring event %s not found", item.UID)
+		}
+		masterID = master.Id
+	}
+
+	originalStart := eventDateTimeString(item.event.OriginalStartTime)
+	instanceID, err := resolveRecurringInstanceID(ctx, svc, calendarID, masterID, originalStart)
+	if err != nil {
+		if cancel {
+			// Already cancelled (or never existed): nothing to do.
+			item.Action = calendarImportUnchanged
+			return nil
+		}
+		return err
+	}
+	item.EventID = instanceID
+	if !cancel {
+		current, getErr := svc.Events.Get(calendarID, instanceID).Context(ctx).Do()
+		if getErr == nil && icsEventUnchanged(current, item.event) {
+			item.Action = calendarImportUnchanged
+			return nil
+		}
+	}
+	if dryRun {
+		return nil
+	}
+	if cancel {
+		return svc.Events.Delete(calendarID, instanceID).Context(ctx).Do()
+	}
+
+	ev := item.event
+	ev.Id = instanceID
+	ev.RecurringEventId = masterID
+	ev.ICalUID = ""
+	ev.Recurrence = nil
+	ev.Sequence = 0
+	_, err = svc.Events.Update(calendarID, instanceID, ev).Context(ctx).Do()
+	return err
+}
+
+// findEventByICalUID returns the series or single event with the given
+// iCalUID, including deleted ones, or nil.
+func findEventByICalUID(ctx context.Context, svc *calendar.Service, calendarID, uid string) (*calendar.Event, error) {
+	resp, err := svc.Events.List(calendarID).ICalUID(uid).ShowDeleted(true).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("look up %s: %w", uid, err)
+	}
+	var found *calendar.Event
+	for _, ev := range resp.Items {
+		if ev == nil || ev.RecurringEventId != "" {
+			continue
+		}
+		if found == nil || (found.Status == "cancelled" && ev.Status != "cancelled") {
+			found = ev
+		}
+	}
+	return found, nil
+}
+
+// icsEventUnchanged compares the fields an iCalendar file carries.
+func icsEventUnchanged(live, want *calendar.Event) bool {
+	if live.Summary != want.Summary ||
+		strings.TrimSpace(live.Description) != strings.TrimSpace(want.Description) ||
+		live.Location != want.Location {
+		return false
+	}
+	if !sameEventDateTime(live.Start, want.Start) || !sameEventDateTime(live.End, want.End) {
+		return false
+	}
+	if strings.Join(live.Recurrence, "\n") != strings.Join(want.Recurrence, "\n") {
+		return false
+	}
+	if defaultString(want.Status, "confirmed") != defaultString(live.Status, "confirmed") ||
+		defaultString(want.Transparency, "opaque") != defaultString(live.Transparency, "opaque") ||
+		defaultString(want.Visibility, "default") != defaultString(live.Visibility, "default") {
+		return false
+	}
+	if !slices.Equal(attendeeEmails(live.Attendees), attendeeEmails(want.Attendees)) {
+		return false
+	}
+	if want.Reminders != nil {
+		if live.Reminders == nil || live.Reminders.UseDefault {
+			return false
+		}
+		if !slices.Equal(reminderKeys(live.Reminders.Overrides), reminderKeys(want.Reminders.Overrides)) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameEventDateTime(a, b *calendar.EventDateTime) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Date != "" || b.Date != "" {
+		return a.Date == b.Date
+	}
+	ta, errA := time.Parse(time.RFC3339, a.DateTime)
+	tb, errB := time.Parse(time.RFC3339, b.DateTime)
+	return errA == nil && errB == nil && ta.Equal(tb)
+}
+
+func defaultString(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func attendeeEmails(attendees []*calendar.EventAttendee) []string {
+	out := make([]string, 0, len(attendees))
+	for _, a := range attendees {
+		if a != nil && a.Email != "" {
+			out = append(out, strings.ToLower(a.Email))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func reminderKeys(reminders []*calendar.EventReminder) []string {
+	out := make([]string, 0, len(reminders))
+	for _, r := range reminders {
+		if r != nil {
+			out = append(out, fmt.Sprintf("%s:%d", r.Method, r.Minutes))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func eventDateTimeString(edt *calendar.EventDateTime) string {
+	if edt == nil {
+		return ""
+	}
+	if edt.Date != "" {
+		return edt.Date
+	}
+	return edt.DateTime
+}
+
+func writeCalendarImportPlan(ctx context.Context, u *ui.UI, dryRun bool, items []*calendarImportItem) error {
+	counts := map[string]int{}
+	failed := 0
+	for _, item := range items {
+		counts[item.Action]++
+		if item.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		counts["failed"] = failed
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"dry_run": dryRun,
+			"op":      "calendar.import",
+			"events":  items,
+			"summary": counts,
+		}); err != nil {
+			return err
+		}
+	} else {
+		if dryRun {
+			u.Err().Printf("Plan: %d to create, %d to update, %d to cancel, %d unchanged",
+				counts[calendarImportCreate], counts[calendarImportUpdate], counts[calendarImportCancel], counts[calendarImportUnchanged])
+		}
+		w, flush := tableWriter(ctx)
+		fmt.Fprintln(w, "ACTION\tSTART\tSUMMARY\tUID")
+		for _, item := range items {
+			action := item.Action
+			if item.Error != "" {
+				action = "error: " + item.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", sanitizeTab(action), item.Start, sanitizeTab(item.Summary), sanitizeTab(item.UID))
+		}
+		flush()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d events failed to import", failed, len(items))
+	}
+	return nil
+}
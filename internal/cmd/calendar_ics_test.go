@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/ics"
+)
+
+func sampleICSSeries() *calendar.Event {
+	return &calendar.Event{
+		Id:          "series1",
+		ICalUID:     "series1@google.com",
+		Summary:     "Weekly sync; planning",
+		Description: "Agenda:\n- status",
+		Location:    "Room 1",
+		Updated:     "2030-01-01T12:00:00Z",
+		Start:       &calendar.EventDateTime{DateTime: "2030-01-07T10:00:00+01:00", TimeZone: "Europe/Berlin"},
+		End:         &calendar.EventDateTime{DateTime: "2030-01-07T10:30:00+01:00", TimeZone: "Europe/Berlin"},
+		Recurrence:  []string{"RRULE:FREQ=WEEKLY;BYDAY=MO"},
+		Organizer:   &calendar.EventOrganizer{Email: "me@example.com", DisplayName: "Me"},
+		Attendees: []*calendar.EventAttendee{
+			{Email: "ana@example.com", DisplayName: "Doe, Ana", ResponseStatus: "accepted"},
+			{Email: "bob@example.com", Optional: true, ResponseStatus: "needsAction"},
+		},
+		HangoutLink: "https://meet.google.com/abc-defg-hij",
+		Reminders: &calendar.EventReminders{Overrides: []*calendar.EventReminder{
+			{Method: "popup", Minutes: 10},
+			{Method: "email", Minutes: 1440},
+		}},
+	}
+}
+
+func TestEventToVEvent_RoundTrip(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	series := sampleICSSeries()
+	cancelled := &calendar.Event{
+		Id:                "series1_20300114T090000Z",
+		Status:            "cancelled",
+		RecurringEventId:  "series1",
+		OriginalStartTime: &calendar.EventDateTime{DateTime: "2030-01-14T10:00:00+01:00", TimeZone: "Europe/Berlin"},
+	}
+	allDay := &calendar.Event{
+		Id:      "day1",
+		ICalUID: "day1@google.com",
+		Summary: "Offsite",
+		Start:   &calendar.EventDateTime{Date: "2030-02-01"},
+		End:     &calendar.EventDateTime{Date: "2030-02-02"},
+	}
+
+	cal := ics.NewComponent("VCALENDAR")
+	if n := appendVEvents(cal, []*calendar.Event{series, cancelled, allDay}, time.Now()); n != 2 {
+		t.Fatalf("expected 2 exported events, got %d", n)
+	}
+	var buf bytes.Buffer
+	if err := ics.Encode(&buf, cal); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	out := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Berlin:20300107T100000\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO\r\n",
+		"EXDATE;TZID=Europe/Berlin:20300114T100000\r\n",
+		"SUMMARY:Weekly sync\\; planning\r\n",
+		"ATTENDEE;CN=\"Doe, Ana\";ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:mailto:ana@example.com\r\n",
+		"X-GOOGLE-CONFERENCE:https://meet.google.com/abc-defg-hij\r\n",
+		"TRIGGER:-PT10M\r\n",
+		"TRIGGER:-P1D\r\n",
+		"DTSTART;VALUE=DATE:20300201\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	roots, err := ics.Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	items, err := icsImportItems(roots, time.UTC)
+	if err != nil {
+		t.Fatalf("icsImportItems: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	got := items[0].event
+	if got.ICalUID != series.ICalUID || got.Start.TimeZone != "Europe/Berlin" {
+		t.Fatalf("unexpected series: %+v %+v", got, got.Start)
+	}
+	if len(got.Recurrence) != 2 || got.Recurrence[1] != "EXDATE;TZID=Europe/Berlin:20300114T100000" {
+		t.Fatalf("unexpected recurrence: %v", got.Recurrence)
+	}
+	if !got.Attendees[1].Optional || got.Attendees[0].ResponseStatus != "accepted" {
+		t.Fatalf("unexpected attendees: %+v %+v", got.Attendees[0], got.Attendees[1])
+	}
+	if !strings.Contains(got.Description, "Join: https://meet.google.com/abc-defg-hij") {
+		t.Fatalf("expected conference link in description, got %q", got.Description)
+	}
+
+	// Apart from the conference link and the EXDATE, the series is unchanged.
+	series.Description = got.Description
+	series.Recurrence = got.Recurrence
+	if !icsEventUnchanged(series, got) {
+		t.Fatalf("expected round-tripped series to compare unchanged")
+	}
+	if !icsEventUnchanged(allDay, items[1].event) {
+		t.Fatalf("expected all-day event to compare unchanged: %+v", items[1].event)
+	}
+}
+
+func TestVEventToEvent_DurationAndFloatingTime(t *testing.T) {
+	src := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:float@example.com\r\n" +
+		"DTSTART:20300107T090000\r\n" +
+		"DURATION:PT45M\r\n" +
+		"STATUS:TENTATIVE\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"TRIGGER;VALUE=DATE-TIME:20300107T080000Z\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	roots, err := ics.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	ev, err := vEventToEvent(roots[0].Components("VEVENT")[0], time.UTC)
+	if err != nil {
+		t.Fatalf("vEventToEvent: %v", err)
+	}
+	if ev.Start.DateTime != "2030-01-07T09:00:00Z" || ev.End.DateTime != "2030-01-07T09:45:00Z" {
+		t.Fatalf("unexpected times: %+v %+v", ev.Start, ev.End)
+	}
+	if ev.Status != "tentative" || ev.Reminders != nil {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+
+	missingUID := strings.Replace(src, "UID:float@example.com\r\n", "", 1)
+	roots, _ = ics.Parse(strings.NewReader(missingUID))
+	if _, err := vEventToEvent(roots[0].Components("VEVENT")[0], time.UTC); err == nil {
+		t.Fatalf("expected error for VEVENT without UID")
+	}
+}
+
+func TestExecute_CalendarExport(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	svc, closeSrv := newCalendarTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/calendars/primary/events") && r.Method == http.MethodGet {
+			if r.URL.Query().Get("singleEvents") != "false" || r.URL.Query().Get("showDeleted") != "true" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []*calendar.Event{sampleICSSeries()}})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(closeSrv)
+	origNew := newCalendarService
+	t.Cleanup(func() { newCalendarService = origNew })
+	newCalendarService = stubCalendarService(svc)
+
+	outPath := filepath.Join(t.TempDir(), "cal.ics")
+	out := captureStdout(t, func() {
+		_ = captureStderr(t, func() {
+			if err := Execute([]string{
+				"--json",
+				"--account", "a@b.com",
+				"calendar", "export", "primary",
+				"--from", "2030-01-01", "--to", "2030-01-31",
+				"--out", outPath,
+			}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+		})
+	})
+	if !strings.Contains(out, `"events": 1`) {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if _, err := os.Stat(outPath + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected temp file to be renamed away, got %v", err)
+	}
+	text := string(data)
+	if !strings.HasPrefix(text, "BEGIN:VCALENDAR\r\nPRODID:") || !strings.Contains(text, "X-WR-TIMEZONE:UTC\r\n") ||
+		!strings.Contains(text, "UID:series1@google.com\r\n") {
+		t.Fatalf("unexpected export:\n%s", text)
+	}
+}
+
+func TestExecute_CalendarImport(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	existing := sampleICSSeries()
+	existing.Description = "Agenda:\n- status\n\nJoin: https://meet.google.com/abc-defg-hij"
+
+	cal := ics.NewComponent("VCALENDAR")
+	appendVEvents(cal, []*calendar.Event{existing, {
+		ICalUID: "new@example.com",
+		Summary: "New event",
+		Start:   &calendar.EventDateTime{DateTime: "2030-01-08T09:00:00Z"},
+		End:     &calendar.EventDateTime{DateTime: "2030-01-08T10:00:00Z"},
+	}}, time.Now())
+	var buf bytes.Buffer
+	if err := ics.Encode(&buf, cal); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	icsPath := filepath.Join(t.TempDir(), "in.ics")
+	if err := os.WriteFile(icsPath, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		imported []string
+		updates  int
+	)
+	svc, closeSrv := newCalendarTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/calendars/primary/events"):
+			items := []*calendar.Event{}
+			if r.URL.Query().Get("iCalUID") == existing.ICalUID {
+				items = append(items, existing)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/calendars/primary/events/import"):
+			var ev calendar.Event
+			_ = json.NewDecoder(r.Body).Decode(&ev)
+			mu.Lock()
+			imported = append(imported, ev.ICalUID)
+			mu.Unlock()
+			ev.Id = "created1"
+			_ = json.NewEncoder(w).Encode(ev)
+		case r.Method == http.MethodPut:
+			mu.Lock()
+			updates++
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "x"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(closeSrv)
+	origNew := newCalendarService
+	t.Cleanup(func() { newCalendarService = origNew })
+	newCalendarService = stubCalendarService(svc)
+
+	run := func(args ...string) string {
+		t.Helper()
+		return captureStdout(t, func() {
+			_ = captureStderr(t, func() {
+				if err := Execute(append([]string{"--json", "--account", "a@b.com"}, args...)); err != nil {
+					t.Fatalf("Execute: %v", err)
+				}
+			})
+		})
+	}
+
+	var plan struct {
+		DryRun bool `json:"dry_run"`
+		Events []struct {
+			Action string `json:"action"`
+			UID    string `json:"uid"`
+		} `json:"events"`
+	}
+	out := run("--dry-run", "calendar", "import", "primary", icsPath)
+	if err := json.Unmarshal([]byte(out), &plan); err != nil {
+		t.Fatalf("json parse: %v\nout=%q", err, out)
+	}
+	if !plan.DryRun || len(plan.Events) != 2 || plan.Events[0].Action != calendarImportUnchanged || plan.Events[1].Action != calendarImportCreate {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if len(imported) != 0 || updates != 0 {
+		t.Fatalf("dry run must not write (imported=%v updates=%d)", imported, updates)
+	}
+
+	run("calendar", "import", "primary", icsPath)
+	if len(imported) != 1 || imported[0] != "new@example.com" || updates != 0 {
+		t.Fatalf("expected only the new event to be imported (imported=%v updates=%d)", imported, updates)
+	}
+}
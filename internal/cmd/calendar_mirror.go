@@ -0,0 +1,593 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/timeparse"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// Private extended properties that tag mirrored events with their source.
+const (
+	calendarMirrorPropSource = "gogMirrorSource"
+	calendarMirrorPropID     = "gogMirrorId"
+)
+
+const (
+	calendarMirrorCreate    = "create"
+	calendarMirrorUpdate    = "update"
+	calendarMirrorDelete    = "delete"
+	calendarMirrorUnchanged = "unchanged"
+)
+
+type CalendarMirrorCmd struct {
+	FromAccount string `name:"from-account" help:"Source account (default: --account)"`
+	FromCal     string `name:"from-cal" help:"Source calendar ID" default:"primary"`
+	ToAccount   string `name:"to-account" help:"Target account (default: --account)"`
+	ToCal       string `name:"to-cal" help:"Target calendar ID" default:"primary"`
+	Busy        bool   `name:"busy" aliases:"anonymize" help:"Mirror events as anonymized busy blocks (no details)"`
+	BusyTitle   string `name:"busy-title" help:"Title of --busy blocks" default:"Busy"`
+	IncludeFree bool   `name:"include-free" help:"Also mirror events shown as free or that you declined"`
+	Since       string `name:"since" help:"On a full sync, skip events that ended before this (e.g., 7d, 2025-01-01)" default:"7d"`
+	Full        bool   `name:"full" help:"Ignore the stored sync token and reconcile all mirrored events"`
+}
+
+// calendarMirrorState is the persisted sync token for one source/target pair.
+type calendarMirrorState struct {
+	FromAccount  string `json:"fromAccount"`
+	FromCalendar string `json:"fromCalendar"`
+	ToAccount    string `json:"toAccount"`
+	ToCalendar   string `json:"toCalendar"`
+	SyncToken    string `json:"syncToken"`
+	UpdatedAtMs  int64  `json:"updatedAtMs"`
+}
+
+type calendarMirrorChange struct {
+	Action   string `json:"action"`
+	SourceID string `json:"sourceId"`
+	TargetID string `json:"targetId,omitempty"`
+	Start    string `json:"start,omitempty"`
+	Summary  string `json:"summary,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func calendarMirrorStatePath(fromAccount, fromCal, toAccount, toCal string) (string, error) {
+	dir, err := config.EnsureCalendarMirrorDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(fromAccount) + "\x00" + fromCal + "\x00" + strings.ToLower(toAccount) + "\x00" + toCal))
+	name := sanitizeAccountForPath(fromAccount) + "_" + sanitizeAccountForPath(toAccount) + "_" + hex.EncodeToString(sum[:8])
+	return filepath.Join(dir, name+".json"), nil
+}
+
+func loadCalendarMirrorState(path string) (*calendarMirrorState, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path inside config dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &calendarMirrorState{}, nil
+		}
+		return nil, err
+	}
+	var state calendarMirrorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("read calendar mirror state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+func saveCalendarMirrorState(path string, state *calendarMirrorState) error {
+	state.UpdatedAtMs = time.Now().UnixMilli()
+	payload, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(payload, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (c *CalendarMirrorCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	fromAccount, err := calendarMirrorAccount(flags, c.FromAccount)
+	if err != nil {
+		return err
+	}
+	toAccount, err := calendarMirrorAccount(flags, c.ToAccount)
+	if err != nil {
+		return err
+	}
+	fromCal := strings.TrimSpace(c.FromCal)
+	toCal := strings.TrimSpace(c.ToCal)
+	if fromCal == "" || toCal == "" {
+		return usage("empty --from-cal or --to-cal")
+	}
+	if strings.EqualFold(fromAccount, toAccount) && fromCal == toCal {
+		return usage("source and target calendar are the same")
+	}
+	since, err := parseCalendarMirrorSince(c.Since, time.Now())
+	if err != nil {
+		return err
+	}
+	dryRun := flags != nil && flags.DryRun
+
+	srcSvc, err := newCalendarService(ctx, fromAccount)
+	if err != nil {
+		return err
+	}
+	dstSvc := srcSvc
+	if !strings.EqualFold(fromAccount, toAccount) {
+		if dstSvc, err = newCalendarService(ctx, toAccount); err != nil {
+			return err
+		}
+	}
+	if fromCal, err = resolveCalendarID(ctx, srcSvc, fromCal); err != nil {
+		return err
+	}
+	if toCal, err = resolveCalendarID(ctx, dstSvc, toCal); err != nil {
+		return err
+	}
+
+	statePath, err := calendarMirrorStatePath(fromAccount, fromCal, toAccount, toCal)
+	if err != nil {
+		return err
+	}
+	state, err := loadCalendarMirrorState(statePath)
+	if err != nil {
+		return err
+	}
+
+	full := c.Full || state.SyncToken == ""
+	var (
+		events    []*calendar.Event
+		syncToken string
+	)
+	if !full {
+		events, syncToken, err = listCalendarMirrorSource(ctx, srcSvc, fromCal, state.SyncToken, since)
+		var apiErr *gapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusGone {
+			u.Err().Println("Stored sync token expired; running a full sync")
+			full = true
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if full {
+		events, syncToken, err = listCalendarMirrorSource(ctx, srcSvc, fromCal, "", since)
+		if err != nil {
+			return err
+		}
+	}
+
+	sourceKey := strings.ToLower(fromAccount) + "/" + fromCal
+	targets, err := listCalendarMirrorTargets(ctx, dstSvc, toCal, sourceKey)
+	if err != nil {
+		return err
+	}
+
+	m := &calendarMirror{
+		svc:       dstSvc,
+		cal:       toCal,
+		sourceKey: sourceKey,
+		targets:   targets,
+		busy:      c.Busy,
+		busyTitle: strings.TrimSpace(c.BusyTitle),
+		withFree:  c.IncludeFree,
+		dryRun:    dryRun,
+	}
+	if m.busyTitle == "" {
+		m.busyTitle = "Busy"
+	}
+
+	// Series before their modified instances, so instances find the series.
+	seen := map[string]bool{}
+	var changes []calendarMirrorChange
+	for _, pass := range []bool{false, true} {
+		for _, ev := range events {
+			if ev == nil || (ev.RecurringEventId != "") != pass {
+				continue
+			}
+			seen[ev.Id] = true
+			if ch := m.apply(ctx, ev); ch != nil {
+				changes = append(changes, *ch)
+			}
+		}
+	}
+	if full {
+		changes = append(changes, m.removeStale(ctx, seen, since)...)
+	}
+
+	failed := 0
+	for _, ch := range changes {
+		if ch.Error != "" {
+			failed++
+		}
+	}
+	if !dryRun && failed == 0 && syncToken != "" {
+		state.FromAccount = fromAccount
+		state.FromCalendar = fromCal
+		state.ToAccount = toAccount
+		state.ToCalendar = toCal
+		state.SyncToken = syncToken
+		if err := saveCalendarMirrorState(statePath, state); err != nil {
+			return err
+		}
+	}
+
+	return writeCalendarMirrorPlan(ctx, u, dryRun, full, fromAccount+"/"+fromCal, toAccount+"/"+toCal, changes)
+}
+
+func calendarMirrorAccount(flags *RootFlags, value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return requireAccount(flags)
+	}
+	scoped := RootFlags{Account: value}
+	if flags != nil {
+		scoped = *flags
+		scoped.Account = value
+	}
+	return requireAccount(&scoped)
+}
+
+// parseCalendarMirrorSince accepts Nd in addition to the usual --since forms.
+func parseCalendarMirrorSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(strings.ToLower(value), "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	parsed, err := timeparse.ParseSince(value, now, time.Local)
+	if err != nil {
+		return time.Time{}, usagef("invalid --since %q (use e.g. 7d, 24h or a date)", value)
+	}
+	return parsed.Time, nil
+}
+
+// listCalendarMirrorSource returns changed events since syncToken, or all
+// events ending after since when syncToken is empty, plus the next sync token.
+// Series are listed unexpanded; deleted events are included so their mirrors
+// can be removed.
+func listCalendarMirrorSource(ctx context.Context, svc *calendar.Service, calendarID, syncToken string, since time.Time) ([]*calendar.Event, string, error) {
+	var (
+		events    []*calendar.Event
+		pageToken string
+	)
+	for {
+		call := svc.Events.List(calendarID).
+			SingleEvents(false).
+			ShowDeleted(true).
+			MaxResults(2500)
+		if syncToken != "" {
+			call = call.SyncToken(syncToken)
+		} else {
+			call = call.TimeMin(since.Format(time.RFC3339))
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		events = append(events, resp.Items...)
+		if resp.NextPageToken == "" {
+			return events, resp.NextSyncToken, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+// listCalendarMirrorTargets returns the mirrored events in the target
+// calendar, keyed by the source event ID they were copied from.
+func listCalendarMirrorTargets(ctx context.Context, svc *calendar.Service, calendarID, sourceKey string) (map[string]*calendar.Event, error) {
+	fetch := func(pageToken string) ([]*calendar.Event, string, error) {
+		call := svc.Events.List(calendarID).
+			PrivateExtendedProperty(calendarMirrorPropSource + "=" + sourceKey).
+			SingleEvents(false).
+			MaxResults(2500)
+		if strings.TrimSpace(pageToken) != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Items, resp.NextPageToken, nil
+	}
+	items, err := collectAllPages("", fetch)
+	if err != nil {
+		return nil, err
+	}
+	targets := make(map[string]*calendar.Event, len(items))
+	for _, ev := range items {
+		if id := calendarMirrorSourceID(ev); id != "" {
+			targets[id] = ev
+		}
+	}
+	return targets, nil
+}
+
+func calendarMirrorSourceID(ev *calendar.Event) string {
+	if ev == nil || ev.ExtendedProperties == nil {
+		return ""
+	}
+	return ev.ExtendedProperties.Private[calendarMirrorPropID]
+}
+
+type calendarMirror struct {
+	svc       *calendar.Service
+	cal       string
+	sourceKey string
+	targets   map[string]*calendar.Event
+	busy      bool
+	busyTitle string
+	withFree  bool
+	dryRun    bool
+}
+
+// apply mirrors one source event and returns the change, or nil when there
+// is nothing to do.
+func (m *calendarMirror) apply(ctx context.Context, ev *calendar.Event) *calendarMirrorChange {
+	ch := &calendarMirrorChange{SourceID: ev.Id, Start: eventDateTimeString(ev.Start), Summary: ev.Summary}
+	target := m.targets[ev.Id]
+
+	if ev.Status == "cancelled" || !m.shouldMirror(ev) {
+		switch {
+		case target != nil:
+			ch.TargetID = target.Id
+		case ev.RecurringEventId != "":
+			id, err := m.instanceID(ctx, ev)
+			if err != nil || id == "" {
+				return nil
+			}
+			ch.TargetID = id
+		default:
+			return nil
+		}
+		ch.Action = calendarMirrorDelete
+		if !m.dryRun {
+			if err := m.svc.Events.Delete(m.cal, ch.TargetID).Context(ctx).Do(); err != nil {
+				ch.Error = err.Error()
+			}
+		}
+		return ch
+	}
+
+	want := m.mirrorEvent(ev)
+	if target == nil && ev.RecurringEventId != "" {
+		id, err := m.instanceID(ctx, ev)
+		if err != nil {
+			ch.Action = calendarMirrorUpdate
+			ch.Error = err.Error()
+			return ch
+		}
+		if id == "" {
+			// The series was not mirrored (e.g., it is filtered out).
+			return nil
+		}
+		target = &calendar.Event{Id: id}
+	}
+
+	switch {
+	case target == nil:
+		ch.Action = calendarMirrorCreate
+		if m.dryRun {
+			return ch
+		}
+		created, err := m.svc.Events.Insert(m.cal, want).Context(ctx).Do()
+		if err != nil {
+			ch.Error = err.Error()
+			return ch
+		}
+		ch.TargetID = created.Id
+		m.targets[ev.Id] = created
+	case calendarMirrorEventUnchanged(target, want):
+		ch.Action = calendarMirrorUnchanged
+		ch.TargetID = target.Id
+	default:
+		ch.Action = calendarMirrorUpdate
+		ch.TargetID = target.Id
+		if m.dryRun {
+			return ch
+		}
+		updated, err := m.svc.Events.Update(m.cal, target.Id, want).Context(ctx).Do()
+		if err != nil {
+			ch.Error = err.Error()
+			return ch
+		}
+		m.targets[ev.Id] = updated
+	}
+	return ch
+}
+
+// instanceID finds the mirrored occurrence of a source instance in the
+// target copy of its series. It returns "" when the series is not mirrored.
+func (m *calendarMirror) instanceID(ctx context.Context, ev *calendar.Event) (string, error) {
+	series := m.targets[ev.RecurringEventId]
+	if series == nil || ev.OriginalStartTime == nil {
+		return "", nil
+	}
+	return resolveRecurringInstanceID(ctx, m.svc, m.cal, series.Id, eventDateTimeString(ev.OriginalStartTime))
+}
+
+// shouldMirror skips free time, declined invitations, working locations and
+// events that are themselves mirrors (to avoid loops between two calendars).
+func (m *calendarMirror) shouldMirror(ev *calendar.Event) bool {
+	if ev.ExtendedProperties != nil && ev.ExtendedProperties.Private[calendarMirrorPropSource] != "" {
+		return false
+	}
+	if ev.EventType == eventTypeWorkingLocation {
+		return false
+	}
+	if m.withFree {
+		return true
+	}
+	if ev.Transparency == "transparent" {
+		return false
+	}
+	for _, a := range ev.Attendees {
+		if a != nil && a.Self && a.ResponseStatus == "declined" {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *calendarMirror) mirrorEvent(ev *calendar.Event) *calendar.Event {
+	out := &calendar.Event{
+		Start:        copyEventDateTime(ev.Start),
+		End:          copyEventDateTime(ev.End),
+		Status:       ev.Status,
+		Transparency: ev.Transparency,
+		Reminders: &calendar.EventReminders{
+			UseDefault:      false,
+			ForceSendFields: []string{"UseDefault"},
+		},
+		ExtendedProperties: buildExtendedProperties([]string{
+			calendarMirrorPropSource + "=" + m.sourceKey,
+			calendarMirrorPropID + "=" + ev.Id,
+		}, nil),
+	}
+	if ev.RecurringEventId == "" {
+		out.Recurrence = ev.Recurrence
+	}
+	if m.busy {
+		out.Summary = m.busyTitle
+		out.Visibility = "private"
+		return out
+	}
+	out.Summary = ev.Summary
+	out.Description = ev.Description
+	out.Location = ev.Location
+	out.Visibility = ev.Visibility
+	return out
+}
+
+// removeStale deletes mirrors whose source no longer exists. Only used on a
+// full sync, where every source event ending after since has been seen.
+func (m *calendarMirror) removeStale(ctx context.Context, seen map[string]bool, since time.Time) []calendarMirrorChange {
+	var changes []calendarMirrorChange
+	for sourceID, target := range m.targets {
+		if seen[sourceID] || target.Id == "" || target.RecurringEventId != "" {
+			continue
+		}
+		if len(target.Recurrence) == 0 {
+			if end, err := time.Parse(time.RFC3339, eventDateTimeString(target.End)); err == nil && end.Before(since) {
+				continue
+			}
+		}
+		ch := calendarMirrorChange{
+			Action:   calendarMirrorDelete,
+			SourceID: sourceID,
+			TargetID: target.Id,
+			Start:    eventDateTimeString(target.Start),
+			Summary:  target.Summary,
+		}
+		if !m.dryRun {
+			if err := m.svc.Events.Delete(m.cal, target.Id).Context(ctx).Do(); err != nil {
+				ch.Error = err.Error()
+			}
+		}
+		changes = append(changes, ch)
+	}
+	return changes
+}
+
+func copyEventDateTime(edt *calendar.EventDateTime) *calendar.EventDateTime {
+	if edt == nil {
+		return nil
+	}
+	return &calendar.EventDateTime{Date: edt.Date, DateTime: edt.DateTime, TimeZone: edt.TimeZone}
+}
+
+func calendarMirrorEventUnchanged(live, want *calendar.Event) bool {
+	return live.Summary == want.Summary &&
+		live.Description == want.Description &&
+		live.Location == want.Location &&
+		sameEventDateTime(live.Start, want.Start) &&
+		sameEventDateTime(live.End, want.End) &&
+		(live.RecurringEventId != "" || slices.Equal(live.Recurrence, want.Recurrence)) &&
+		defaultString(live.Status, "confirmed") == defaultString(want.Status, "confirmed") &&
+		defaultString(live.Transparency, "opaque") == defaultString(want.Transparency, "opaque") &&
+		defaultString(live.Visibility, "default") == defaultString(want.Visibility, "default")
+}
+
+func writeCalendarMirrorPlan(ctx context.Context, u *ui.UI, dryRun, full bool, from, to string, changes []calendarMirrorChange) error {
+	counts := map[string]int{}
+	failed := 0
+	for _, ch := range changes {
+		counts[ch.Action]++
+		if ch.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		counts["failed"] = failed
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if changes == nil {
+			changes = []calendarMirrorChange{}
+		}
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"dry_run": dryRun,
+			"op":      "calendar.mirror",
+			"from":    from,
+			"to":      to,
+			"full":    full,
+			"changes": changes,
+			"summary": counts,
+		}); err != nil {
+			return err
+		}
+	} else {
+		prefix := "Mirrored"
+		if dryRun {
+			prefix = "Plan"
+		}
+		u.Err().Printf("%s: %d to create, %d to update, %d to delete, %d unchanged",
+			prefix, counts[calendarMirrorCreate], counts[calendarMirrorUpdate], counts[calendarMirrorDelete], counts[calendarMirrorUnchanged])
+		if counts[calendarMirrorCreate]+counts[calendarMirrorUpdate]+counts[calendarMirrorDelete] > 0 {
+			w, flush := tableWriter(ctx)
+			fmt.Fprintln(w, "ACTION\tSTART\tSUMMARY\tSOURCE")
+			for _, ch := range changes {
+				if ch.Action == calendarMirrorUnchanged {
+					continue
+				}
+				action := ch.Action
+				if ch.Error != "" {
+					action = "error: " + ch.Error
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", sanitizeTab(action), ch.Start, sanitizeTab(ch.Summary), ch.SourceID)
+			}
+			flush()
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d mirror changes failed", failed, len(changes))
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestParseCalendarMirrorSince(t *testing.T) {
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	got, err := parseCalendarMirrorSince("7d", now)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !got.Equal(now.AddDate(0, 0, -7)) {
+		t.Fatalf("unexpected since: %s", got)
+	}
+	if got, err = parseCalendarMirrorSince("24h", now); err != nil || !got.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("unexpected since for 24h: %s (%v)", got, err)
+	}
+	if _, err := parseCalendarMirrorSince("soon", now); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+type mirrorTestTarget struct {
+	mu      sync.Mutex
+	events  map[string]*calendar.Event
+	nextID  int
+	deleted []string
+}
+
+func newCalendarMirrorTestServices(t *testing.T, source http.HandlerFunc) *mirrorTestTarget {
+	t.Helper()
+
+	target := &mirrorTestTarget{events: map[string]*calendar.Event{}}
+	dstSvc, closeDst := newCalendarTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target.mu.Lock()
+		defer target.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		const prefix = "/calendars/primary/events"
+		path := strings.TrimPrefix(r.URL.Path, "/calendar/v3")
+		switch {
+		case r.Method == http.MethodGet && path == prefix:
+			filter := r.URL.Query().Get("privateExtendedProperty")
+			items := []*calendar.Event{}
+			for _, ev := range target.events {
+				if filter == calendarMirrorPropSource+"="+ev.ExtendedProperties.Private[calendarMirrorPropSource] {
+					items = append(items, ev)
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+		case r.Method == http.MethodPost && path == prefix:
+			var ev calendar.Event
+			_ = json.NewDecoder(r.Body).Decode(&ev)
+			target.nextID++
+			ev.Id = fmt.Sprintf("m%d", target.nextID)
+			target.events[ev.Id] = &ev
+			_ = json.NewEncoder(w).Encode(ev)
+		case r.Method == http.MethodDelete && strings.HasPrefix(path, prefix+"/"):
+			id := strings.TrimPrefix(path, prefix+"/")
+			delete(target.events, id)
+			target.deleted = append(target.deleted, id)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(closeDst)
+	srcSvc, closeSrc := newCalendarTestService(t, source)
+	t.Cleanup(closeSrc)
+
+	orig := newCalendarService
+	t.Cleanup(func() { newCalendarService = orig })
+	newCalendarService = func(_ context.Context, account string) (*calendar.Service, error) {
+		if account == "work@example.com" {
+			return srcSvc, nil
+		}
+		return dstSvc, nil
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	return target
+}
+
+func TestExecute_CalendarMirror_BusyIncremental(t *testing.T) {
+	var syncTokens []string
+	target := newCalendarMirrorTestServices(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/calendars/primary/events") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		token := r.URL.Query().Get("syncToken")
+		syncTokens = append(syncTokens, token)
+		if token == "" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"items": []map[string]any{
+					{
+						"id":          "e1",
+						"status":      "confirmed",
+						"summary":     "Board meeting",
+						"description": "secret",
+						"start":       map[string]any{"dateTime": "2030-01-07T10:00:00Z"},
+						"end":         map[string]any{"dateTime": "2030-01-07T11:00:00Z"},
+						"attendees":   []map[string]any{{"email": "ceo@example.com"}},
+					},
+					{
+						"id":           "e2",
+						"status":       "confirmed",
+						"summary":      "Focus",
+						"transparency": "transparent",
+						"start":        map[string]any{"dateTime": "2030-01-07T12:00:00Z"},
+						"end":          map[string]any{"dateTime": "2030-01-07T13:00:00Z"},
+					},
+				},
+				"nextSyncToken": "tok1",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"items":         []map[string]any{{"id": "e1", "status": "cancelled"}},
+			"nextSyncToken": "tok2",
+		})
+	})
+
+	run := func() map[string]int {
+		out := captureStdout(t, func() {
+			_ = captureStderr(t, func() {
+				if err := Execute([]string{
+					"--json",
+					"calendar", "mirror",
+					"--from-account", "work@example.com",
+					"--to-account", "me@example.com",
+					"--busy",
+				}); err != nil {
+					t.Fatalf("Execute: %v", err)
+				}
+			})
+		})
+		var parsed struct {
+			Summary map[string]int `json:"summary"`
+		}
+		if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+			t.Fatalf("json parse: %v\nout=%q", err, out)
+		}
+		return parsed.Summary
+	}
+
+	if got := run(); got["create"] != 1 || len(got) != 1 {
+		t.Fatalf("unexpected first run summary: %v", got)
+	}
+	if len(target.events) != 1 {
+		t.Fatalf("expected 1 mirrored event, got %d", len(target.events))
+	}
+	var mirrored *calendar.Event
+	for _, ev := range target.events {
+		mirrored = ev
+	}
+	if mirrored.Summary != "Busy" || mirrored.Description != "" || len(mirrored.Attendees) != 0 || mirrored.Visibility != "private" {
+		t.Fatalf("expected anonymized busy block, got %+v", mirrored)
+	}
+	if mirrored.ExtendedProperties.Private[calendarMirrorPropID] != "e1" ||
+		mirrored.ExtendedProperties.Private[calendarMirrorPropSource] != "work@example.com/primary" {
+		t.Fatalf("unexpected mirror tags: %+v", mirrored.ExtendedProperties.Private)
+	}
+
+	if got := run(); got["delete"] != 1 {
+		t.Fatalf("unexpected second run summary: %v", got)
+	}
+	if len(target.events) != 0 || len(target.deleted) != 1 {
+		t.Fatalf("expected mirror to be deleted, got %+v", target.events)
+	}
+	if len(syncTokens) != 2 || syncTokens[0] != "" || syncTokens[1] != "tok1" {
+		t.Fatalf("unexpected sync tokens: %v", syncTokens)
+	}
+
+	stateFiles, _ := filepath.Glob(filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "*", "state", "calendar-mirror", "*.json"))
+	if len(stateFiles) != 1 {
+		t.Fatalf("expected one state file, got %v", stateFiles)
+	}
+	if _, err := os.Stat(stateFiles[0] + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected temp state file to be renamed away, got %v", err)
+	}
+	state, err := loadCalendarMirrorState(stateFiles[0])
+	if err != nil || state.SyncToken != "tok2" {
+		t.Fatalf("unexpected state: %+v (%v)", state, err)
+	}
+}
+
+func TestExecute_CalendarMirror_SameCalendar(t *testing.T) {
+	err := Execute([]string{"--account", "a@b.com", "calendar", "mirror"})
+	if err == nil || !strings.Contains(err.Error(), "same") {
+		t.Fatalf("expected same-calendar error, got %v", err)
+	}
+}
@@ -63,7 +63,13 @@ func matchesOriginalStart(event *calendar.Event, originalStart string) bool {
 			return true
 		}
 	}
-	return false
+	// The same instant may be written with a different offset.
+	want, err := time.Parse(time.RFC3339, originalStart)
+	if err != nil || event.OriginalStartTime == nil {
+		return false
+	}
+	got, err := time.Parse(time.RFC3339, event.OriginalStartTime.DateTime)
+	return err == nil && got.Equal(want)
 }
 
 func originalStartRange(originalStart string) (string, string, error) {
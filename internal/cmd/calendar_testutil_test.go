@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+// newCalendarTestService serves h behind withPrimaryCalendar so commands can
+// resolve the primary calendar's timezone.
+func newCalendarTestService(t *testing.T, h http.Handler) (*calendar.Service, func()) {
+	t.Helper()
+
+	srv := httptest.NewServer(withPrimaryCalendar(h))
+
+	svc, err := calendar.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		srv.Close()
+		t.Fatalf("NewService: %v", err)
+	}
+	return svc, srv.Close
+}
+
+func stubCalendarService(svc *calendar.Service) func(context.Context, string) (*calendar.Service, error) {
+	return func(context.Context, string) (*calendar.Service, error) { return svc, nil }
+}
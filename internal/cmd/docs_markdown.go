@@ -68,6 +68,12 @@ func utf16Len(s string) int64 {
 
 // ParseMarkdown parses markdown text into structured elements
 func ParseMarkdown(text string) []MarkdownElement {
+	return parseMarkdown(text, false)
+}
+
+// parseMarkdown parses markdown text; keepEmptyLines emits MDEmptyLine for
+// blank lines so callers can tell paragraphs apart from wrapped lines.
+func parseMarkdown(text string, keepEmptyLines bool) []MarkdownElement {
 	var elements []MarkdownElement
 	lines := strings.Split(text, "\n")
 
@@ -104,6 +110,9 @@ func ParseMarkdown(text string) []MarkdownElement {
 
 		// Empty line
 		if strings.TrimSpace(line) == "" {
+			if keepEmptyLines {
+				elements = append(elements, MarkdownElement{Type: MDEmptyLine})
+			}
 			continue
 		}
 
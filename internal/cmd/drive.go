@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"google.golang.org/api/drive/v3"
 	gapi "google.golang.org/api/googleapi"
@@ -37,6 +38,7 @@ const (
 	driveMimeGoogleSheet   = "application/vnd.google-apps.spreadsheet"
 	driveMimeGoogleSlides  = "application/vnd.google-apps.presentation"
 	driveMimeGoogleDrawing = "application/vnd.google-apps.drawing"
+	driveMimeFolder        = "application/vnd.google-apps.folder"
 	mimePDF                = "application/pdf"
 	mimeCSV                = "text/csv"
 	mimeDocx               = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
@@ -61,22 +63,27 @@ const (
 )
 
 type DriveCmd struct {
-	Ls          DriveLsCmd          `cmd:"" name:"ls" help:"List files in a folder (default: root)"`
-	Search      DriveSearchCmd      `cmd:"" name:"search" help:"Full-text search across Drive"`
-	Get         DriveGetCmd         `cmd:"" name:"get" help:"Get file metadata"`
-	Download    DriveDownloadCmd    `cmd:"" name:"download" help:"Download a file (exports Google Docs formats)"`
-	Copy        DriveCopyCmd        `cmd:"" name:"copy" help:"Copy a file"`
-	Upload      DriveUploadCmd      `cmd:"" name:"upload" help:"Upload a file"`
-	Mkdir       DriveMkdirCmd       `cmd:"" name:"mkdir" help:"Create a folder"`
-	Delete      DriveDeleteCmd      `cmd:"" name:"delete" help:"Move a file to trash (use --permanent to delete forever)" aliases:"rm,del"`
-	Move        DriveMoveCmd        `cmd:"" name:"move" help:"Move a file to a different folder"`
-	Rename      DriveRenameCmd      `cmd:"" name:"rename" help:"Rename a file or folder"`
-	Share       DriveShareCmd       `cmd:"" name:"share" help:"Share a file or folder"`
-	Unshare     DriveUnshareCmd     `cmd:"" name:"unshare" help:"Remove a permission from a file"`
-	Permissions DrivePermissionsCmd `cmd:"" name:"permissions" help:"List permissions on a file"`
-	URL         DriveURLCmd         `cmd:"" name:"url" help:"Print web URLs for files"`
-	Comments    DriveCommentsCmd    `cmd:"" name:"comments" help:"Manage comments on files"`
-	Drives      DriveDrivesCmd      `cmd:"" name:"drives" help:"List shared drives (Team Drives)"`
+	Ls                DriveLsCmd                `cmd:"" name:"ls" help:"List files in a folder (default: root)"`
+	Search            DriveSearchCmd            `cmd:"" name:"search" help:"Full-text search across Drive"`
+	Get               DriveGetCmd               `cmd:"" name:"get" help:"Get file metadata"`
+	Download          DriveDownloadCmd          `cmd:"" name:"download" help:"Download a file (exports Google Docs formats)"`
+	Copy              DriveCopyCmd              `cmd:"" name:"copy" help:"Copy a file"`
+	Upload            DriveUploadCmd            `cmd:"" name:"upload" help:"Upload a file"`
+	Mkdir             DriveMkdirCmd             `cmd:"" name:"mkdir" help:"Create a folder"`
+	Delete            DriveDeleteCmd            `cmd:"" name:"delete" help:"Move a file to trash (use --permanent to delete forever)" aliases:"rm,del"`
+	Move              DriveMoveCmd              `cmd:"" name:"move" help:"Move a file to a different folder"`
+	Rename            DriveRenameCmd            `cmd:"" name:"rename" help:"Rename a file or folder"`
+	Share             DriveShareCmd             `cmd:"" name:"share" help:"Share a file or folder"`
+	Unshare           DriveUnshareCmd           `cmd:"" name:"unshare" help:"Remove a permission from a file"`
+	Permissions       DrivePermissionsCmd       `cmd:"" name:"permissions" help:"List permissions on a file"`
+	Audit             DriveAuditCmd             `cmd:"" name:"audit" help:"Report every permission below a folder or shared drive"`
+	TransferOwnership DriveTransferOwnershipCmd `cmd:"" name:"transfer-ownership" aliases:"chown" help:"Transfer ownership of a file or folder tree to another user"`
+	URL               DriveURLCmd               `cmd:"" name:"url" help:"Print web URLs for files"`
+	Comments          DriveCommentsCmd          `cmd:"" name:"comments" help:"Manage comments on files"`
+	Drives            DriveDrivesCmd            `cmd:"" name:"drives" help:"List shared drives (Team Drives)"`
+	Sync              DriveSyncCmd              `cmd:"" name:"sync" help:"Sync a local directory with a Drive folder (both directions)"`
+	Revisions         DriveRevisionsCmd         `cmd:"" name:"revisions" aliases:"revision,versions" help:"List, download, and manage file revisions"`
+	Changes           DriveChangesCmd           `cmd:"" name:"changes" aliases:"watch" help:"List changes since the stored cursor (use --follow to stream)"`
 }
 
 type DriveLsCmd struct {
@@ -270,9 +277,11 @@ func (c *DriveGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type DriveDownloadCmd struct {
-	FileID string         `arg:"" name:"fileId" help:"File ID"`
-	Output OutputPathFlag `embed:""`
-	Format string         `name:"format" help:"Export format for Google Docs files: pdf|csv|xlsx|pptx|txt|png|docx (default: inferred)"`
+	FileID      string         `arg:"" name:"fileId" help:"File ID"`
+	Output      OutputPathFlag `embed:""`
+	Format      string         `name:"format" help:"Export format for Google Docs files: pdf|csv|xlsx|pptx|txt|png|docx (default: inferred)"`
+	Recursive   bool           `name:"recursive" help:"Download a folder and everything below it (--out is the destination directory)"`
+	Concurrency int            `name:"concurrency" help:"Parallel downloads for --recursive" default:"4"`
 }
 
 func (c *DriveDownloadCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -306,6 +315,15 @@ func (c *DriveDownloadCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if meta.Name == "" {
 		return errors.New("file has no name")
 	}
+	if meta.MimeType == driveMimeFolder {
+		if !c.Recursive {
+			return usage("fileId is a folder (use --recursive)")
+		}
+		return c.runRecursive(ctx, u, svc, meta)
+	}
+	if c.Recursive {
+		return usagef("--recursive requires a folder (mimeType=%s)", meta.MimeType)
+	}
 	if fileFormatErr := validateDriveDownloadFormatForFile(meta, c.Format); fileFormatErr != nil {
 		return fileFormatErr
 	}
@@ -332,6 +350,21 @@ func (c *DriveDownloadCmd) Run(ctx context.Context, flags *RootFlags) error {
 	return nil
 }
 
+func (c *DriveDownloadCmd) runRecursive(ctx context.Context, u *ui.UI, svc *drive.Service, folder *drive.File) error {
+	destDir, err := resolveDriveFolderDownloadDir(folder, c.Output.Path)
+	if err != nil {
+		return err
+	}
+	results, err := downloadDriveFolder(ctx, svc, u, folder, destDir, strings.ToLower(strings.TrimSpace(c.Format)), c.Concurrency)
+	if err != nil {
+		return err
+	}
+	return writeDriveTransferSummary(ctx, u, map[string]any{
+		"folderId": folder.Id,
+		"path":     destDir,
+	}, results)
+}
+
 type DriveCopyCmd struct {
 	FileID string `arg:"" name:"fileId" help:"File ID"`
 	Name   string `arg:"" name:"name" help:"New file name"`
@@ -353,6 +386,10 @@ type DriveUploadCmd struct {
 	KeepRevisionForever bool   `name:"keep-revision-forever" help:"Keep the new head revision forever (binary files only)"`
 	Convert             bool   `name:"convert" help:"Auto-convert to native Google format based on file extension (create only)"`
 	ConvertTo           string `name:"convert-to" help:"Convert to a specific Google format: doc|sheet|slides (create only)"`
+	Recursive           bool   `name:"recursive" help:"Upload a directory tree as a new folder (under --parent)"`
+	Concurrency         int    `name:"concurrency" help:"Parallel uploads for --recursive" default:"4"`
+	ChunkSize           string `name:"chunk-size" help:"Chunk size for resumable uploads; larger files upload in chunks (e.g. 8MB, 32MB)" default:"8MB"`
+	Resume              bool   `name:"resume" help:"Continue an interrupted upload of the same file"`
 }
 
 func (c *DriveUploadCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -371,6 +408,26 @@ func (c *DriveUploadCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
+	st, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if st.IsDir() {
+		if !c.Recursive {
+			return usage("localPath is a directory (use --recursive)")
+		}
+		return c.runRecursive(ctx, u, account, localPath)
+	}
+	if c.Recursive {
+		return usage("--recursive requires a directory")
+	}
+
+	chunkSize, err := parseDriveChunkSize(c.ChunkSize)
+	if err != nil {
+		return err
+	}
+	resumable := c.Resume || st.Size() > chunkSize
+
 	f, err := os.Open(localPath) //nolint:gosec // user-provided path
 	if err != nil {
 		return err
@@ -426,18 +483,22 @@ func (c *DriveUploadCmd) Run(ctx context.Context, flags *RootFlags) error {
 			}
 		}
 
-		createCall := svc.Files.Create(meta).
-			SupportsAllDrives(true).
-			Media(f, gapi.ContentType(mimeType)).
-			Fields("id, name, mimeType, size, webViewLink").
-			Context(ctx)
-		if c.KeepRevisionForever {
-			createCall = createCall.KeepRevisionForever(true)
+		var created *drive.File
+		if resumable {
+			created, err = c.uploadResumable(ctx, u, svc, account, f, st, localPath, "", meta, mimeType, chunkSize)
+		} else {
+			createCall := svc.Files.Create(meta).
+				SupportsAllDrives(true).
+				Media(f, gapi.ContentType(mimeType)).
+				Fields("id, name, mimeType, size, webViewLink").
+				Context(ctx)
+			if c.KeepRevisionForever {
+				createCall = createCall.KeepRevisionForever(true)
+			}
+			created, err = createCall.Do()
 		}
-
-		created, createErr := createCall.Do()
-		if createErr != nil {
-			return createErr
+		if err != nil {
+			return err
 		}
 
 		if outfmt.IsJSON(ctx) {
@@ -466,20 +527,12 @@ func (c *DriveUploadCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return fmt.Errorf("cannot replace content for Google Workspace files (mimeType=%s)", existing.MimeType)
 	}
 
-	meta := &drive.File{}
-	if fileName != "" {
-		meta.Name = fileName
-	}
-
-	call := svc.Files.Update(replaceFileID, meta).
-		SupportsAllDrives(true).
-		Media(f, gapi.ContentType(mimeType)).
-		Fields("id, name, mimeType, size, webViewLink").
-		Context(ctx)
-	if c.KeepRevisionForever {
-		call = call.KeepRevisionForever(true)
+	var updated *drive.File
+	if resumable {
+		updated, err = c.uploadResumable(ctx, u, svc, account, f, st, localPath, replaceFileID, &drive.File{Name: fileName}, mimeType, chunkSize)
+	} else {
+		updated, err = replaceDriveFileContent(ctx, svc, replaceFileID, fileName, f, mimeType, c.KeepRevisionForever)
 	}
-	updated, err := call.Do()
 	if err != nil {
 		return err
 	}
@@ -501,6 +554,89 @@ func (c *DriveUploadCmd) Run(ctx context.Context, flags *RootFlags) error {
 	return nil
 }
 
+// uploadResumable sends f in chunks through a persisted resumable session,
+// drawing a progress bar on stderr for human-readable output.
+func (c *DriveUploadCmd) uploadResumable(ctx context.Context, u *ui.UI, svc *drive.Service, account string, f *os.File, st os.FileInfo, localPath, fileID string, meta *drive.File, mimeType string, chunkSize int64) (*drive.File, error) {
+	client, err := newDriveHTTPClient(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	absPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	up := &driveResumableUpload{
+		Account:             account,
+		LocalPath:           absPath,
+		Size:                st.Size(),
+		ModTime:             st.ModTime(),
+		FileID:              fileID,
+		Meta:                meta,
+		MimeType:            mimeType,
+		KeepRevisionForever: c.KeepRevisionForever,
+		ChunkSize:           chunkSize,
+		Resume:              c.Resume,
+	}
+
+	progress, done := func(int64) {}, func() {}
+	if !outfmt.IsJSON(ctx) && !outfmt.IsPlain(ctx) {
+		progress, done = driveUploadProgressBar(u, st.Size())
+	}
+	file, err := up.Run(ctx, u, svc, client, f, progress)
+	done()
+	return file, err
+}
+
+func (c *DriveUploadCmd) runRecursive(ctx context.Context, u *ui.UI, account string, localDir string) error {
+	if strings.TrimSpace(c.ReplaceFileID) != "" {
+		return usage("--recursive cannot be combined with --replace")
+	}
+	if strings.TrimSpace(c.ConvertTo) != "" || strings.TrimSpace(c.MimeType) != "" {
+		return usage("--recursive cannot be combined with --convert-to or --mime-type")
+	}
+
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		name = filepath.Base(filepath.Clean(localDir))
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	folder, results, err := uploadDriveDirectory(ctx, svc, u, localDir, name, strings.TrimSpace(c.Parent), c.Convert, c.KeepRevisionForever, c.Concurrency)
+	if err != nil {
+		return err
+	}
+	return writeDriveTransferSummary(ctx, u, map[string]any{
+		"folderId": folder.Id,
+		"name":     folder.Name,
+		"link":     folder.WebViewLink,
+	}, results)
+}
+
+// replaceDriveFileContent uploads new content for an existing file in-place,
+// preserving its ID (and thus shared links/permissions). An empty name keeps the
+// current file name.
+func replaceDriveFileContent(ctx context.Context, svc *drive.Service, fileID string, name string, media io.Reader, mimeType string, keepRevisionForever bool) (*drive.File, error) {
+	meta := &drive.File{}
+	if name != "" {
+		meta.Name = name
+	}
+
+	call := svc.Files.Update(fileID, meta).
+		SupportsAllDrives(true).
+		Media(media, gapi.ContentType(mimeType)).
+		Fields("id, name, mimeType, size, webViewLink").
+		Context(ctx)
+	if keepRevisionForever {
+		call = call.KeepRevisionForever(true)
+	}
+	return call.Do()
+}
+
 type DriveMkdirCmd struct {
 	Name   string `arg:"" name:"name" help:"Folder name"`
 	Parent string `name:"parent" help:"Parent folder ID"`
@@ -523,19 +659,7 @@ func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	f := &drive.File{
-		Name:     name,
-		MimeType: "application/vnd.google-apps.folder",
-	}
-	if strings.TrimSpace(c.Parent) != "" {
-		f.Parents = []string{strings.TrimSpace(c.Parent)}
-	}
-
-	created, err := svc.Files.Create(f).
-		SupportsAllDrives(true).
-		Fields("id, name, webViewLink").
-		Context(ctx).
-		Do()
+	created, err := createDriveFolder(ctx, svc, name, strings.TrimSpace(c.Parent))
 	if err != nil {
 		return err
 	}
@@ -552,6 +676,23 @@ func (c *DriveMkdirCmd) Run(ctx context.Context, flags *RootFlags) error {
 	return nil
 }
 
+// createDriveFolder creates a folder named name under parent (My Drive root when empty).
+func createDriveFolder(ctx context.Context, svc *drive.Service, name string, parent string) (*drive.File, error) {
+	f := &drive.File{
+		Name:     name,
+		MimeType: driveMimeFolder,
+	}
+	if parent != "" {
+		f.Parents = []string{parent}
+	}
+
+	return svc.Files.Create(f).
+		SupportsAllDrives(true).
+		Fields("id, name, webViewLink").
+		Context(ctx).
+		Do()
+}
+
 type DriveDeleteCmd struct {
 	FileID    string `arg:"" name:"fileId" help:"File ID"`
 	Permanent bool   `name:"permanent" help:"Permanently delete instead of moving to trash" default:"false"`
@@ -710,8 +851,11 @@ type DriveShareCmd struct {
 	Anyone       bool   `name:"anyone" hidden:"" help:"(deprecated) Use --to=anyone"`
 	Email        string `name:"email" help:"User email (for --to=user)"`
 	Domain       string `name:"domain" help:"Domain (for --to=domain; e.g. example.com)"`
-	Role         string `name:"role" help:"Permission: reader|writer" default:"reader"`
+	Role         string `name:"role" help:"Permission: reader|commenter|writer|fileOrganizer|organizer" default:"reader"`
 	Discoverable bool   `name:"discoverable" help:"Allow file discovery in search (anyone/domain only)"`
+	Expires      string `name:"expires" aliases:"expiration" help:"Expire access at a time (RFC3339, YYYY-MM-DD, or relative like 30d; --to=user only)"`
+	Recursive    bool   `name:"recursive" help:"Apply to a folder and everything below it (use --dry-run to preview)"`
+	Concurrency  int    `name:"concurrency" help:"Parallel permission updates for --recursive" default:"4"`
 }
 
 func (c *DriveShareCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -772,20 +916,19 @@ func (c *DriveShareCmd) Run(ctx context.Context, flags *RootFlags) error {
 		// Should be guarded by enum, but keep a friendly message for future changes.
 		return usage("invalid --to (expected anyone|user|domain)")
 	}
-	role := strings.TrimSpace(c.Role)
-	if role == "" {
-		role = drivePermRoleReader
-	}
-	if role != drivePermRoleReader && role != drivePermRoleWriter {
-		return usage("invalid --role (expected reader|writer)")
+	role, err := normalizeDriveShareRole(c.Role)
+	if err != nil {
+		return err
 	}
-
-	svc, err := newDriveService(ctx, account)
+	expiration, err := parseDriveExpiration(c.Expires, time.Now())
 	if err != nil {
 		return err
 	}
+	if expiration != "" && to != driveShareToUser {
+		return usage("--expires is only valid for --to=user")
+	}
 
-	perm := &drive.Permission{Role: role}
+	perm := &drive.Permission{Role: role, ExpirationTime: expiration}
 	switch to {
 	case driveShareToAnyone:
 		perm.Type = "anyone"
@@ -799,10 +942,32 @@ func (c *DriveShareCmd) Run(ctx context.Context, flags *RootFlags) error {
 		perm.EmailAddress = email
 	}
 
+	if !c.Recursive {
+		if err := dryRunExit(ctx, flags, "drive.share", map[string]any{
+			"file_id":         fileID,
+			"type":            perm.Type,
+			"role":            perm.Role,
+			"email":           perm.EmailAddress,
+			"domain":          perm.Domain,
+			"discoverable":    perm.AllowFileDiscovery,
+			"expiration_time": perm.ExpirationTime,
+		}); err != nil {
+			return err
+		}
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+	if c.Recursive {
+		return c.runRecursive(ctx, u, flags, svc, fileID, perm)
+	}
+
 	created, err := svc.Permissions.Create(fileID, perm).
 		SupportsAllDrives(true).
 		SendNotificationEmail(false).
-		Fields("id, type, role, emailAddress, domain, allowFileDiscovery").
+		Fields("id, type, role, emailAddress, domain, allowFileDiscovery, expirationTime").
 		Context(ctx).
 		Do()
 	if err != nil {
@@ -829,7 +994,10 @@ func (c *DriveShareCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 type DriveUnshareCmd struct {
 	FileID       string `arg:"" name:"fileId" help:"File ID"`
-	PermissionID string `arg:"" name:"permissionId" help:"Permission ID"`
+	PermissionID string `arg:"" optional:"" name:"permissionId" help:"Permission ID (or use --email)"`
+	Email        string `name:"email" help:"Remove access for this user or group email instead of a permission ID"`
+	Recursive    bool   `name:"recursive" help:"Also remove the access from everything below the folder (use --dry-run to preview)"`
+	Concurrency  int    `name:"concurrency" help:"Parallel permission updates for --recursive" default:"4"`
 }
 
 func (c *DriveUnshareCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -840,11 +1008,18 @@ func (c *DriveUnshareCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 	fileID := strings.TrimSpace(c.FileID)
 	permissionID := strings.TrimSpace(c.PermissionID)
+	email := strings.TrimSpace(c.Email)
 	if fileID == "" {
 		return usage("empty fileId")
 	}
-	if permissionID == "" {
-		return usage("empty permissionId")
+	if permissionID == "" && email == "" {
+		return usage("empty permissionId (or use --email)")
+	}
+	if permissionID != "" && email != "" {
+		return usage("use either permissionId or --email, not both")
+	}
+	if email != "" || c.Recursive {
+		return c.runBulk(ctx, u, flags, account, fileID, permissionID, email)
 	}
 
 	if confirmErr := confirmDestructive(ctx, flags, fmt.Sprintf("remove permission %s from drive file %s", permissionID, fileID)); confirmErr != nil {
@@ -1040,7 +1215,7 @@ func escapeDriveQueryString(s string) string {
 }
 
 func driveType(mimeType string) string {
-	if mimeType == "application/vnd.google-apps.folder" {
+	if mimeType == driveMimeFolder {
 		return "folder"
 	}
 	return strFile
@@ -1155,22 +1330,30 @@ func downloadDriveFile(ctx context.Context, svc *drive.Service, meta *drive.File
 	}
 	defer resp.Body.Close()
 
+	n, err := saveDriveDownload(resp, outPath)
+	if err != nil {
+		return "", 0, err
+	}
+	return outPath, n, nil
+}
+
+// saveDriveDownload writes a successful download response to outPath.
+func saveDriveDownload(resp *http.Response, outPath string) (int64, error) {
+	if resp == nil {
+		return 0, errors.New("empty download response")
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return "", 0, fmt.Errorf("download failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		return 0, fmt.Errorf("download failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
 	}
 
 	f, err := os.Create(outPath) //nolint:gosec // user-provided path
 	if err != nil {
-		return "", 0, err
+		return 0, err
 	}
 	defer f.Close()
 
-	n, err := io.Copy(f, resp.Body)
-	if err != nil {
-		return "", 0, err
-	}
-	return outPath, n, nil
+	return io.Copy(f, resp.Body)
 }
 
 func driveFilesListCallWithDriveSupport(call *drive.FilesListCall, allDrives bool) *drive.FilesListCall {
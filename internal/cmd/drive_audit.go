@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const driveAuditPermissionFields = "nextPageToken, permissions(id, type, role, emailAddress, domain, displayName, " +
+	"allowFileDiscovery, expirationTime, deleted, permissionDetails(inherited, inheritedFrom))"
+
+type DriveAuditCmd struct {
+	FolderID     string `arg:"" name:"folderId" help:"Folder ID or shared drive ID to audit"`
+	Format       string `name:"format" help:"Report format: table|csv (use --json for JSON)" default:"table" enum:"table,csv"`
+	Out          string `name:"out" help:"Write the report to a file (CSV, or JSON with --json)"`
+	ExternalOnly bool   `name:"external-only" help:"Only permissions granted outside --domain (includes anyone-with-link)"`
+	AnyoneOnly   bool   `name:"anyone-only" help:"Only anyone-with-link/public permissions"`
+	DirectOnly   bool   `name:"direct-only" help:"Skip permissions inherited from a parent folder"`
+	Domain       string `name:"domain" help:"Internal domain for --external-only (default: the account's domain)"`
+	Concurrency  int    `name:"concurrency" help:"Parallel permission lookups" default:"4"`
+}
+
+// driveAuditRow is one permission on one file, flattened for CSV/JSON reports.
+type driveAuditRow struct {
+	Path               string `json:"path"`
+	FileID             string `json:"fileId"`
+	MimeType           string `json:"mimeType"`
+	PermissionID       string `json:"permissionId"`
+	Type               string `json:"type"`
+	Role               string `json:"role"`
+	EmailAddress       string `json:"emailAddress,omitempty"`
+	Domain             string `json:"domain,omitempty"`
+	DisplayName        string `json:"displayName,omitempty"`
+	Inherited          bool   `json:"inherited"`
+	InheritedFrom      string `json:"inheritedFrom,omitempty"`
+	External           bool   `json:"external"`
+	AllowFileDiscovery bool   `json:"allowFileDiscovery,omitempty"`
+	ExpirationTime     string `json:"expirationTime,omitempty"`
+	WebViewLink        string `json:"webViewLink,omitempty"`
+	Error              string `json:"error,omitempty"`
+}
+
+var driveAuditCSVHeader = []string{
+	"path", "fileId", "mimeType", "permissionId", "type", "role", "emailAddress", "domain",
+	"displayName", "inherited", "inheritedFrom", "external", "allowFileDiscovery", "expirationTime", "webViewLink", "error",
+}
+
+func (r driveAuditRow) csvRecord() []string {
+	return []string{
+		r.Path, r.FileID, r.MimeType, r.PermissionID, r.Type, r.Role, r.EmailAddress, r.Domain,
+		r.DisplayName, strconv.FormatBool(r.Inherited), r.InheritedFrom, strconv.FormatBool(r.External),
+		strconv.FormatBool(r.AllowFileDiscovery), r.ExpirationTime, r.WebViewLink, r.Error,
+	}
+}
+
+func (c *DriveAuditCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	folderID := normalizeGoogleID(strings.TrimSpace(c.FolderID))
+	if folderID == "" {
+		return usage("empty folderId")
+	}
+	domain := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(c.Domain), "@"))
+	if domain == "" {
+		if _, after, ok := strings.Cut(account, "@"); ok {
+			domain = strings.ToLower(after)
+		}
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	root, err := svc.Files.Get(folderID).
+		SupportsAllDrives(true).
+		Fields("id, name, mimeType, webViewLink").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+	if root.MimeType != driveMimeFolder {
+		return usage("folderId is not a folder (use drive permissions for single files)")
+	}
+
+	entries, err := walkDriveTree(ctx, svc, root.Id, "id, name, mimeType, parents, webViewLink")
+	if err != nil {
+		return err
+	}
+	entries = append([]driveTreeEntry{{Path: ".", File: root}}, entries...)
+
+	perms, failed := listDriveTreePermissions(ctx, svc, u, entries, c.Concurrency)
+	rows := buildDriveAuditRows(entries, perms, failed, domain)
+	summary := map[string]int{
+		"files":       len(entries),
+		"permissions": len(rows) - len(failed),
+	}
+	if len(failed) > 0 {
+		summary["errors"] = len(failed)
+	}
+	for _, r := range rows {
+		if r.Type == "anyone" {
+			summary["anyone"]++
+		}
+		if r.External {
+			summary["external"]++
+		}
+	}
+	rows = filterDriveAuditRows(rows, c.ExternalOnly, c.AnyoneOnly, c.DirectOnly)
+	summary["matched"] = len(rows)
+
+	if out := strings.TrimSpace(c.Out); out != "" {
+		return c.writeReportFile(ctx, u, out, root, rows, summary)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if rows == nil {
+			rows = []driveAuditRow{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"folderId":    root.Id,
+			"name":        root.Name,
+			"domain":      domain,
+			"permissions": rows,
+			"summary":     summary,
+		})
+	}
+	if c.Format == "csv" {
+		return writeDriveAuditCSV(os.Stdout, rows)
+	}
+
+	if len(rows) == 0 {
+		u.Err().Println("No permissions matched")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "PATH\tTYPE\tROLE\tWHO\tINHERITED\tEXTERNAL")
+	for _, r := range rows {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\t-\t-\terror: %s\t-\t-\n", r.Path, r.Error)
+			continue
+		}
+		who := r.EmailAddress
+		if who == "" {
+			who = r.Domain
+		}
+		if who == "" {
+			who = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%t\n", r.Path, r.Type, r.Role, who, r.Inherited, r.External)
+	}
+	return nil
+}
+
+func (c *DriveAuditCmd) writeReportFile(ctx context.Context, u *ui.UI, outPath string, root *drive.File, rows []driveAuditRow, summary map[string]int) error {
+	outPath, err := config.ExpandPath(outPath)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(outPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:gosec // user-provided path
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if outfmt.IsJSON(ctx) {
+		if rows == nil {
+			rows = []driveAuditRow{}
+		}
+		if err := outfmt.WriteJSON(ctx, f, map[string]any{
+			"folderId":    root.Id,
+			"name":        root.Name,
+			"permissions": rows,
+			"summary":     summary,
+		}); err != nil {
+			return err
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"path": outPath, "summary": summary})
+	}
+	if err := writeDriveAuditCSV(f, rows); err != nil {
+		return err
+	}
+	u.Out().Printf("path\t%s", outPath)
+	u.Out().Printf("permissions\t%d", len(rows))
+	return nil
+}
+
+func writeDriveAuditCSV(w io.Writer, rows []driveAuditRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(driveAuditCSVHeader); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := cw.Write(r.csvRecord()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// listDriveTreePermissions fetches the permissions of every entry with bounded
+// parallelism, keyed by file ID. Files whose permissions cannot be listed
+// (readers get 403 on shared items) are returned in failed and warned about,
+// so one unreadable file does not abort a whole tree.
+func listDriveTreePermissions(ctx context.Context, svc *drive.Service, u *ui.UI, entries []driveTreeEntry, concurrency int) (perms map[string][]*drive.Permission, failed map[string]error) {
+	if concurrency <= 0 {
+		concurrency = defaultDriveTransferConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	perms = make(map[string][]*drive.Permission, len(entries))
+	failed = map[string]error{}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	seen := make(map[string]bool, len(entries))
+	var scanned []driveTreeEntry
+	for _, entry := range entries {
+		fileID := entry.File.Id
+		if seen[fileID] {
+			continue
+		}
+		seen[fileID] = true
+		scanned = append(scanned, entry)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			list, err := listDrivePermissions(ctx, svc, fileID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[fileID] = err
+				return
+			}
+			perms[fileID] = list
+		}()
+	}
+	wg.Wait()
+	if u != nil {
+		for _, entry := range scanned {
+			if err := failed[entry.File.Id]; err != nil {
+				u.Err().Printf("Warning: could not list permissions for %s: %v", entry.Path, err)
+			}
+		}
+		u.Err().Printf("Scanned %d items", len(seen))
+	}
+	return perms, failed
+}
+
+func listDrivePermissions(ctx context.Context, svc *drive.Service, fileID string) ([]*drive.Permission, error) {
+	fetch := func(pageToken string) ([]*drive.Permission, string, error) {
+		call := svc.Permissions.List(fileID).
+			SupportsAllDrives(true).
+			PageSize(100).
+			Fields(gapi.Field(driveAuditPermissionFields)).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Permissions, resp.NextPageToken, nil
+	}
+	return collectAllPages("", fetch)
+}
+
+// buildDriveAuditRows flattens permissions in tree order. A permission counts as
+// inherited when Drive reports it (shared drives) or when the parent folder in
+// the tree carries the same permission with the same role (My Drive).
+func buildDriveAuditRows(entries []driveTreeEntry, perms map[string][]*drive.Permission, failed map[string]error, domain string) []driveAuditRow {
+	paths := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if _, ok := paths[e.File.Id]; !ok {
+			paths[e.File.Id] = e.Path
+		}
+	}
+	roleOn := func(fileID, permID string) string {
+		for _, p := range perms[fileID] {
+			if p != nil && p.Id == permID {
+				return p.Role
+			}
+		}
+		return ""
+	}
+
+	var rows []driveAuditRow
+	reported := map[string]bool{}
+	for _, e := range entries {
+		f := e.File
+		if err := failed[f.Id]; err != nil && !reported[f.Id] {
+			reported[f.Id] = true
+			rows = append(rows, driveAuditRow{Path: e.Path, FileID: f.Id, MimeType: f.MimeType, WebViewLink: f.WebViewLink, Error: err.Error()})
+			continue
+		}
+		for _, p := range perms[f.Id] {
+			if p == nil || p.Deleted {
+				continue
+			}
+			row := driveAuditRow{
+				Path:               e.Path,
+				FileID:             f.Id,
+				MimeType:           f.MimeType,
+				PermissionID:       p.Id,
+				Type:               p.Type,
+				Role:               p.Role,
+				EmailAddress:       p.EmailAddress,
+				Domain:             p.Domain,
+				DisplayName:        p.DisplayName,
+				External:           isExternalDrivePermission(p, domain),
+				AllowFileDiscovery: p.AllowFileDiscovery,
+				ExpirationTime:     p.ExpirationTime,
+				WebViewLink:        f.WebViewLink,
+			}
+			for _, d := range p.PermissionDetails {
+				if d != nil && d.Inherited {
+					row.Inherited = true
+					row.InheritedFrom = d.InheritedFrom
+					break
+				}
+			}
+			if !row.Inherited && e.Path != "." {
+				for _, parent := range f.Parents {
+					if _, inTree := paths[parent]; inTree && roleOn(parent, p.Id) == p.Role {
+						row.Inherited = true
+						row.InheritedFrom = parent
+						break
+					}
+				}
+			}
+			if row.InheritedFrom != "" {
+				if parentPath, ok := paths[row.InheritedFrom]; ok {
+					row.InheritedFrom = parentPath
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// isExternalDrivePermission reports whether p grants access outside domain.
+// Anyone-with-link is always external; without a domain nothing else is.
+func isExternalDrivePermission(p *drive.Permission, domain string) bool {
+	switch p.Type {
+	case "anyone":
+		return true
+	case "domain":
+		return domain != "" && !strings.EqualFold(p.Domain, domain)
+	case "user", "group":
+		_, after, ok := strings.Cut(p.EmailAddress, "@")
+		return domain != "" && ok && !strings.EqualFold(after, domain)
+	default:
+		return false
+	}
+}
+
+func filterDriveAuditRows(rows []driveAuditRow, externalOnly, anyoneOnly, directOnly bool) []driveAuditRow {
+	if !externalOnly && !anyoneOnly && !directOnly {
+		return rows
+	}
+	out := rows[:0:0]
+	for _, r := range rows {
+		if r.Error != "" {
+			// Unreadable files could hold any permission; always report them.
+			out = append(out, r)
+			continue
+		}
+		if externalOnly && !r.External {
+			continue
+		}
+		if anyoneOnly && r.Type != "anyone" {
+			continue
+		}
+		if directOnly && r.Inherited {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
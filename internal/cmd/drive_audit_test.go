@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newDriveAuditTestServer(t *testing.T) func() {
+	t.Helper()
+
+	owner := map[string]any{"id": "p-owner", "type": "user", "role": "owner", "emailAddress": "a@b.com"}
+	anyone := map[string]any{"id": "anyoneWithLink", "type": "anyone", "role": "reader"}
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+		q := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/files/root1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "root1", "name": "Team", "mimeType": driveMimeFolder})
+		case path == "/files" && strings.Contains(q.Get("q"), "'root1' in parents"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"files": []map[string]any{
+				{"id": "sub1", "name": "Docs", "mimeType": driveMimeFolder, "parents": []string{"root1"}},
+			}})
+		case path == "/files" && strings.Contains(q.Get("q"), "'sub1' in parents"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"files": []map[string]any{
+				{"id": "f1", "name": "plan.txt", "mimeType": "text/plain", "parents": []string{"sub1"}},
+				{"id": "f2", "name": "locked.txt", "mimeType": "text/plain", "parents": []string{"sub1"}},
+			}})
+		case path == "/files/root1/permissions":
+			_ = json.NewEncoder(w).Encode(map[string]any{"permissions": []any{owner, anyone}})
+		case path == "/files/sub1/permissions":
+			_ = json.NewEncoder(w).Encode(map[string]any{"permissions": []any{owner, anyone}})
+		case path == "/files/f1/permissions":
+			_ = json.NewEncoder(w).Encode(map[string]any{"permissions": []any{
+				owner,
+				anyone,
+				map[string]any{"id": "p-ext", "type": "user", "role": "writer", "emailAddress": "x@partner.org"},
+				map[string]any{"id": "p-dom", "type": "domain", "role": "reader", "domain": "b.com"},
+			}})
+		case path == "/files/f2/permissions":
+			// Readers of a shared item may not list its permissions.
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 403, "message": "insufficientFilePermissions"}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	newDriveService = stubDriveService(svc)
+	return closeSrv
+}
+
+func TestDriveAudit_ExternalOnly_JSON(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+	defer newDriveAuditTestServer(t)()
+
+	var out string
+	stderr := captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "audit", "root1", "--external-only", "--direct-only"}); err != nil {
+				t.Fatalf("audit: %v", err)
+			}
+		})
+	})
+
+	var got struct {
+		Permissions []driveAuditRow `json:"permissions"`
+		Summary     map[string]int  `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	// The anyone link on root is direct; on Docs and plan.txt it is inherited.
+	// The unreadable file is reported regardless of the filters.
+	if len(got.Permissions) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", got.Permissions)
+	}
+	if got.Permissions[0].Path != "." || got.Permissions[0].Type != "anyone" {
+		t.Fatalf("unexpected first row: %+v", got.Permissions[0])
+	}
+	if got.Permissions[1].Path != "Docs/plan.txt" || got.Permissions[1].EmailAddress != "x@partner.org" {
+		t.Fatalf("unexpected second row: %+v", got.Permissions[1])
+	}
+	if got.Permissions[2].Path != "Docs/locked.txt" || !strings.Contains(got.Permissions[2].Error, "insufficientFilePermissions") {
+		t.Fatalf("expected error row for locked.txt, got %+v", got.Permissions[2])
+	}
+	if got.Summary["files"] != 4 || got.Summary["permissions"] != 8 || got.Summary["errors"] != 1 || got.Summary["anyone"] != 3 || got.Summary["external"] != 4 {
+		t.Fatalf("unexpected summary: %v", got.Summary)
+	}
+	if !strings.Contains(stderr, "could not list permissions for Docs/locked.txt") {
+		t.Fatalf("expected warning for locked.txt, got %q", stderr)
+	}
+}
+
+func TestDriveAudit_AnyoneOnly_CSV(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+	defer newDriveAuditTestServer(t)()
+
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute([]string{"--account", "a@b.com", "drive", "audit", "root1", "--anyone-only", "--format", "csv"}); err != nil {
+				t.Fatalf("audit: %v", err)
+			}
+		})
+	})
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v (out=%q)", err, out)
+	}
+	if len(records) != 5 || records[0][0] != "path" || records[4][0] != "Docs/locked.txt" || records[4][15] == "" {
+		t.Fatalf("unexpected csv: %q", out)
+	}
+	if records[2][0] != "Docs" || records[2][9] != "true" || records[2][10] != "." {
+		t.Fatalf("expected inherited row for Docs, got %v", records[2])
+	}
+}
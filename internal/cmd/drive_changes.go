@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	driveChangeAdded    = "added"
+	driveChangeModified = "modified"
+	driveChangeTrashed  = "trashed"
+	driveChangeRemoved  = "removed"
+	driveChangeShared   = "shared"
+
+	// driveChangesSharingLimit bounds the remembered permission sets so a
+	// long --follow run does not grow the state file without limit.
+	driveChangesSharingLimit = 5000
+
+	driveChangesFields = "nextPageToken, newStartPageToken, changes(changeType, time, removed, fileId, driveId, " +
+		"file(id, name, mimeType, parents, trashed, createdTime, modifiedTime, shared, permissionIds, webViewLink, lastModifyingUser(emailAddress)))"
+)
+
+// driveChangesWait sleeps between --follow polls; tests replace it to stop the loop.
+var driveChangesWait = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+type DriveChangesCmd struct {
+	Since     string        `name:"since" help:"Start page token (default: stored cursor)"`
+	DriveID   string        `name:"drive" help:"Only report changes in this shared drive ID"`
+	Folder    string        `name:"folder" help:"Only report changes below this folder ID (including subfolders)"`
+	Max       int64         `name:"max" aliases:"limit" help:"Changes per API page (max 1000)" default:"100"`
+	Follow    bool          `name:"follow" aliases:"tail" help:"Keep polling and stream change events as NDJSON"`
+	Interval  time.Duration `name:"interval" help:"Polling interval for --follow" default:"30s"`
+	Reset     bool          `name:"reset" help:"Discard the stored cursor and start from now"`
+	Peek      bool          `name:"peek" help:"Do not advance the stored cursor"`
+	FailEmpty bool          `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
+}
+
+// driveChangeEvent is one classified entry of the Drive change feed.
+type driveChangeEvent struct {
+	Type        string   `json:"type"`
+	FileID      string   `json:"fileId"`
+	Name        string   `json:"name,omitempty"`
+	MimeType    string   `json:"mimeType,omitempty"`
+	Parents     []string `json:"parents,omitempty"`
+	DriveID     string   `json:"driveId,omitempty"`
+	Time        string   `json:"time,omitempty"`
+	ModifiedBy  string   `json:"modifiedBy,omitempty"`
+	WebViewLink string   `json:"webViewLink,omitempty"`
+}
+
+// driveChangesState is the persisted cursor for one account (and shared drive).
+// Sharing remembers the permission set of files seen in the feed so that
+// re-shares can be told apart from other metadata updates.
+type driveChangesState struct {
+	Account     string            `json:"account"`
+	DriveID     string            `json:"driveId,omitempty"`
+	PageToken   string            `json:"pageToken"`
+	CheckedAtMs int64             `json:"checkedAtMs,omitempty"`
+	Sharing     map[string]string `json:"sharing,omitempty"`
+	UpdatedAtMs int64             `json:"updatedAtMs"`
+}
+
+func driveChangesStatePath(account, driveID string) (string, error) {
+	dir, err := config.EnsureDriveChangesDir()
+	if err != nil {
+		return "", err
+	}
+	name := sanitizeAccountForPath(account)
+	if driveID = strings.TrimSpace(driveID); driveID != "" {
+		name += "_" + sanitizeAccountForPath(driveID)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+func loadDriveChangesState(path string) (*driveChangesState, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path inside config dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &driveChangesState{}, nil
+		}
+		return nil, err
+	}
+	var state driveChangesState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("read drive changes state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+func saveDriveChangesState(path string, state *driveChangesState) error {
+	state.UpdatedAtMs = time.Now().UnixMilli()
+	payload, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(payload, '\n'), 0o600)
+}
+
+func (c *DriveChangesCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if c.Max <= 0 || c.Max > 1000 {
+		return usage("--max must be between 1 and 1000")
+	}
+	if c.Follow && c.Interval < time.Second {
+		return usage("--interval must be at least 1s")
+	}
+	driveID := normalizeGoogleID(strings.TrimSpace(c.DriveID))
+	folderID := normalizeGoogleID(strings.TrimSpace(c.Folder))
+
+	statePath, err := driveChangesStatePath(account, driveID)
+	if err != nil {
+		return err
+	}
+	state, err := loadDriveChangesState(statePath)
+	if err != nil {
+		return err
+	}
+	if c.Reset {
+		state = &driveChangesState{}
+	}
+	state.Account = account
+	state.DriveID = driveID
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	var scope map[string]bool
+	if folderID != "" {
+		scope, err = driveFolderScope(ctx, svc, folderID)
+		if err != nil {
+			return err
+		}
+	}
+
+	token := strings.TrimSpace(c.Since)
+	if token == "" {
+		token = state.PageToken
+	}
+	initialized := false
+	if token == "" {
+		token, err = driveStartPageToken(ctx, svc, driveID)
+		if err != nil {
+			return err
+		}
+		initialized = true
+		state.PageToken = token
+		state.CheckedAtMs = time.Now().UnixMilli()
+		if !c.Peek {
+			if err := saveDriveChangesState(statePath, state); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.Follow {
+		return c.follow(ctx, u, svc, statePath, state, token, scope)
+	}
+
+	if initialized && u != nil {
+		u.Err().Println("Initialized change cursor; rerun to see subsequent changes")
+	}
+	events, next, err := c.poll(ctx, svc, state, token, scope)
+	if err != nil {
+		return err
+	}
+	if !c.Peek {
+		if err := saveDriveChangesState(statePath, state); err != nil {
+			return err
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if events == nil {
+			events = []driveChangeEvent{}
+		}
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"changes":           events,
+			"newStartPageToken": next,
+		}); err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return failEmptyExit(c.FailEmpty)
+		}
+		return nil
+	}
+
+	if len(events) == 0 {
+		u.Err().Println("No changes")
+		return failEmptyExit(c.FailEmpty)
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "TYPE\tTIME\tNAME\tID")
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Type, formatDateTime(e.Time), e.Name, e.FileID)
+	}
+	return nil
+}
+
+// follow polls the change feed until ctx is cancelled, writing one JSON object
+// per line for every event and persisting the cursor after each poll.
+func (c *DriveChangesCmd) follow(ctx context.Context, u *ui.UI, svc *drive.Service, statePath string, state *driveChangesState, token string, scope map[string]bool) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	if u != nil {
+		u.Err().Printf("Watching Drive changes every %s (Ctrl-C to stop)", c.Interval)
+	}
+	for {
+		events, next, err := c.poll(ctx, svc, state, token, scope)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		token = next
+		if !c.Peek {
+			if err := saveDriveChangesState(statePath, state); err != nil {
+				return err
+			}
+		}
+		if err := driveChangesWait(ctx, c.Interval); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// poll reads every change after token and returns the classified events and
+// the token to continue from. state is updated in place but not saved.
+func (c *DriveChangesCmd) poll(ctx context.Context, svc *drive.Service, state *driveChangesState, token string, scope map[string]bool) ([]driveChangeEvent, string, error) {
+	lastCheck := time.UnixMilli(state.CheckedAtMs)
+	checkedAt := time.Now()
+	driveID := state.DriveID
+
+	var events []driveChangeEvent
+	for token != "" {
+		call := svc.Changes.List(token).
+			PageSize(c.Max).
+			IncludeRemoved(true).
+			SupportsAllDrives(true).
+			IncludeItemsFromAllDrives(true).
+			Fields(gapi.Field(driveChangesFields)).
+			Context(ctx)
+		if driveID != "" {
+			call = call.DriveId(driveID)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		for _, ch := range resp.Changes {
+			if ch == nil || ch.ChangeType == "drive" {
+				continue
+			}
+			if scope != nil && !driveChangeInScope(ch, scope) {
+				continue
+			}
+			if ev, ok := classifyDriveChange(ch, lastCheck, state); ok {
+				events = append(events, ev)
+			}
+		}
+		if resp.NewStartPageToken != "" {
+			token = resp.NewStartPageToken
+			break
+		}
+		token = resp.NextPageToken
+	}
+
+	state.PageToken = token
+	state.CheckedAtMs = checkedAt.UnixMilli()
+	return events, token, nil
+}
+
+func driveStartPageToken(ctx context.Context, svc *drive.Service, driveID string) (string, error) {
+	call := svc.Changes.GetStartPageToken().SupportsAllDrives(true).Context(ctx)
+	if driveID != "" {
+		call = call.DriveId(driveID)
+	}
+	resp, err := call.Do()
+	if err != nil {
+		return "", err
+	}
+	return resp.StartPageToken, nil
+}
+
+// driveFolderScope returns folderID and the IDs of all folders below it.
+func driveFolderScope(ctx context.Context, svc *drive.Service, folderID string) (map[string]bool, error) {
+	entries, err := walkDriveTree(ctx, svc, folderID, "id, name, mimeType, parents")
+	if err != nil {
+		return nil, err
+	}
+	scope := map[string]bool{folderID: true}
+	for _, e := range entries {
+		if e.File.MimeType == driveMimeFolder {
+			scope[e.File.Id] = true
+		}
+	}
+	return scope, nil
+}
+
+// driveChangeInScope reports whether a change belongs to the scoped folder tree.
+// Folders created inside the tree extend the scope for later changes.
+func driveChangeInScope(ch *drive.Change, scope map[string]bool) bool {
+	if ch.File == nil {
+		// Permanently deleted files have no parents left to check.
+		return scope[ch.FileId]
+	}
+	for _, p := range ch.File.Parents {
+		if scope[p] {
+			if ch.File.MimeType == driveMimeFolder {
+				scope[ch.File.Id] = true
+			}
+			return true
+		}
+	}
+	return scope[ch.FileId]
+}
+
+// classifyDriveChange maps a raw change onto an event type. Drive does not say
+// what changed, so the type is inferred: creation after the previous check is
+// "added", a different permission set is "shared", and anything else is
+// "modified".
+func classifyDriveChange(ch *drive.Change, lastCheck time.Time, state *driveChangesState) (driveChangeEvent, bool) {
+	ev := driveChangeEvent{FileID: ch.FileId, DriveID: ch.DriveId, Time: ch.Time}
+	if state.Sharing == nil {
+		state.Sharing = map[string]string{}
+	}
+
+	f := ch.File
+	if ch.Removed || f == nil {
+		delete(state.Sharing, ch.FileId)
+		ev.Type = driveChangeRemoved
+		return ev, ch.FileId != ""
+	}
+
+	ev.Name = f.Name
+	ev.MimeType = f.MimeType
+	ev.Parents = f.Parents
+	ev.WebViewLink = f.WebViewLink
+	if f.LastModifyingUser != nil {
+		ev.ModifiedBy = f.LastModifyingUser.EmailAddress
+	}
+
+	perms := append([]string(nil), f.PermissionIds...)
+	sort.Strings(perms)
+	sharing := strings.Join(perms, ",")
+	prevSharing, seen := state.Sharing[ch.FileId]
+	switch {
+	case f.Trashed:
+		delete(state.Sharing, ch.FileId)
+	case seen:
+		state.Sharing[ch.FileId] = sharing
+	default:
+		pruneDriveChangesSharing(state.Sharing, driveChangesSharingLimit)
+		state.Sharing[ch.FileId] = sharing
+	}
+
+	created := parseDriveTime(f.CreatedTime)
+	modified := parseDriveTime(f.ModifiedTime)
+	switch {
+	case f.Trashed:
+		ev.Type = driveChangeTrashed
+	case state.CheckedAtMs > 0 && !created.IsZero() && !created.Before(lastCheck):
+		ev.Type = driveChangeAdded
+	case seen && prevSharing != sharing:
+		ev.Type = driveChangeShared
+	case !seen && f.Shared && state.CheckedAtMs > 0 && !modified.IsZero() && modified.Before(lastCheck):
+		// Content untouched since the last check, so the change is metadata;
+		// for a shared file that is most likely its permissions.
+		ev.Type = driveChangeShared
+	default:
+		ev.Type = driveChangeModified
+	}
+	return ev, true
+}
+
+// pruneDriveChangesSharing makes room for one more entry once sharing holds
+// limit entries, dropping a quarter of them. A dropped file only loses exact
+// re-share detection until it shows up in the feed again.
+func pruneDriveChangesSharing(sharing map[string]string, limit int) {
+	if len(sharing) < limit {
+		return
+	}
+	drop := len(sharing) - limit*3/4
+	for id := range sharing {
+		if drop <= 0 {
+			return
+		}
+		delete(sharing, id)
+		drop--
+	}
+}
+
+func parseDriveTime(raw string) time.Time {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(raw))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
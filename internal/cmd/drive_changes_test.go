@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestDriveChanges_CursorAndFollow(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+
+	origNew := newDriveService
+	origWait := driveChangesWait
+	t.Cleanup(func() {
+		newDriveService = origNew
+		driveChangesWait = origWait
+	})
+
+	now := time.Now().UTC().Add(time.Minute).Format(time.RFC3339)
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch strings.TrimPrefix(r.URL.Path, "/drive/v3") {
+		case "/changes/startPageToken":
+			_ = json.NewEncoder(w).Encode(map[string]any{"startPageToken": "10"})
+		case "/changes":
+			switch r.URL.Query().Get("pageToken") {
+			case "10":
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"nextPageToken": "10b",
+					"changes": []map[string]any{
+						{"fileId": "new1", "time": now, "file": map[string]any{"id": "new1", "name": "New.txt", "createdTime": now, "modifiedTime": now}},
+						{"fileId": "old1", "time": now, "file": map[string]any{"id": "old1", "name": "Old.txt", "trashed": true}},
+					},
+				})
+			case "10b":
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"newStartPageToken": "11",
+					"changes": []map[string]any{
+						{"fileId": "gone1", "time": now, "removed": true},
+						{"changeType": "drive", "driveId": "d1"},
+					},
+				})
+			case "11":
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"newStartPageToken": "12",
+					"changes": []map[string]any{
+						{"fileId": "old2", "time": now, "file": map[string]any{"id": "old2", "name": "Plan", "modifiedTime": "2020-01-01T00:00:00Z", "createdTime": "2020-01-01T00:00:00Z"}},
+					},
+				})
+			default:
+				_ = json.NewEncoder(w).Encode(map[string]any{"newStartPageToken": r.URL.Query().Get("pageToken")})
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer closeSrv()
+	newDriveService = stubDriveService(svc)
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out string
+		_ = captureStderr(t, func() {
+			out = captureStdout(t, func() {
+				if err := Execute(append([]string{"--json", "--account", "a@b.com", "drive", "changes"}, args...)); err != nil {
+					t.Fatalf("changes %v: %v", args, err)
+				}
+			})
+		})
+		return out
+	}
+
+	// The first run only stores a fresh cursor; the sample server still reports the backlog.
+	type result struct {
+		Changes []driveChangeEvent `json:"changes"`
+		Token   string             `json:"newStartPageToken"`
+	}
+	var first result
+	if err := json.Unmarshal([]byte(run()), &first); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if first.Token != "11" || len(first.Changes) != 3 {
+		t.Fatalf("unexpected first result: %+v", first)
+	}
+	wantTypes := []string{driveChangeAdded, driveChangeTrashed, driveChangeRemoved}
+	for i, ev := range first.Changes {
+		if ev.Type != wantTypes[i] {
+			t.Fatalf("change %d: expected %s, got %+v", i, wantTypes[i], ev)
+		}
+	}
+
+	statePath, err := driveChangesStatePath("a@b.com", "")
+	if err != nil {
+		t.Fatalf("statePath: %v", err)
+	}
+	state, err := loadDriveChangesState(statePath)
+	if err != nil || state.PageToken != "11" {
+		t.Fatalf("expected stored cursor 11, got %+v err=%v", state, err)
+	}
+
+	polls := 0
+	driveChangesWait = func(context.Context, time.Duration) error {
+		polls++
+		if polls == 2 {
+			return context.Canceled
+		}
+		return nil
+	}
+	out := run("--follow")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one NDJSON line, got %q", out)
+	}
+	var ev driveChangeEvent
+	if err := json.Unmarshal([]byte(lines[0]), &ev); err != nil {
+		t.Fatalf("unmarshal line: %v", err)
+	}
+	if ev.FileID != "old2" || ev.Type != driveChangeModified {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+	state, err = loadDriveChangesState(statePath)
+	if err != nil || state.PageToken != "12" {
+		t.Fatalf("expected stored cursor 12, got %+v err=%v", state, err)
+	}
+}
+
+func TestClassifyDriveChange_Sharing(t *testing.T) {
+	lastCheck := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	state := &driveChangesState{CheckedAtMs: lastCheck.UnixMilli()}
+	change := func(perms ...string) *drive.Change {
+		return &drive.Change{FileId: "f1", File: &drive.File{
+			Id:            "f1",
+			CreatedTime:   "2024-01-01T00:00:00Z",
+			ModifiedTime:  "2025-01-03T00:00:00Z",
+			PermissionIds: perms,
+		}}
+	}
+
+	if ev, _ := classifyDriveChange(change("owner"), lastCheck, state); ev.Type != driveChangeModified {
+		t.Fatalf("expected modified, got %s", ev.Type)
+	}
+	if ev, _ := classifyDriveChange(change("anyoneWithLink", "owner"), lastCheck, state); ev.Type != driveChangeShared {
+		t.Fatalf("expected shared, got %s", ev.Type)
+	}
+	if ev, _ := classifyDriveChange(change("owner", "anyoneWithLink"), lastCheck, state); ev.Type != driveChangeModified {
+		t.Fatalf("expected modified for same permissions, got %s", ev.Type)
+	}
+
+	untouched := &drive.Change{FileId: "f2", File: &drive.File{Id: "f2", Shared: true, ModifiedTime: "2024-06-01T00:00:00Z"}}
+	if ev, _ := classifyDriveChange(untouched, lastCheck, state); ev.Type != driveChangeShared {
+		t.Fatalf("expected shared for metadata-only change, got %s", ev.Type)
+	}
+}
+
+func TestClassifyDriveChange_SharingIsBounded(t *testing.T) {
+	lastCheck := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	state := &driveChangesState{CheckedAtMs: lastCheck.UnixMilli()}
+	for i := 0; i < driveChangesSharingLimit*2; i++ {
+		id := fmt.Sprintf("f%d", i)
+		_, _ = classifyDriveChange(&drive.Change{FileId: id, File: &drive.File{Id: id, PermissionIds: []string{"owner"}}}, lastCheck, state)
+	}
+	if n := len(state.Sharing); n > driveChangesSharingLimit {
+		t.Fatalf("sharing map grew to %d entries (limit %d)", n, driveChangesSharingLimit)
+	}
+
+	trashed := &drive.Change{FileId: "t1", File: &drive.File{Id: "t1", Trashed: true}}
+	state.Sharing["t1"] = "owner"
+	if ev, _ := classifyDriveChange(trashed, lastCheck, state); ev.Type != driveChangeTrashed {
+		t.Fatalf("expected trashed, got %s", ev.Type)
+	}
+	if _, ok := state.Sharing["t1"]; ok {
+		t.Fatalf("expected trashed file to be forgotten")
+	}
+}
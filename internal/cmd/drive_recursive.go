@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	defaultDriveTransferConcurrency = 4
+
+	driveTransferDownloaded = "downloaded"
+	driveTransferUploaded   = "uploaded"
+	driveTransferSkipped    = "skipped"
+	driveTransferFailed     = "failed"
+)
+
+// driveTransferResult reports the outcome for one file of a recursive transfer.
+type driveTransferResult struct {
+	Path   string `json:"path"`
+	FileID string `json:"fileId,omitempty"`
+	Status string `json:"status"`
+	Size   int64  `json:"size,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// driveTransferTask transfers one file. It returns the Drive file ID and byte count.
+type driveTransferTask struct {
+	Path string
+	Run  func(ctx context.Context) (string, int64, error)
+}
+
+// runDriveTransfers executes tasks with bounded parallelism, printing per-file
+// progress to stderr. Results keep the task order.
+func runDriveTransfers(ctx context.Context, u *ui.UI, concurrency int, doneStatus string, tasks []driveTransferTask) []driveTransferResult {
+	results := make([]driveTransferResult, len(tasks))
+	runDriveBounded(ctx, concurrency, len(tasks), func(idx int) error {
+		id, size, err := tasks[idx].Run(ctx)
+		results[idx].FileID = id
+		results[idx].Size = size
+		return err
+	}, func(idx, finished int, err error) {
+		res := &results[idx]
+		res.Path = tasks[idx].Path
+		res.Status = doneStatus
+		if err != nil {
+			res.Status = driveTransferFailed
+			res.Error = err.Error()
+		}
+		if u == nil {
+			return
+		}
+		if res.Error != "" {
+			u.Err().Printf("[%d/%d] failed %s: %s", finished, len(tasks), res.Path, res.Error)
+			return
+		}
+		u.Err().Printf("[%d/%d] %s %s (%s)", finished, len(tasks), res.Status, res.Path, formatDriveSize(res.Size))
+	})
+	return results
+}
+
+// runDriveBounded calls run for each index below n on a fixed pool of
+// workers. done is called once per index, one call at a time, with the count
+// finished so far; indexes not started before ctx ends get ctx's error.
+func runDriveBounded(ctx context.Context, concurrency, n int, run func(idx int) error, done func(idx, finished int, err error)) {
+	if concurrency <= 0 {
+		concurrency = defaultDriveTransferConcurrency
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		finished int
+	)
+	finish := func(idx int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		finished++
+		done(idx, finished, err)
+	}
+
+	jobs := make(chan int)
+	for w := 0; w < min(concurrency, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				finish(idx, run(idx))
+			}
+		}()
+	}
+	for idx := 0; idx < n; idx++ {
+		select {
+		case jobs <- idx:
+		case <-ctx.Done():
+			finish(idx, ctx.Err())
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func writeDriveTransferSummary(ctx context.Context, u *ui.UI, root map[string]any, results []driveTransferResult) error {
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if results == nil {
+			results = []driveTransferResult{}
+		}
+		out := map[string]any{
+			"files":   results,
+			"summary": counts,
+		}
+		for k, v := range root {
+			out[k] = v
+		}
+		if err := outfmt.WriteJSON(ctx, os.Stdout, out); err != nil {
+			return err
+		}
+	} else {
+		keys := make([]string, 0, len(root))
+		for k := range root {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			u.Out().Printf("%s\t%v", k, root[k])
+		}
+		for _, status := range []string{driveTransferDownloaded, driveTransferUploaded, driveTransferSkipped, driveTransferFailed} {
+			if counts[status] > 0 {
+				u.Out().Printf("%s\t%d", status, counts[status])
+			}
+		}
+	}
+
+	if failed := counts[driveTransferFailed]; failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(results))
+	}
+	return nil
+}
+
+// downloadDriveFolder recreates folderID below destDir and downloads every file.
+// Google Workspace files are exported using format when valid for their type,
+// otherwise the inferred default export format.
+func downloadDriveFolder(ctx context.Context, svc *drive.Service, u *ui.UI, folder *drive.File, destDir string, format string, concurrency int) ([]driveTransferResult, error) {
+	entries, err := walkDriveTree(ctx, svc, folder.Id, "id, name, mimeType, size, parents")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(destDir, 0o700); err != nil {
+		return nil, err
+	}
+
+	type download struct {
+		file    *drive.File
+		outPath string
+		format  string
+	}
+	var (
+		skipped   []driveTransferResult
+		downloads []download
+	)
+	taken := map[string]int{}
+	for _, entry := range entries {
+		f := entry.File
+		localPath := filepath.Join(destDir, filepath.FromSlash(entry.Path))
+		switch {
+		case f.MimeType == driveMimeFolder:
+			if err := os.MkdirAll(localPath, 0o700); err != nil {
+				return nil, err
+			}
+			continue
+		case isGoogleWorkspaceMimeType(f.MimeType) && !isDriveExportable(f.MimeType):
+			skipped = append(skipped, driveTransferResult{Path: localPath, FileID: f.Id, Status: driveTransferSkipped, Reason: "not exportable (" + f.MimeType + ")"})
+			continue
+		}
+
+		d := download{file: f, outPath: localPath}
+		if isGoogleWorkspaceMimeType(f.MimeType) {
+			exportMimeType := driveExportMimeType(f.MimeType)
+			if format != "" {
+				if formatMimeType, formatErr := driveExportMimeTypeForFormat(f.MimeType, format); formatErr == nil {
+					d.format = format
+					exportMimeType = formatMimeType
+				}
+			}
+			d.outPath = replaceExt(localPath, driveExportExtension(exportMimeType))
+		}
+		taken[strings.ToLower(d.outPath)]++
+		downloads = append(downloads, d)
+	}
+
+	// Drive allows siblings with the same name (and same-named sibling folders
+	// merge locally); give every clashing file a "name (fileId).ext" path so
+	// parallel downloads never write the same file.
+	tasks := make([]driveTransferTask, 0, len(downloads))
+	for _, d := range downloads {
+		if taken[strings.ToLower(d.outPath)] > 1 {
+			d.outPath = driveUniqueLocalPath(d.outPath, d.file.Id)
+		}
+		tasks = append(tasks, driveTransferTask{
+			Path: d.outPath,
+			Run: func(ctx context.Context) (string, int64, error) {
+				_, n, err := downloadDriveFile(ctx, svc, d.file, d.outPath, d.format)
+				return d.file.Id, n, err
+			},
+		})
+	}
+
+	results := runDriveTransfers(ctx, u, concurrency, driveTransferDownloaded, tasks)
+	return append(skipped, results...), nil
+}
+
+// driveUniqueLocalPath inserts the Drive file ID before the extension.
+func driveUniqueLocalPath(p, fileID string) string {
+	ext := filepath.Ext(p)
+	return strings.TrimSuffix(p, ext) + " (" + fileID + ")" + ext
+}
+
+func resolveDriveFolderDownloadDir(folder *drive.File, outPathFlag string) (string, error) {
+	dest := strings.TrimSpace(outPathFlag)
+	if dest != "" {
+		return config.ExpandPath(dest)
+	}
+	dir, err := config.EnsureDriveDownloadsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s_%s", folder.Id, driveLocalName(folder.Name))), nil
+}
+
+// uploadDriveDirectory creates a folder named name below parent (root when empty)
+// that mirrors localDir, and uploads every regular file into it.
+func uploadDriveDirectory(ctx context.Context, svc *drive.Service, u *ui.UI, localDir, name, parent string, convert, keepRevisionForever bool, concurrency int) (*drive.File, []driveTransferResult, error) {
+	rootFolder, err := createDriveFolder(ctx, svc, name, parent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	folders := map[string]string{".": rootFolder.Id}
+	var (
+		skipped []driveTransferResult
+		tasks   []driveTransferTask
+	)
+	walkErr := filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		parentID, ok := folders[path.Dir(rel)]
+		if !ok {
+			return fmt.Errorf("missing Drive folder for %s", path.Dir(rel))
+		}
+		if d.IsDir() {
+			// WalkDir visits directories before their contents, so parents always exist.
+			created, err := createDriveFolder(ctx, svc, d.Name(), parentID)
+			if err != nil {
+				return err
+			}
+			folders[rel] = created.Id
+			if u != nil {
+				u.Err().Printf("created folder %s", rel)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			skipped = append(skipped, driveTransferResult{Path: p, Status: driveTransferSkipped, Reason: "not a regular file"})
+			return nil
+		}
+
+		meta := &drive.File{Name: d.Name(), Parents: []string{parentID}}
+		if convert {
+			if convertMimeType, ok := googleConvertMimeType(p); ok {
+				meta.MimeType = convertMimeType
+				meta.Name = stripOfficeExt(meta.Name)
+			}
+		}
+		tasks = append(tasks, driveTransferTask{
+			Path: p,
+			Run: func(ctx context.Context) (string, int64, error) {
+				created, err := uploadNewDriveFile(ctx, svc, p, meta, keepRevisionForever)
+				if err != nil {
+					return "", 0, err
+				}
+				return created.Id, created.Size, nil
+			},
+		})
+		return nil
+	})
+	if walkErr != nil {
+		return rootFolder, nil, walkErr
+	}
+
+	results := runDriveTransfers(ctx, u, concurrency, driveTransferUploaded, tasks)
+	return rootFolder, append(skipped, results...), nil
+}
+
+// uploadNewDriveFile creates a new Drive file described by meta with the content of localPath.
+func uploadNewDriveFile(ctx context.Context, svc *drive.Service, localPath string, meta *drive.File, keepRevisionForever bool) (*drive.File, error) {
+	f, err := os.Open(localPath) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	call := svc.Files.Create(meta).
+		SupportsAllDrives(true).
+		Media(f, gapi.ContentType(guessMimeType(localPath))).
+		Fields("id, name, mimeType, size, md5Checksum, modifiedTime, webViewLink").
+		Context(ctx)
+	if keepRevisionForever {
+		call = call.KeepRevisionForever(true)
+	}
+	return call.Do()
+}
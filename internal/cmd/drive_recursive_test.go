@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDriveDownload_Recursive_JSON(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodGet && path == "/files/root1":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "root1", "name": "Reports", "mimeType": driveMimeFolder})
+		case r.Method == http.MethodGet && path == "/files":
+			w.Header().Set("Content-Type", "application/json")
+			files := []map[string]any{}
+			switch {
+			case strings.Contains(q.Get("q"), "'root1' in parents"):
+				files = []map[string]any{
+					{"id": "sub1", "name": "Q1", "mimeType": driveMimeFolder},
+					{"id": "bin1", "name": "a.bin", "mimeType": "application/octet-stream", "size": "3"},
+					{"id": "form1", "name": "Survey", "mimeType": "application/vnd.google-apps.form"},
+				}
+			case strings.Contains(q.Get("q"), "'sub1' in parents"):
+				files = []map[string]any{
+					{"id": "doc1", "name": "Notes", "mimeType": driveMimeGoogleDoc},
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"files": files})
+		case r.Method == http.MethodGet && path == "/files/bin1" && q.Get("alt") == "media":
+			_, _ = w.Write([]byte("abc"))
+		case r.Method == http.MethodGet && path == "/files/doc1/export":
+			if got := q.Get("mimeType"); got != mimeDocx {
+				t.Errorf("expected docx export, got %q", got)
+			}
+			_, _ = w.Write([]byte("docx"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer closeSrv()
+	newDriveService = stubDriveService(svc)
+
+	dest := filepath.Join(t.TempDir(), "out")
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "download", "root1", "--recursive", "--format", "docx", "--out", dest}); err != nil {
+				t.Fatalf("download: %v", err)
+			}
+		})
+	})
+
+	var got struct {
+		Path    string                `json:"path"`
+		Files   []driveTransferResult `json:"files"`
+		Summary map[string]int        `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if got.Path != dest || got.Summary[driveTransferDownloaded] != 2 || got.Summary[driveTransferSkipped] != 1 {
+		t.Fatalf("unexpected summary: %+v", got)
+	}
+	if b, err := os.ReadFile(filepath.Join(dest, "a.bin")); err != nil || string(b) != "abc" {
+		t.Fatalf("a.bin: %q err=%v", b, err)
+	}
+	if b, err := os.ReadFile(filepath.Join(dest, "Q1", "Notes.docx")); err != nil || string(b) != "docx" {
+		t.Fatalf("Notes.docx: %q err=%v", b, err)
+	}
+}
+
+func TestDriveDownload_Recursive_SameNameSiblings(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+		q := r.URL.Query()
+		switch {
+		case r.Method == http.MethodGet && path == "/files/root1":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "root1", "name": "Reports", "mimeType": driveMimeFolder})
+		case r.Method == http.MethodGet && path == "/files":
+			w.Header().Set("Content-Type", "application/json")
+			files := []map[string]any{}
+			if strings.Contains(q.Get("q"), "'root1' in parents") {
+				files = []map[string]any{
+					{"id": "f1", "name": "report.pdf", "mimeType": "application/pdf", "size": "3"},
+					{"id": "f2", "name": "report.pdf", "mimeType": "application/pdf", "size": "3"},
+					// Exports to report.pdf as well.
+					{"id": "doc1", "name": "report", "mimeType": driveMimeGoogleDoc},
+					{"id": "f3", "name": "other.pdf", "mimeType": "application/pdf", "size": "3"},
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"files": files})
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "/files/f") && q.Get("alt") == "media":
+			_, _ = w.Write([]byte(strings.TrimPrefix(path, "/files/")))
+		case r.Method == http.MethodGet && path == "/files/doc1/export":
+			_, _ = w.Write([]byte("doc1"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer closeSrv()
+	newDriveService = stubDriveService(svc)
+
+	dest := filepath.Join(t.TempDir(), "out")
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "download", "root1", "--recursive", "--out", dest}); err != nil {
+				t.Fatalf("download: %v", err)
+			}
+		})
+	})
+
+	for name, want := range map[string]string{
+		"report (f1).pdf":   "f1",
+		"report (f2).pdf":   "f2",
+		"report (doc1).pdf": "doc1",
+		"other.pdf":         "f3",
+	} {
+		if b, err := os.ReadFile(filepath.Join(dest, name)); err != nil || string(b) != want {
+			t.Fatalf("%s: %q err=%v", name, b, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "report.pdf")); err == nil {
+		t.Fatalf("clashing files must not share report.pdf")
+	}
+}
+
+func TestDriveDownload_FolderRequiresRecursive(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "root1", "name": "Reports", "mimeType": driveMimeFolder})
+	}))
+	defer closeSrv()
+	newDriveService = stubDriveService(svc)
+
+	_ = captureStderr(t, func() {
+		err := Execute([]string{"--account", "a@b.com", "drive", "download", "root1"})
+		if err == nil || !strings.Contains(err.Error(), "--recursive") {
+			t.Fatalf("expected --recursive hint, got %v", err)
+		}
+	})
+}
+
+func TestDriveUpload_Recursive_JSON(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	var (
+		mu      sync.Mutex
+		folders = map[string]string{}
+		uploads int
+		nextID  int
+	)
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mu.Lock()
+		defer mu.Unlock()
+		nextID++
+		switch {
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/drive/v3/files"):
+			uploads++
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": fmt.Sprintf("file%d", nextID), "size": "1"})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/files"):
+			var body struct {
+				Name    string   `json:"name"`
+				Parents []string `json:"parents"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			id := "folder-" + body.Name
+			parent := ""
+			if len(body.Parents) > 0 {
+				parent = body.Parents[0]
+			}
+			folders[body.Name] = parent
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "name": body.Name, "webViewLink": "https://example.com/" + id})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer closeSrv()
+	newDriveService = stubDriveService(svc)
+
+	dir := filepath.Join(t.TempDir(), "site")
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o700); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	for _, p := range []string{"index.html", filepath.Join("assets", "app.js")} {
+		if err := os.WriteFile(filepath.Join(dir, p), []byte("x"), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "upload", dir, "--recursive", "--parent", "p0"}); err != nil {
+				t.Fatalf("upload: %v", err)
+			}
+		})
+	})
+
+	if folders["site"] != "p0" || folders["assets"] != "folder-site" {
+		t.Fatalf("unexpected folder hierarchy: %v", folders)
+	}
+	if uploads != 2 {
+		t.Fatalf("expected 2 uploads, got %d", uploads)
+	}
+	var got struct {
+		FolderID string         `json:"folderId"`
+		Summary  map[string]int `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if got.FolderID != "folder-site" || got.Summary[driveTransferUploaded] != 2 {
+		t.Fatalf("unexpected output: %+v", got)
+	}
+}
@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var newDriveHTTPClient = googleapi.NewDriveHTTPClient
+
+const driveRevisionFields = "id, mimeType, modifiedTime, size, keepForever, published, originalFilename, md5Checksum, lastModifyingUser(displayName, emailAddress)"
+
+// DriveRevisionsCmd is the parent command for file revision subcommands.
+type DriveRevisionsCmd struct {
+	List     DriveRevisionsListCmd     `cmd:"" name:"list" aliases:"ls" help:"List revisions of a file"`
+	Get      DriveRevisionsGetCmd      `cmd:"" name:"get" aliases:"info,show" help:"Get revision metadata"`
+	Download DriveRevisionsDownloadCmd `cmd:"" name:"download" aliases:"dl" help:"Download a revision (exports Google Docs formats)"`
+	Keep     DriveRevisionsKeepCmd     `cmd:"" name:"keep" help:"Keep a revision forever (binary files only)"`
+	Unkeep   DriveRevisionsUnkeepCmd   `cmd:"" name:"unkeep" help:"Let Drive purge a revision automatically again"`
+	Delete   DriveRevisionsDeleteCmd   `cmd:"" name:"delete" aliases:"rm,del,remove" help:"Permanently delete a revision (binary files only)"`
+}
+
+type DriveRevisionsListCmd struct {
+	FileID    string `arg:"" name:"fileId" help:"File ID"`
+	Max       int64  `name:"max" aliases:"limit" help:"Max results" default:"100"`
+	Page      string `name:"page" aliases:"cursor" help:"Page token"`
+	All       bool   `name:"all" aliases:"all-pages,allpages" help:"Fetch all pages"`
+	FailEmpty bool   `name:"fail-empty" aliases:"non-empty,require-results" help:"Exit with code 3 if no results"`
+}
+
+func (c *DriveRevisionsListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	fileID := normalizeGoogleID(strings.TrimSpace(c.FileID))
+	if fileID == "" {
+		return usage("empty fileId")
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	fetch := func(pageToken string) ([]*drive.Revision, string, error) {
+		call := svc.Revisions.List(fileID).
+			PageSize(c.Max).
+			Fields("nextPageToken", "revisions("+driveRevisionFields+")").
+			Context(ctx)
+		if strings.TrimSpace(pageToken) != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Revisions, resp.NextPageToken, nil
+	}
+
+	var revisions []*drive.Revision
+	nextPageToken := ""
+	if c.All {
+		all, err := collectAllPages(c.Page, fetch)
+		if err != nil {
+			return err
+		}
+		revisions = all
+	} else {
+		var err error
+		revisions, nextPageToken, err = fetch(c.Page)
+		if err != nil {
+			return err
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"fileId":        fileID,
+			"revisions":     revisions,
+			"nextPageToken": nextPageToken,
+		}); err != nil {
+			return err
+		}
+		if len(revisions) == 0 {
+			return failEmptyExit(c.FailEmpty)
+		}
+		return nil
+	}
+
+	if len(revisions) == 0 {
+		u.Err().Println("No revisions")
+		return failEmptyExit(c.FailEmpty)
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ID\tMODIFIED\tSIZE\tKEEP\tAUTHOR")
+	for _, r := range revisions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\n",
+			r.Id,
+			formatDateTime(r.ModifiedTime),
+			formatDriveSize(r.Size),
+			r.KeepForever,
+			driveRevisionAuthor(r),
+		)
+	}
+	printNextPageHint(u, nextPageToken)
+	return nil
+}
+
+type DriveRevisionsGetCmd struct {
+	FileID     string `arg:"" name:"fileId" help:"File ID"`
+	RevisionID string `arg:"" name:"revisionId" help:"Revision ID"`
+}
+
+func (c *DriveRevisionsGetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	fileID, revisionID, err := driveRevisionArgs(c.FileID, c.RevisionID)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	rev, err := svc.Revisions.Get(fileID, revisionID).
+		Fields(driveRevisionFields + ", exportLinks").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"revision": rev})
+	}
+
+	u.Out().Printf("id\t%s", rev.Id)
+	u.Out().Printf("modified\t%s", rev.ModifiedTime)
+	u.Out().Printf("size\t%s", formatDriveSize(rev.Size))
+	if rev.MimeType != "" {
+		u.Out().Printf("type\t%s", rev.MimeType)
+	}
+	if rev.OriginalFilename != "" {
+		u.Out().Printf("filename\t%s", rev.OriginalFilename)
+	}
+	if author := driveRevisionAuthor(rev); author != "-" {
+		u.Out().Printf("author\t%s", author)
+	}
+	u.Out().Printf("keep_forever\t%t", rev.KeepForever)
+	if len(rev.ExportLinks) > 0 {
+		u.Out().Printf("export_formats\t%s", strings.Join(sortedKeys(rev.ExportLinks), ","))
+	}
+	return nil
+}
+
+type DriveRevisionsDownloadCmd struct {
+	FileID     string         `arg:"" name:"fileId" help:"File ID"`
+	RevisionID string         `arg:"" name:"revisionId" help:"Revision ID"`
+	Output     OutputPathFlag `embed:""`
+	Format     string         `name:"format" help:"Export format for Google Docs files: pdf|csv|xlsx|pptx|txt|png|docx (default: inferred)"`
+}
+
+func (c *DriveRevisionsDownloadCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	fileID, revisionID, err := driveRevisionArgs(c.FileID, c.RevisionID)
+	if err != nil {
+		return err
+	}
+	if formatErr := validateDriveDownloadFormatFlag(c.Format); formatErr != nil {
+		return formatErr
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	meta, err := svc.Files.Get(fileID).
+		SupportsAllDrives(true).
+		Fields("id, name, mimeType").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+	if fileFormatErr := validateDriveDownloadFormatForFile(meta, c.Format); fileFormatErr != nil {
+		return fileFormatErr
+	}
+
+	// Name revision downloads <fileId>_<revisionId>_<name> so they don't clobber head downloads.
+	destPath, err := resolveDriveDownloadDestPath(&drive.File{Id: meta.Id + "_" + revisionID, Name: meta.Name}, c.Output.Path)
+	if err != nil {
+		return err
+	}
+
+	var (
+		resp    *http.Response
+		outPath = destPath
+	)
+	if isGoogleWorkspaceMimeType(meta.MimeType) {
+		exportMimeType, mimeErr := driveExportMimeTypeForFormat(meta.MimeType, c.Format)
+		if mimeErr != nil {
+			return mimeErr
+		}
+		rev, revErr := svc.Revisions.Get(fileID, revisionID).Fields("id, exportLinks").Context(ctx).Do()
+		if revErr != nil {
+			return revErr
+		}
+		link := rev.ExportLinks[exportMimeType]
+		if link == "" {
+			return fmt.Errorf("revision %s cannot be exported as %s (available: %s)", revisionID, exportMimeType, strings.Join(sortedKeys(rev.ExportLinks), ", "))
+		}
+		outPath = replaceExt(destPath, driveExportExtension(exportMimeType))
+		resp, err = driveRevisionExportDownload(ctx, account, link)
+	} else {
+		resp, err = driveRevisionDownload(ctx, svc, fileID, revisionID)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	size, err := saveDriveDownload(resp, outPath)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"path":       outPath,
+			"size":       size,
+			"revisionId": revisionID,
+		})
+	}
+
+	u.Out().Printf("path\t%s", outPath)
+	u.Out().Printf("size\t%s", formatDriveSize(size))
+	return nil
+}
+
+var driveRevisionDownload = func(ctx context.Context, svc *drive.Service, fileID, revisionID string) (*http.Response, error) {
+	return svc.Revisions.Get(fileID, revisionID).Context(ctx).Download()
+}
+
+var driveRevisionExportDownload = func(ctx context.Context, account, link string) (*http.Response, error) {
+	client, err := newDriveHTTPClient(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+type DriveRevisionsKeepCmd struct {
+	FileID     string `arg:"" name:"fileId" help:"File ID"`
+	RevisionID string `arg:"" name:"revisionId" help:"Revision ID"`
+}
+
+func (c *DriveRevisionsKeepCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return setDriveRevisionKeepForever(ctx, flags, c.FileID, c.RevisionID, true)
+}
+
+type DriveRevisionsUnkeepCmd struct {
+	FileID     string `arg:"" name:"fileId" help:"File ID"`
+	RevisionID string `arg:"" name:"revisionId" help:"Revision ID"`
+}
+
+func (c *DriveRevisionsUnkeepCmd) Run(ctx context.Context, flags *RootFlags) error {
+	return setDriveRevisionKeepForever(ctx, flags, c.FileID, c.RevisionID, false)
+}
+
+func setDriveRevisionKeepForever(ctx context.Context, flags *RootFlags, rawFileID, rawRevisionID string, keep bool) error {
+	u := ui.FromContext(ctx)
+	fileID, revisionID, err := driveRevisionArgs(rawFileID, rawRevisionID)
+	if err != nil {
+		return err
+	}
+
+	if err := dryRunExit(ctx, flags, "drive.revisions.update", map[string]any{
+		"file_id":      fileID,
+		"revision_id":  revisionID,
+		"keep_forever": keep,
+	}); err != nil {
+		return err
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	rev := &drive.Revision{KeepForever: keep}
+	if !keep {
+		rev.ForceSendFields = []string{"KeepForever"}
+	}
+	updated, err := svc.Revisions.Update(fileID, revisionID, rev).
+		Fields(driveRevisionFields).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"revision": updated})
+	}
+	u.Out().Printf("id\t%s", updated.Id)
+	u.Out().Printf("keep_forever\t%t", updated.KeepForever)
+	return nil
+}
+
+type DriveRevisionsDeleteCmd struct {
+	FileID     string `arg:"" name:"fileId" help:"File ID"`
+	RevisionID string `arg:"" name:"revisionId" help:"Revision ID"`
+}
+
+func (c *DriveRevisionsDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	fileID, revisionID, err := driveRevisionArgs(c.FileID, c.RevisionID)
+	if err != nil {
+		return err
+	}
+
+	if confirmErr := confirmDestructive(ctx, flags, fmt.Sprintf("permanently delete revision %s of drive file %s", revisionID, fileID)); confirmErr != nil {
+		return confirmErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.Revisions.Delete(fileID, revisionID).Context(ctx).Do(); err != nil {
+		return err
+	}
+
+	return writeResult(ctx, u,
+		kv("deleted", true),
+		kv("fileId", fileID),
+		kv("revisionId", revisionID),
+	)
+}
+
+func driveRevisionArgs(rawFileID, rawRevisionID string) (string, string, error) {
+	fileID := normalizeGoogleID(strings.TrimSpace(rawFileID))
+	revisionID := strings.TrimSpace(rawRevisionID)
+	if fileID == "" {
+		return "", "", usage("empty fileId")
+	}
+	if revisionID == "" {
+		return "", "", usage("empty revisionId")
+	}
+	return fileID, revisionID, nil
+}
+
+func driveRevisionAuthor(r *drive.Revision) string {
+	if r == nil || r.LastModifyingUser == nil {
+		return "-"
+	}
+	if r.LastModifyingUser.EmailAddress != "" {
+		return r.LastModifyingUser.EmailAddress
+	}
+	if r.LastModifyingUser.DisplayName != "" {
+		return r.LastModifyingUser.DisplayName
+	}
+	return "-"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDriveRevisions_ListAndKeep_JSON(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	var keepBody string
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && path == "/files/f1/revisions":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"revisions": []map[string]any{
+					{"id": "r1", "modifiedTime": "2024-01-01T00:00:00Z", "size": "10", "keepForever": true},
+					{"id": "r2", "modifiedTime": "2024-02-01T00:00:00Z", "size": "12"},
+				},
+			})
+		case r.Method == http.MethodPatch && path == "/files/f1/revisions/r1":
+			keepBody = readBody(t, r)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "r1", "keepForever": false})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer closeSrv()
+	newDriveService = stubDriveService(svc)
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "revisions", "list", "f1"}); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+	})
+	var listed struct {
+		Revisions []struct {
+			ID string `json:"id"`
+		} `json:"revisions"`
+	}
+	if err := json.Unmarshal([]byte(out), &listed); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if len(listed.Revisions) != 2 || listed.Revisions[1].ID != "r2" {
+		t.Fatalf("unexpected revisions: %+v", listed)
+	}
+
+	_ = captureStdout(t, func() {
+		if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "revisions", "unkeep", "f1", "r1"}); err != nil {
+			t.Fatalf("unkeep: %v", err)
+		}
+	})
+	if !strings.Contains(keepBody, `"keepForever":false`) {
+		t.Fatalf("expected keepForever=false to be sent, got %q", keepBody)
+	}
+}
+
+func TestDriveRevisions_DownloadExport(t *testing.T) {
+	origNew := newDriveService
+	origHTTP := newDriveHTTPClient
+	t.Cleanup(func() {
+		newDriveService = origNew
+		newDriveHTTPClient = origHTTP
+	})
+
+	exportSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("exportFormat") != "docx" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("old docx"))
+	}))
+	defer exportSrv.Close()
+	newDriveHTTPClient = func(context.Context, string) (*http.Client, error) { return exportSrv.Client(), nil }
+
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && path == "/files/doc1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "doc1", "name": "Plan", "mimeType": driveMimeGoogleDoc})
+		case r.Method == http.MethodGet && path == "/files/doc1/revisions/r7":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": "r7",
+				"exportLinks": map[string]string{
+					mimeDocx: exportSrv.URL + "/export?exportFormat=docx",
+					mimePDF:  exportSrv.URL + "/export?exportFormat=pdf",
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer closeSrv()
+	newDriveService = stubDriveService(svc)
+
+	dir := t.TempDir()
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "revisions", "download", "doc1", "r7", "--format", "docx", "--out", dir}); err != nil {
+			t.Fatalf("download: %v", err)
+		}
+	})
+	var got struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if got.Path != filepath.Join(dir, "doc1_r7_Plan.docx") {
+		t.Fatalf("unexpected path: %q", got.Path)
+	}
+	if b, err := os.ReadFile(got.Path); err != nil || string(b) != "old docx" {
+		t.Fatalf("content: %q err=%v", b, err)
+	}
+}
@@ -0,0 +1,563 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/timeparse"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	driveShareActionCreate   = "create"
+	driveShareActionUpdate   = "update"
+	driveShareActionDelete   = "delete"
+	driveShareActionTransfer = "transfer"
+	driveShareActionSkip     = "skip"
+
+	drivePermRoleCommenter     = "commenter"
+	drivePermRoleFileOrganizer = "fileOrganizer"
+	drivePermRoleOrganizer     = "organizer"
+	drivePermRoleOwner         = "owner"
+
+	driveShareTargetFields = "id, name, mimeType, parents, ownedByMe"
+)
+
+var driveShareRoles = []string{
+	drivePermRoleReader,
+	drivePermRoleCommenter,
+	drivePermRoleWriter,
+	drivePermRoleFileOrganizer,
+	drivePermRoleOrganizer,
+}
+
+// normalizeDriveShareRole validates --role case-insensitively and returns the
+// API spelling (e.g. fileorganizer -> fileOrganizer).
+func normalizeDriveShareRole(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return drivePermRoleReader, nil
+	}
+	for _, role := range driveShareRoles {
+		if strings.EqualFold(raw, role) {
+			return role, nil
+		}
+	}
+	return "", usagef("invalid --role (expected %s)", strings.Join(driveShareRoles, "|"))
+}
+
+// parseDriveExpiration accepts RFC3339/date expressions understood by the
+// calendar commands plus relative durations like 72h or 30d.
+func parseDriveExpiration(raw string, now time.Time) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+
+	var t time.Time
+	if days, ok := strings.CutSuffix(strings.ToLower(raw), "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			t = now.AddDate(0, 0, n)
+		}
+	}
+	if t.IsZero() {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			t = now.Add(d)
+		}
+	}
+	if t.IsZero() {
+		parsed, err := timeparse.ParseRangeExpr(raw, now, time.Local)
+		if err != nil {
+			return "", usagef("invalid --expires %q (use RFC3339, YYYY-MM-DD, or a duration like 30d)", raw)
+		}
+		t = parsed
+	}
+	if !t.After(now) {
+		return "", usage("--expires must be in the future")
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+// driveShareAction is one planned (or applied) permission change on one file.
+type driveShareAction struct {
+	Action       string `json:"action"`
+	Path         string `json:"path"`
+	FileID       string `json:"fileId"`
+	PermissionID string `json:"permissionId,omitempty"`
+	Role         string `json:"role,omitempty"`
+	PreviousRole string `json:"previousRole,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// driveShareTargets resolves fileID, and with recursive every item below it.
+func driveShareTargets(ctx context.Context, svc *drive.Service, fileID string, recursive bool) ([]driveTreeEntry, error) {
+	root, err := svc.Files.Get(fileID).
+		SupportsAllDrives(true).
+		Fields(gapi.Field(driveShareTargetFields)).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, err
+	}
+	entries := []driveTreeEntry{{Path: root.Name, File: root}}
+	if !recursive {
+		return entries, nil
+	}
+	if root.MimeType != driveMimeFolder {
+		return nil, usage("--recursive requires a folder")
+	}
+	children, err := walkDriveTree(ctx, svc, root.Id, driveShareTargetFields)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{root.Id: true}
+	for _, child := range children {
+		if seen[child.File.Id] {
+			continue
+		}
+		seen[child.File.Id] = true
+		entries = append(entries, driveTreeEntry{Path: root.Name + "/" + child.Path, File: child.File})
+	}
+	return entries, nil
+}
+
+// findDrivePermission returns the permission in perms granting access to the
+// same grantee as want (anyone, a domain, or a user/group email).
+func findDrivePermission(perms []*drive.Permission, want *drive.Permission) *drive.Permission {
+	for _, p := range perms {
+		if p == nil || p.Deleted || p.Type != want.Type {
+			continue
+		}
+		switch want.Type {
+		case "anyone":
+			return p
+		case "domain":
+			if strings.EqualFold(p.Domain, want.Domain) {
+				return p
+			}
+		default:
+			if strings.EqualFold(p.EmailAddress, want.EmailAddress) {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+func findDrivePermissionByEmail(perms []*drive.Permission, email string) *drive.Permission {
+	for _, p := range perms {
+		if p != nil && !p.Deleted && (p.Type == "user" || p.Type == "group") && strings.EqualFold(p.EmailAddress, email) {
+			return p
+		}
+	}
+	return nil
+}
+
+// planDriveShare decides, per target, whether perm must be created, updated
+// (different role or expiration) or is already in place. Folder permissions
+// cascade, so only the root (entries[0]) is granted directly; descendants are
+// touched only when a direct permission of their own would leave the grantee
+// with different effective access than the folder grant.
+func planDriveShare(entries []driveTreeEntry, perms map[string][]*drive.Permission, failed map[string]error, perm *drive.Permission) []driveShareAction {
+	actions := make([]driveShareAction, 0, len(entries))
+	for i, e := range entries {
+		if err := failed[e.File.Id]; err != nil {
+			actions = append(actions, driveSharePermissionsUnreadable(e, err))
+			continue
+		}
+		action := driveShareAction{Path: e.Path, FileID: e.File.Id, Role: perm.Role}
+		existing := findDrivePermission(perms[e.File.Id], perm)
+		switch {
+		case existing == nil && i > 0:
+			action.Action = driveShareActionSkip
+			action.Reason = "inherits from folder"
+		case existing == nil:
+			action.Action = driveShareActionCreate
+		case existing.Role == drivePermRoleOwner:
+			action.Action = driveShareActionSkip
+			action.PermissionID = existing.Id
+			action.Reason = "grantee owns the file"
+		case existing.Role == perm.Role && (perm.ExpirationTime == "" || existing.ExpirationTime == perm.ExpirationTime):
+			action.Action = driveShareActionSkip
+			action.PermissionID = existing.Id
+			action.Reason = "already has " + existing.Role
+		case i > 0 && (drivePermissionInheritedOnly(existing) || driveShareRoleRank(existing.Role) <= driveShareRoleRank(perm.Role)):
+			// Either the permission follows the folder, or the folder grant
+			// already dominates it; effective access matches the root.
+			action.Action = driveShareActionSkip
+			action.PermissionID = existing.Id
+			action.Reason = "inherits from folder"
+		default:
+			action.Action = driveShareActionUpdate
+			action.PermissionID = existing.Id
+			action.PreviousRole = existing.Role
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// driveSharePermissionsUnreadable skips an entry whose permissions could not be
+// listed; without them there is no safe way to plan a change.
+func driveSharePermissionsUnreadable(e driveTreeEntry, err error) driveShareAction {
+	return driveShareAction{Action: driveShareActionSkip, Path: e.Path, FileID: e.File.Id, Reason: "could not list permissions: " + err.Error()}
+}
+
+// drivePermissionInheritedOnly reports whether every detail of p says it is
+// inherited from a parent folder (shared drives only report this).
+func drivePermissionInheritedOnly(p *drive.Permission) bool {
+	if len(p.PermissionDetails) == 0 {
+		return false
+	}
+	for _, d := range p.PermissionDetails {
+		if d == nil || !d.Inherited {
+			return false
+		}
+	}
+	return true
+}
+
+// driveShareRoleRank orders roles by the access they grant; unknown roles
+// rank lowest.
+func driveShareRoleRank(role string) int {
+	if role == drivePermRoleOwner {
+		return len(driveShareRoles)
+	}
+	for i, r := range driveShareRoles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// runDriveShareActions applies every non-skip action with bounded parallelism
+// and records failures on the action itself.
+func runDriveShareActions(ctx context.Context, u *ui.UI, concurrency int, actions []driveShareAction, apply func(context.Context, *driveShareAction) error) {
+	var pending []*driveShareAction
+	for i := range actions {
+		if actions[i].Action != driveShareActionSkip {
+			pending = append(pending, &actions[i])
+		}
+	}
+	runDriveBounded(ctx, concurrency, len(pending), func(idx int) error {
+		return apply(ctx, pending[idx])
+	}, func(idx, finished int, err error) {
+		a := pending[idx]
+		if err != nil {
+			a.Error = err.Error()
+		}
+		if u == nil {
+			return
+		}
+		if err != nil {
+			u.Err().Printf("[%d/%d] failed %s %s: %v", finished, len(pending), a.Action, a.Path, err)
+			return
+		}
+		u.Err().Printf("[%d/%d] %s %s", finished, len(pending), a.Action, a.Path)
+	})
+}
+
+// writeDriveSharePlan prints a per-file plan (dryRun) or the applied result,
+// and fails when any action failed.
+func writeDriveSharePlan(ctx context.Context, u *ui.UI, op string, dryRun bool, actions []driveShareAction) error {
+	counts := map[string]int{}
+	failed := 0
+	for _, a := range actions {
+		counts[a.Action]++
+		if a.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		counts["failed"] = failed
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if actions == nil {
+			actions = []driveShareAction{}
+		}
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"dry_run": dryRun,
+			"op":      op,
+			"actions": actions,
+			"summary": counts,
+		}); err != nil {
+			return err
+		}
+	} else {
+		if dryRun {
+			u.Err().Printf("Dry run: would %s", op)
+		}
+		if len(actions) == 0 {
+			u.Err().Println("No matching files")
+		} else {
+			w, flush := tableWriter(ctx)
+			fmt.Fprintln(w, "ACTION\tPATH\tROLE\tDETAIL")
+			for _, a := range actions {
+				detail := a.Reason
+				switch {
+				case a.Error != "":
+					detail = "error: " + a.Error
+				case a.PreviousRole != "":
+					detail = "was " + a.PreviousRole
+				}
+				if detail == "" {
+					detail = "-"
+				}
+				role := a.Role
+				if role == "" {
+					role = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.Action, a.Path, role, detail)
+			}
+			flush()
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d permission changes failed", failed, len(actions)-counts[driveShareActionSkip])
+	}
+	return nil
+}
+
+func (c *DriveShareCmd) runRecursive(ctx context.Context, u *ui.UI, flags *RootFlags, svc *drive.Service, fileID string, perm *drive.Permission) error {
+	entries, err := driveShareTargets(ctx, svc, fileID, true)
+	if err != nil {
+		return err
+	}
+	perms, failed := listDriveTreePermissions(ctx, svc, u, entries, c.Concurrency)
+	actions := planDriveShare(entries, perms, failed, perm)
+	op := fmt.Sprintf("grant %s to %s on %s (%d changes)", perm.Role, driveShareGrantee(perm), entries[0].Path, len(actions)-countDriveShareActions(actions, driveShareActionSkip))
+	if flags.DryRun {
+		return writeDriveSharePlan(ctx, u, op, true, actions)
+	}
+
+	runDriveShareActions(ctx, u, c.Concurrency, actions, func(ctx context.Context, a *driveShareAction) error {
+		if a.Action == driveShareActionUpdate {
+			update := &drive.Permission{Role: perm.Role, ExpirationTime: perm.ExpirationTime}
+			_, err := svc.Permissions.Update(a.FileID, a.PermissionID, update).
+				SupportsAllDrives(true).
+				Fields("id").
+				Context(ctx).
+				Do()
+			return err
+		}
+		created, err := svc.Permissions.Create(a.FileID, perm).
+			SupportsAllDrives(true).
+			SendNotificationEmail(false).
+			Fields("id").
+			Context(ctx).
+			Do()
+		if err == nil {
+			a.PermissionID = created.Id
+		}
+		return err
+	})
+	return writeDriveSharePlan(ctx, u, op, false, actions)
+}
+
+func driveShareGrantee(perm *drive.Permission) string {
+	switch perm.Type {
+	case "anyone":
+		return "anyone with the link"
+	case "domain":
+		return perm.Domain
+	default:
+		return perm.EmailAddress
+	}
+}
+
+// runBulk removes a user's (or a specific permission ID's) access from the
+// file, or from every item below it with --recursive.
+func (c *DriveUnshareCmd) runBulk(ctx context.Context, u *ui.UI, flags *RootFlags, account, fileID, permissionID, email string) error {
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+	entries, err := driveShareTargets(ctx, svc, fileID, c.Recursive)
+	if err != nil {
+		return err
+	}
+	perms, failed := listDriveTreePermissions(ctx, svc, u, entries, c.Concurrency)
+
+	var actions []driveShareAction
+	for _, e := range entries {
+		if err := failed[e.File.Id]; err != nil {
+			actions = append(actions, driveSharePermissionsUnreadable(e, err))
+			continue
+		}
+		var match *drive.Permission
+		if email != "" {
+			match = findDrivePermissionByEmail(perms[e.File.Id], email)
+		} else {
+			for _, p := range perms[e.File.Id] {
+				if p != nil && p.Id == permissionID {
+					match = p
+					break
+				}
+			}
+		}
+		if match == nil {
+			continue
+		}
+		action := driveShareAction{Action: driveShareActionDelete, Path: e.Path, FileID: e.File.Id, PermissionID: match.Id, Role: match.Role}
+		if match.Role == drivePermRoleOwner {
+			action.Action = driveShareActionSkip
+			action.Reason = "cannot remove the owner"
+		}
+		actions = append(actions, action)
+	}
+
+	who := email
+	if who == "" {
+		who = "permission " + permissionID
+	}
+	op := fmt.Sprintf("remove access for %s from %d files", who, len(actions)-countDriveShareActions(actions, driveShareActionSkip))
+	if flags.DryRun {
+		return writeDriveSharePlan(ctx, u, op, true, actions)
+	}
+	if countDriveShareActions(actions, driveShareActionDelete) > 0 {
+		if err := confirmDestructive(ctx, flags, op); err != nil {
+			return err
+		}
+	}
+
+	runDriveShareActions(ctx, u, c.Concurrency, actions, func(ctx context.Context, a *driveShareAction) error {
+		err := svc.Permissions.Delete(a.FileID, a.PermissionID).SupportsAllDrives(true).Context(ctx).Do()
+		// Removing access on a folder can cascade to its children before we reach them.
+		var apiErr *gapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			return nil
+		}
+		return err
+	})
+	return writeDriveSharePlan(ctx, u, op, false, actions)
+}
+
+func countDriveShareActions(actions []driveShareAction, action string) int {
+	n := 0
+	for _, a := range actions {
+		if a.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+type DriveTransferOwnershipCmd struct {
+	FileID      string `arg:"" name:"fileId" help:"File or folder ID"`
+	Email       string `arg:"" name:"email" help:"New owner email"`
+	Recursive   bool   `name:"recursive" help:"Also transfer everything you own below the folder"`
+	Pending     bool   `name:"pending" help:"Propose ownership instead (required for consumer accounts; the new owner must accept)"`
+	Concurrency int    `name:"concurrency" help:"Parallel permission updates for --recursive" default:"4"`
+}
+
+func (c *DriveTransferOwnershipCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	fileID := normalizeGoogleID(strings.TrimSpace(c.FileID))
+	if fileID == "" {
+		return usage("empty fileId")
+	}
+	email := strings.TrimSpace(c.Email)
+	if email == "" || !strings.Contains(email, "@") {
+		return usage("invalid email")
+	}
+	if strings.EqualFold(email, account) {
+		return usage("new owner must differ from the current account")
+	}
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+	entries, err := driveShareTargets(ctx, svc, fileID, c.Recursive)
+	if err != nil {
+		return err
+	}
+	perms, failed := listDriveTreePermissions(ctx, svc, u, entries, c.Concurrency)
+
+	role := drivePermRoleOwner
+	if c.Pending {
+		role = drivePermRoleWriter
+	}
+	actions := make([]driveShareAction, 0, len(entries))
+	for _, e := range entries {
+		if err := failed[e.File.Id]; err != nil {
+			actions = append(actions, driveSharePermissionsUnreadable(e, err))
+			continue
+		}
+		action := driveShareAction{Action: driveShareActionTransfer, Path: e.Path, FileID: e.File.Id, Role: role}
+		existing := findDrivePermissionByEmail(perms[e.File.Id], email)
+		if existing != nil {
+			action.PermissionID = existing.Id
+			action.PreviousRole = existing.Role
+		}
+		switch {
+		case !e.File.OwnedByMe:
+			action.Action = driveShareActionSkip
+			action.Reason = "not owned by " + account
+		case existing != nil && existing.Role == drivePermRoleOwner:
+			action.Action = driveShareActionSkip
+			action.Reason = "already owner"
+		case c.Pending && existing != nil && existing.PendingOwner:
+			action.Action = driveShareActionSkip
+			action.Reason = "already pending owner"
+		}
+		actions = append(actions, action)
+	}
+
+	verb := "transfer ownership"
+	if c.Pending {
+		verb = "propose ownership"
+	}
+	op := fmt.Sprintf("%s of %d files to %s", verb, countDriveShareActions(actions, driveShareActionTransfer), email)
+	if flags.DryRun {
+		return writeDriveSharePlan(ctx, u, op, true, actions)
+	}
+	if countDriveShareActions(actions, driveShareActionTransfer) > 0 {
+		if err := confirmDestructive(ctx, flags, op); err != nil {
+			return err
+		}
+	}
+
+	runDriveShareActions(ctx, u, c.Concurrency, actions, func(ctx context.Context, a *driveShareAction) error {
+		if a.PermissionID != "" {
+			update := &drive.Permission{Role: a.Role}
+			call := svc.Permissions.Update(a.FileID, a.PermissionID, update).SupportsAllDrives(true).Fields("id")
+			if c.Pending {
+				update.PendingOwner = true
+			} else {
+				call = call.TransferOwnership(true)
+			}
+			_, err := call.Context(ctx).Do()
+			return err
+		}
+		perm := &drive.Permission{Type: "user", EmailAddress: email, Role: a.Role, PendingOwner: c.Pending}
+		call := svc.Permissions.Create(a.FileID, perm).SupportsAllDrives(true).Fields("id")
+		if !c.Pending {
+			call = call.TransferOwnership(true)
+		}
+		created, err := call.Context(ctx).Do()
+		if err == nil {
+			a.PermissionID = created.Id
+		}
+		return err
+	})
+	return writeDriveSharePlan(ctx, u, op, false, actions)
+}
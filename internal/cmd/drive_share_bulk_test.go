@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+type driveShareTestServer struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (s *driveShareTestServer) install(t *testing.T) func() {
+	t.Helper()
+
+	perms := map[string][]map[string]any{
+		"root1": {
+			{"id": "p-owner", "type": "user", "role": "owner", "emailAddress": "a@b.com"},
+			{"id": "p-x", "type": "user", "role": "reader", "emailAddress": "x@y.com"},
+		},
+		"f1": {
+			{"id": "p-owner", "type": "user", "role": "owner", "emailAddress": "a@b.com"},
+			{"id": "p-x", "type": "user", "role": "writer", "emailAddress": "X@y.com"},
+		},
+		"f2": {
+			{"id": "p-other", "type": "user", "role": "owner", "emailAddress": "other@b.com"},
+		},
+	}
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+		q := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			s.mu.Lock()
+			entry := r.Method + " " + path
+			if q.Get("transferOwnership") == "true" {
+				entry += " transfer"
+			}
+			if r.Method != http.MethodDelete {
+				entry += " " + strings.TrimSpace(readBody(t, r))
+			}
+			s.writes = append(s.writes, entry)
+			s.mu.Unlock()
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "p-new"})
+			return
+		}
+		switch {
+		case path == "/files/root1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "root1", "name": "Team", "mimeType": driveMimeFolder, "ownedByMe": true})
+		case path == "/files/f1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "f1", "name": "a.txt", "mimeType": "text/plain", "ownedByMe": true})
+		case path == "/files" && strings.Contains(q.Get("q"), "'root1' in parents"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"files": []map[string]any{
+				{"id": "f1", "name": "a.txt", "mimeType": "text/plain", "ownedByMe": true},
+				{"id": "f2", "name": "b.txt", "mimeType": "text/plain"},
+			}})
+		case strings.HasSuffix(path, "/permissions"):
+			id := strings.TrimSuffix(strings.TrimPrefix(path, "/files/"), "/permissions")
+			_ = json.NewEncoder(w).Encode(map[string]any{"permissions": perms[id]})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	newDriveService = stubDriveService(svc)
+	return closeSrv
+}
+
+func TestDriveShare_Recursive_DryRunPlan(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+	srv := &driveShareTestServer{}
+	defer srv.install(t)()
+
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--dry-run", "--account", "a@b.com", "drive", "share", "root1", "--recursive", "--to", "user", "--email", "x@y.com", "--role", "reader"}); err != nil {
+				t.Fatalf("share: %v", err)
+			}
+		})
+	})
+	if len(srv.writes) != 0 {
+		t.Fatalf("dry run must not write, got %v", srv.writes)
+	}
+
+	var got struct {
+		DryRun  bool               `json:"dry_run"`
+		Actions []driveShareAction `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	want := map[string]string{"Team": driveShareActionSkip, "Team/a.txt": driveShareActionUpdate, "Team/b.txt": driveShareActionSkip}
+	if !got.DryRun || len(got.Actions) != len(want) {
+		t.Fatalf("unexpected plan: %+v", got)
+	}
+	for _, a := range got.Actions {
+		if want[a.Path] != a.Action {
+			t.Fatalf("unexpected action for %s: %+v", a.Path, a)
+		}
+	}
+}
+
+func TestDriveShare_Recursive_ApplyWithExpiration(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+	srv := &driveShareTestServer{}
+	defer srv.install(t)()
+
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "share", "root1", "--recursive", "--to", "user", "--email", "x@y.com", "--role", "commenter", "--expires", "30d"}); err != nil {
+				t.Fatalf("share: %v", err)
+			}
+		})
+	})
+	// b.txt inherits the folder grant; a.txt keeps a broader direct role and
+	// must be narrowed explicitly.
+	if len(srv.writes) != 2 {
+		t.Fatalf("expected 2 writes, got %v", srv.writes)
+	}
+	joined := strings.Join(srv.writes, "\n")
+	for _, want := range []string{"PATCH /files/root1/permissions/p-x", "PATCH /files/f1/permissions/p-x"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("missing %q in %v", want, srv.writes)
+		}
+	}
+	if !strings.Contains(joined, `"role":"commenter"`) || !strings.Contains(joined, `"expirationTime"`) {
+		t.Fatalf("expected commenter role with expiration, got %v", srv.writes)
+	}
+}
+
+func TestDriveShare_Recursive_NewGranteeOnlyOnRoot(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+	srv := &driveShareTestServer{}
+	defer srv.install(t)()
+
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "share", "root1", "--recursive", "--to", "user", "--email", "new@y.com", "--role", "writer"}); err != nil {
+				t.Fatalf("share: %v", err)
+			}
+		})
+	})
+	if len(srv.writes) != 1 || !strings.HasPrefix(srv.writes[0], "POST /files/root1/permissions") {
+		t.Fatalf("expected a single create on the folder, got %v", srv.writes)
+	}
+}
+
+func TestPlanDriveShare_InheritedChildren(t *testing.T) {
+	entries := []driveTreeEntry{
+		{Path: "Team", File: &drive.File{Id: "root"}},
+		{Path: "Team/inherited", File: &drive.File{Id: "c1"}},
+		{Path: "Team/lower", File: &drive.File{Id: "c2"}},
+		{Path: "Team/higher", File: &drive.File{Id: "c3"}},
+	}
+	perms := map[string][]*drive.Permission{
+		"root": {{Id: "p", Type: "user", Role: "writer", EmailAddress: "x@y.com"}},
+		"c1": {{Id: "p", Type: "user", Role: "writer", EmailAddress: "x@y.com",
+			PermissionDetails: []*drive.PermissionPermissionDetails{{Inherited: true, InheritedFrom: "root"}}}},
+		"c2": {{Id: "p", Type: "user", Role: "reader", EmailAddress: "x@y.com"}},
+		"c3": {{Id: "p", Type: "user", Role: "organizer", EmailAddress: "x@y.com"}},
+	}
+	actions := planDriveShare(entries, perms, nil, &drive.Permission{Type: "user", Role: "commenter", EmailAddress: "x@y.com"})
+	want := []string{driveShareActionUpdate, driveShareActionSkip, driveShareActionSkip, driveShareActionUpdate}
+	for i, a := range actions {
+		if a.Action != want[i] {
+			t.Fatalf("%s: got %s, want %s (%+v)", a.Path, a.Action, want[i], a)
+		}
+	}
+
+	// A child whose permissions could not be listed is left alone.
+	failed := map[string]error{"c3": errors.New("forbidden")}
+	actions = planDriveShare(entries, perms, failed, &drive.Permission{Type: "user", Role: "commenter", EmailAddress: "x@y.com"})
+	if actions[3].Action != driveShareActionSkip || !strings.Contains(actions[3].Reason, "forbidden") {
+		t.Fatalf("expected unreadable child to be skipped, got %+v", actions[3])
+	}
+}
+
+func TestDriveUnshare_ByEmailRecursive(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+	srv := &driveShareTestServer{}
+	defer srv.install(t)()
+
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--force", "--account", "a@b.com", "drive", "unshare", "root1", "--email", "x@y.com", "--recursive"}); err != nil {
+				t.Fatalf("unshare: %v", err)
+			}
+		})
+	})
+	if len(srv.writes) != 2 || !strings.Contains(strings.Join(srv.writes, "\n"), "DELETE /files/f1/permissions/p-x") {
+		t.Fatalf("unexpected deletes: %v", srv.writes)
+	}
+}
+
+func TestDriveTransferOwnership(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+	srv := &driveShareTestServer{}
+	defer srv.install(t)()
+
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--force", "--account", "a@b.com", "drive", "transfer-ownership", "root1", "x@y.com", "--recursive"}); err != nil {
+				t.Fatalf("transfer: %v", err)
+			}
+		})
+	})
+	// b.txt is owned by someone else and must be skipped.
+	if len(srv.writes) != 2 {
+		t.Fatalf("expected 2 transfers, got %v", srv.writes)
+	}
+	for _, w := range srv.writes {
+		if !strings.HasPrefix(w, "PATCH ") || !strings.Contains(w, " transfer ") || !strings.Contains(w, `"role":"owner"`) {
+			t.Fatalf("unexpected transfer request %q", w)
+		}
+	}
+	if !strings.Contains(out, `"not owned by a@b.com"`) {
+		t.Fatalf("expected skip reason in output: %q", out)
+	}
+}
+
+func TestParseDriveExpiration(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]string{
+		"30d":                  "2026-03-31T12:00:00Z",
+		"72h":                  "2026-03-04T12:00:00Z",
+		"2026-04-01T09:00:00Z": "2026-04-01T09:00:00Z",
+	}
+	for in, want := range cases {
+		got, err := parseDriveExpiration(in, now)
+		if err != nil || got != want {
+			t.Fatalf("parseDriveExpiration(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"yesterday", "nope", "2020-01-01"} {
+		if _, err := parseDriveExpiration(in, now); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
@@ -0,0 +1,657 @@
+package cmd
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // Drive exposes md5Checksum; used for change detection only
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	driveSyncUpload       = "upload"
+	driveSyncUpdate       = "update"
+	driveSyncDownload     = "download"
+	driveSyncMkdir        = "mkdir"
+	driveSyncDeleteLocal  = "delete-local"
+	driveSyncDeleteRemote = "delete-remote"
+	driveSyncSkip         = "skip"
+	driveSyncConflict     = "conflict"
+)
+
+// Values for --prefer.
+const (
+	driveSyncPreferNone  = "none"
+	driveSyncPreferLocal = "local"
+	driveSyncPreferDrive = "drive"
+)
+
+// DriveSyncCmd mirrors a local directory and a Drive folder in both directions.
+type DriveSyncCmd struct {
+	LocalDir string `arg:"" name:"localDir" help:"Local directory"`
+	FolderID string `arg:"" name:"folderId" help:"Drive folder ID"`
+	Delete   bool   `name:"delete" help:"Propagate deletions: files removed on one side since the last sync are removed (local) or trashed (Drive) on the other unless they changed there since"`
+	State    string `name:"state" help:"Sync state file (default: gogcli config dir, per account/folder/directory)"`
+	Prefer   string `name:"prefer" help:"Which copy wins when a file differs on both sides and has never been synced: none (report a conflict)|local|drive" default:"none" enum:"none,local,drive"`
+}
+
+type driveSyncAction struct {
+	Action   string `json:"action"`
+	Path     string `json:"path"`
+	FileID   string `json:"fileId,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Error    string `json:"error,omitempty"`
+	local    *driveSyncLocalFile
+	remote   *drive.File
+	parentOf string
+}
+
+type driveSyncLocalFile struct {
+	Path    string
+	AbsPath string
+	Size    int64
+	ModTime time.Time
+	md5     string
+}
+
+type driveSyncStateEntry struct {
+	FileID             string `json:"fileId"`
+	LocalSize          int64  `json:"localSize"`
+	LocalModTimeMs     int64  `json:"localModTimeMs"`
+	LocalMD5           string `json:"localMd5,omitempty"`
+	RemoteMD5          string `json:"remoteMd5,omitempty"`
+	RemoteModifiedTime string `json:"remoteModifiedTime,omitempty"`
+}
+
+type driveSyncState struct {
+	Account     string                         `json:"account"`
+	FolderID    string                         `json:"folderId"`
+	LocalDir    string                         `json:"localDir"`
+	UpdatedAtMs int64                          `json:"updatedAtMs,omitempty"`
+	Files       map[string]driveSyncStateEntry `json:"files"`
+}
+
+func (c *DriveSyncCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	folderID := normalizeGoogleID(strings.TrimSpace(c.FolderID))
+	if folderID == "" {
+		return usage("empty folderId")
+	}
+	localDir := strings.TrimSpace(c.LocalDir)
+	if localDir == "" {
+		return usage("empty localDir")
+	}
+	localDir, err = config.ExpandPath(localDir)
+	if err != nil {
+		return err
+	}
+	localDir, err = filepath.Abs(localDir)
+	if err != nil {
+		return err
+	}
+	st, err := os.Stat(localDir)
+	if err != nil {
+		return err
+	}
+	if !st.IsDir() {
+		return usagef("%s is not a directory", localDir)
+	}
+
+	statePath, err := driveSyncStatePath(account, folderID, localDir, c.State)
+	if err != nil {
+		return err
+	}
+	state, err := loadDriveSyncState(statePath)
+	if err != nil {
+		return err
+	}
+	if state.FolderID != "" && state.FolderID != folderID {
+		return fmt.Errorf("state file %s belongs to folder %s (use --state to pick another file)", statePath, state.FolderID)
+	}
+	state.Account = account
+	state.FolderID = folderID
+	state.LocalDir = localDir
+
+	svc, err := newDriveService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	root, err := svc.Files.Get(folderID).
+		SupportsAllDrives(true).
+		Fields("id, name, mimeType").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return err
+	}
+	if root.MimeType != driveMimeFolder {
+		return usagef("%s is not a folder (mimeType=%s)", folderID, root.MimeType)
+	}
+
+	local, err := scanDriveSyncLocal(localDir)
+	if err != nil {
+		return err
+	}
+	remoteEntries, err := walkDriveTree(ctx, svc, folderID, driveTreeFileFields)
+	if err != nil {
+		return err
+	}
+
+	actions := planDriveSync(local, remoteEntries, state, c.Delete, c.Prefer)
+	dryRun := flags != nil && flags.DryRun
+
+	if !dryRun && c.Delete && driveSyncHasDeletes(actions) {
+		if confirmErr := confirmDestructive(ctx, flags, fmt.Sprintf("sync %s with drive folder %s and delete removed files", localDir, folderID)); confirmErr != nil {
+			return confirmErr
+		}
+	}
+
+	failed := 0
+	if !dryRun {
+		failed = applyDriveSync(ctx, svc, u, localDir, folderID, remoteEntries, actions, state)
+		state.UpdatedAtMs = time.Now().UnixMilli()
+		if saveErr := saveDriveSyncState(statePath, state); saveErr != nil {
+			return saveErr
+		}
+	}
+
+	if err := writeDriveSyncResult(ctx, u, localDir, folderID, statePath, dryRun, actions); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("drive sync: %d of %d actions failed", failed, len(actions))
+	}
+	return nil
+}
+
+func driveSyncStatePath(account, folderID, localDir, override string) (string, error) {
+	if override = strings.TrimSpace(override); override != "" {
+		return config.ExpandPath(override)
+	}
+	dir, err := config.EnsureDriveSyncDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(folderID + "\x00" + localDir))
+	name := fmt.Sprintf("%s-%s.json", sanitizeAccountForPath(account), hex.EncodeToString(sum[:6]))
+	return filepath.Join(dir, name), nil
+}
+
+func loadDriveSyncState(path string) (*driveSyncState, error) {
+	state := &driveSyncState{}
+	data, err := os.ReadFile(path) //nolint:gosec // state path from config dir or user flag
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			state.Files = map[string]driveSyncStateEntry{}
+			return state, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("read sync state %s: %w", path, err)
+	}
+	if state.Files == nil {
+		state.Files = map[string]driveSyncStateEntry{}
+	}
+	return state, nil
+}
+
+func saveDriveSyncState(path string, state *driveSyncState) error {
+	payload, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(payload, '\n'), 0o600)
+}
+
+func scanDriveSyncLocal(root string) (map[string]*driveSyncLocalFile, error) {
+	out := map[string]*driveSyncLocalFile{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		out[rel] = &driveSyncLocalFile{
+			Path:    rel,
+			AbsPath: p,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (f *driveSyncLocalFile) MD5() (string, error) {
+	if f.md5 != "" {
+		return f.md5, nil
+	}
+	sum, err := fileMD5(f.AbsPath)
+	if err != nil {
+		return "", err
+	}
+	f.md5 = sum
+	return sum, nil
+}
+
+func fileMD5(path string) (string, error) {
+	fh, err := os.Open(path) //nolint:gosec // path from directory walk
+	if err != nil {
+		return "", err
+	}
+	defer fh.Close()
+	h := md5.New() //nolint:gosec // matches Drive md5Checksum
+	if _, err := io.Copy(h, fh); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// driveSyncRemotePath returns the local path a remote file maps to. Google
+// Workspace files are mirrored as exports, so they gain the export extension.
+func driveSyncRemotePath(entry driveTreeEntry) string {
+	if isGoogleWorkspaceMimeType(entry.File.MimeType) {
+		return entry.Path + driveExportExtension(driveExportMimeType(entry.File.MimeType))
+	}
+	return entry.Path
+}
+
+func planDriveSync(local map[string]*driveSyncLocalFile, remoteEntries []driveTreeEntry, state *driveSyncState, propagateDeletes bool, prefer string) []driveSyncAction {
+	var actions []driveSyncAction
+
+	remote := map[string]*drive.File{}
+	remoteFolders := map[string]bool{"": true}
+	for _, entry := range remoteEntries {
+		f := entry.File
+		switch {
+		case f.MimeType == driveMimeFolder:
+			remoteFolders[entry.Path] = true
+			continue
+		case isGoogleWorkspaceMimeType(f.MimeType) && !isDriveExportable(f.MimeType):
+			actions = append(actions, driveSyncAction{Action: driveSyncSkip, Path: entry.Path, FileID: f.Id, Reason: "not exportable (" + f.MimeType + ")"})
+			continue
+		}
+		p := driveSyncRemotePath(entry)
+		if _, dup := remote[p]; dup {
+			actions = append(actions, driveSyncAction{Action: driveSyncSkip, Path: p, FileID: f.Id, Reason: "duplicate name in Drive folder"})
+			continue
+		}
+		remote[p] = f
+	}
+
+	paths := make([]string, 0, len(local)+len(remote))
+	for p := range local {
+		paths = append(paths, p)
+	}
+	for p := range remote {
+		if _, ok := local[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	plannedFolders := map[string]bool{}
+	planFolders := func(p string) {
+		var missing []string
+		for dir := path.Dir(p); dir != "." && !remoteFolders[dir] && !plannedFolders[dir]; dir = path.Dir(dir) {
+			missing = append(missing, dir)
+		}
+		for i := len(missing) - 1; i >= 0; i-- {
+			plannedFolders[missing[i]] = true
+			actions = append(actions, driveSyncAction{Action: driveSyncMkdir, Path: missing[i]})
+		}
+	}
+
+	for _, p := range paths {
+		lf := local[p]
+		rf := remote[p]
+		prev, synced := state.Files[p]
+
+		switch {
+		case lf != nil && rf == nil:
+			if synced && propagateDeletes {
+				if !driveSyncLocalUnchanged(lf, prev) {
+					actions = append(actions, driveSyncAction{Action: driveSyncConflict, Path: p, Size: lf.Size, Reason: "removed from Drive but changed locally; keeping local copy"})
+					continue
+				}
+				actions = append(actions, driveSyncAction{Action: driveSyncDeleteLocal, Path: p, Size: lf.Size, Reason: "removed from Drive", local: lf})
+				continue
+			}
+			planFolders(p)
+			actions = append(actions, driveSyncAction{Action: driveSyncUpload, Path: p, Size: lf.Size, Reason: "new local file", local: lf, parentOf: path.Dir(p)})
+		case lf == nil && rf != nil:
+			if synced && propagateDeletes {
+				if prev.FileID != rf.Id || prev.RemoteModifiedTime != rf.ModifiedTime {
+					actions = append(actions, driveSyncAction{Action: driveSyncConflict, Path: p, FileID: rf.Id, Reason: "removed locally but changed on Drive; keeping Drive copy"})
+					continue
+				}
+				actions = append(actions, driveSyncAction{Action: driveSyncDeleteRemote, Path: p, FileID: rf.Id, Reason: "removed locally", remote: rf})
+				continue
+			}
+			actions = append(actions, driveSyncAction{Action: driveSyncDownload, Path: p, FileID: rf.Id, Size: rf.Size, Reason: "new remote file", remote: rf})
+		default:
+			if action, ok := planDriveSyncBoth(p, lf, rf, prev, synced, state, prefer); ok {
+				actions = append(actions, action)
+			}
+		}
+	}
+
+	for p := range state.Files {
+		if local[p] == nil && remote[p] == nil {
+			delete(state.Files, p)
+		}
+	}
+	return actions
+}
+
+// driveSyncLocalUnchanged reports whether a local file still matches the state
+// recorded at the last sync (size and mtime, falling back to the content hash).
+func driveSyncLocalUnchanged(lf *driveSyncLocalFile, prev driveSyncStateEntry) bool {
+	if prev.LocalSize == lf.Size && prev.LocalModTimeMs == lf.ModTime.UnixMilli() {
+		return true
+	}
+	if prev.LocalMD5 == "" {
+		return false
+	}
+	sum, err := lf.MD5()
+	return err == nil && sum == prev.LocalMD5
+}
+
+// planDriveSyncBoth decides what to do with a path that exists on both sides.
+// It returns false when both sides are already in sync.
+func planDriveSyncBoth(p string, lf *driveSyncLocalFile, rf *drive.File, prev driveSyncStateEntry, synced bool, state *driveSyncState, prefer string) (driveSyncAction, bool) {
+	localUnchanged := synced && prev.LocalSize == lf.Size && prev.LocalModTimeMs == lf.ModTime.UnixMilli()
+	remoteUnchanged := synced && prev.FileID == rf.Id && prev.RemoteModifiedTime == rf.ModifiedTime
+	if localUnchanged && remoteUnchanged {
+		return driveSyncAction{}, false
+	}
+
+	native := isGoogleWorkspaceMimeType(rf.MimeType)
+	if !native && rf.Md5Checksum != "" {
+		if sum, err := lf.MD5(); err == nil && sum == rf.Md5Checksum {
+			state.Files[p] = driveSyncStateEntryFor(rf, lf, sum)
+			return driveSyncAction{}, false
+		}
+	}
+	if !localUnchanged && synced && prev.LocalMD5 != "" {
+		if sum, err := lf.MD5(); err == nil && sum == prev.LocalMD5 {
+			localUnchanged = true
+		}
+	}
+
+	upload := driveSyncAction{Action: driveSyncUpdate, Path: p, FileID: rf.Id, Size: lf.Size, local: lf, remote: rf}
+	download := driveSyncAction{Action: driveSyncDownload, Path: p, FileID: rf.Id, Size: rf.Size, local: lf, remote: rf}
+	if native {
+		upload = driveSyncAction{Action: driveSyncSkip, Path: p, FileID: rf.Id, Reason: "local edits to exported Google Workspace files are not uploaded"}
+	}
+
+	switch {
+	case localUnchanged:
+		download.Reason = "changed on Drive"
+		return download, true
+	case remoteUnchanged:
+		if !native {
+			upload.Reason = "changed locally"
+		}
+		return upload, true
+	}
+
+	// Never synced and different: there is no last sync to tell which side
+	// changed, so neither copy is touched unless --prefer picks one.
+	if !synced {
+		switch prefer {
+		case driveSyncPreferDrive:
+			download.Reason = "differs before first sync; keeping Drive copy (--prefer drive)"
+			return download, true
+		case driveSyncPreferLocal:
+			if !native {
+				upload.Reason = "differs before first sync; keeping local copy (--prefer local)"
+			}
+			return upload, true
+		}
+		return driveSyncAction{Action: driveSyncConflict, Path: p, FileID: rf.Id, Size: lf.Size, Reason: "differs on both sides before first sync; keeping both (use --prefer local|drive)"}, true
+	}
+
+	// Both sides changed since the last sync: newest wins.
+	remoteTime, _ := time.Parse(time.RFC3339Nano, rf.ModifiedTime)
+	if remoteTime.After(lf.ModTime) {
+		download.Reason = "conflict: Drive copy is newer"
+		return download, true
+	}
+	if !native {
+		upload.Reason = "conflict: local copy is newer"
+	}
+	return upload, true
+}
+
+func driveSyncStateEntryFor(rf *drive.File, lf *driveSyncLocalFile, localMD5 string) driveSyncStateEntry {
+	return driveSyncStateEntry{
+		FileID:             rf.Id,
+		LocalSize:          lf.Size,
+		LocalModTimeMs:     lf.ModTime.UnixMilli(),
+		LocalMD5:           localMD5,
+		RemoteMD5:          rf.Md5Checksum,
+		RemoteModifiedTime: rf.ModifiedTime,
+	}
+}
+
+func driveSyncHasDeletes(actions []driveSyncAction) bool {
+	for _, a := range actions {
+		if a.Action == driveSyncDeleteLocal || a.Action == driveSyncDeleteRemote {
+			return true
+		}
+	}
+	return false
+}
+
+func applyDriveSync(ctx context.Context, svc *drive.Service, u *ui.UI, localDir, rootID string, remoteEntries []driveTreeEntry, actions []driveSyncAction, state *driveSyncState) int {
+	folders := map[string]string{"": rootID, ".": rootID}
+	for _, entry := range remoteEntries {
+		if entry.File.MimeType == driveMimeFolder {
+			if _, ok := folders[entry.Path]; !ok {
+				folders[entry.Path] = entry.File.Id
+			}
+		}
+	}
+
+	failed := 0
+	for i := range actions {
+		a := &actions[i]
+		if a.Action == driveSyncSkip || a.Action == driveSyncConflict {
+			continue
+		}
+		if err := applyDriveSyncAction(ctx, svc, localDir, folders, a, state); err != nil {
+			a.Error = err.Error()
+			failed++
+			if u != nil {
+				u.Err().Printf("%s %s: %v", a.Action, a.Path, err)
+			}
+			continue
+		}
+		if u != nil && !outfmt.IsJSON(ctx) {
+			u.Err().Printf("%s %s", a.Action, a.Path)
+		}
+	}
+	return failed
+}
+
+func applyDriveSyncAction(ctx context.Context, svc *drive.Service, localDir string, folders map[string]string, a *driveSyncAction, state *driveSyncState) error {
+	switch a.Action {
+	case driveSyncMkdir:
+		parentID, ok := folders[path.Dir(a.Path)]
+		if !ok {
+			return fmt.Errorf("parent folder for %s was not created", a.Path)
+		}
+		created, err := createDriveFolder(ctx, svc, path.Base(a.Path), parentID)
+		if err != nil {
+			return err
+		}
+		folders[a.Path] = created.Id
+		a.FileID = created.Id
+		return nil
+
+	case driveSyncUpload, driveSyncUpdate:
+		sum, err := a.local.MD5()
+		if err != nil {
+			return err
+		}
+		var uploaded *drive.File
+		if a.Action == driveSyncUpload {
+			parentID, ok := folders[a.parentOf]
+			if !ok {
+				return fmt.Errorf("parent folder for %s was not created", a.Path)
+			}
+			uploaded, err = uploadNewDriveFile(ctx, svc, a.local.AbsPath, &drive.File{Name: path.Base(a.Path), Parents: []string{parentID}}, false)
+		} else {
+			uploaded, err = uploadDriveSyncReplace(ctx, svc, a.local.AbsPath, a.FileID)
+		}
+		if err != nil {
+			return err
+		}
+		a.FileID = uploaded.Id
+		state.Files[a.Path] = driveSyncStateEntryFor(uploaded, a.local, sum)
+		return nil
+
+	case driveSyncDownload:
+		dest := filepath.Join(localDir, filepath.FromSlash(a.Path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+			return err
+		}
+		outPath, _, err := downloadDriveFile(ctx, svc, a.remote, dest, "")
+		if err != nil {
+			return err
+		}
+		if remoteTime, parseErr := time.Parse(time.RFC3339Nano, a.remote.ModifiedTime); parseErr == nil {
+			_ = os.Chtimes(outPath, remoteTime, remoteTime)
+		}
+		info, err := os.Stat(outPath)
+		if err != nil {
+			return err
+		}
+		lf := &driveSyncLocalFile{Path: a.Path, AbsPath: outPath, Size: info.Size(), ModTime: info.ModTime()}
+		sum, err := lf.MD5()
+		if err != nil {
+			return err
+		}
+		state.Files[a.Path] = driveSyncStateEntryFor(a.remote, lf, sum)
+		return nil
+
+	case driveSyncDeleteLocal:
+		if err := os.Remove(a.local.AbsPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		delete(state.Files, a.Path)
+		return nil
+
+	case driveSyncDeleteRemote:
+		_, err := svc.Files.Update(a.FileID, &drive.File{Trashed: true}).
+			SupportsAllDrives(true).
+			Fields("id, trashed").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return err
+		}
+		delete(state.Files, a.Path)
+		return nil
+	}
+	return fmt.Errorf("unknown sync action %q", a.Action)
+}
+
+func uploadDriveSyncReplace(ctx context.Context, svc *drive.Service, localPath, fileID string) (*drive.File, error) {
+	f, err := os.Open(localPath) //nolint:gosec // path from directory walk
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return replaceDriveFileContent(ctx, svc, fileID, "", f, guessMimeType(localPath), false)
+}
+
+func writeDriveSyncResult(ctx context.Context, u *ui.UI, localDir, folderID, statePath string, dryRun bool, actions []driveSyncAction) error {
+	summary := map[string]int{}
+	for _, a := range actions {
+		key := a.Action
+		if a.Error != "" {
+			key = "failed"
+		}
+		summary[key]++
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if actions == nil {
+			actions = []driveSyncAction{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"localDir": localDir,
+			"folderId": folderID,
+			"state":    statePath,
+			"dryRun":   dryRun,
+			"actions":  actions,
+			"summary":  summary,
+		})
+	}
+
+	if len(actions) == 0 {
+		u.Err().Println("Already in sync")
+		return nil
+	}
+
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "ACTION\tPATH\tDETAIL")
+	for _, a := range actions {
+		detail := a.Reason
+		if a.Error != "" {
+			detail = "error: " + a.Error
+		}
+		if detail == "" {
+			detail = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", a.Action, a.Path, detail)
+	}
+	if dryRun {
+		u.Err().Println("Dry run: no changes made")
+	}
+	return nil
+}
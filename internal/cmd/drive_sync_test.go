@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestPlanDriveSync(t *testing.T) {
+	now := time.Now()
+	local := map[string]*driveSyncLocalFile{
+		"new.txt":       {Path: "new.txt", Size: 3, ModTime: now},
+		"sub/deep.txt":  {Path: "sub/deep.txt", Size: 4, ModTime: now},
+		"same.txt":      {Path: "same.txt", Size: 5, ModTime: now},
+		"edited.txt":    {Path: "edited.txt", Size: 9, ModTime: now},
+		"gone-remote.x": {Path: "gone-remote.x", Size: 1, ModTime: now},
+		"edited-gone.x": {Path: "edited-gone.x", Size: 7, ModTime: now},
+	}
+	remote := []driveTreeEntry{
+		{Path: "same.txt", File: &drive.File{Id: "s1", MimeType: "text/plain", ModifiedTime: "2024-01-01T00:00:00Z"}},
+		{Path: "edited.txt", File: &drive.File{Id: "e1", MimeType: "text/plain", ModifiedTime: "2024-01-01T00:00:00Z"}},
+		{Path: "Report", File: &drive.File{Id: "d1", MimeType: driveMimeGoogleDoc, ModifiedTime: "2024-01-01T00:00:00Z"}},
+		{Path: "Form", File: &drive.File{Id: "f1", MimeType: "application/vnd.google-apps.form"}},
+		{Path: "gone-local.bin", File: &drive.File{Id: "g1", MimeType: "application/octet-stream", ModifiedTime: "2024-01-01T00:00:00Z"}},
+		{Path: "changed-gone.bin", File: &drive.File{Id: "c1", MimeType: "application/octet-stream", ModifiedTime: "2024-02-01T00:00:00Z"}},
+	}
+	state := &driveSyncState{Files: map[string]driveSyncStateEntry{
+		"same.txt":         {FileID: "s1", LocalSize: 5, LocalModTimeMs: now.UnixMilli(), RemoteModifiedTime: "2024-01-01T00:00:00Z"},
+		"edited.txt":       {FileID: "e1", LocalSize: 1, LocalModTimeMs: 1, RemoteModifiedTime: "2024-01-01T00:00:00Z"},
+		"gone-remote.x":    {FileID: "r1", LocalSize: 1, LocalModTimeMs: now.UnixMilli()},
+		"gone-local.bin":   {FileID: "g1", RemoteModifiedTime: "2024-01-01T00:00:00Z"},
+		"edited-gone.x":    {FileID: "x1", LocalSize: 2, LocalModTimeMs: 1},
+		"changed-gone.bin": {FileID: "c1", RemoteModifiedTime: "2024-01-01T00:00:00Z"},
+		"stale":            {FileID: "zz"},
+	}}
+
+	actions := planDriveSync(local, remote, state, true, driveSyncPreferNone)
+	got := map[string]string{}
+	for _, a := range actions {
+		got[a.Path] = a.Action
+	}
+	want := map[string]string{
+		"new.txt":        driveSyncUpload,
+		"sub":            driveSyncMkdir,
+		"sub/deep.txt":   driveSyncUpload,
+		"edited.txt":     driveSyncUpdate,
+		"Report.pdf":     driveSyncDownload,
+		"Form":           driveSyncSkip,
+		"gone-remote.x":  driveSyncDeleteLocal,
+		"gone-local.bin": driveSyncDeleteRemote,
+		// Edited since the last sync: never delete, report a conflict instead.
+		"edited-gone.x":    driveSyncConflict,
+		"changed-gone.bin": driveSyncConflict,
+	}
+	for p, action := range want {
+		if got[p] != action {
+			t.Fatalf("%s: expected %q, got %q (all=%v)", p, action, got[p], got)
+		}
+	}
+	if _, ok := got["same.txt"]; ok {
+		t.Fatalf("unchanged file should not produce an action: %v", got)
+	}
+	if _, ok := state.Files["stale"]; ok {
+		t.Fatalf("expected stale state entry to be pruned")
+	}
+
+	// Without --delete, files missing on one side are copied back instead.
+	state.Files["gone-remote.x"] = driveSyncStateEntry{FileID: "r1"}
+	state.Files["gone-local.bin"] = driveSyncStateEntry{FileID: "g1"}
+	actions = planDriveSync(local, remote, state, false, driveSyncPreferNone)
+	for _, a := range actions {
+		if a.Action == driveSyncDeleteLocal || a.Action == driveSyncDeleteRemote {
+			t.Fatalf("unexpected delete without --delete: %+v", a)
+		}
+	}
+}
+
+func TestPlanDriveSync_FirstSyncDifferences(t *testing.T) {
+	local := map[string]*driveSyncLocalFile{
+		"notes.txt": {Path: "notes.txt", Size: 3, ModTime: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	remote := []driveTreeEntry{
+		{Path: "notes.txt", File: &drive.File{Id: "n1", MimeType: "text/plain", Md5Checksum: "other", ModifiedTime: "2024-01-01T00:00:00Z"}},
+	}
+	local["notes.txt"].md5 = "mine"
+
+	for prefer, want := range map[string]string{
+		driveSyncPreferNone:  driveSyncConflict,
+		driveSyncPreferLocal: driveSyncUpdate,
+		driveSyncPreferDrive: driveSyncDownload,
+	} {
+		state := &driveSyncState{Files: map[string]driveSyncStateEntry{}}
+		actions := planDriveSync(local, remote, state, false, prefer)
+		if len(actions) != 1 || actions[0].Action != want {
+			t.Fatalf("--prefer %s: expected %q, got %+v", prefer, want, actions)
+		}
+	}
+}
+
+func TestDriveSync_UploadsAndDownloads(t *testing.T) {
+	origNew := newDriveService
+	t.Cleanup(func() { newDriveService = origNew })
+
+	var (
+		mu        sync.Mutex
+		uploads   []string
+		downloads int
+	)
+	svc, closeSrv := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/drive/v3")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && path == "/files/root1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "root1", "name": "Reports", "mimeType": driveMimeFolder})
+		case r.Method == http.MethodGet && path == "/files/remote1" && r.URL.Query().Get("alt") == "media":
+			mu.Lock()
+			downloads++
+			mu.Unlock()
+			_, _ = w.Write([]byte("remote data"))
+		case r.Method == http.MethodGet && path == "/files":
+			if !strings.Contains(r.URL.Query().Get("q"), "'root1' in parents") {
+				_ = json.NewEncoder(w).Encode(map[string]any{"files": []any{}})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"files": []map[string]any{
+					{"id": "remote1", "name": "remote.txt", "mimeType": "text/plain", "size": "11", "md5Checksum": "abc", "modifiedTime": "2024-01-01T00:00:00Z"},
+				},
+			})
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/drive/v3/files"):
+			mu.Lock()
+			uploads = append(uploads, r.URL.Path)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "new1", "name": "local.txt", "modifiedTime": "2024-02-01T00:00:00Z"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer closeSrv()
+	newDriveService = stubDriveService(svc)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "local.txt"), []byte("local"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	// Dry run only plans.
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--json", "--dry-run", "--account", "a@b.com", "drive", "sync", dir, "root1", "--state", statePath}); err != nil {
+			t.Fatalf("sync dry-run: %v", err)
+		}
+	})
+	var plan struct {
+		DryRun  bool              `json:"dryRun"`
+		Actions []driveSyncAction `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(out), &plan); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if !plan.DryRun || len(plan.Actions) != 2 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if len(uploads) != 0 || downloads != 0 {
+		t.Fatalf("dry run made changes")
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Fatalf("dry run should not write state, err=%v", err)
+	}
+
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "drive", "sync", dir, "root1", "--state", statePath}); err != nil {
+				t.Fatalf("sync: %v", err)
+			}
+		})
+	})
+	if len(uploads) != 1 || downloads != 1 {
+		t.Fatalf("expected 1 upload and 1 download, got %d/%d", len(uploads), downloads)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "remote.txt"))
+	if err != nil || string(data) != "remote data" {
+		t.Fatalf("downloaded file: %q err=%v", data, err)
+	}
+
+	var state driveSyncState
+	raw, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("read state: %v", err)
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		t.Fatalf("unmarshal state: %v", err)
+	}
+	if state.Files["local.txt"].FileID != "new1" || state.Files["remote.txt"].FileID != "remote1" {
+		t.Fatalf("unexpected state: %+v", state.Files)
+	}
+}
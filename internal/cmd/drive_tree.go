@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+)
+
+const (
+	driveMimeShortcut = "application/vnd.google-apps.shortcut"
+
+	driveTreeFileFields = "id, name, mimeType, size, md5Checksum, modifiedTime, parents"
+	driveTreePageSize   = 1000
+)
+
+// driveTreeEntry is a file or folder found below a root folder.
+type driveTreeEntry struct {
+	// Path is slash-separated and relative to the root folder.
+	Path string
+	File *drive.File
+}
+
+// listDriveFolderChildren returns all non-trashed direct children of folderID.
+func listDriveFolderChildren(ctx context.Context, svc *drive.Service, folderID string, fields string) ([]*drive.File, error) {
+	fetch := func(pageToken string) ([]*drive.File, string, error) {
+		call := svc.Files.List().
+			Q(buildDriveListQuery(folderID, "")).
+			PageSize(driveTreePageSize).
+			OrderBy("folder, name")
+		call = driveFilesListCallWithDriveSupport(call, true)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.
+			Fields(gapi.Field("nextPageToken, files(" + fields + ")")).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Files, resp.NextPageToken, nil
+	}
+	return collectAllPages("", fetch)
+}
+
+// walkDriveTree lists everything below rootID depth-first. Folders are
+// returned before their contents so callers can recreate the hierarchy in order.
+func walkDriveTree(ctx context.Context, svc *drive.Service, rootID string, fields string) ([]driveTreeEntry, error) {
+	var out []driveTreeEntry
+	seen := map[string]bool{rootID: true}
+
+	var walk func(folderID string, prefix string) error
+	walk = func(folderID string, prefix string) error {
+		children, err := listDriveFolderChildren(ctx, svc, folderID, fields)
+		if err != nil {
+			return err
+		}
+		for _, f := range children {
+			if f == nil {
+				continue
+			}
+			entry := driveTreeEntry{Path: path.Join(prefix, driveLocalName(f.Name)), File: f}
+			out = append(out, entry)
+			if f.MimeType != driveMimeFolder || seen[f.Id] {
+				continue
+			}
+			// A file can have several parents; guard against visiting a folder twice.
+			seen[f.Id] = true
+			if err := walk(f.Id, entry.Path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(rootID, ""); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// driveLocalName turns a Drive file name into a single safe path element.
+func driveLocalName(name string) string {
+	name = strings.TrimSpace(strings.ReplaceAll(name, "/", "_"))
+	switch name {
+	case "", ".", "..":
+		return "_"
+	default:
+		return name
+	}
+}
+
+func isGoogleWorkspaceMimeType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "application/vnd.google-apps.")
+}
+
+// isDriveExportable reports whether a Google Workspace file can be exported
+// through downloadDriveFile (Forms, Sites, shortcuts etc. cannot).
+func isDriveExportable(mimeType string) bool {
+	switch mimeType {
+	case driveMimeGoogleDoc, driveMimeGoogleSheet, driveMimeGoogleSlides, driveMimeGoogleDrawing:
+		return true
+	default:
+		return false
+	}
+}
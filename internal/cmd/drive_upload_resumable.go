@@ -0,0 +1,415 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+	"google.golang.org/api/drive/v3"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	// Drive requires resumable chunks to be multiples of 256 KiB (except the last one).
+	driveUploadChunkQuantum   = 256 * 1024
+	defaultDriveUploadChunk   = "8MB"
+	driveUploadChunkTimeout   = 10 * time.Minute
+	driveUploadResponseFields = "id, name, mimeType, size, md5Checksum, modifiedTime, webViewLink"
+	statusResumeIncomplete    = 308
+)
+
+// driveResumableUpload describes one upload through Drive's resumable protocol.
+// The session URI is persisted so an interrupted transfer can be continued
+// with `drive upload --resume`.
+type driveResumableUpload struct {
+	Account             string
+	LocalPath           string
+	Size                int64
+	ModTime             time.Time
+	FileID              string // replace target; empty creates a new file
+	Meta                *drive.File
+	MimeType            string
+	KeepRevisionForever bool
+	ChunkSize           int64
+	Resume              bool
+}
+
+type driveUploadSession struct {
+	SessionURI  string   `json:"sessionUri"`
+	Account     string   `json:"account"`
+	LocalPath   string   `json:"localPath"`
+	Size        int64    `json:"size"`
+	ModTimeMs   int64    `json:"modTimeMs"`
+	FileID      string   `json:"fileId,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Parents     []string `json:"parents,omitempty"`
+	CreatedAtMs int64    `json:"createdAtMs"`
+}
+
+// errDriveUploadSessionGone reports an expired or unknown resumable session.
+var errDriveUploadSessionGone = errors.New("upload session expired")
+
+func (up *driveResumableUpload) sessionPath() (string, error) {
+	dir, err := config.EnsureDriveUploadsDir()
+	if err != nil {
+		return "", err
+	}
+	parents := ""
+	name := ""
+	if up.Meta != nil {
+		parents = strings.Join(up.Meta.Parents, ",")
+		name = up.Meta.Name
+	}
+	key := strings.Join([]string{
+		strings.ToLower(up.Account),
+		up.LocalPath,
+		up.FileID,
+		parents,
+		name,
+		strconv.FormatInt(up.Size, 10),
+		strconv.FormatInt(up.ModTime.UnixMilli(), 10),
+	}, "\x00")
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:12])+".json"), nil
+}
+
+// Run uploads f and returns the resulting Drive file. progress, when non-nil,
+// receives the number of bytes the server has confirmed.
+func (up *driveResumableUpload) Run(ctx context.Context, u *ui.UI, svc *drive.Service, client *http.Client, f io.ReadSeeker, progress func(int64)) (*drive.File, error) {
+	statePath, err := up.sessionPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sessionURI string
+		offset     int64
+	)
+	if up.Resume {
+		sessionURI, offset, err = up.resumeSession(ctx, client, statePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			if u != nil {
+				u.Err().Println("No interrupted upload found; starting a new upload")
+			}
+		case errors.Is(err, errDriveUploadSessionGone):
+			if u != nil {
+				u.Err().Println("Previous upload session expired; starting a new upload")
+			}
+			sessionURI = ""
+		case err != nil:
+			return nil, err
+		}
+		if offset > up.Size {
+			return nil, fmt.Errorf("upload session reports %d bytes but file has %d", offset, up.Size)
+		}
+	}
+	if sessionURI == "" {
+		sessionURI, err = up.startSession(ctx, svc, client)
+		if err != nil {
+			return nil, err
+		}
+		offset = 0
+		if saveErr := up.saveSession(statePath, sessionURI); saveErr != nil {
+			return nil, saveErr
+		}
+	} else if u != nil {
+		u.Err().Printf("Resuming upload at %s of %s", formatDriveSize(offset), formatDriveSize(up.Size))
+	}
+
+	file, err := up.sendChunks(ctx, client, sessionURI, f, offset, progress)
+	if err != nil {
+		return nil, fmt.Errorf("%w (rerun with --resume to continue)", err)
+	}
+	_ = os.Remove(statePath)
+	return file, nil
+}
+
+func (up *driveResumableUpload) startSession(ctx context.Context, svc *drive.Service, client *http.Client) (string, error) {
+	q := url.Values{}
+	q.Set("uploadType", "resumable")
+	q.Set("supportsAllDrives", "true")
+	q.Set("fields", driveUploadResponseFields)
+	if up.KeepRevisionForever {
+		q.Set("keepRevisionForever", "true")
+	}
+
+	method := http.MethodPost
+	endpoint := gapi.ResolveRelative(svc.BasePath, "/upload/drive/v3/files")
+	if up.FileID != "" {
+		method = http.MethodPatch
+		endpoint = gapi.ResolveRelative(svc.BasePath, "/upload/drive/v3/files/"+url.PathEscape(up.FileID))
+	}
+
+	meta := up.Meta
+	if meta == nil {
+		meta = &drive.File{}
+	}
+	body, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+"?"+q.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Type", up.MimeType)
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(up.Size, 10))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := gapi.CheckResponse(resp); err != nil {
+		return "", err
+	}
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", errors.New("drive did not return a resumable upload session")
+	}
+	return location, nil
+}
+
+// resumeSession loads the stored session and asks Drive how much it has received.
+func (up *driveResumableUpload) resumeSession(ctx context.Context, client *http.Client, statePath string) (string, int64, error) {
+	data, err := os.ReadFile(statePath) //nolint:gosec // path inside config dir
+	if err != nil {
+		return "", 0, err
+	}
+	var session driveUploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return "", 0, fmt.Errorf("read upload session %s: %w", statePath, err)
+	}
+	if session.SessionURI == "" {
+		return "", 0, os.ErrNotExist
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, session.SessionURI, http.NoBody)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", up.Size))
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == statusResumeIncomplete:
+		return session.SessionURI, driveUploadNextOffset(resp.Header.Get("Range")), nil
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated:
+		// Everything was received before the interruption.
+		return session.SessionURI, up.Size, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return "", 0, errDriveUploadSessionGone
+	default:
+		if err := gapi.CheckResponse(resp); err != nil {
+			return "", 0, err
+		}
+		return "", 0, fmt.Errorf("unexpected upload status %s", resp.Status)
+	}
+}
+
+func (up *driveResumableUpload) saveSession(statePath, sessionURI string) error {
+	session := driveUploadSession{
+		SessionURI:  sessionURI,
+		Account:     up.Account,
+		LocalPath:   up.LocalPath,
+		Size:        up.Size,
+		ModTimeMs:   up.ModTime.UnixMilli(),
+		FileID:      up.FileID,
+		CreatedAtMs: time.Now().UnixMilli(),
+	}
+	if up.Meta != nil {
+		session.Name = up.Meta.Name
+		session.Parents = up.Meta.Parents
+	}
+	payload, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, append(payload, '\n'), 0o600)
+}
+
+func (up *driveResumableUpload) sendChunks(ctx context.Context, client *http.Client, sessionURI string, f io.ReadSeeker, offset int64, progress func(int64)) (*drive.File, error) {
+	chunkSize := up.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = driveUploadChunkQuantum
+	}
+	buf := make([]byte, chunkSize)
+
+	// The API client's overall timeout is sized for metadata calls and would
+	// cut off large chunks on slow links; each chunk gets its own deadline.
+	chunkClient := *client
+	chunkClient.Timeout = 0
+
+	for {
+		if progress != nil {
+			progress(offset)
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		n, err := io.ReadFull(f, buf)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		chunkCtx, cancel := context.WithTimeout(ctx, driveUploadChunkTimeout)
+		req, err := http.NewRequestWithContext(chunkCtx, http.MethodPut, sessionURI, bytes.NewReader(buf[:n]))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		req.ContentLength = int64(n)
+		if n == 0 {
+			req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", up.Size))
+		} else {
+			req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(n)-1, up.Size))
+		}
+
+		resp, err := chunkClient.Do(req)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		switch resp.StatusCode {
+		case statusResumeIncomplete:
+			next := driveUploadNextOffset(resp.Header.Get("Range"))
+			resp.Body.Close()
+			cancel()
+			if next <= offset && n > 0 {
+				return nil, fmt.Errorf("upload made no progress at byte %d", offset)
+			}
+			offset = next
+		case http.StatusOK, http.StatusCreated:
+			var file drive.File
+			decodeErr := json.NewDecoder(resp.Body).Decode(&file)
+			resp.Body.Close()
+			cancel()
+			if decodeErr != nil {
+				return nil, fmt.Errorf("decode upload response: %w", decodeErr)
+			}
+			if progress != nil {
+				progress(up.Size)
+			}
+			return &file, nil
+		default:
+			checkErr := gapi.CheckResponse(resp)
+			resp.Body.Close()
+			cancel()
+			if checkErr == nil {
+				checkErr = fmt.Errorf("unexpected upload status %s", resp.Status)
+			}
+			return nil, checkErr
+		}
+	}
+}
+
+// driveUploadNextOffset parses a "bytes=0-1234" Range header into the next byte to send.
+func driveUploadNextOffset(rangeHeader string) int64 {
+	rangeHeader = strings.TrimSpace(rangeHeader)
+	if rangeHeader == "" {
+		return 0
+	}
+	_, last, ok := strings.Cut(rangeHeader, "-")
+	if !ok {
+		return 0
+	}
+	end, err := strconv.ParseInt(strings.TrimSpace(last), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return end + 1
+}
+
+// parseDriveChunkSize parses sizes like 8MB, 512KiB or 1048576 and rounds up to
+// the 256 KiB granularity Drive requires.
+func parseDriveChunkSize(raw string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	if s == "" {
+		s = defaultDriveUploadChunk
+	}
+	mult := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{
+		{"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			mult = unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, usagef("invalid --chunk-size %q (e.g. 8MB, 512KB)", raw)
+	}
+	size := n * mult
+	if rem := size % driveUploadChunkQuantum; rem != 0 {
+		size += driveUploadChunkQuantum - rem
+	}
+	return size, nil
+}
+
+// stderrIsTerminal reports whether stderr is a TTY; stubbed in tests.
+var stderrIsTerminal = func() bool { return term.IsTerminal(int(os.Stderr.Fd())) }
+
+// driveUploadProgressBar renders a single-line progress bar on stderr. It is
+// a no-op unless stderr is a terminal, so logs and captured output stay clean.
+func driveUploadProgressBar(u *ui.UI, total int64) (update func(int64), done func()) {
+	if u == nil || total <= 0 || !stderrIsTerminal() {
+		return func(int64) {}, func() {}
+	}
+	const width = 30
+	start := time.Now()
+	drawn := false
+	update = func(sent int64) {
+		pct := float64(sent) / float64(total)
+		filled := int(pct * width)
+		rate := ""
+		if elapsed := time.Since(start).Seconds(); elapsed > 0 && sent > 0 {
+			rate = fmt.Sprintf(" %s/s", formatDriveSize(int64(float64(sent)/elapsed)))
+		}
+		u.Err().Print(fmt.Sprintf("\r[%s%s] %3.0f%% %s/%s%s ",
+			strings.Repeat("=", filled),
+			strings.Repeat(" ", width-filled),
+			pct*100,
+			formatDriveSize(sent),
+			formatDriveSize(total),
+			rate,
+		))
+		drawn = true
+	}
+	done = func() {
+		if drawn {
+			u.Err().Print("\n")
+		}
+	}
+	return update, done
+}
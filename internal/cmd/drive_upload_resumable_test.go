@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+func TestDriveUploadProgressBar_OnlyOnTerminal(t *testing.T) {
+	orig := stderrIsTerminal
+	t.Cleanup(func() { stderrIsTerminal = orig })
+
+	for _, tty := range []bool{false, true} {
+		stderrIsTerminal = func() bool { return tty }
+		var errBuf bytes.Buffer
+		u, err := ui.New(ui.Options{Stdout: io.Discard, Stderr: &errBuf, Color: "never"})
+		if err != nil {
+			t.Fatalf("ui.New: %v", err)
+		}
+		update, done := driveUploadProgressBar(u, 100)
+		update(50)
+		done()
+		if got := strings.Contains(errBuf.String(), "\r["); got != tty {
+			t.Fatalf("tty=%v: unexpected progress output %q", tty, errBuf.String())
+		}
+	}
+}
+
+func TestParseDriveChunkSize(t *testing.T) {
+	for raw, want := range map[string]int64{
+		"":       8 << 20,
+		"8MB":    8 << 20,
+		"512kb":  512 << 10,
+		"1MiB":   1 << 20,
+		"100":    driveUploadChunkQuantum,
+		"300KB":  512 << 10,
+		"2G":     2 << 30,
+		" 16M  ": 16 << 20,
+	} {
+		got, err := parseDriveChunkSize(raw)
+		if err != nil {
+			t.Fatalf("parseDriveChunkSize(%q): %v", raw, err)
+		}
+		if got != want {
+			t.Fatalf("parseDriveChunkSize(%q) = %d, want %d", raw, got, want)
+		}
+	}
+	for _, raw := range []string{"abc", "-1MB", "0"} {
+		if _, err := parseDriveChunkSize(raw); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+// resumableDriveServer emulates Drive's resumable upload protocol. failAt makes
+// the chunk starting at that offset fail once with a server error.
+type resumableDriveServer struct {
+	t      *testing.T
+	mu     sync.Mutex
+	data   []byte
+	starts int
+	failAt int64
+	total  int64
+	delay  time.Duration // per chunk PUT
+}
+
+func (s *resumableDriveServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/drive/v3/files":
+		if r.URL.Query().Get("uploadType") != "resumable" {
+			s.t.Errorf("expected resumable upload, got %q", r.URL.RawQuery)
+		}
+		var meta struct {
+			Name    string   `json:"name"`
+			Parents []string `json:"parents"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&meta)
+		if meta.Name != "big.bin" || len(meta.Parents) != 1 || meta.Parents[0] != "p1" {
+			s.t.Errorf("unexpected metadata: %+v", meta)
+		}
+		_, _ = fmt.Sscan(r.Header.Get("X-Upload-Content-Length"), &s.total)
+		s.starts++
+		s.data = nil
+		w.Header().Set("Location", "http://"+r.Host+"/session/1")
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPut && r.URL.Path == "/session/1":
+		time.Sleep(s.delay)
+		body, _ := io.ReadAll(r.Body)
+		if len(body) > 0 {
+			var start, end, total int64
+			if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err != nil {
+				s.t.Errorf("bad Content-Range %q", r.Header.Get("Content-Range"))
+			}
+			if start != int64(len(s.data)) {
+				s.t.Errorf("chunk starts at %d, server has %d", start, len(s.data))
+			}
+			if s.failAt >= 0 && start == s.failAt {
+				s.failAt = -1
+				http.Error(w, `{"error":{"code":503,"message":"backend unavailable"}}`, http.StatusServiceUnavailable)
+				return
+			}
+			s.data = append(s.data, body...)
+		}
+		if int64(len(s.data)) < s.total {
+			if len(s.data) > 0 {
+				w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(s.data)-1))
+			}
+			w.WriteHeader(statusResumeIncomplete)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "big1", "name": "big.bin", "size": fmt.Sprint(len(s.data))})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDriveUpload_ResumableChunks(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+
+	origNew := newDriveService
+	origHTTP := newDriveHTTPClient
+	t.Cleanup(func() {
+		newDriveService = origNew
+		newDriveHTTPClient = origHTTP
+	})
+
+	srv := &resumableDriveServer{t: t, failAt: 2 * driveUploadChunkQuantum}
+	svc, closeSrv := newDriveTestService(t, srv)
+	defer closeSrv()
+	newDriveService = stubDriveService(svc)
+	newDriveHTTPClient = func(context.Context, string) (*http.Client, error) { return http.DefaultClient, nil }
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), (5*driveUploadChunkQuantum/2)/16)
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(localPath, content, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	args := []string{"--json", "--account", "a@b.com", "drive", "upload", localPath, "--parent", "p1", "--chunk-size", "256KB"}
+
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			err := Execute(args)
+			if err == nil || !strings.Contains(err.Error(), "--resume") {
+				t.Fatalf("expected interrupted upload with --resume hint, got %v", err)
+			}
+		})
+	})
+	if len(srv.data) != 2*driveUploadChunkQuantum {
+		t.Fatalf("expected two chunks before failure, got %d bytes", len(srv.data))
+	}
+
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute(append(args, "--resume")); err != nil {
+				t.Fatalf("resume: %v", err)
+			}
+		})
+	})
+	if srv.starts != 1 {
+		t.Fatalf("expected the stored session to be reused, got %d session starts", srv.starts)
+	}
+	if !bytes.Equal(srv.data, content) {
+		t.Fatalf("uploaded content mismatch: got %d bytes, want %d", len(srv.data), len(content))
+	}
+
+	var got struct {
+		File struct {
+			ID string `json:"id"`
+		} `json:"file"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if got.File.ID != "big1" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(home, "xdg-config", "gogcli", "state", "drive-uploads"))
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected session state to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestDriveUploadSendChunks_OutlastsClientTimeout(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 2*driveUploadChunkQuantum)
+	server := &resumableDriveServer{t: t, failAt: -1, total: int64(len(payload)), delay: 150 * time.Millisecond}
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+
+	// Shorter than one chunk takes: the chunk context, not this, must apply.
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	up := &driveResumableUpload{Size: int64(len(payload)), ChunkSize: driveUploadChunkQuantum}
+	file, err := up.sendChunks(context.Background(), client, srv.URL+"/session/1", bytes.NewReader(payload), 0, nil)
+	if err != nil {
+		t.Fatalf("sendChunks: %v", err)
+	}
+	if file.Id != "big1" || !bytes.Equal(server.data, payload) {
+		t.Fatalf("unexpected upload result: %+v (%d bytes on server)", file, len(server.data))
+	}
+}
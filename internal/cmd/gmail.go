@@ -26,13 +26,18 @@ type GmailCmd struct {
 	Attachment GmailAttachmentCmd `cmd:"" name:"attachment" group:"Read" help:"Download a single attachment"`
 	URL        GmailURLCmd        `cmd:"" name:"url" group:"Read" help:"Print Gmail web URLs for threads"`
 	History    GmailHistoryCmd    `cmd:"" name:"history" group:"Read" help:"Gmail history"`
+	Export     GmailExportCmd     `cmd:"" name:"export" aliases:"backup" group:"Read" help:"Archive messages to mbox or Maildir (incremental)"`
+	Stats      GmailStatsCmd      `cmd:"" name:"stats" aliases:"analytics" group:"Read" help:"Mailbox statistics by sender, domain, label, list and time"`
 
 	Labels GmailLabelsCmd `cmd:"" name:"labels" aliases:"label" group:"Organize" help:"Label operations"`
 	Batch  GmailBatchCmd  `cmd:"" name:"batch" group:"Organize" help:"Batch operations"`
 
-	Send   GmailSendCmd   `cmd:"" name:"send" group:"Write" help:"Send an email"`
-	Track  GmailTrackCmd  `cmd:"" name:"track" group:"Write" help:"Email open tracking"`
-	Drafts GmailDraftsCmd `cmd:"" name:"drafts" aliases:"draft" group:"Write" help:"Draft operations"`
+	Send      GmailSendCmd      `cmd:"" name:"send" group:"Write" help:"Send an email"`
+	Track     GmailTrackCmd     `cmd:"" name:"track" group:"Write" help:"Email open tracking"`
+	Drafts    GmailDraftsCmd    `cmd:"" name:"drafts" aliases:"draft" group:"Write" help:"Draft operations"`
+	Merge     GmailMergeCmd     `cmd:"" name:"merge" aliases:"mailmerge" group:"Write" help:"Send personalised messages from a CSV (mail merge)"`
+	Scheduled GmailScheduledCmd `cmd:"" name:"scheduled" aliases:"schedule" group:"Write" help:"Locally scheduled sends (see send --at)"`
+	Import    GmailImportCmd    `cmd:"" name:"import" aliases:"restore" group:"Write" help:"Import .eml, mbox, or Maildir messages (resumable)"`
 
 	Settings GmailSettingsCmd `cmd:"" name:"settings" group:"Admin" help:"Settings and admin"`
 
@@ -52,6 +57,7 @@ type GmailSettingsCmd struct {
 	Forwarding  GmailForwardingCmd  `cmd:"" name:"forwarding" group:"Admin" help:"Forwarding addresses"`
 	AutoForward GmailAutoForwardCmd `cmd:"" name:"autoforward" group:"Admin" help:"Auto-forwarding settings"`
 	SendAs      GmailSendAsCmd      `cmd:"" name:"sendas" group:"Admin" help:"Send-as settings"`
+	Signature   GmailSignatureCmd   `cmd:"" name:"signature" group:"Admin" help:"Send-as signatures"`
 	Vacation    GmailVacationCmd    `cmd:"" name:"vacation" group:"Admin" help:"Vacation responder"`
 	Watch       GmailWatchCmd       `cmd:"" name:"watch" group:"Admin" help:"Manage Gmail watch"`
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/steipete/gogcli/internal/config"
@@ -35,3 +36,63 @@ func resolveBodyInput(body, bodyFile string) (string, error) {
 	}
 	return string(b), nil
 }
+
+// composeBody is a message body resolved from the --body* flags.
+type composeBody struct {
+	Text   string
+	HTML   string
+	Inline []mailAttachment
+}
+
+// resolveComposeBody resolves --body, --body-file, --body-html and
+// --body-markdown. Markdown (from --body-markdown or a .md --body-file) is
+// rendered to HTML plus a text alternative; local images in it become inline
+// parts, resolved relative to the Markdown file.
+func resolveComposeBody(body, bodyFile, bodyHTML, bodyMarkdown string) (composeBody, error) {
+	markdownFile := isMarkdownPath(bodyFile)
+	if strings.TrimSpace(bodyMarkdown) == "" && !markdownFile {
+		text, err := resolveBodyInput(body, bodyFile)
+		if err != nil {
+			return composeBody{}, err
+		}
+		return composeBody{Text: text, HTML: bodyHTML}, nil
+	}
+	if strings.TrimSpace(bodyMarkdown) != "" && (strings.TrimSpace(body) != "" || strings.TrimSpace(bodyFile) != "") {
+		return composeBody{}, usage("use only one of --body, --body-file, or --body-markdown")
+	}
+	if strings.TrimSpace(bodyHTML) != "" {
+		return composeBody{}, usage("use only one of --body-html or a Markdown body")
+	}
+
+	src := bodyMarkdown
+	baseDir := ""
+	if markdownFile {
+		var err error
+		src, err = resolveBodyInput(body, bodyFile)
+		if err != nil {
+			return composeBody{}, err
+		}
+		expanded, err := config.ExpandPath(strings.TrimSpace(bodyFile))
+		if err != nil {
+			return composeBody{}, err
+		}
+		baseDir = filepath.Dir(expanded)
+	}
+	if strings.TrimSpace(src) == "" {
+		return composeBody{}, nil
+	}
+	rendered, err := renderMarkdownEmail(src, baseDir)
+	if err != nil {
+		return composeBody{}, err
+	}
+	return composeBody{Text: rendered.Text, HTML: rendered.HTML, Inline: rendered.Inline}, nil
+}
+
+func isMarkdownPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(strings.TrimSpace(path))) {
+	case ".md", ".markdown":
+		return true
+	default:
+		return false
+	}
+}
@@ -265,7 +265,7 @@ func (c *GmailDraftsSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	msg, err := svc.Users.Drafts.Send("me", &gmail.Draft{Id: draftID}).Do()
+	msg, err := sendGmailDraft(ctx, svc, draftID)
 	if err != nil {
 		return err
 	}
@@ -282,18 +282,24 @@ func (c *GmailDraftsSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	return nil
 }
 
+func sendGmailDraft(ctx context.Context, svc *gmail.Service, draftID string) (*gmail.Message, error) {
+	return svc.Users.Drafts.Send("me", &gmail.Draft{Id: draftID}).Context(ctx).Do()
+}
+
 type GmailDraftsCreateCmd struct {
 	To               string   `name:"to" help:"Recipients (comma-separated)"`
 	Cc               string   `name:"cc" help:"CC recipients (comma-separated)"`
 	Bcc              string   `name:"bcc" help:"BCC recipients (comma-separated)"`
 	Subject          string   `name:"subject" help:"Subject (required)"`
-	Body             string   `name:"body" help:"Body (plain text; required unless --body-html is set)"`
-	BodyFile         string   `name:"body-file" help:"Body file path (plain text; '-' for stdin)"`
+	Body             string   `name:"body" help:"Body (plain text; required unless --body-html or --body-markdown is set)"`
+	BodyFile         string   `name:"body-file" help:"Body file path (plain text, or Markdown for .md/.markdown; '-' for stdin)"`
 	BodyHTML         string   `name:"body-html" help:"Body (HTML; optional)"`
+	BodyMarkdown     string   `name:"body-markdown" help:"Body (Markdown; sent as HTML with a plain-text alternative, local images inlined)"`
 	ReplyToMessageID string   `name:"reply-to-message-id" help:"Reply to Gmail message ID (sets In-Reply-To/References and thread)"`
 	ReplyTo          string   `name:"reply-to" help:"Reply-To header address"`
 	Attach           []string `name:"attach" help:"Attachment file path (repeatable)"`
 	From             string   `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+	Signature        bool     `name:"signature" help:"Append the send-as signature (of --from or the account) to the text and HTML parts"`
 }
 
 type draftComposeInput struct {
@@ -307,7 +313,9 @@ type draftComposeInput struct {
 	ReplyToThreadID  string
 	ReplyTo          string
 	Attach           []string
+	Inline           []mailAttachment
 	From             string
+	Signature        bool
 }
 
 func (c draftComposeInput) validate() error {
@@ -315,7 +323,7 @@ func (c draftComposeInput) validate() error {
 		return usage("required: --subject")
 	}
 	if strings.TrimSpace(c.Body) == "" && strings.TrimSpace(c.BodyHTML) == "" {
-		return usage("required: --body, --body-file, or --body-html")
+		return usage("required: --body, --body-file, --body-html, or --body-markdown")
 	}
 	return nil
 }
@@ -336,6 +344,19 @@ func buildDraftMessage(ctx context.Context, svc *gmail.Service, account string,
 		}
 	}
 
+	body, bodyHTML := input.Body, input.BodyHTML
+	if input.Signature {
+		sendingEmail := account
+		if strings.TrimSpace(input.From) != "" {
+			sendingEmail = strings.TrimSpace(input.From)
+		}
+		signature, err := fetchSendAsSignature(ctx, svc, sendingEmail)
+		if err != nil {
+			return nil, "", err
+		}
+		body, bodyHTML = appendSignature(body, bodyHTML, signature)
+	}
+
 	info, err := fetchReplyInfo(ctx, svc, input.ReplyToMessageID, input.ReplyToThreadID, false)
 	if err != nil {
 		return nil, "", err
@@ -344,7 +365,7 @@ func buildDraftMessage(ctx context.Context, svc *gmail.Service, account string,
 	references := info.References
 	threadID := info.ThreadID
 
-	atts := make([]mailAttachment, 0, len(input.Attach))
+	atts := make([]mailAttachment, 0, len(input.Attach)+len(input.Inline))
 	for _, p := range input.Attach {
 		expanded, expandErr := config.ExpandPath(p)
 		if expandErr != nil {
@@ -352,6 +373,7 @@ func buildDraftMessage(ctx context.Context, svc *gmail.Service, account string,
 		}
 		atts = append(atts, mailAttachment{Path: expanded})
 	}
+	atts = append(atts, input.Inline...)
 
 	raw, err := buildRFC822(mailOptions{
 		From:        fromAddr,
@@ -360,8 +382,8 @@ func buildDraftMessage(ctx context.Context, svc *gmail.Service, account string,
 		Bcc:         splitCSV(input.Bcc),
 		ReplyTo:     input.ReplyTo,
 		Subject:     input.Subject,
-		Body:        input.Body,
-		BodyHTML:    input.BodyHTML,
+		Body:        body,
+		BodyHTML:    bodyHTML,
 		InReplyTo:   inReplyTo,
 		References:  references,
 		Attachments: atts,
@@ -404,7 +426,7 @@ func writeDraftResult(ctx context.Context, u *ui.UI, draft *gmail.Draft, threadI
 func (c *GmailDraftsCreateCmd) Run(ctx context.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
 
-	body, err := resolveBodyInput(c.Body, c.BodyFile)
+	composed, err := resolveComposeBody(c.Body, c.BodyFile, c.BodyHTML, c.BodyMarkdown)
 	if err != nil {
 		return err
 	}
@@ -424,13 +446,15 @@ func (c *GmailDraftsCreateCmd) Run(ctx context.Context, flags *RootFlags) error
 		Cc:               c.Cc,
 		Bcc:              c.Bcc,
 		Subject:          c.Subject,
-		Body:             body,
-		BodyHTML:         c.BodyHTML,
+		Body:             composed.Text,
+		BodyHTML:         composed.HTML,
 		ReplyToMessageID: replyToMessageID,
 		ReplyToThreadID:  "",
 		ReplyTo:          c.ReplyTo,
 		Attach:           attachPaths,
+		Inline:           composed.Inline,
 		From:             c.From,
+		Signature:        c.Signature,
 	}
 	if validateErr := input.validate(); validateErr != nil {
 		return validateErr
@@ -447,6 +471,8 @@ func (c *GmailDraftsCreateCmd) Run(ctx context.Context, flags *RootFlags) error
 		"reply_to":            strings.TrimSpace(input.ReplyTo),
 		"from":                strings.TrimSpace(input.From),
 		"attachments":         attachPaths,
+		"inline_images":       mailAttachmentPaths(input.Inline),
+		"signature":           input.Signature,
 	}); dryRunErr != nil {
 		return dryRunErr
 	}
@@ -479,13 +505,15 @@ type GmailDraftsUpdateCmd struct {
 	Cc               string   `name:"cc" help:"CC recipients (comma-separated)"`
 	Bcc              string   `name:"bcc" help:"BCC recipients (comma-separated)"`
 	Subject          string   `name:"subject" help:"Subject (required)"`
-	Body             string   `name:"body" help:"Body (plain text; required unless --body-html is set)"`
-	BodyFile         string   `name:"body-file" help:"Body file path (plain text; '-' for stdin)"`
+	Body             string   `name:"body" help:"Body (plain text; required unless --body-html or --body-markdown is set)"`
+	BodyFile         string   `name:"body-file" help:"Body file path (plain text, or Markdown for .md/.markdown; '-' for stdin)"`
 	BodyHTML         string   `name:"body-html" help:"Body (HTML; optional)"`
+	BodyMarkdown     string   `name:"body-markdown" help:"Body (Markdown; sent as HTML with a plain-text alternative, local images inlined)"`
 	ReplyToMessageID string   `name:"reply-to-message-id" help:"Reply to Gmail message ID (sets In-Reply-To/References and thread)"`
 	ReplyTo          string   `name:"reply-to" help:"Reply-To header address"`
 	Attach           []string `name:"attach" help:"Attachment file path (repeatable)"`
 	From             string   `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+	Signature        bool     `name:"signature" help:"Append the send-as signature (of --from or the account) to the text and HTML parts"`
 }
 
 func (c *GmailDraftsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -502,7 +530,7 @@ func (c *GmailDraftsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error
 		to = *c.To
 	}
 
-	body, err := resolveBodyInput(c.Body, c.BodyFile)
+	composed, err := resolveComposeBody(c.Body, c.BodyFile, c.BodyHTML, c.BodyMarkdown)
 	if err != nil {
 		return err
 	}
@@ -522,13 +550,15 @@ func (c *GmailDraftsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error
 		Cc:               c.Cc,
 		Bcc:              c.Bcc,
 		Subject:          c.Subject,
-		Body:             body,
-		BodyHTML:         c.BodyHTML,
+		Body:             composed.Text,
+		BodyHTML:         composed.HTML,
 		ReplyToMessageID: replyToMessageID,
 		ReplyToThreadID:  "",
 		ReplyTo:          c.ReplyTo,
 		Attach:           attachPaths,
+		Inline:           composed.Inline,
 		From:             c.From,
+		Signature:        c.Signature,
 	}
 	if validateErr := input.validate(); validateErr != nil {
 		return validateErr
@@ -547,6 +577,8 @@ func (c *GmailDraftsUpdateCmd) Run(ctx context.Context, flags *RootFlags) error
 		"reply_to":            strings.TrimSpace(input.ReplyTo),
 		"from":                strings.TrimSpace(input.From),
 		"attachments":         attachPaths,
+		"inline_images":       mailAttachmentPaths(input.Inline),
+		"signature":           input.Signature,
 	}); dryRunErr != nil {
 		return dryRunErr
 	}
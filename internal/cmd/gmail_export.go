@@ -0,0 +1,604 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	gapi "google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailExportFormatMbox    = "mbox"
+	gmailExportFormatMaildir = "maildir"
+
+	gmailExportBatchSize = 100
+)
+
+type GmailExportCmd struct {
+	Query            []string `arg:"" optional:"" name:"query" help:"Gmail search query (default: all mail)"`
+	Out              string   `name:"out" required:"" help:"Destination mbox file or Maildir directory"`
+	Format           string   `name:"format" help:"Archive format: mbox|maildir" default:"mbox" enum:"mbox,maildir"`
+	IncludeSpamTrash bool     `name:"include-spam-trash" help:"Include messages from Spam and Trash"`
+	Concurrency      int      `name:"concurrency" help:"Parallel message downloads" default:"8"`
+	Full             bool     `name:"full" help:"Ignore the stored checkpoint and re-run the whole query (already exported messages are still skipped)"`
+}
+
+// gmailExportState remembers how far one destination has been archived so
+// later (or interrupted) runs only fetch messages added since HistoryID or
+// received after LastInternalDate. Exported message IDs live in an
+// append-only sidecar file (see gmailExportIDsPath).
+type gmailExportState struct {
+	Account          string `json:"account"`
+	Query            string `json:"query,omitempty"`
+	Format           string `json:"format"`
+	Path             string `json:"path"`
+	HistoryID        string `json:"historyId,omitempty"`
+	LastInternalDate int64  `json:"lastInternalDate,omitempty"`
+	UpdatedAtMs      int64  `json:"updatedAtMs"`
+}
+
+func gmailExportStatePath(account, outPath, format, query string) (string, error) {
+	dir, err := config.EnsureGmailExportDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{strings.ToLower(account), outPath, format, query}, "\x00")))
+	return filepath.Join(dir, sanitizeAccountForPath(account)+"_"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// gmailExportIDsPath is the append-only list of message IDs (one per line)
+// already written to the archive described by statePath.
+func gmailExportIDsPath(statePath string) string {
+	return strings.TrimSuffix(statePath, ".json") + ".ids"
+}
+
+func loadGmailExportIDs(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path inside config dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]bool{}, nil
+		}
+		return nil, err
+	}
+	lines := strings.Split(string(data), "\n")
+	ids := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		if id := strings.TrimSpace(line); id != "" {
+			ids[id] = true
+		}
+	}
+	return ids, nil
+}
+
+func appendGmailExportIDs(f *os.File, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := f.WriteString(strings.Join(ids, "\n") + "\n"); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+func loadGmailExportState(path string) (*gmailExportState, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path inside config dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &gmailExportState{}, nil
+		}
+		return nil, err
+	}
+	var state gmailExportState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("read export state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+func (s *gmailExportState) save(path string) error {
+	s.UpdatedAtMs = time.Now().UnixMilli()
+	payload, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(payload, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (c *GmailExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	query := strings.TrimSpace(strings.Join(c.Query, " "))
+	format := strings.ToLower(strings.TrimSpace(c.Format))
+	if format != gmailExportFormatMbox && format != gmailExportFormatMaildir {
+		return usage("invalid --format (expected mbox|maildir)")
+	}
+	outPath, err := config.ExpandPath(strings.TrimSpace(c.Out))
+	if err != nil {
+		return err
+	}
+	if outPath == "" {
+		return usage("empty --out")
+	}
+	outPath, err = filepath.Abs(outPath)
+	if err != nil {
+		return err
+	}
+
+	statePath, err := gmailExportStatePath(account, outPath, format, query)
+	if err != nil {
+		return err
+	}
+	state, err := loadGmailExportState(statePath)
+	if err != nil {
+		return err
+	}
+	state.Account = account
+	state.Query = query
+	state.Format = format
+	state.Path = outPath
+
+	exported, err := loadGmailExportIDs(gmailExportIDsPath(statePath))
+	if err != nil {
+		return err
+	}
+	idsFile, err := os.OpenFile(gmailExportIDsPath(statePath), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // path inside config dir
+	if err != nil {
+		return err
+	}
+	defer idsFile.Close()
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	idToName, err := fetchLabelIDToName(svc)
+	if err != nil {
+		return err
+	}
+
+	// Capture the history position before listing so nothing that arrives
+	// while we export is missed by the next incremental run.
+	profile, err := svc.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	ids, incremental, err := c.candidates(ctx, u, svc, state, query)
+	if err != nil {
+		return err
+	}
+
+	pending := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !exported[id] {
+			pending = append(pending, id)
+			exported[id] = true
+		}
+	}
+
+	archive, err := openGmailArchive(format, outPath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	written, skipped := 0, 0
+	for start := 0; start < len(pending); start += gmailExportBatchSize {
+		end := min(start+gmailExportBatchSize, len(pending))
+		msgs, fetchErr := fetchGmailRawMessages(ctx, svc, pending[start:end], c.Concurrency)
+		if fetchErr != nil {
+			return fetchErr
+		}
+		batch := make([]string, 0, len(msgs))
+		for _, msg := range msgs {
+			if msg == nil || (!c.IncludeSpamTrash && hasGmailLabel(msg.LabelIds, "SPAM", "TRASH")) {
+				skipped++
+				continue
+			}
+			raw, decodeErr := decodeBase64URLBytes(msg.Raw)
+			if decodeErr != nil {
+				return fmt.Errorf("decode message %s: %w", msg.Id, decodeErr)
+			}
+			if writeErr := archive.Write(msg, raw, gmailLabelNames(msg.LabelIds, idToName)); writeErr != nil {
+				return writeErr
+			}
+			batch = append(batch, msg.Id)
+			state.LastInternalDate = max(state.LastInternalDate, msg.InternalDate)
+			written++
+		}
+		if err := archive.Flush(); err != nil {
+			return err
+		}
+		if err := appendGmailExportIDs(idsFile, batch); err != nil {
+			return err
+		}
+		if err := state.save(statePath); err != nil {
+			return err
+		}
+		u.Err().Printf("[%d/%d] exported", end, len(pending))
+	}
+
+	state.HistoryID = formatHistoryID(profile.HistoryId)
+	if err := state.save(statePath); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"path":        outPath,
+			"format":      format,
+			"exported":    written,
+			"skipped":     skipped,
+			"incremental": incremental,
+			"historyId":   state.HistoryID,
+		})
+	}
+	u.Out().Printf("path\t%s", outPath)
+	u.Out().Printf("format\t%s", format)
+	u.Out().Printf("exported\t%d", written)
+	u.Out().Printf("incremental\t%t", incremental)
+	u.Out().Printf("history_id\t%s", state.HistoryID)
+	return nil
+}
+
+// candidates returns the message IDs to consider for this run, oldest first,
+// and whether they were narrowed down from a previous checkpoint. Without a
+// query the history API lists new mail; otherwise (or once the historyId has
+// expired) only messages received after the last exported one are listed.
+func (c *GmailExportCmd) candidates(ctx context.Context, u *ui.UI, svc *gmail.Service, state *gmailExportState, query string) ([]string, bool, error) {
+	if !c.Full && query == "" && state.HistoryID != "" {
+		ids, err := historyAddedMessageIDs(ctx, svc, state.HistoryID)
+		var apiErr *gapi.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusNotFound {
+			return ids, true, err
+		}
+		u.Err().Println("Stored historyId expired; listing messages since the last checkpoint")
+	}
+
+	incremental := !c.Full && state.LastInternalDate > 0
+	if incremental {
+		// after: has second precision; the overlap is dropped as already exported.
+		after := fmt.Sprintf("after:%d", state.LastInternalDate/1000-1)
+		if query != "" {
+			query = "(" + query + ") " + after
+		} else {
+			query = after
+		}
+	}
+	ids, err := listGmailMessageIDs(ctx, svc, query, c.IncludeSpamTrash)
+	if err != nil {
+		return nil, false, err
+	}
+	// The list API returns newest first; archives read best oldest first.
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+	return ids, incremental, nil
+}
+
+func historyAddedMessageIDs(ctx context.Context, svc *gmail.Service, startHistoryID string) ([]string, error) {
+	startID, err := parseHistoryID(startHistoryID)
+	if err != nil {
+		return nil, err
+	}
+	fetch := func(pageToken string) ([]string, string, error) {
+		call := svc.Users.History.List("me").StartHistoryId(startID).MaxResults(500)
+		call.HistoryTypes("messageAdded")
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return collectHistoryMessageIDs(resp), resp.NextPageToken, nil
+	}
+	return collectAllPages("", fetch)
+}
+
+func listGmailMessageIDs(ctx context.Context, svc *gmail.Service, query string, includeSpamTrash bool) ([]string, error) {
+	fetch := func(pageToken string) ([]string, string, error) {
+		call := svc.Users.Messages.List("me").
+			MaxResults(500).
+			IncludeSpamTrash(includeSpamTrash).
+			Fields("nextPageToken", "messages/id").
+			Context(ctx)
+		if query != "" {
+			call = call.Q(query)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		ids := make([]string, 0, len(resp.Messages))
+		for _, m := range resp.Messages {
+			if m != nil && m.Id != "" {
+				ids = append(ids, m.Id)
+			}
+		}
+		return ids, resp.NextPageToken, nil
+	}
+	return collectAllPages("", fetch)
+}
+
+// fetchGmailRawMessages downloads messages in raw format with bounded
+// parallelism, returning them in the order of ids (nil for deleted messages).
+func fetchGmailRawMessages(ctx context.Context, svc *gmail.Service, ids []string, concurrency int) ([]*gmail.Message, error) {
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	sem := make(chan struct{}, concurrency)
+	msgs := make([]*gmail.Message, len(ids))
+	errs := make([]error, len(ids))
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(idx int, id string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+			msg, err := svc.Users.Messages.Get("me", id).Format(gmailFormatRaw).Context(ctx).Do()
+			// Messages deleted since they were listed are simply left out.
+			var apiErr *gapi.Error
+			if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+				return
+			}
+			msgs[idx], errs[idx] = msg, err
+		}(i, id)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("fetch message %s: %w", ids[i], err)
+		}
+	}
+	return msgs, nil
+}
+
+func hasGmailLabel(labelIDs []string, want ...string) bool {
+	for _, id := range labelIDs {
+		for _, w := range want {
+			if id == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func gmailLabelNames(labelIDs []string, idToName map[string]string) []string {
+	names := make([]string, 0, len(labelIDs))
+	for _, id := range labelIDs {
+		if name, ok := idToName[id]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, id)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// gmailArchive appends raw RFC 822 messages to an mbox file or Maildir.
+type gmailArchive interface {
+	Write(msg *gmail.Message, raw []byte, labels []string) error
+	Flush() error
+	Close() error
+}
+
+func openGmailArchive(format, outPath string) (gmailArchive, error) {
+	if format == gmailExportFormatMaildir {
+		return openGmailMaildir(outPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(outPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, err
+	}
+	return &gmailMbox{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// gmailExportMessage prepends Gmail metadata headers and normalizes line endings to LF.
+func gmailExportMessage(msg *gmail.Message, raw []byte, labels []string) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(raw) + 128)
+	if msg.ThreadId != "" {
+		fmt.Fprintf(&buf, "X-GM-THRID: %s\n", msg.ThreadId)
+	}
+	fmt.Fprintf(&buf, "X-Gmail-Message-Id: %s\n", msg.Id)
+	if len(labels) > 0 {
+		fmt.Fprintf(&buf, "X-Gmail-Labels: %s\n", strings.Join(labels, ","))
+	}
+	buf.Write(bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n")))
+	return buf.Bytes()
+}
+
+func gmailInternalTime(msg *gmail.Message) time.Time {
+	if msg.InternalDate > 0 {
+		return time.UnixMilli(msg.InternalDate).UTC()
+	}
+	return time.Now().UTC()
+}
+
+type gmailMbox struct {
+	f *os.File
+	w *bufio.Writer
+}
+
+var mboxFromLine = regexp.MustCompile(`^>*From `)
+
+// Write appends msg using mboxrd quoting so the file can be split losslessly.
+func (m *gmailMbox) Write(msg *gmail.Message, raw []byte, labels []string) error {
+	if _, err := fmt.Fprintf(m.w, "From MAILER-DAEMON %s\n", gmailInternalTime(msg).Format(time.ANSIC)); err != nil {
+		return err
+	}
+	body := gmailExportMessage(msg, raw, labels)
+	body = bytes.TrimRight(body, "\n")
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		if mboxFromLine.Match(line) {
+			if err := m.w.WriteByte('>'); err != nil {
+				return err
+			}
+		}
+		if _, err := m.w.Write(line); err != nil {
+			return err
+		}
+		if err := m.w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return m.w.WriteByte('\n')
+}
+
+func (m *gmailMbox) Flush() error {
+	if err := m.w.Flush(); err != nil {
+		return err
+	}
+	return m.f.Sync()
+}
+
+func (m *gmailMbox) Close() error {
+	flushErr := m.w.Flush()
+	closeErr := m.f.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
+
+// gmailMaildir stores every message in the root Maildir and links it into a
+// Maildir++ subfolder (".Label.Sub") for each of its labels.
+type gmailMaildir struct {
+	root string
+}
+
+func openGmailMaildir(root string) (*gmailMaildir, error) {
+	if err := ensureMaildir(root); err != nil {
+		return nil, err
+	}
+	return &gmailMaildir{root: root}, nil
+}
+
+func ensureMaildir(dir string) error {
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *gmailMaildir) Write(msg *gmail.Message, raw []byte, labels []string) error {
+	flags := ""
+	if hasGmailLabel(msg.LabelIds, "STARRED") {
+		flags += "F"
+	}
+	if !hasGmailLabel(msg.LabelIds, "UNREAD") {
+		flags += "S"
+	}
+	name := fmt.Sprintf("%d.%s.gog:2,%s", gmailInternalTime(msg).Unix(), msg.Id, flags)
+
+	tmp := filepath.Join(m.root, "tmp", name)
+	if err := os.WriteFile(tmp, gmailExportMessage(msg, raw, labels), 0o600); err != nil {
+		return err
+	}
+	dest := filepath.Join(m.root, "cur", name)
+	if err := os.Rename(tmp, dest); err != nil {
+		return err
+	}
+
+	for _, label := range labels {
+		// Read/starred state is carried by the filename flags; categories are noise.
+		if label == "UNREAD" || label == "STARRED" || strings.HasPrefix(label, "CATEGORY_") {
+			continue
+		}
+		folder := filepath.Join(m.root, gmailMaildirFolderName(label))
+		if err := ensureMaildir(folder); err != nil {
+			return err
+		}
+		if err := linkOrCopyFile(dest, filepath.Join(folder, "cur", name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *gmailMaildir) Flush() error { return nil }
+func (m *gmailMaildir) Close() error { return nil }
+
+// gmailMaildirFolderName maps a label like "Work/Projects" to ".Work.Projects".
+func gmailMaildirFolderName(label string) string {
+	parts := strings.Split(label, "/")
+	for i, p := range parts {
+		p = strings.ReplaceAll(p, ".", "_")
+		parts[i] = strings.Map(func(r rune) rune {
+			if r == os.PathSeparator || r == 0 {
+				return '_'
+			}
+			return r
+		}, p)
+	}
+	return "." + strings.Join(parts, ".")
+}
+
+func linkOrCopyFile(src, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src) //nolint:gosec // file we just wrote
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600) //nolint:gosec // inside the export dir
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type gmailExportTestServer struct {
+	mu       sync.Mutex
+	round    int
+	rawGets  []string
+	queries  []string
+	messages map[string]map[string]any
+}
+
+func newGmailExportTestServer(t *testing.T) *gmailExportTestServer {
+	t.Helper()
+
+	raw := func(s string) string { return base64.URLEncoding.EncodeToString([]byte(s)) }
+	s := &gmailExportTestServer{messages: map[string]map[string]any{
+		"m1": {"id": "m1", "threadId": "t1", "internalDate": "1700000000000", "labelIds": []string{"INBOX", "UNREAD"},
+			"raw": raw("From: a@b.com\r\nSubject: one\r\n\r\nFrom the start\r\nbody\r\n")},
+		"m2": {"id": "m2", "threadId": "t2", "internalDate": "1700000100000", "labelIds": []string{"Label_1", "STARRED"},
+			"raw": raw("From: c@d.com\r\nSubject: two\r\n\r\nsecond\r\n")},
+		"m3": {"id": "m3", "threadId": "t3", "internalDate": "1700000200000", "labelIds": []string{"INBOX"},
+			"raw": raw("From: e@f.com\r\nSubject: three\r\n\r\nthird\r\n")},
+	}}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/labels":
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{
+				{"id": "INBOX", "name": "INBOX"},
+				{"id": "Label_1", "name": "Work/Projects"},
+			}})
+		case path == "/profile":
+			s.round++
+			_ = json.NewEncoder(w).Encode(map[string]any{"historyId": fmt.Sprint(500 + s.round*100)})
+		case path == "/messages":
+			q := r.URL.Query().Get("q")
+			s.queries = append(s.queries, q)
+			ids := []map[string]any{{"id": "m2"}, {"id": "m1"}}
+			if strings.Contains(q, "after:") {
+				ids = []map[string]any{{"id": "m3"}, {"id": "m2"}}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"messages": ids})
+		case path == "/history":
+			if got := r.URL.Query().Get("startHistoryId"); got != "600" {
+				t.Errorf("unexpected startHistoryId %q", got)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "700",
+				"history": []map[string]any{
+					{"id": "650", "messagesAdded": []map[string]any{{"message": map[string]any{"id": "m3"}}, {"message": map[string]any{"id": "other"}}}},
+				},
+			})
+		case strings.HasPrefix(path, "/messages/"):
+			id := strings.TrimPrefix(path, "/messages/")
+			msg, ok := s.messages[id]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			s.rawGets = append(s.rawGets, id)
+			_ = json.NewEncoder(w).Encode(msg)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	stubGmailService(t, srv)
+	return s
+}
+
+func runGmailExport(t *testing.T, args ...string) string {
+	t.Helper()
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute(append([]string{"--json", "--account", "a@b.com", "gmail", "export"}, args...)); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+		})
+	})
+	return out
+}
+
+func TestGmailExport_MboxIncremental(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	srv := newGmailExportTestServer(t)
+
+	mbox := filepath.Join(t.TempDir(), "mail.mbox")
+	out := runGmailExport(t, "newer_than:1y", "--out", mbox)
+	var got struct {
+		Exported    int    `json:"exported"`
+		Incremental bool   `json:"incremental"`
+		HistoryID   string `json:"historyId"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if got.Exported != 2 || got.Incremental || got.HistoryID != "600" {
+		t.Fatalf("unexpected first run: %+v", got)
+	}
+
+	data, err := os.ReadFile(mbox)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	text := string(data)
+	if strings.Count(text, "\nFrom MAILER-DAEMON ") != 1 || !strings.HasPrefix(text, "From MAILER-DAEMON ") {
+		t.Fatalf("expected two mbox entries:\n%s", text)
+	}
+	if strings.Index(text, "Subject: one") > strings.Index(text, "Subject: two") {
+		t.Fatalf("expected oldest message first:\n%s", text)
+	}
+	for _, want := range []string{"\n>From the start\n", "X-Gmail-Labels: INBOX,UNREAD\n", "X-Gmail-Labels: STARRED,Work/Projects\n", "X-GM-THRID: t1\n"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing %q in mbox:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "\r") {
+		t.Fatalf("expected LF line endings")
+	}
+
+	srv.rawGets = nil
+	out = runGmailExport(t, "newer_than:1y", "--out", mbox)
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if got.Exported != 1 || !got.Incremental || got.HistoryID != "700" {
+		t.Fatalf("unexpected incremental run: %+v", got)
+	}
+	// The second run only lists mail received after the last exported message.
+	if len(srv.queries) != 2 || srv.queries[0] != "newer_than:1y" || srv.queries[1] != "(newer_than:1y) after:1700000099" {
+		t.Fatalf("unexpected list queries: %v", srv.queries)
+	}
+	if len(srv.rawGets) != 1 || srv.rawGets[0] != "m3" {
+		t.Fatalf("expected only m3 to be fetched, got %v", srv.rawGets)
+	}
+	data, _ = os.ReadFile(mbox)
+	if !strings.Contains(string(data), "Subject: three") {
+		t.Fatalf("expected m3 appended")
+	}
+
+	// Exported IDs are appended to a sidecar file instead of the state JSON.
+	idFiles, _ := filepath.Glob(filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "*", "state", "gmail-export", "*.ids"))
+	if len(idFiles) != 1 {
+		t.Fatalf("expected one ids file, got %v", idFiles)
+	}
+	ids, _ := os.ReadFile(idFiles[0])
+	if string(ids) != "m1\nm2\nm3\n" {
+		t.Fatalf("unexpected ids file: %q", ids)
+	}
+	state, _ := os.ReadFile(strings.TrimSuffix(idFiles[0], ".ids") + ".json")
+	if !strings.Contains(string(state), `"lastInternalDate": 1700000200000`) {
+		t.Fatalf("unexpected state: %s", state)
+	}
+}
+
+func TestGmailExport_HistoryWithoutQuery(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	srv := newGmailExportTestServer(t)
+
+	mbox := filepath.Join(t.TempDir(), "all.mbox")
+	_ = runGmailExport(t, "--out", mbox)
+	srv.rawGets = nil
+	_ = runGmailExport(t, "--out", mbox)
+
+	if len(srv.queries) != 1 || srv.queries[0] != "" {
+		t.Fatalf("expected a single full listing, got %v", srv.queries)
+	}
+	if len(srv.rawGets) != 1 || srv.rawGets[0] != "m3" {
+		t.Fatalf("expected only m3 from history, got %v", srv.rawGets)
+	}
+}
+
+func TestGmailExport_Maildir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	newGmailExportTestServer(t)
+
+	dir := filepath.Join(t.TempDir(), "Mail")
+	_ = runGmailExport(t, "newer_than:1y", "--out", dir, "--format", "maildir")
+
+	all, err := os.ReadDir(filepath.Join(dir, "cur"))
+	if err != nil || len(all) != 2 {
+		t.Fatalf("expected 2 messages in root maildir, got %v err=%v", all, err)
+	}
+	names := []string{all[0].Name(), all[1].Name()}
+	if !strings.HasSuffix(names[0], ".m1.gog:2,") || !strings.HasSuffix(names[1], ".m2.gog:2,FS") {
+		t.Fatalf("unexpected maildir names: %v", names)
+	}
+	work, err := os.ReadDir(filepath.Join(dir, ".Work.Projects", "cur"))
+	if err != nil || len(work) != 1 || work[0].Name() != names[1] {
+		t.Fatalf("expected m2 in .Work.Projects, got %v err=%v", work, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".INBOX", "cur", names[0])); err != nil {
+		t.Fatalf("expected m1 in .INBOX: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".UNREAD")); !os.IsNotExist(err) {
+		t.Fatalf("UNREAD must be a flag, not a folder")
+	}
+}
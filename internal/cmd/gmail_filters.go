@@ -19,6 +19,9 @@ type GmailFiltersCmd struct {
 	Get    GmailFiltersGetCmd    `cmd:"" name:"get" aliases:"info,show" help:"Get a specific filter"`
 	Create GmailFiltersCreateCmd `cmd:"" name:"create" aliases:"add,new" help:"Create a new email filter"`
 	Delete GmailFiltersDeleteCmd `cmd:"" name:"delete" aliases:"rm,del,remove" help:"Delete a filter"`
+	Export GmailFiltersExportCmd `cmd:"" name:"export" help:"Export all filters as YAML, JSON, or Gmail XML"`
+	Diff   GmailFiltersDiffCmd   `cmd:"" name:"diff" aliases:"plan" help:"Show what apply would change"`
+	Apply  GmailFiltersApplyCmd  `cmd:"" name:"apply" aliases:"sync" help:"Create and delete filters to match a file"`
 }
 
 type GmailFiltersListCmd struct{}
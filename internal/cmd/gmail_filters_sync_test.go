@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+type gmailFiltersTestServer struct {
+	mu         sync.Mutex
+	failCreate bool
+	writes     []string
+	created    []gmail.Filter
+}
+
+func newGmailFiltersTestServer(t *testing.T) *gmailFiltersTestServer {
+	t.Helper()
+
+	s := &gmailFiltersTestServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/labels" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{
+				{"id": "INBOX", "name": "INBOX"},
+				{"id": "UNREAD", "name": "UNREAD"},
+				{"id": "Label_1", "name": "Work"},
+			}})
+		case path == "/labels" && r.Method == http.MethodPost:
+			var label gmail.Label
+			_ = json.NewDecoder(r.Body).Decode(&label)
+			s.writes = append(s.writes, "create label "+label.Name)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "Label_2", "name": label.Name})
+		case path == "/settings/filters" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"filter": []map[string]any{
+				{"id": "f-keep", "criteria": map[string]any{"from": "boss@x.com"}, "action": map[string]any{"addLabelIds": []string{"Label_1"}, "removeLabelIds": []string{"INBOX"}}},
+				{"id": "f-old", "criteria": map[string]any{"subject": "lottery"}, "action": map[string]any{"addLabelIds": []string{"TRASH"}}},
+			}})
+		case path == "/settings/filters" && r.Method == http.MethodPost:
+			if s.failCreate {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 400, "message": "invalid filter"}})
+				return
+			}
+			var f gmail.Filter
+			_ = json.NewDecoder(r.Body).Decode(&f)
+			s.created = append(s.created, f)
+			s.writes = append(s.writes, "create filter")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "f-new"})
+		case strings.HasPrefix(path, "/settings/filters/") && r.Method == http.MethodDelete:
+			s.writes = append(s.writes, "delete "+strings.TrimPrefix(path, "/settings/filters/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	stubGmailService(t, srv)
+	return s
+}
+
+const gmailFiltersTestYAML = `filters:
+  - criteria:
+      from: boss@x.com
+    action:
+      addLabels: [work]
+      removeLabels: [INBOX]
+  - criteria:
+      query: list:news.example.com
+    action:
+      addLabels: [Newsletters]
+      removeLabels: [UNREAD]
+`
+
+func TestGmailFiltersExport_YAMLResolvesLabelNames(t *testing.T) {
+	newGmailFiltersTestServer(t)
+
+	out := captureStdout(t, func() {
+		if err := Execute([]string{"--account", "a@b.com", "gmail", "filters", "export"}); err != nil {
+			t.Fatalf("export: %v", err)
+		}
+	})
+	specs, err := decodeGmailFilters([]byte(out), gmailFiltersFormatYAML)
+	if err != nil {
+		t.Fatalf("decode: %v (out=%q)", err, out)
+	}
+	if len(specs) != 2 || specs[0].Criteria.From != "boss@x.com" || !reflect.DeepEqual(specs[0].Action.AddLabels, []string{"Work"}) {
+		t.Fatalf("unexpected export: %+v", specs)
+	}
+}
+
+func TestGmailFiltersApply_Converges(t *testing.T) {
+	srv := newGmailFiltersTestServer(t)
+	file := filepath.Join(t.TempDir(), "filters.yaml")
+	if err := os.WriteFile(file, []byte(gmailFiltersTestYAML), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "gmail", "filters", "diff", file}); err != nil {
+				t.Fatalf("diff: %v", err)
+			}
+		})
+	})
+	if len(srv.writes) != 0 {
+		t.Fatalf("diff must not write: %v", srv.writes)
+	}
+	var plan struct {
+		Summary        map[string]int `json:"summary"`
+		LabelsToCreate []string       `json:"labelsToCreate"`
+	}
+	if err := json.Unmarshal([]byte(out), &plan); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if plan.Summary["keep"] != 1 || plan.Summary["create"] != 1 || plan.Summary["delete"] != 1 || strings.Join(plan.LabelsToCreate, ",") != "Newsletters" {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--force", "--account", "a@b.com", "gmail", "filters", "apply", file}); err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+		})
+	})
+	if strings.Join(srv.writes, ",") != "create label Newsletters,create filter,delete f-old" {
+		t.Fatalf("unexpected writes: %v", srv.writes)
+	}
+	got := srv.created[0]
+	if got.Criteria.Query != "list:news.example.com" || strings.Join(got.Action.AddLabelIds, ",") != "Label_2" || strings.Join(got.Action.RemoveLabelIds, ",") != "UNREAD" {
+		t.Fatalf("unexpected created filter: %+v %+v", got.Criteria, got.Action)
+	}
+}
+
+func TestGmailFiltersApply_CreateFailureSkipsDeletes(t *testing.T) {
+	srv := newGmailFiltersTestServer(t)
+	srv.failCreate = true
+	file := filepath.Join(t.TempDir(), "filters.yaml")
+	if err := os.WriteFile(file, []byte(gmailFiltersTestYAML), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	var err error
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			err = Execute([]string{"--json", "--force", "--account", "a@b.com", "gmail", "filters", "apply", file})
+		})
+	})
+	if err == nil {
+		t.Fatalf("expected apply to fail")
+	}
+	for _, w := range srv.writes {
+		if strings.HasPrefix(w, "delete ") {
+			t.Fatalf("no filter may be deleted after a failed create: %v", srv.writes)
+		}
+	}
+}
+
+func TestGmailFiltersApply_ShowsPlanBeforeConfirm(t *testing.T) {
+	srv := newGmailFiltersTestServer(t)
+	file := filepath.Join(t.TempDir(), "filters.yaml")
+	if err := os.WriteFile(file, []byte(gmailFiltersTestYAML), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	var out, errOut string
+	var err error
+	errOut = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			err = Execute([]string{"--account", "a@b.com", "gmail", "filters", "apply", file})
+		})
+	})
+	if err == nil || !strings.Contains(err.Error(), "without --force") {
+		t.Fatalf("expected non-interactive apply to be refused, got %v", err)
+	}
+	if len(srv.writes) != 0 {
+		t.Fatalf("refused apply must not write: %v", srv.writes)
+	}
+	if !strings.Contains(errOut, "Plan: 1 to create, 1 to delete, 1 unchanged") || !strings.Contains(out, "f-old") {
+		t.Fatalf("expected the plan before the confirmation, got stdout=%q stderr=%q", out, errOut)
+	}
+}
+
+func TestGmailFiltersXML_RoundTrip(t *testing.T) {
+	specs := []gmailFilterSpec{
+		{
+			Criteria: gmailFilterCriteriaSpec{From: "a@x.com", NegatedQuery: "unsubscribe", HasAttachment: true, Size: 2048, SizeComparison: "smaller"},
+			Action:   gmailFilterActionSpec{AddLabels: []string{"Receipts", "STARRED", "CATEGORY_UPDATES"}, RemoveLabels: []string{"INBOX", "UNREAD"}},
+		},
+		{
+			Criteria: gmailFilterCriteriaSpec{Subject: "alert"},
+			Action:   gmailFilterActionSpec{Forward: "ops@x.com", RemoveLabels: []string{"SPAM"}},
+		},
+	}
+	data, warnings, err := encodeGmailFiltersXML(specs, "a@b.com", time.Unix(1700000000, 0).UTC())
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("encode: %v %v", err, warnings)
+	}
+	text := string(data)
+	for _, want := range []string{`xmlns:apps="http://schemas.google.com/apps/2006"`, `<apps:property name="doesNotHaveTheWord" value="unsubscribe">`, `<apps:property name="shouldArchive" value="true">`, `value="^smartlabel_notification"`} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("missing %q in:\n%s", want, text)
+		}
+	}
+
+	got, err := decodeGmailFiltersXML(data)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 2 || got[0].key() != specs[0].key() || got[1].key() != specs[1].key() {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, specs)
+	}
+
+	// Gmail's own export uses KB/MB units.
+	web := `<?xml version='1.0' encoding='UTF-8'?><feed xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+<entry><category term='filter'></category><apps:property name='size' value='5'/><apps:property name='sizeOperator' value='s_sl'/><apps:property name='sizeUnit' value='s_smb'/><apps:property name='label' value='Big'/></entry></feed>`
+	got, err = decodeGmailFiltersXML([]byte(web))
+	if err != nil || len(got) != 1 || got[0].Criteria.Size != 5<<20 || got[0].Criteria.SizeComparison != "larger" || got[0].Action.AddLabels[0] != "Big" {
+		t.Fatalf("unexpected web decode: %+v err=%v", got, err)
+	}
+}
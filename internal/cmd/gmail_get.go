@@ -12,9 +12,10 @@ import (
 )
 
 type GmailGetCmd struct {
-	MessageID string `arg:"" name:"messageId" help:"Message ID"`
-	Format    string `name:"format" help:"Message format: full|metadata|raw" default:"full"`
-	Headers   string `name:"headers" help:"Metadata headers (comma-separated; only for --format=metadata)"`
+	MessageID  string `arg:"" name:"messageId" help:"Message ID"`
+	Format     string `name:"format" help:"Message format: full|metadata|raw" default:"full"`
+	Headers    string `name:"headers" help:"Metadata headers (comma-separated; only for --format=metadata)"`
+	BodyFormat string `name:"body-format" help:"Body rendering: plain|markdown|html" default:"plain" enum:"plain,markdown,html"`
 }
 
 const (
@@ -87,7 +88,7 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 			payload["unsubscribe"] = unsubscribe
 		}
 		if format == gmailFormatFull {
-			if body := bestBodyText(msg.Payload); body != "" {
+			if body := renderMessageBody(msg.Payload, c.BodyFormat); body != "" {
 				payload["body"] = body
 			}
 		}
@@ -131,7 +132,7 @@ func (c *GmailGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 			printAttachmentLines(u.Out(), attachments)
 		}
 		if format == gmailFormatFull {
-			body := bestBodyText(msg.Payload)
+			body := renderMessageBody(msg.Payload, c.BodyFormat)
 			if body != "" {
 				u.Out().Println("")
 				u.Out().Println(body)
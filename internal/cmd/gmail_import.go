@@ -0,0 +1,759 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailImportFormatAuto = "auto"
+	gmailImportFormatEML  = "eml"
+
+	// gmailImportProgressEvery controls how often progress is reported.
+	gmailImportProgressEvery = 20
+)
+
+type GmailImportCmd struct {
+	Path     string   `arg:"" name:"path" help:"An .eml file, a directory of .eml files, an mbox file, or a Maildir"`
+	Format   string   `name:"format" help:"Source format: auto|eml|mbox|maildir" default:"auto" enum:"auto,eml,mbox,maildir"`
+	Label    []string `name:"label" help:"Label to add to every imported message (name or ID; can be repeated)"`
+	MapLabel []string `name:"map-label" help:"Rename a source label before import: FROM=TO (empty TO drops it; can be repeated)"`
+	Insert   bool     `name:"insert" help:"Use messages.insert (skips spam and inbox classification) instead of messages.import"`
+	Restart  bool     `name:"restart" help:"Ignore the progress journal and import every message again"`
+}
+
+// gmailImportJournal records which source messages already reached Gmail so an
+// interrupted import can be resumed without creating duplicates. Each import
+// is appended as a "key<TAB>messageId" line as soon as Gmail accepts it.
+type gmailImportJournal struct {
+	Imported map[string]string
+
+	f *os.File
+}
+
+func gmailImportJournalPath(account, source string) (string, error) {
+	dir, err := config.EnsureGmailImportDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(account) + "\x00" + source))
+	return filepath.Join(dir, sanitizeAccountForPath(account)+"_"+hex.EncodeToString(sum[:8])+".journal"), nil
+}
+
+// openGmailImportJournal loads the journal at path and opens it for
+// appending. restart discards previous progress.
+func openGmailImportJournal(path string, restart bool) (*gmailImportJournal, error) {
+	if restart {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	j := &gmailImportJournal{Imported: map[string]string{}}
+	data, err := os.ReadFile(path) //nolint:gosec // path inside config dir
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		// A torn last line (crash mid-write) has no tab and is ignored.
+		if key, id, ok := strings.Cut(strings.TrimSpace(line), "\t"); ok && key != "" {
+			j.Imported[key] = id
+		}
+	}
+
+	j.f, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // path inside config dir
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if _, err := j.f.WriteString("\n"); err != nil {
+			_ = j.f.Close()
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// add records one imported message.
+func (j *gmailImportJournal) add(key, messageID string) error {
+	if _, err := fmt.Fprintf(j.f, "%s\t%s\n", key, messageID); err != nil {
+		return err
+	}
+	j.Imported[key] = messageID
+	return nil
+}
+
+func (j *gmailImportJournal) Close() error {
+	syncErr := j.f.Sync()
+	closeErr := j.f.Close()
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
+
+// gmailImportItem is one message read from the source, with Gmail metadata
+// headers stripped and line endings normalized to CRLF.
+type gmailImportItem struct {
+	Key     string   `json:"key"`
+	Source  string   `json:"source"`
+	Subject string   `json:"subject,omitempty"`
+	Date    string   `json:"date,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+	Unread  bool     `json:"unread"`
+	Skip    bool     `json:"skip,omitempty"`
+
+	raw []byte
+}
+
+func (c *GmailImportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	source, err := config.ExpandPath(strings.TrimSpace(c.Path))
+	if err != nil {
+		return err
+	}
+	if source == "" {
+		return usage("empty path")
+	}
+	source, err = filepath.Abs(source)
+	if err != nil {
+		return err
+	}
+	format, err := detectGmailImportFormat(source, strings.ToLower(strings.TrimSpace(c.Format)))
+	if err != nil {
+		return err
+	}
+	renames, err := parseGmailLabelMap(c.MapLabel)
+	if err != nil {
+		return err
+	}
+	dryRun := flags != nil && flags.DryRun
+
+	journalPath, err := gmailImportJournalPath(account, source)
+	if err != nil {
+		return err
+	}
+	journal, err := openGmailImportJournal(journalPath, c.Restart && !dryRun)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+	if c.Restart {
+		// A dry run must not discard progress, but it plans as if it had.
+		journal.Imported = map[string]string{}
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	resolver, err := newGmailImportLabelResolver(svc, dryRun)
+	if err != nil {
+		return err
+	}
+
+	var planned []gmailImportItem
+	imported, skipped := 0, 0
+	scanErr := scanGmailImportSource(source, format, func(item *gmailImportItem) error {
+		item.Labels = applyGmailLabelMap(append(item.Labels, c.Label...), renames)
+		_, item.Skip = journal.Imported[item.Key]
+		if item.Skip {
+			skipped++
+		}
+		if dryRun {
+			if !item.Skip {
+				if _, err := resolver.resolve(ctx, item.Labels, item.Unread); err != nil {
+					return err
+				}
+			}
+			item.raw = nil
+			planned = append(planned, *item)
+			return nil
+		}
+		if item.Skip {
+			return nil
+		}
+
+		labelIDs, err := resolver.resolve(ctx, item.Labels, item.Unread)
+		if err != nil {
+			return err
+		}
+		msg, err := c.importMessage(ctx, svc, item.raw, labelIDs)
+		if err != nil {
+			return fmt.Errorf("import %s: %w", item.Source, err)
+		}
+		if err := journal.add(item.Key, msg.Id); err != nil {
+			return err
+		}
+		imported++
+		if imported%gmailImportProgressEvery == 0 {
+			u.Err().Printf("imported %d messages", imported)
+		}
+		return nil
+	})
+	if dryRun {
+		if scanErr != nil {
+			return scanErr
+		}
+		return writeGmailImportPlan(ctx, u, source, format, planned, resolver.missing())
+	}
+	if scanErr != nil {
+		if imported > 0 {
+			u.Err().Printf("Imported %d messages before the error; re-run to resume", imported)
+		}
+		return scanErr
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"source":        source,
+			"format":        format,
+			"imported":      imported,
+			"skipped":       skipped,
+			"labelsCreated": resolver.created,
+		})
+	}
+	u.Out().Printf("source\t%s", source)
+	u.Out().Printf("format\t%s", format)
+	u.Out().Printf("imported\t%d", imported)
+	u.Out().Printf("skipped\t%d", skipped)
+	if len(resolver.created) > 0 {
+		u.Out().Printf("labels_created\t%s", strings.Join(resolver.created, ", "))
+	}
+	return nil
+}
+
+func (c *GmailImportCmd) importMessage(ctx context.Context, svc *gmail.Service, raw []byte, labelIDs []string) (*gmail.Message, error) {
+	msg := &gmail.Message{
+		Raw:      base64.RawURLEncoding.EncodeToString(raw),
+		LabelIds: labelIDs,
+	}
+	// dateHeader keeps the original Date: as the Gmail internal date.
+	if c.Insert {
+		return svc.Users.Messages.Insert("me", msg).InternalDateSource("dateHeader").Context(ctx).Do()
+	}
+	return svc.Users.Messages.Import("me", msg).InternalDateSource("dateHeader").NeverMarkSpam(true).Context(ctx).Do()
+}
+
+func writeGmailImportPlan(ctx context.Context, u *ui.UI, source, format string, items []gmailImportItem, labelsToCreate []string) error {
+	pending := 0
+	for _, item := range items {
+		if !item.Skip {
+			pending++
+		}
+	}
+	if outfmt.IsJSON(ctx) {
+		if items == nil {
+			items = []gmailImportItem{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"dry_run":        true,
+			"op":             "gmail.import",
+			"source":         source,
+			"format":         format,
+			"messages":       items,
+			"labelsToCreate": labelsToCreate,
+			"summary":        map[string]int{"messages": len(items), "pending": pending, "skipped": len(items) - pending},
+		})
+	}
+
+	u.Err().Printf("Dry run: would import %d of %d messages from %s (%s)", pending, len(items), source, format)
+	if len(labelsToCreate) > 0 {
+		u.Err().Printf("Labels to create: %s", strings.Join(labelsToCreate, ", "))
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	fmt.Fprintln(w, "SOURCE\tDATE\tSUBJECT\tLABELS\tSTATE")
+	for _, item := range items {
+		state := "read"
+		if item.Unread {
+			state = "unread"
+		}
+		if item.Skip {
+			state = "already imported"
+		}
+		labels := strings.Join(item.Labels, ",")
+		if labels == "" {
+			labels = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.Source, item.Date, truncateRunes(item.Subject, 60), labels, state)
+	}
+	flush()
+	return nil
+}
+
+func detectGmailImportFormat(path, format string) (string, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if format != "" && format != gmailImportFormatAuto {
+		switch format {
+		case gmailImportFormatEML, gmailExportFormatMbox, gmailExportFormatMaildir:
+		default:
+			return "", usage("invalid --format (expected auto|eml|mbox|maildir)")
+		}
+		if format == gmailExportFormatMaildir && !st.IsDir() {
+			return "", usagef("%s is not a Maildir directory", path)
+		}
+		if format == gmailExportFormatMbox && st.IsDir() {
+			return "", usagef("%s is a directory, not an mbox file", path)
+		}
+		return format, nil
+	}
+
+	if st.IsDir() {
+		for _, sub := range []string{"cur", "new"} {
+			if info, err := os.Stat(filepath.Join(path, sub)); err == nil && info.IsDir() {
+				return gmailExportFormatMaildir, nil
+			}
+		}
+		return gmailImportFormatEML, nil
+	}
+	if strings.EqualFold(filepath.Ext(path), ".eml") {
+		return gmailImportFormatEML, nil
+	}
+	f, err := os.Open(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, 5)
+	n, _ := io.ReadFull(f, head)
+	if string(head[:n]) == "From " {
+		return gmailExportFormatMbox, nil
+	}
+	return gmailImportFormatEML, nil
+}
+
+func scanGmailImportSource(path, format string, fn func(*gmailImportItem) error) error {
+	switch format {
+	case gmailExportFormatMbox:
+		return scanGmailImportMbox(path, fn)
+	case gmailExportFormatMaildir:
+		return scanGmailImportMaildir(path, fn)
+	default:
+		return scanGmailImportEML(path, fn)
+	}
+}
+
+func scanGmailImportEML(path string, fn func(*gmailImportItem) error) error {
+	st, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !st.IsDir() {
+		return emitGmailImportFile(path, filepath.Base(path), fn)
+	}
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(p), ".eml") {
+			return nil
+		}
+		rel, relErr := filepath.Rel(path, p)
+		if relErr != nil {
+			rel = p
+		}
+		return emitGmailImportFile(p, filepath.ToSlash(rel), fn)
+	})
+}
+
+func emitGmailImportFile(path, name string, fn func(*gmailImportItem) error) error {
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return err
+	}
+	item := parseGmailImportMessage(data)
+	item.Source = name
+	return fn(item)
+}
+
+// scanGmailImportMbox streams an mbox file, undoing mboxrd ">From " quoting.
+func scanGmailImportMbox(path string, fn func(*gmailImportItem) error) error {
+	f, err := os.Open(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	base := filepath.Base(path)
+	r := bufio.NewReader(f)
+	var buf bytes.Buffer
+	count := 0
+	started := false
+	emit := func() error {
+		if !started {
+			return nil
+		}
+		count++
+		item := parseGmailImportMessage(buf.Bytes())
+		item.Source = fmt.Sprintf("%s#%d", base, count)
+		buf.Reset()
+		return fn(item)
+	}
+	for {
+		line, readErr := r.ReadBytes('\n')
+		if len(line) > 0 {
+			switch {
+			case bytes.HasPrefix(line, []byte("From ")):
+				if err := emit(); err != nil {
+					return err
+				}
+				started = true
+			case started:
+				if mboxFromLine.Match(line) {
+					line = line[1:]
+				}
+				buf.Write(line)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	return emit()
+}
+
+type gmailMaildirEntry struct {
+	path    string
+	name    string
+	unread  bool
+	starred bool
+	folders []string
+}
+
+// scanGmailImportMaildir reads a Maildir and its Maildir++ subfolders. A message
+// stored in several folders (as written by gmail export) is imported once with
+// every folder as a label.
+func scanGmailImportMaildir(root string, fn func(*gmailImportItem) error) error {
+	folders := []string{""}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() && strings.HasPrefix(e.Name(), ".") && len(e.Name()) > 1 {
+			folders = append(folders, e.Name())
+		}
+	}
+	sort.Strings(folders[1:])
+
+	byKey := map[string]*gmailMaildirEntry{}
+	var order []string
+	for _, folder := range folders {
+		label := gmailMaildirFolderLabel(folder)
+		for _, sub := range []string{"cur", "new"} {
+			dir := filepath.Join(root, folder, sub)
+			files, err := os.ReadDir(dir)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				if file.IsDir() {
+					continue
+				}
+				p := filepath.Join(dir, file.Name())
+				data, err := os.ReadFile(p) //nolint:gosec // inside the Maildir
+				if err != nil {
+					return err
+				}
+				key := parseGmailImportMessage(data).Key
+				entry, ok := byKey[key]
+				if !ok {
+					rel, _ := filepath.Rel(root, p)
+					entry = &gmailMaildirEntry{
+						path:    p,
+						name:    filepath.ToSlash(rel),
+						unread:  sub == "new" || !maildirHasFlag(file.Name(), 'S'),
+						starred: maildirHasFlag(file.Name(), 'F'),
+					}
+					byKey[key] = entry
+					order = append(order, key)
+				}
+				if label != "" {
+					entry.folders = append(entry.folders, label)
+				}
+			}
+		}
+	}
+
+	for _, key := range order {
+		entry := byKey[key]
+		data, err := os.ReadFile(entry.path) //nolint:gosec // inside the Maildir
+		if err != nil {
+			return err
+		}
+		item := parseGmailImportMessage(data)
+		item.Source = entry.name
+		// Maildir flags win over exported labels: the mail client may have
+		// changed them since. X-Gmail-Labels carries exact label names, while
+		// folder names are lossy, so folders are only a fallback.
+		item.Unread = entry.unread
+		labels := item.Labels
+		if len(labels) == 0 {
+			labels = entry.folders
+		}
+		labels = dedupeGmailLabels(labels, "STARRED")
+		if entry.starred {
+			labels = append(labels, "STARRED")
+		}
+		item.Labels = labels
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gmailMaildirFolderLabel maps ".Work.Projects" back to "Work/Projects".
+func gmailMaildirFolderLabel(folder string) string {
+	folder = strings.TrimPrefix(folder, ".")
+	if folder == "" {
+		return ""
+	}
+	return strings.ReplaceAll(folder, ".", "/")
+}
+
+func maildirHasFlag(name string, flag byte) bool {
+	idx := strings.LastIndex(name, ":2,")
+	if idx < 0 {
+		return false
+	}
+	return strings.IndexByte(name[idx+3:], flag) >= 0
+}
+
+var gmailImportStrippedHeaders = []string{"x-gmail-labels", "x-gm-thrid", "x-gmail-message-id"}
+
+// parseGmailImportMessage strips the metadata headers added by gmail export,
+// picks up labels and read state, and rebuilds the message with CRLF endings.
+func parseGmailImportMessage(data []byte) *gmailImportItem {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	header, body, found := bytes.Cut(data, []byte("\n\n"))
+	if !found {
+		header, body = data, nil
+	}
+
+	item := &gmailImportItem{}
+	status := ""
+	hasStatus := false
+	var out bytes.Buffer
+	out.Grow(len(data) + len(data)/32)
+	for _, field := range splitHeaderFields(header) {
+		name, value, _ := strings.Cut(field, ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(strings.ReplaceAll(value, "\n", ""))
+		switch name {
+		case "x-gmail-labels":
+			for _, l := range strings.Split(value, ",") {
+				if l = strings.TrimSpace(l); l != "" {
+					item.Labels = append(item.Labels, l)
+				}
+			}
+		case "status":
+			status, hasStatus = value, true
+		case "subject":
+			if decoded, err := new(mime.WordDecoder).DecodeHeader(value); err == nil {
+				value = decoded
+			}
+			item.Subject = value
+		case "date":
+			item.Date = value
+		}
+		if containsFold(gmailImportStrippedHeaders, name) {
+			continue
+		}
+		out.WriteString(strings.ReplaceAll(field, "\n", "\r\n"))
+		out.WriteString("\r\n")
+	}
+	out.WriteString("\r\n")
+	// Trailing blank lines differ between mbox and Maildir copies of the same
+	// message; trim them so both produce the same journal key.
+	if body = bytes.TrimRight(body, "\n"); len(body) > 0 {
+		out.Write(bytes.ReplaceAll(body, []byte("\n"), []byte("\r\n")))
+		out.WriteString("\r\n")
+	}
+
+	switch {
+	case containsFold(item.Labels, "UNREAD"):
+		item.Unread = true
+	case hasStatus:
+		item.Unread = !strings.Contains(status, "R")
+	}
+	item.Labels = dedupeGmailLabels(item.Labels, "UNREAD")
+	item.raw = out.Bytes()
+	sum := sha256.Sum256(item.raw)
+	item.Key = hex.EncodeToString(sum[:16])
+	return item
+}
+
+// splitHeaderFields splits an LF-terminated header block into fields, keeping
+// folded continuation lines attached to their field.
+func splitHeaderFields(header []byte) []string {
+	var fields []string
+	for _, line := range strings.Split(string(header), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(fields) > 0 {
+			fields[len(fields)-1] += "\n" + line
+			continue
+		}
+		if line != "" {
+			fields = append(fields, line)
+		}
+	}
+	return fields
+}
+
+func containsFold(list []string, want string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeGmailLabels removes duplicates (case-insensitively) and any excluded names.
+func dedupeGmailLabels(labels []string, exclude ...string) []string {
+	seen := map[string]bool{}
+	for _, e := range exclude {
+		seen[strings.ToLower(e)] = true
+	}
+	out := make([]string, 0, len(labels))
+	for _, l := range labels {
+		key := strings.ToLower(l)
+		if l == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, l)
+	}
+	return out
+}
+
+func parseGmailLabelMap(pairs []string) (map[string]string, error) {
+	out := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		from, to, ok := strings.Cut(pair, "=")
+		from = strings.TrimSpace(from)
+		if !ok || from == "" {
+			return nil, usagef("invalid --map-label %q (expected FROM=TO)", pair)
+		}
+		out[strings.ToLower(from)] = strings.TrimSpace(to)
+	}
+	return out, nil
+}
+
+func applyGmailLabelMap(labels []string, renames map[string]string) []string {
+	out := make([]string, 0, len(labels))
+	for _, l := range labels {
+		l = strings.TrimSpace(l)
+		if to, ok := renames[strings.ToLower(l)]; ok {
+			l = to
+		}
+		out = append(out, l)
+	}
+	return dedupeGmailLabels(out)
+}
+
+// gmailImportSkippedLabels cannot be applied to imported messages.
+var gmailImportSkippedLabels = []string{"DRAFT", "CHAT"}
+
+func isGmailSystemLabel(name string) bool {
+	switch strings.ToUpper(name) {
+	case "INBOX", "SPAM", "TRASH", "UNREAD", "STARRED", "IMPORTANT", "SENT":
+		return true
+	}
+	return strings.HasPrefix(strings.ToUpper(name), "CATEGORY_")
+}
+
+// gmailImportLabelResolver maps label names to IDs, creating missing user
+// labels on first use (or only recording them during a dry run).
+type gmailImportLabelResolver struct {
+	svc     *gmail.Service
+	dryRun  bool
+	ids     map[string]string
+	toMake  map[string]string
+	created []string
+}
+
+func newGmailImportLabelResolver(svc *gmail.Service, dryRun bool) (*gmailImportLabelResolver, error) {
+	ids, err := fetchLabelNameToID(svc)
+	if err != nil {
+		return nil, err
+	}
+	return &gmailImportLabelResolver{svc: svc, dryRun: dryRun, ids: ids, toMake: map[string]string{}}, nil
+}
+
+func (r *gmailImportLabelResolver) resolve(ctx context.Context, names []string, unread bool) ([]string, error) {
+	out := make([]string, 0, len(names)+1)
+	if unread {
+		out = append(out, "UNREAD")
+	}
+	for _, name := range names {
+		if containsFold(gmailImportSkippedLabels, name) {
+			continue
+		}
+		if isGmailSystemLabel(name) {
+			out = append(out, strings.ToUpper(name))
+			continue
+		}
+		key := strings.ToLower(name)
+		if id, ok := r.ids[key]; ok {
+			out = append(out, id)
+			continue
+		}
+		if r.dryRun {
+			r.toMake[key] = name
+			continue
+		}
+		label, err := createLabel(ctx, r.svc, name)
+		if err != nil {
+			return nil, mapLabelCreateError(err, name)
+		}
+		r.ids[key] = label.Id
+		r.created = append(r.created, label.Name)
+		out = append(out, label.Id)
+	}
+	return dedupeGmailLabels(out), nil
+}
+
+func (r *gmailImportLabelResolver) missing() []string {
+	out := make([]string, 0, len(r.toMake))
+	for _, name := range r.toMake {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+type gmailImportTestServer struct {
+	mu      sync.Mutex
+	failOn  string
+	created []string
+	imports []gmail.Message
+	queries []string
+}
+
+func newGmailImportTestServer(t *testing.T) *gmailImportTestServer {
+	t.Helper()
+
+	s := &gmailImportTestServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/labels" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{
+				{"id": "INBOX", "name": "INBOX", "type": "system"},
+				{"id": "Label_1", "name": "Work/Projects", "type": "user"},
+			}})
+		case path == "/labels" && r.Method == http.MethodPost:
+			var label gmail.Label
+			_ = json.NewDecoder(r.Body).Decode(&label)
+			s.created = append(s.created, label.Name)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "Label_new", "name": label.Name})
+		case path == "/messages/import" && r.Method == http.MethodPost:
+			var msg gmail.Message
+			_ = json.NewDecoder(r.Body).Decode(&msg)
+			raw, _ := base64.RawURLEncoding.DecodeString(msg.Raw)
+			if s.failOn != "" && strings.Contains(string(raw), s.failOn) {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 400, "message": "bad message"}})
+				return
+			}
+			msg.Raw = string(raw)
+			s.imports = append(s.imports, msg)
+			s.queries = append(s.queries, r.URL.RawQuery)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": fmt.Sprintf("g%d", len(s.imports))})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	stubGmailService(t, srv)
+	return s
+}
+
+func setupGmailImportTest(t *testing.T) *gmailImportTestServer {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	return newGmailImportTestServer(t)
+}
+
+func runGmailImport(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out string
+	var err error
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			err = Execute(append([]string{"--json", "--account", "a@b.com", "gmail", "import"}, args...))
+		})
+	})
+	return out, err
+}
+
+const gmailImportTestMbox = "From MAILER-DAEMON Tue Nov 14 22:13:20 2023\n" +
+	"X-GM-THRID: t1\n" +
+	"X-Gmail-Message-Id: m1\n" +
+	"X-Gmail-Labels: INBOX,UNREAD,Work/Projects\n" +
+	"From: a@b.com\n" +
+	"Subject: one\n" +
+	"Date: Tue, 14 Nov 2023 22:13:20 +0000\n" +
+	"\n" +
+	">From the start\n" +
+	">>From quoted\n" +
+	"\n" +
+	"From MAILER-DAEMON Tue Nov 14 22:15:00 2023\n" +
+	"From: c@d.com\n" +
+	"Subject: two\n" +
+	"Status: RO\n" +
+	"\n" +
+	"second\n" +
+	"\n"
+
+func TestGmailImport_MboxResumesFromJournal(t *testing.T) {
+	srv := setupGmailImportTest(t)
+	mbox := filepath.Join(t.TempDir(), "mail.mbox")
+	if err := os.WriteFile(mbox, []byte(gmailImportTestMbox), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	srv.failOn = "Subject: two"
+	if _, err := runGmailImport(t, mbox, "--label", "Imported"); err == nil {
+		t.Fatalf("expected the second message to fail")
+	}
+	if len(srv.imports) != 1 || len(srv.created) != 1 || srv.created[0] != "Imported" {
+		t.Fatalf("unexpected first run: imports=%d created=%v", len(srv.imports), srv.created)
+	}
+	// The successful import is journaled immediately, not in batches.
+	journals, _ := filepath.Glob(filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "*", "state", "gmail-import", "*.journal"))
+	if len(journals) != 1 {
+		t.Fatalf("expected one journal, got %v", journals)
+	}
+	if data, _ := os.ReadFile(journals[0]); strings.Count(string(data), "\n") != 1 || !strings.HasSuffix(string(data), "\tg1\n") {
+		t.Fatalf("unexpected journal: %q", data)
+	}
+	first := srv.imports[0]
+	if strings.Join(first.LabelIds, ",") != "UNREAD,INBOX,Label_1,Label_new" {
+		t.Fatalf("unexpected labels: %v", first.LabelIds)
+	}
+	if !strings.Contains(srv.queries[0], "internalDateSource=dateHeader") {
+		t.Fatalf("expected dateHeader internal date, got %q", srv.queries[0])
+	}
+	if strings.Contains(first.Raw, "X-Gmail-Labels") || strings.Contains(first.Raw, "X-GM-THRID") {
+		t.Fatalf("export headers must be stripped:\n%s", first.Raw)
+	}
+	if !strings.Contains(first.Raw, "\r\n\r\nFrom the start\r\n>From quoted\r\n") {
+		t.Fatalf("expected mboxrd unquoting and CRLF:\n%q", first.Raw)
+	}
+
+	srv.failOn = ""
+	out, err := runGmailImport(t, mbox, "--label", "Imported")
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	var got struct {
+		Imported int `json:"imported"`
+		Skipped  int `json:"skipped"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if got.Imported != 1 || got.Skipped != 1 || len(srv.imports) != 2 {
+		t.Fatalf("unexpected resume: %+v imports=%d", got, len(srv.imports))
+	}
+	// Status: RO marks the message as read and adds no system labels.
+	if strings.Join(srv.imports[1].LabelIds, ",") != "Label_new" {
+		t.Fatalf("unexpected labels for read message: %v", srv.imports[1].LabelIds)
+	}
+}
+
+func TestGmailImportJournal_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acct_1234.journal")
+
+	j, err := openGmailImportJournal(path, false)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, kv := range [][2]string{{"k1", "g1"}, {"k2", "g2"}} {
+		if err := j.add(kv[0], kv[1]); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// A torn trailing line from a crash is ignored.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	_, _ = f.WriteString("k3")
+	_ = f.Close()
+
+	j, err = openGmailImportJournal(path, false)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	_ = j.Close()
+	if len(j.Imported) != 2 || j.Imported["k1"] != "g1" || j.Imported["k2"] != "g2" {
+		t.Fatalf("unexpected journal: %v", j.Imported)
+	}
+
+	j, err = openGmailImportJournal(path, true)
+	if err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	_ = j.Close()
+	if len(j.Imported) != 0 {
+		t.Fatalf("expected restart to clear the journal, got %v", j.Imported)
+	}
+}
+
+func TestGmailImport_MaildirDryRun(t *testing.T) {
+	srv := setupGmailImportTest(t)
+	root := filepath.Join(t.TempDir(), "Mail")
+	for _, dir := range []string{"cur", "new", ".Work.Projects/cur", ".Archive/cur"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o700); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+	}
+	labeled := "X-Gmail-Labels: UNREAD,Work/Projects\nSubject: one\n\nbody\n"
+	write := func(rel, body string) {
+		if err := os.WriteFile(filepath.Join(root, rel), []byte(body), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	write("cur/1.m1.gog:2,FS", labeled)
+	write(".Work.Projects/cur/1.m1.gog:2,FS", labeled)
+	write(".Archive/cur/2.x:2,", "Subject: two\r\n\r\nother\r\n")
+
+	out, err := runGmailImport(t, root, "--dry-run")
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(srv.imports) != 0 || len(srv.created) != 0 {
+		t.Fatalf("dry run must not write: imports=%d created=%v", len(srv.imports), srv.created)
+	}
+	var got struct {
+		DryRun         bool              `json:"dry_run"`
+		Format         string            `json:"format"`
+		Messages       []gmailImportItem `json:"messages"`
+		LabelsToCreate []string          `json:"labelsToCreate"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if !got.DryRun || got.Format != "maildir" || len(got.Messages) != 2 {
+		t.Fatalf("unexpected plan: %+v", got)
+	}
+	one, two := got.Messages[0], got.Messages[1]
+	if one.Source != "cur/1.m1.gog:2,FS" || one.Unread || strings.Join(one.Labels, ",") != "Work/Projects,STARRED" {
+		t.Fatalf("unexpected first message: %+v", one)
+	}
+	if two.Subject != "two" || !two.Unread || strings.Join(two.Labels, ",") != "Archive" {
+		t.Fatalf("unexpected second message: %+v", two)
+	}
+	if strings.Join(got.LabelsToCreate, ",") != "Archive" {
+		t.Fatalf("unexpected labels to create: %v", got.LabelsToCreate)
+	}
+}
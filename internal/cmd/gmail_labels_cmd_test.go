@@ -36,23 +36,6 @@ func newLabelsServer(t *testing.T, listLabels []map[string]any, handleCreate fun
 	}))
 }
 
-func stubGmailService(t *testing.T, srv *httptest.Server) {
-	t.Helper()
-
-	origNew := newGmailService
-	t.Cleanup(func() { newGmailService = origNew })
-
-	svc, err := gmail.NewService(context.Background(),
-		option.WithoutAuthentication(),
-		option.WithHTTPClient(srv.Client()),
-		option.WithEndpoint(srv.URL+"/"),
-	)
-	if err != nil {
-		t.Fatalf("NewService: %v", err)
-	}
-	newGmailService = func(context.Context, string) (*gmail.Service, error) { return svc, nil }
-}
-
 func TestGmailLabelsGetCmd_JSON(t *testing.T) {
 	origNew := newGmailService
 	t.Cleanup(func() { newGmailService = origNew })
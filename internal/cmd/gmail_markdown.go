@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/htmltext"
+)
+
+// markdownEmail is a Markdown body rendered for sending: HTML, a text
+// alternative and the local images the HTML references by cid.
+type markdownEmail struct {
+	HTML   string
+	Text   string
+	Inline []mailAttachment
+}
+
+const (
+	mdQuoteStyle = "margin:0 0 0 .8ex;border-left:2px solid #ccc;padding-left:1ex"
+	mdCodeStyle  = "background:#f4f4f4;padding:8px;border-radius:4px;overflow:auto"
+	mdCellStyle  = "border:1px solid #ddd;padding:4px 8px;text-align:left"
+)
+
+// renderMarkdownEmail renders src to an email body. Images that point at
+// local files (resolved against baseDir) become inline attachments.
+func renderMarkdownEmail(src, baseDir string) (markdownEmail, error) {
+	r := &markdownRenderer{baseDir: baseDir, cids: map[string]string{}}
+	body, err := r.render(src)
+	if err != nil {
+		return markdownEmail{}, err
+	}
+	doc := "<!DOCTYPE html>\n<html><body>\n" + body + "</body></html>\n"
+	return markdownEmail{
+		HTML:   doc,
+		Text:   htmltext.Text(doc),
+		Inline: r.inline,
+	}, nil
+}
+
+// renderMarkdownFragment renders src to an HTML fragment for places that
+// cannot carry inline parts (signatures), so images must be URLs.
+func renderMarkdownFragment(src string) (string, error) {
+	r := &markdownRenderer{remoteOnly: true}
+	return r.render(src)
+}
+
+type markdownRenderer struct {
+	baseDir    string
+	remoteOnly bool
+	images     []markdownImage
+	cids       map[string]string
+	inline     []mailAttachment
+	err        error
+}
+
+var mdImagePlaceholderPattern = regexp.MustCompile(`&lt;&lt;IMG_(\d+)&gt;&gt;`)
+
+// render parses src with the same parser as docs imports and renders the
+// elements as HTML. Images are pulled out first, as for docs, and put back
+// as <img> tags once the surrounding text is escaped.
+func (r *markdownRenderer) render(src string) (string, error) {
+	src = strings.ReplaceAll(strings.ReplaceAll(src, "\r\n", "\n"), "\r", "\n")
+	var cleaned string
+	cleaned, r.images = extractMarkdownImages(src)
+	elements := parseMarkdown(cleaned, true)
+
+	var b strings.Builder
+	for i := 0; i < len(elements); {
+		el := elements[i]
+		switch el.Type {
+		case MDHeading1, MDHeading2, MDHeading3, MDHeading4, MDHeading5, MDHeading6:
+			level := int(el.Type-MDHeading1) + 1
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, r.inlines(strings.TrimRight(el.Content, "# ")), level)
+			i++
+
+		case MDCodeBlock:
+			fmt.Fprintf(&b, "<pre style=\"%s\"><code>%s</code></pre>\n", mdCodeStyle, html.EscapeString(r.restoreImages(el.Content)))
+			i++
+
+		case MDHorizontalRule:
+			b.WriteString("<hr>\n")
+			i++
+
+		case MDBlockquote:
+			var quoted []string
+			for ; i < len(elements) && elements[i].Type == MDBlockquote; i++ {
+				quoted = append(quoted, r.inlines(elements[i].Content))
+			}
+			fmt.Fprintf(&b, "<blockquote style=\"%s\">\n<p>%s</p>\n</blockquote>\n", mdQuoteStyle, strings.Join(quoted, "<br>\n"))
+
+		case MDListItem, MDNumberedList:
+			tag := "ul"
+			if el.Type == MDNumberedList {
+				tag = "ol"
+			}
+			fmt.Fprintf(&b, "<%s>\n", tag)
+			for ; i < len(elements) && elements[i].Type == el.Type; i++ {
+				fmt.Fprintf(&b, "<li>%s</li>\n", r.inlines(elements[i].Content))
+			}
+			fmt.Fprintf(&b, "</%s>\n", tag)
+
+		case MDTable:
+			b.WriteString("<table style=\"border-collapse:collapse\">\n")
+			for n, row := range el.TableCells {
+				cell := "td"
+				if n == 0 {
+					cell = "th"
+				}
+				b.WriteString("<tr>")
+				for _, content := range row {
+					fmt.Fprintf(&b, "<%s style=\"%s\">%s</%s>", cell, mdCellStyle, r.inlines(content), cell)
+				}
+				b.WriteString("</tr>\n")
+			}
+			b.WriteString("</table>\n")
+			i++
+
+		case MDParagraph:
+			// Lines without a blank line between them stay one paragraph;
+			// the line breaks are kept because that is how mail is written.
+			var lines []string
+			for ; i < len(elements) && elements[i].Type == MDParagraph; i++ {
+				lines = append(lines, r.inlines(strings.TrimSpace(elements[i].Content)))
+			}
+			fmt.Fprintf(&b, "<p>%s</p>\n", strings.Join(lines, "<br>\n"))
+
+		default:
+			i++
+		}
+	}
+	if r.err != nil {
+		return "", r.err
+	}
+	return b.String(), nil
+}
+
+// inlines renders the bold, italic, code and link spans found by
+// ParseInlineFormatting. Its offsets count UTF-16 code units.
+func (r *markdownRenderer) inlines(text string) string {
+	styles, plain := ParseInlineFormatting(text)
+	units := utf16.Encode([]rune(plain))
+	slice := func(start, end int64) string {
+		return html.EscapeString(string(utf16.Decode(units[start:end])))
+	}
+
+	var b strings.Builder
+	var pos int64
+	for _, style := range styles {
+		if style.Start < pos || style.End > int64(len(units)) {
+			continue // overlapping spans keep the first match
+		}
+		b.WriteString(slice(pos, style.Start))
+		var open, closing string
+		if style.Link != "" {
+			open += "<a href=\"" + html.EscapeString(style.Link) + "\">"
+			closing = "</a>" + closing
+		}
+		if style.Bold {
+			open += "<strong>"
+			closing = "</strong>" + closing
+		}
+		if style.Italic {
+			open += "<em>"
+			closing = "</em>" + closing
+		}
+		if style.Code {
+			open += "<code>"
+			closing = "</code>" + closing
+		}
+		b.WriteString(open + slice(style.Start, style.End) + closing)
+		pos = style.End
+	}
+	b.WriteString(slice(pos, int64(len(units))))
+
+	return mdImagePlaceholderPattern.ReplaceAllStringFunc(b.String(), func(match string) string {
+		if img, ok := r.placeholderImage(match); ok {
+			return r.image(img.alt, img.originalRef)
+		}
+		return match
+	})
+}
+
+// restoreImages puts image placeholders back as Markdown, for code blocks.
+func (r *markdownRenderer) restoreImages(text string) string {
+	for _, img := range r.images {
+		text = strings.ReplaceAll(text, img.placeholder(), "!["+img.alt+"]("+img.originalRef+")")
+	}
+	return text
+}
+
+func (r *markdownRenderer) placeholderImage(match string) (markdownImage, bool) {
+	n, err := strconv.Atoi(mdImagePlaceholderPattern.FindStringSubmatch(match)[1])
+	if err != nil || n >= len(r.images) {
+		return markdownImage{}, false
+	}
+	return r.images[n], true
+}
+
+// image renders an <img>, turning local paths into cid: references.
+func (r *markdownRenderer) image(alt, src string) string {
+	alt = html.EscapeString(alt)
+	if mdIsRemoteURL(src) {
+		return fmt.Sprintf("<img src=\"%s\" alt=\"%s\">", html.EscapeString(src), alt)
+	}
+	if r.remoteOnly {
+		if r.err == nil {
+			r.err = fmt.Errorf("markdown image %q must be an http(s) URL", src)
+		}
+		return ""
+	}
+	cid, err := r.inlineImage(src)
+	if err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return ""
+	}
+	return fmt.Sprintf("<img src=\"cid:%s\" alt=\"%s\" style=\"max-width:100%%\">", cid, alt)
+}
+
+func mdIsRemoteURL(src string) bool {
+	lower := strings.ToLower(src)
+	for _, prefix := range []string{"http://", "https://", "cid:", "data:"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *markdownRenderer) inlineImage(src string) (string, error) {
+	path, err := config.ExpandPath(strings.TrimPrefix(src, "file://"))
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) && r.baseDir != "" {
+		path = filepath.Join(r.baseDir, path)
+	}
+	if cid, ok := r.cids[path]; ok {
+		return cid, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("markdown image %q: %w", src, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("markdown image %q is a directory", src)
+	}
+	cid := fmt.Sprintf("img%d@gogcli", len(r.inline)+1)
+	r.cids[path] = cid
+	r.inline = append(r.inline, mailAttachment{Path: path, ContentID: cid})
+	return cid, nil
+}
+
+func mailAttachmentPaths(atts []mailAttachment) []string {
+	paths := make([]string, 0, len(atts))
+	for _, a := range atts {
+		paths = append(paths, a.Path)
+	}
+	return paths
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestRenderMarkdownEmail(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "chart.png"), []byte("PNG"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	src := strings.Join([]string{
+		"## Weekly *update*",
+		"",
+		"Hello **team**,",
+		"see [the report](https://x.test/r) for snake_case details.",
+		"",
+		"- one",
+		"- two",
+		"",
+		"1. first",
+		"2. second",
+		"",
+		"> quoted <text>",
+		"",
+		"```",
+		"go test ./...",
+		"```",
+		"",
+		"| Name | Status |",
+		"| ---- | ------ |",
+		"| API  | `ok`   |",
+		"",
+		"![chart](chart.png) ![logo](https://x.test/logo.png) ![again](chart.png)",
+	}, "\n")
+	got, err := renderMarkdownEmail(src, dir)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	for _, want := range []string{
+		"<h2>Weekly <em>update</em></h2>",
+		"<p>Hello <strong>team</strong>,<br>\nsee <a href=\"https://x.test/r\">the report</a> for snake_case details.</p>",
+		"<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n<ol>\n<li>first</li>\n<li>second</li>\n</ol>",
+		"quoted &lt;text&gt;",
+		"<code>go test ./...</code></pre>",
+		"<code>ok</code></td>",
+		`<img src="cid:img1@gogcli" alt="chart"`,
+		`<img src="https://x.test/logo.png" alt="logo">`,
+		`<img src="cid:img1@gogcli" alt="again"`,
+	} {
+		if !strings.Contains(got.HTML, want) {
+			t.Fatalf("html missing %q:\n%s", want, got.HTML)
+		}
+	}
+	for _, want := range []string{"Weekly update", "the report [1]", "- two\n\n1. first\n2. second", "> quoted <text>", "Name  Status", "[1]: https://x.test/r"} {
+		if !strings.Contains(got.Text, want) {
+			t.Fatalf("text missing %q:\n%s", want, got.Text)
+		}
+	}
+	if len(got.Inline) != 1 || got.Inline[0].ContentID != "img1@gogcli" || got.Inline[0].Path != filepath.Join(dir, "chart.png") {
+		t.Fatalf("unexpected inline images: %+v", got.Inline)
+	}
+
+	if _, err := renderMarkdownEmail("![x](missing.png)", dir); err == nil {
+		t.Fatalf("expected missing image to fail")
+	}
+}
+
+func TestResolveComposeBody_Markdown(t *testing.T) {
+	dir := t.TempDir()
+	mdPath := filepath.Join(dir, "note.md")
+	if err := os.WriteFile(mdPath, []byte("Hi *there*\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	got, err := resolveComposeBody("", mdPath, "", "")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if !strings.Contains(got.HTML, "<em>there</em>") || got.Text != "Hi there" {
+		t.Fatalf("unexpected body: %+v", got)
+	}
+
+	txtPath := filepath.Join(dir, "note.txt")
+	if err := os.WriteFile(txtPath, []byte("Hi *there*\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got, err := resolveComposeBody("", txtPath, "", ""); err != nil || got.HTML != "" || got.Text != "Hi *there*\n" {
+		t.Fatalf("plain file should stay plain: %+v %v", got, err)
+	}
+
+	for _, args := range [][4]string{
+		{"plain", "", "", "*md*"},
+		{"", txtPath, "", "*md*"},
+		{"", "", "<p>x</p>", "*md*"},
+		{"", mdPath, "<p>x</p>", ""},
+	} {
+		if _, err := resolveComposeBody(args[0], args[1], args[2], args[3]); err == nil {
+			t.Fatalf("expected conflict error for %q", args)
+		}
+	}
+}
+
+func TestGmailDraftsCreate_BodyMarkdownInlinesImages(t *testing.T) {
+	var raw string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/users/me/drafts") && r.Method == http.MethodPost {
+			var draft gmail.Draft
+			_ = json.NewDecoder(r.Body).Decode(&draft)
+			if draft.Message != nil {
+				raw = draft.Message.Raw
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "d1", "message": map[string]any{"id": "m1"}})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	stubGmailService(t, srv)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "chart.png"), []byte("PNG"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	mdPath := filepath.Join(dir, "update.md")
+	if err := os.WriteFile(mdPath, []byte("# Status\n\nAll **green**.\n\n![chart](chart.png)\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	_ = captureStdout(t, func() {
+		if err := Execute([]string{"--json", "--account", "a@b.com", "gmail", "drafts", "create", "--to", "x@example.com", "--subject", "S", "--body-file", mdPath}); err != nil {
+			t.Fatalf("drafts create: %v", err)
+		}
+	})
+
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		t.Fatalf("decode raw: %v", err)
+	}
+	msg := string(decoded)
+	for _, want := range []string{
+		"multipart/related",
+		"multipart/alternative",
+		"Status\r\n\r\nAll green.",
+		"<h1>Status</h1>",
+		`src="cid:img1@gogcli"`,
+		"Content-ID: <img1@gogcli>",
+		"Content-Disposition: inline; filename=\"chart.png\"",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("message missing %q:\n%s", want, msg)
+		}
+	}
+}
@@ -0,0 +1,504 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailMergeStatusSent    = "sent"
+	gmailMergeStatusDrafted = "drafted"
+	gmailMergeStatusSkipped = "skipped"
+	gmailMergeStatusFailed  = "failed"
+	gmailMergeStatusPlanned = "planned"
+)
+
+// gmailMergeWait sleeps between messages; tests replace it to avoid real delays.
+var gmailMergeWait = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+type GmailMergeCmd struct {
+	CSV          string        `name:"csv" required:"" help:"CSV file with a header row; each following row is one message"`
+	Template     string        `name:"template" required:"" help:"Plain text body template file (Go text/template, fields from the CSV header)"`
+	TemplateHTML string        `name:"template-html" help:"Optional HTML body template file (Go html/template)"`
+	Subject      string        `name:"subject" required:"" help:"Subject template, e.g. 'Hello {{.name}}'"`
+	ToColumn     string        `name:"to-column" help:"CSV column holding the recipient address" default:"email"`
+	Cc           string        `name:"cc" help:"CC recipients template (comma-separated)"`
+	Bcc          string        `name:"bcc" help:"BCC recipients template (comma-separated)"`
+	ReplyTo      string        `name:"reply-to" help:"Reply-To header address"`
+	From         string        `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+	Attach       []string      `name:"attach" help:"Attachment path template (repeatable; rows rendering an empty path get no attachment)"`
+	Delay        time.Duration `name:"delay" help:"Pause between messages (rate limit)" default:"1s"`
+	Draft        bool          `name:"draft" help:"Create drafts instead of sending"`
+	Restart      bool          `name:"restart" help:"Ignore the send log and process every row again"`
+}
+
+// gmailMergeRow is both the per-row NDJSON status written to stdout and the
+// entry appended to the send log.
+type gmailMergeRow struct {
+	Row         int      `json:"row"`
+	Key         string   `json:"key"`
+	To          string   `json:"to"`
+	Subject     string   `json:"subject,omitempty"`
+	Attachments []string `json:"attachments,omitempty"`
+	Status      string   `json:"status"`
+	MessageID   string   `json:"messageId,omitempty"`
+	ThreadID    string   `json:"threadId,omitempty"`
+	DraftID     string   `json:"draftId,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	AtMs        int64    `json:"atMs,omitempty"`
+
+	body     string
+	bodyHTML string
+	cc       string
+	bcc      string
+}
+
+type gmailMergeTemplates struct {
+	subject *template.Template
+	body    *template.Template
+	html    *htmltemplate.Template
+	cc      *template.Template
+	bcc     *template.Template
+	attach  []*template.Template
+}
+
+func (c *GmailMergeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	csvPath, err := config.ExpandPath(strings.TrimSpace(c.CSV))
+	if err != nil {
+		return err
+	}
+	csvPath, err = filepath.Abs(csvPath)
+	if err != nil {
+		return err
+	}
+	header, records, err := readGmailMergeCSV(csvPath)
+	if err != nil {
+		return err
+	}
+	toColumn := strings.TrimSpace(c.ToColumn)
+	if !containsFold(header, toColumn) {
+		return usagef("CSV has no %q column (use --to-column; columns: %s)", toColumn, strings.Join(header, ", "))
+	}
+
+	tmpls, err := c.parseTemplates()
+	if err != nil {
+		return err
+	}
+	// Render every row before sending anything so a template error cannot
+	// leave a half-finished merge behind.
+	rows := make([]*gmailMergeRow, 0, len(records))
+	occurrences := make(map[string]int, len(records))
+	duplicates := 0
+	for i, record := range records {
+		row, renderErr := tmpls.render(i+1, header, record, toColumn)
+		if renderErr != nil {
+			return renderErr
+		}
+		// Identical rows are separate recipients of the same message; number
+		// the copies so resuming does not mistake one for another.
+		occurrences[row.Key]++
+		if n := occurrences[row.Key]; n > 1 {
+			row.Key = gmailMergeDuplicateKey(row.Key, n)
+			duplicates++
+		}
+		rows = append(rows, row)
+	}
+	if duplicates > 0 {
+		u.Err().Printf("Warning: %d CSV rows repeat an earlier row; each copy will be sent", duplicates)
+	}
+
+	mode := gmailMergeStatusSent
+	if c.Draft {
+		mode = gmailMergeStatusDrafted
+	}
+	logPath, err := gmailMergeLogPath(account, csvPath, mode)
+	if err != nil {
+		return err
+	}
+	done := map[string]bool{}
+	if !c.Restart {
+		done, err = loadGmailMergeLog(logPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	emit := func(row *gmailMergeRow) error {
+		return enc.Encode(row)
+	}
+
+	if flags != nil && flags.DryRun {
+		for _, row := range rows {
+			row.Status = gmailMergeStatusPlanned
+			if done[row.Key] {
+				row.Status = gmailMergeStatusSkipped
+			}
+			if err := emit(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	fromAddr := ""
+	if !c.Draft {
+		fromAddr, _, err = resolveSendFrom(ctx, svc, account, c.From)
+		if err != nil {
+			return err
+		}
+	}
+
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // path inside config dir
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	counts := map[string]int{}
+	processed := 0
+	for _, row := range rows {
+		if done[row.Key] {
+			row.Status = gmailMergeStatusSkipped
+			counts[row.Status]++
+			if err := emit(row); err != nil {
+				return err
+			}
+			continue
+		}
+		if processed > 0 && c.Delay > 0 {
+			if err := gmailMergeWait(ctx, c.Delay); err != nil {
+				return err
+			}
+		}
+		processed++
+
+		if sendErr := c.deliver(ctx, svc, account, fromAddr, row); sendErr != nil {
+			row.Status = gmailMergeStatusFailed
+			row.Error = sendErr.Error()
+		} else {
+			row.Status = mode
+			row.AtMs = time.Now().UnixMilli()
+			if err := appendGmailMergeLog(logFile, row); err != nil {
+				return err
+			}
+		}
+		counts[row.Status]++
+		if err := emit(row); err != nil {
+			return err
+		}
+	}
+
+	u.Err().Printf("%s %d, skipped %d, failed %d", mode, counts[mode], counts[gmailMergeStatusSkipped], counts[gmailMergeStatusFailed])
+	if failed := counts[gmailMergeStatusFailed]; failed > 0 {
+		return fmt.Errorf("%d of %d rows failed; re-run to retry them", failed, len(rows))
+	}
+	return nil
+}
+
+func (c *GmailMergeCmd) deliver(ctx context.Context, svc *gmail.Service, account, fromAddr string, row *gmailMergeRow) error {
+	if c.Draft {
+		msg, _, err := buildDraftMessage(ctx, svc, account, draftComposeInput{
+			To:       row.To,
+			Cc:       row.cc,
+			Bcc:      row.bcc,
+			Subject:  row.Subject,
+			Body:     row.body,
+			BodyHTML: row.bodyHTML,
+			ReplyTo:  c.ReplyTo,
+			Attach:   row.Attachments,
+			From:     c.From,
+		})
+		if err != nil {
+			return err
+		}
+		draft, err := svc.Users.Drafts.Create("me", &gmail.Draft{Message: msg}).Context(ctx).Do()
+		if err != nil {
+			return err
+		}
+		row.DraftID = draft.Id
+		if draft.Message != nil {
+			row.MessageID = draft.Message.Id
+			row.ThreadID = draft.Message.ThreadId
+		}
+		return nil
+	}
+
+	atts := make([]mailAttachment, 0, len(row.Attachments))
+	for _, p := range row.Attachments {
+		atts = append(atts, mailAttachment{Path: p})
+	}
+	results, err := sendGmailBatches(ctx, svc, sendMessageOptions{
+		FromAddr:    fromAddr,
+		ReplyTo:     c.ReplyTo,
+		Subject:     row.Subject,
+		Body:        row.body,
+		BodyHTML:    row.bodyHTML,
+		Attachments: atts,
+	}, []sendBatch{{To: splitCSV(row.To), Cc: splitCSV(row.cc), Bcc: splitCSV(row.bcc)}})
+	if err != nil {
+		return err
+	}
+	if len(results) > 0 {
+		row.MessageID = results[0].MessageID
+		row.ThreadID = results[0].ThreadID
+	}
+	return nil
+}
+
+func (c *GmailMergeCmd) parseTemplates() (*gmailMergeTemplates, error) {
+	text := func(name, src string) (*template.Template, error) {
+		t, err := template.New(name).Option("missingkey=error").Parse(src)
+		if err != nil {
+			return nil, usagef("invalid %s template: %v", name, err)
+		}
+		return t, nil
+	}
+	readFile := func(path string) (string, error) {
+		path, err := config.ExpandPath(strings.TrimSpace(path))
+		if err != nil {
+			return "", err
+		}
+		b, err := os.ReadFile(path) //nolint:gosec // user-provided path
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	if strings.TrimSpace(c.Subject) == "" {
+		return nil, usage("required: --subject")
+	}
+	var (
+		out gmailMergeTemplates
+		err error
+	)
+	if out.subject, err = text("subject", c.Subject); err != nil {
+		return nil, err
+	}
+	src, err := readFile(c.Template)
+	if err != nil {
+		return nil, err
+	}
+	if out.body, err = text("body", src); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(c.TemplateHTML) != "" {
+		src, err = readFile(c.TemplateHTML)
+		if err != nil {
+			return nil, err
+		}
+		out.html, err = htmltemplate.New("html").Option("missingkey=error").Parse(src)
+		if err != nil {
+			return nil, usagef("invalid html template: %v", err)
+		}
+	}
+	if out.cc, err = text("cc", c.Cc); err != nil {
+		return nil, err
+	}
+	if out.bcc, err = text("bcc", c.Bcc); err != nil {
+		return nil, err
+	}
+	for _, a := range c.Attach {
+		t, err := text("attach", a)
+		if err != nil {
+			return nil, err
+		}
+		out.attach = append(out.attach, t)
+	}
+	return &out, nil
+}
+
+type gmailMergeExecutor interface {
+	Execute(w io.Writer, data any) error
+}
+
+func (t *gmailMergeTemplates) render(n int, header, record []string, toColumn string) (*gmailMergeRow, error) {
+	data := make(map[string]string, len(header))
+	for i, h := range header {
+		if i < len(record) {
+			data[h] = strings.TrimSpace(record[i])
+		} else {
+			data[h] = ""
+		}
+	}
+	exec := func(name string, tmpl gmailMergeExecutor) (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", usagef("row %d: render %s: %v", n, name, err)
+		}
+		return buf.String(), nil
+	}
+
+	row := &gmailMergeRow{Row: n, Key: gmailMergeRowKey(record)}
+	for h, v := range data {
+		if strings.EqualFold(h, toColumn) {
+			row.To = v
+		}
+	}
+	if row.To == "" {
+		return nil, usagef("row %d: empty %q column", n, toColumn)
+	}
+
+	var err error
+	if row.Subject, err = exec("subject", t.subject); err != nil {
+		return nil, err
+	}
+	row.Subject = strings.TrimSpace(row.Subject)
+	if row.body, err = exec("body", t.body); err != nil {
+		return nil, err
+	}
+	if t.html != nil {
+		if row.bodyHTML, err = exec("html", t.html); err != nil {
+			return nil, err
+		}
+	}
+	if row.cc, err = exec("cc", t.cc); err != nil {
+		return nil, err
+	}
+	if row.bcc, err = exec("bcc", t.bcc); err != nil {
+		return nil, err
+	}
+	for _, a := range t.attach {
+		p, err := exec("attach", a)
+		if err != nil {
+			return nil, err
+		}
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if p, err = config.ExpandPath(p); err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(p); err != nil {
+			return nil, usagef("row %d: attachment %s: %v", n, p, err)
+		}
+		row.Attachments = append(row.Attachments, p)
+	}
+	return row, nil
+}
+
+func readGmailMergeCSV(path string) ([]string, [][]string, error) {
+	f, err := os.Open(path) //nolint:gosec // user-provided path
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(bufio.NewReader(f))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil, usagef("%s is empty", path)
+	}
+	header := records[0]
+	for i, h := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+	}
+	rows := records[1:]
+	out := rows[:0]
+	for _, rec := range rows {
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return header, out, nil
+}
+
+// gmailMergeRowKey identifies a row by its data, so editing the templates
+// after a crash does not resend rows that already went out.
+func gmailMergeRowKey(record []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(record, "\x1f")))
+	return hex.EncodeToString(sum[:12])
+}
+
+// gmailMergeDuplicateKey keys the nth (n >= 2) identical copy of a row.
+func gmailMergeDuplicateKey(key string, n int) string {
+	return fmt.Sprintf("%s-%d", key, n)
+}
+
+func gmailMergeLogPath(account, csvPath, mode string) (string, error) {
+	dir, err := config.EnsureGmailMergeDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(account) + "\x00" + csvPath + "\x00" + mode))
+	return filepath.Join(dir, sanitizeAccountForPath(account)+"_"+hex.EncodeToString(sum[:8])+".ndjson"), nil
+}
+
+func loadGmailMergeLog(path string) (map[string]bool, error) {
+	done := map[string]bool{}
+	f, err := os.Open(path) //nolint:gosec // path inside config dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return done, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry gmailMergeRow
+		// A crash mid-write can leave a truncated last line; ignore it.
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Key == "" {
+			continue
+		}
+		done[entry.Key] = true
+	}
+	return done, scanner.Err()
+}
+
+func appendGmailMergeLog(f *os.File, row *gmailMergeRow) error {
+	line, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+type gmailMergeTestServer struct {
+	mu     sync.Mutex
+	failTo string
+	sent   []string
+	drafts []string
+}
+
+func setupGmailMergeTest(t *testing.T) (*gmailMergeTestServer, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+	origNew, origWait := newGmailService, gmailMergeWait
+	t.Cleanup(func() { newGmailService, gmailMergeWait = origNew, origWait })
+	gmailMergeWait = func(context.Context, time.Duration) error { return nil }
+
+	s := &gmailMergeTestServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/settings/sendAs":
+			_ = json.NewEncoder(w).Encode(map[string]any{"sendAs": []map[string]any{
+				{"sendAsEmail": "a@b.com", "displayName": "Alice", "isPrimary": true},
+			}})
+		case path == "/messages/send":
+			var msg gmail.Message
+			_ = json.NewDecoder(r.Body).Decode(&msg)
+			raw, _ := base64.RawURLEncoding.DecodeString(msg.Raw)
+			if s.failTo != "" && strings.Contains(string(raw), "To: "+s.failTo) {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 400, "message": "invalid to"}})
+				return
+			}
+			s.sent = append(s.sent, string(raw))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": fmt.Sprintf("m%d", len(s.sent)), "threadId": "t"})
+		case path == "/drafts":
+			var draft gmail.Draft
+			_ = json.NewDecoder(r.Body).Decode(&draft)
+			raw, _ := base64.RawURLEncoding.DecodeString(draft.Message.Raw)
+			s.drafts = append(s.drafts, string(raw))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": fmt.Sprintf("d%d", len(s.drafts)), "message": map[string]any{"id": "dm"}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newGmailService = func(context.Context, string) (*gmail.Service, error) { return svc, nil }
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"people.csv": "\ufeffemail,name,invoice\nann@x.com,Ann,inv-1.pdf\nbob@x.com,Bob,\ncat@x.com,Cat,\n",
+		"body.txt":   "Hi {{.name}},\n\nsee attached.\n",
+		"inv-1.pdf":  "%PDF-1.4",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	return s, dir
+}
+
+func runGmailMerge(t *testing.T, dir string, extra ...string) ([]gmailMergeRow, error) {
+	t.Helper()
+	args := append([]string{"--account", "a@b.com", "gmail", "merge",
+		"--csv", filepath.Join(dir, "people.csv"),
+		"--template", filepath.Join(dir, "body.txt"),
+		"--subject", "Invoice for {{.name}}",
+		"--attach", `{{if .invoice}}` + dir + `/{{.invoice}}{{end}}`,
+	}, extra...)
+	var out string
+	var err error
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() { err = Execute(args) })
+	})
+	var rows []gmailMergeRow
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		var row gmailMergeRow
+		if jsonErr := json.Unmarshal(scanner.Bytes(), &row); jsonErr != nil {
+			t.Fatalf("bad NDJSON line %q: %v", scanner.Text(), jsonErr)
+		}
+		rows = append(rows, row)
+	}
+	return rows, err
+}
+
+func mergeStatuses(rows []gmailMergeRow) string {
+	parts := make([]string, 0, len(rows))
+	for _, r := range rows {
+		parts = append(parts, r.To+"="+r.Status)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestGmailMerge_SendResumesAfterFailure(t *testing.T) {
+	srv, dir := setupGmailMergeTest(t)
+
+	srv.failTo = "bob@x.com"
+	rows, err := runGmailMerge(t, dir)
+	if err == nil {
+		t.Fatalf("expected failure for bob")
+	}
+	if got := mergeStatuses(rows); got != "ann@x.com=sent,bob@x.com=failed,cat@x.com=sent" {
+		t.Fatalf("unexpected first run: %s", got)
+	}
+	if len(srv.sent) != 2 {
+		t.Fatalf("expected 2 sends, got %d", len(srv.sent))
+	}
+	ann := srv.sent[0]
+	for _, want := range []string{"Subject: Invoice for Ann", "Hi Ann,", "inv-1.pdf", `From: "Alice" <a@b.com>`} {
+		if !strings.Contains(ann, want) {
+			t.Fatalf("missing %q in message:\n%s", want, ann)
+		}
+	}
+	if strings.Contains(srv.sent[1], "filename=") {
+		t.Fatalf("cat must not get an attachment:\n%s", srv.sent[1])
+	}
+
+	srv.failTo = ""
+	rows, err = runGmailMerge(t, dir)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if got := mergeStatuses(rows); got != "ann@x.com=skipped,bob@x.com=sent,cat@x.com=skipped" {
+		t.Fatalf("unexpected resume: %s", got)
+	}
+	if len(srv.sent) != 3 || !strings.Contains(srv.sent[2], "Hi Bob,") {
+		t.Fatalf("expected only bob to be sent on resume, got %d sends", len(srv.sent))
+	}
+}
+
+func TestGmailMerge_DraftMode(t *testing.T) {
+	srv, dir := setupGmailMergeTest(t)
+
+	rows, err := runGmailMerge(t, dir, "--draft")
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if len(srv.sent) != 0 || len(srv.drafts) != 3 {
+		t.Fatalf("expected 3 drafts and no sends, got sent=%d drafts=%d", len(srv.sent), len(srv.drafts))
+	}
+	if rows[0].Status != gmailMergeStatusDrafted || rows[0].DraftID != "d1" {
+		t.Fatalf("unexpected row: %+v", rows[0])
+	}
+}
+
+func TestGmailMerge_TemplateErrorSendsNothing(t *testing.T) {
+	srv, dir := setupGmailMergeTest(t)
+
+	_, err := runGmailMerge(t, dir, "--cc", "{{.manager}}")
+	if err == nil || !strings.Contains(err.Error(), "row 1") {
+		t.Fatalf("expected render error for row 1, got %v", err)
+	}
+	if len(srv.sent) != 0 {
+		t.Fatalf("nothing must be sent when a row fails to render")
+	}
+}
+
+func TestGmailMerge_DuplicateRowsAreSentSeparately(t *testing.T) {
+	srv, dir := setupGmailMergeTest(t)
+	csv := "email,name,invoice\nann@x.com,Ann,\nann@x.com,Ann,\n"
+	if err := os.WriteFile(filepath.Join(dir, "people.csv"), []byte(csv), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	rows, err := runGmailMerge(t, dir)
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if got := mergeStatuses(rows); got != "ann@x.com=sent,ann@x.com=sent" || rows[0].Key == rows[1].Key {
+		t.Fatalf("expected both copies to be sent with distinct keys: %+v", rows)
+	}
+
+	rows, err = runGmailMerge(t, dir)
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if got := mergeStatuses(rows); got != "ann@x.com=skipped,ann@x.com=skipped" || len(srv.sent) != 2 {
+		t.Fatalf("expected both copies to be skipped on rerun: %s (sent=%d)", got, len(srv.sent))
+	}
+}
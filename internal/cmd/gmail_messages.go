@@ -270,11 +270,9 @@ func sanitizeMessageBody(body string) string {
 	}
 	if looksLikeHTML(body) {
 		body = stripHTMLTags(body)
+	} else {
+		body = strings.Join(strings.Fields(body), " ")
 	}
-	body = strings.ReplaceAll(body, "\t", " ")
-	body = strings.ReplaceAll(body, "\n", " ")
-	body = strings.ReplaceAll(body, "\r", " ")
-	body = strings.TrimSpace(body)
 	return truncateRunes(body, 200)
 }
 
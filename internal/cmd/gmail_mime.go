@@ -20,6 +20,8 @@ type mailAttachment struct {
 	Filename string
 	MIMEType string
 	Data     []byte
+	// ContentID marks an inline part referenced from the HTML body as cid:<ContentID>.
+	ContentID string
 }
 
 type rfc822Config struct {
@@ -117,36 +119,22 @@ func buildRFC822(opts mailOptions, cfg *rfc822Config) ([]byte, error) {
 
 	plainBody := normalizeCRLF(opts.Body)
 	htmlBody := normalizeCRLF(opts.BodyHTML)
-	hasPlain := strings.TrimSpace(plainBody) != ""
 	hasHTML := strings.TrimSpace(htmlBody) != ""
 
-	if len(opts.Attachments) == 0 {
-		switch {
-		case hasPlain && hasHTML:
-			altBoundary, err := randomBoundary()
-			if err != nil {
-				return nil, err
-			}
-			writeHeader(&b, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
-			b.WriteString("\r\n")
+	var attachments, inline []mailAttachment
+	for _, a := range opts.Attachments {
+		if a.ContentID != "" && hasHTML {
+			inline = append(inline, a)
+		} else {
+			attachments = append(attachments, a)
+		}
+	}
 
-			writeTextPart(&b, altBoundary, "text/plain; charset=\"utf-8\"", plainBody)
-			writeTextPart(&b, altBoundary, "text/html; charset=\"utf-8\"", htmlBody)
-			b.WriteString(fmt.Sprintf("--%s--\r\n", altBoundary))
-			return b.Bytes(), nil
-		case hasHTML && !hasPlain:
-			writeHeader(&b, "Content-Type", "text/html; charset=\"utf-8\"")
-			writeHeader(&b, "Content-Transfer-Encoding", "7bit")
-			b.WriteString("\r\n")
-			writeBodyWithTrailingCRLF(&b, htmlBody)
-			return b.Bytes(), nil
-		default:
-			writeHeader(&b, "Content-Type", "text/plain; charset=\"utf-8\"")
-			writeHeader(&b, "Content-Transfer-Encoding", "7bit")
-			b.WriteString("\r\n")
-			writeBodyWithTrailingCRLF(&b, plainBody)
-			return b.Bytes(), nil
+	if len(attachments) == 0 {
+		if err := writeMessageBody(&b, plainBody, htmlBody, inline); err != nil {
+			return nil, err
 		}
+		return b.Bytes(), nil
 	}
 
 	mixedBoundary, err := randomBoundary()
@@ -159,55 +147,107 @@ func buildRFC822(opts mailOptions, cfg *rfc822Config) ([]byte, error) {
 
 	// Body part
 	b.WriteString(fmt.Sprintf("--%s\r\n", mixedBoundary))
+	if err := writeMessageBody(&b, plainBody, htmlBody, inline); err != nil {
+		return nil, err
+	}
+
+	// Attachments
+	for _, a := range attachments {
+		b.WriteString("\r\n")
+		if err := writeAttachmentPart(&b, mixedBoundary, a, "attachment"); err != nil {
+			return nil, err
+		}
+	}
+
+	b.WriteString(fmt.Sprintf("--%s--\r\n", mixedBoundary))
+	return b.Bytes(), nil
+}
+
+// writeMessageBody writes the body entity (headers and content): plain,
+// HTML or multipart/alternative, wrapped in multipart/related when the HTML
+// references inline parts.
+func writeMessageBody(b *bytes.Buffer, plainBody, htmlBody string, inline []mailAttachment) error {
+	if len(inline) > 0 {
+		relatedBoundary, err := randomBoundary()
+		if err != nil {
+			return err
+		}
+		writeHeader(b, "Content-Type", fmt.Sprintf("multipart/related; boundary=%q", relatedBoundary))
+		b.WriteString("\r\n")
+		b.WriteString(fmt.Sprintf("--%s\r\n", relatedBoundary))
+		if err := writeMessageBody(b, plainBody, htmlBody, nil); err != nil {
+			return err
+		}
+		for _, a := range inline {
+			b.WriteString("\r\n")
+			if err := writeAttachmentPart(b, relatedBoundary, a, "inline"); err != nil {
+				return err
+			}
+		}
+		b.WriteString(fmt.Sprintf("--%s--\r\n", relatedBoundary))
+		return nil
+	}
+
+	hasPlain := strings.TrimSpace(plainBody) != ""
+	hasHTML := strings.TrimSpace(htmlBody) != ""
 	switch {
 	case hasPlain && hasHTML:
 		altBoundary, err := randomBoundary()
 		if err != nil {
-			return nil, err
+			return err
 		}
-		b.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q\r\n\r\n", altBoundary))
-		writeTextPart(&b, altBoundary, "text/plain; charset=\"utf-8\"", plainBody)
-		writeTextPart(&b, altBoundary, "text/html; charset=\"utf-8\"", htmlBody)
+		writeHeader(b, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", altBoundary))
+		b.WriteString("\r\n")
+		writeTextPart(b, altBoundary, "text/plain; charset=\"utf-8\"", plainBody)
+		writeTextPart(b, altBoundary, "text/html; charset=\"utf-8\"", htmlBody)
 		b.WriteString(fmt.Sprintf("--%s--\r\n", altBoundary))
 	case hasHTML && !hasPlain:
-		b.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
-		b.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
-		writeBodyWithTrailingCRLF(&b, htmlBody)
+		writeHeader(b, "Content-Type", "text/html; charset=\"utf-8\"")
+		writeHeader(b, "Content-Transfer-Encoding", "7bit")
+		b.WriteString("\r\n")
+		writeBodyWithTrailingCRLF(b, htmlBody)
 	default:
-		b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
-		b.WriteString("Content-Transfer-Encoding: 7bit\r\n\r\n")
-		writeBodyWithTrailingCRLF(&b, plainBody)
+		writeHeader(b, "Content-Type", "text/plain; charset=\"utf-8\"")
+		writeHeader(b, "Content-Transfer-Encoding", "7bit")
+		b.WriteString("\r\n")
+		writeBodyWithTrailingCRLF(b, plainBody)
 	}
+	return nil
+}
 
-	// Attachments
-	for _, a := range opts.Attachments {
-		if a.Filename == "" {
-			a.Filename = filepath.Base(a.Path)
-		}
+// writeAttachmentPart writes one base64 part; disposition is "attachment" or
+// "inline" (inline parts also carry their Content-ID).
+func writeAttachmentPart(b *bytes.Buffer, boundary string, a mailAttachment, disposition string) error {
+	if a.Filename == "" {
+		a.Filename = filepath.Base(a.Path)
+	}
+	if a.MIMEType == "" {
+		a.MIMEType = mime.TypeByExtension(strings.ToLower(filepath.Ext(a.Filename)))
 		if a.MIMEType == "" {
-			a.MIMEType = mime.TypeByExtension(strings.ToLower(filepath.Ext(a.Filename)))
-			if a.MIMEType == "" {
-				a.MIMEType = "application/octet-stream"
-			}
+			a.MIMEType = "application/octet-stream"
 		}
-		if len(a.Data) == 0 {
-			data, err := os.ReadFile(a.Path)
-			if err != nil {
-				return nil, err
-			}
-			a.Data = data
+	}
+	if len(a.Data) == 0 {
+		data, err := os.ReadFile(a.Path)
+		if err != nil {
+			return err
 		}
-
-		b.WriteString(fmt.Sprintf("\r\n--%s\r\n", mixedBoundary))
-		b.WriteString(fmt.Sprintf("Content-Type: %s\r\n", a.MIMEType))
-		b.WriteString("Content-Transfer-Encoding: base64\r\n")
-		b.WriteString(fmt.Sprintf("Content-Disposition: attachment; %s\r\n\r\n", contentDispositionFilename(a.Filename)))
-		b.WriteString(wrapBase64(a.Data))
-		b.WriteString("\r\n")
+		a.Data = data
 	}
 
-	b.WriteString(fmt.Sprintf("--%s--\r\n", mixedBoundary))
-	return b.Bytes(), nil
+	b.WriteString(fmt.Sprintf("--%s\r\n", boundary))
+	b.WriteString(fmt.Sprintf("Content-Type: %s\r\n", a.MIMEType))
+	b.WriteString("Content-Transfer-Encoding: base64\r\n")
+	if disposition == "inline" && a.ContentID != "" {
+		if err := validateHeaderValue(a.ContentID); err != nil {
+			return fmt.Errorf("invalid Content-ID: %w", err)
+		}
+		b.WriteString(fmt.Sprintf("Content-ID: <%s>\r\n", a.ContentID))
+	}
+	b.WriteString(fmt.Sprintf("Content-Disposition: %s; %s\r\n\r\n", disposition, contentDispositionFilename(a.Filename)))
+	b.WriteString(wrapBase64(a.Data))
+	b.WriteString("\r\n")
+	return nil
 }
 
 func writeHeader(b *bytes.Buffer, name, value string) {
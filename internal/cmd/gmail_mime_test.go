@@ -352,3 +352,33 @@ func TestFormatAddressHeadersParsesCommaSeparatedList(t *testing.T) {
 		t.Fatalf("expected both addresses in output, got %q", got)
 	}
 }
+
+func TestBuildRFC822InlineImagesUseRelated(t *testing.T) {
+	raw, err := buildRFC822(mailOptions{
+		From:     "a@b.com",
+		To:       []string{"c@d.com"},
+		Subject:  "Hi",
+		Body:     "Plain",
+		BodyHTML: `<p><img src="cid:img1@gogcli"></p>`,
+		Attachments: []mailAttachment{
+			{Filename: "chart.png", Data: []byte("PNG"), ContentID: "img1@gogcli"},
+			{Filename: "x.txt", MIMEType: "text/plain", Data: []byte("abc")},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	s := string(raw)
+	mixed := strings.Index(s, "multipart/mixed")
+	related := strings.Index(s, "multipart/related")
+	alt := strings.Index(s, "multipart/alternative")
+	if mixed < 0 || related < mixed || alt < related {
+		t.Fatalf("expected mixed > related > alternative nesting: %q", s)
+	}
+	if !strings.Contains(s, "Content-Type: image/png\r\nContent-Transfer-Encoding: base64\r\nContent-ID: <img1@gogcli>\r\nContent-Disposition: inline; filename=\"chart.png\"") {
+		t.Fatalf("missing inline part headers: %q", s)
+	}
+	if !strings.Contains(s, "Content-Disposition: attachment; filename=\"x.txt\"") {
+		t.Fatalf("missing attachment header: %q", s)
+	}
+}
@@ -0,0 +1,539 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/filelock"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/timeparse"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailScheduledPending  = "pending"
+	gmailScheduledSent     = "sent"
+	gmailScheduledFailed   = "failed"
+	gmailScheduledCanceled = "canceled"
+
+	// gmailScheduledRetry is reported (not stored) when a send failed but
+	// stays pending for the next run.
+	gmailScheduledRetry = "retry"
+
+	gmailScheduledMaxAttempts = 3
+)
+
+// gmailScheduledWait sleeps between daemon passes; tests replace it to stop
+// the loop.
+var gmailScheduledWait = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// gmailScheduledSend is one draft waiting in the local schedule. The Gmail
+// API has no scheduled send, so the draft sits in Drafts until
+// `gmail scheduled run` sends it.
+type gmailScheduledSend struct {
+	DraftID     string   `json:"draftId"`
+	Account     string   `json:"account"`
+	SendAtMs    int64    `json:"sendAtMs"`
+	CreatedAtMs int64    `json:"createdAtMs"`
+	Subject     string   `json:"subject,omitempty"`
+	To          []string `json:"to,omitempty"`
+	ThreadID    string   `json:"threadId,omitempty"`
+	Status      string   `json:"status"`
+	Attempts    int      `json:"attempts,omitempty"`
+	LastError   string   `json:"lastError,omitempty"`
+	MessageID   string   `json:"messageId,omitempty"`
+	SentAtMs    int64    `json:"sentAtMs,omitempty"`
+}
+
+type gmailScheduledResult struct {
+	DraftID   string `json:"draftId"`
+	Status    string `json:"status"`
+	Subject   string `json:"subject,omitempty"`
+	MessageID string `json:"messageId,omitempty"`
+	ThreadID  string `json:"threadId,omitempty"`
+	Attempts  int    `json:"attempts,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func parseGmailSendAt(value string, now time.Time) (time.Time, error) {
+	at, err := timeparse.ParseAt(value, now, time.Local)
+	if err != nil {
+		return time.Time{}, usagef("invalid --at: %v", err)
+	}
+	if !at.After(now) {
+		return time.Time{}, usagef("--at %s is in the past", at.Format(time.RFC3339))
+	}
+	return at, nil
+}
+
+func formatScheduledSendAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// gmailScheduleDir holds one JSON file per scheduled draft, so `send --at`,
+// `scheduled cancel` and a running daemon never rewrite each other's entries.
+func gmailScheduleDir(account string) (string, error) {
+	base, err := config.EnsureGmailScheduleDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, sanitizeAccountForPath(account))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail schedule dir: %w", err)
+	}
+	return dir, nil
+}
+
+// lockGmailSchedule serializes senders (and cancels) of one account's
+// schedule across processes, so overlapping cron runs or a daemon never send
+// the same draft twice.
+func lockGmailSchedule(dir string) (*filelock.Lock, error) {
+	return filelock.Acquire(filepath.Join(dir, ".lock"))
+}
+
+func gmailScheduledPath(dir, draftID string) (string, error) {
+	if draftID == "" || draftID != filepath.Base(draftID) || strings.HasPrefix(draftID, ".") {
+		return "", usagef("invalid draftId %q", draftID)
+	}
+	return filepath.Join(dir, draftID+".json"), nil
+}
+
+func saveGmailScheduled(dir string, item *gmailScheduledSend) error {
+	path, err := gmailScheduledPath(dir, item.DraftID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func loadGmailScheduled(dir, draftID string) (*gmailScheduledSend, error) {
+	path, err := gmailScheduledPath(dir, draftID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path inside config dir
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, usagef("no scheduled send for draft %s", draftID)
+		}
+		return nil, err
+	}
+	var item gmailScheduledSend
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return &item, nil
+}
+
+// listGmailScheduled returns the schedule ordered by send time.
+func listGmailScheduled(dir string) ([]*gmailScheduledSend, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*gmailScheduledSend, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		item, err := loadGmailScheduled(dir, strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].SendAtMs != items[j].SendAtMs {
+			return items[i].SendAtMs < items[j].SendAtMs
+		}
+		return items[i].DraftID < items[j].DraftID
+	})
+	return items, nil
+}
+
+// scheduleGmailSend stores the message as a draft and queues it locally.
+func scheduleGmailSend(ctx context.Context, u *ui.UI, svc *gmail.Service, account string, opts sendMessageOptions, batch sendBatch, sendAt time.Time) error {
+	msg, err := buildSendMessage(opts, batch, opts.BodyHTML)
+	if err != nil {
+		return err
+	}
+	draft, err := svc.Users.Drafts.Create("me", &gmail.Draft{Message: msg}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	item := &gmailScheduledSend{
+		DraftID:     draft.Id,
+		Account:     account,
+		SendAtMs:    sendAt.UnixMilli(),
+		CreatedAtMs: time.Now().UnixMilli(),
+		Subject:     strings.TrimSpace(opts.Subject),
+		To:          batch.To,
+		ThreadID:    msg.ThreadId,
+		Status:      gmailScheduledPending,
+	}
+	if draft.Message != nil && draft.Message.ThreadId != "" {
+		item.ThreadID = draft.Message.ThreadId
+	}
+	dir, err := gmailScheduleDir(account)
+	if err != nil {
+		return err
+	}
+	if err := saveGmailScheduled(dir, item); err != nil {
+		return fmt.Errorf("draft %s created but not scheduled: %w", draft.Id, err)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"draftId":  item.DraftID,
+			"threadId": item.ThreadID,
+			"sendAt":   formatScheduledSendAt(sendAt),
+			"from":     opts.FromAddr,
+		})
+	}
+	u.Out().Printf("draft_id\t%s", item.DraftID)
+	u.Out().Printf("send_at\t%s", formatScheduledSendAt(sendAt))
+	u.Err().Println("Scheduled locally; run 'gog gmail scheduled run' (cron or --daemon) to send it.")
+	return nil
+}
+
+// sendDueGmailScheduled sends every pending draft whose time has come.
+// Failures are retried on later runs until gmailScheduledMaxAttempts, except
+// when the draft no longer exists.
+func sendDueGmailScheduled(ctx context.Context, svc *gmail.Service, dir string, now time.Time, report func(gmailScheduledResult) error) error {
+	lock, err := lockGmailSchedule(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	items, err := listGmailScheduled(dir)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Status != gmailScheduledPending || item.SendAtMs > now.UnixMilli() {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Re-read right before sending: the entry may have been sent or
+		// canceled since the list was taken.
+		item, err = loadGmailScheduled(dir, item.DraftID)
+		if err != nil || item.Status != gmailScheduledPending {
+			continue
+		}
+
+		item.Attempts++
+		result := gmailScheduledResult{DraftID: item.DraftID, Subject: item.Subject, Attempts: item.Attempts}
+		msg, sendErr := sendGmailDraft(ctx, svc, item.DraftID)
+		switch {
+		case sendErr == nil:
+			item.Status = gmailScheduledSent
+			item.MessageID = msg.Id
+			item.ThreadID = msg.ThreadId
+			item.SentAtMs = time.Now().UnixMilli()
+			item.LastError = ""
+			result.Status = gmailScheduledSent
+			result.MessageID = msg.Id
+			result.ThreadID = msg.ThreadId
+		case isNotFoundAPIError(sendErr):
+			item.Status = gmailScheduledFailed
+			item.LastError = "draft no longer exists"
+			result.Status = gmailScheduledFailed
+			result.Error = item.LastError
+		default:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			item.LastError = sendErr.Error()
+			if item.Attempts >= gmailScheduledMaxAttempts {
+				item.Status = gmailScheduledFailed
+			}
+			result.Status = gmailScheduledRetry
+			if item.Status == gmailScheduledFailed {
+				result.Status = gmailScheduledFailed
+			}
+			result.Error = item.LastError
+		}
+		if err := saveGmailScheduled(dir, item); err != nil {
+			return err
+		}
+		if err := report(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type GmailScheduledCmd struct {
+	List   GmailScheduledListCmd   `cmd:"" name:"list" aliases:"ls" help:"List scheduled sends"`
+	Cancel GmailScheduledCancelCmd `cmd:"" name:"cancel" aliases:"rm,delete" help:"Cancel a scheduled send"`
+	Run    GmailScheduledRunCmd    `cmd:"" name:"run" help:"Send drafts that are due (from cron, or keep running with --daemon)"`
+}
+
+type GmailScheduledListCmd struct {
+	All bool `name:"all" help:"Include sent and canceled entries"`
+}
+
+func (c *GmailScheduledListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	dir, err := gmailScheduleDir(account)
+	if err != nil {
+		return err
+	}
+	items, err := listGmailScheduled(dir)
+	if err != nil {
+		return err
+	}
+	if !c.All {
+		kept := items[:0]
+		for _, item := range items {
+			if item.Status == gmailScheduledPending || item.Status == gmailScheduledFailed {
+				kept = append(kept, item)
+			}
+		}
+		items = kept
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"scheduled": items})
+	}
+	if len(items) == 0 {
+		u.Err().Println("No scheduled sends")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "DRAFT_ID\tSEND_AT\tSTATUS\tTO\tSUBJECT")
+	for _, item := range items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			item.DraftID,
+			formatUnixMillis(item.SendAtMs),
+			item.Status,
+			strings.Join(item.To, ", "),
+			sanitizeTab(item.Subject),
+		)
+	}
+	return nil
+}
+
+type GmailScheduledCancelCmd struct {
+	DraftID   string `arg:"" name:"draftId" help:"Draft ID of the scheduled send"`
+	KeepDraft bool   `name:"keep-draft" help:"Keep the draft in Drafts instead of deleting it"`
+}
+
+func (c *GmailScheduledCancelCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	draftID := strings.TrimSpace(c.DraftID)
+	if draftID == "" {
+		return usage("empty draftId")
+	}
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	dir, err := gmailScheduleDir(account)
+	if err != nil {
+		return err
+	}
+	item, err := loadGmailScheduled(dir, draftID)
+	if err != nil {
+		return err
+	}
+	if item.Status == gmailScheduledSent || item.Status == gmailScheduledCanceled {
+		return usagef("scheduled send %s is already %s", draftID, item.Status)
+	}
+	if c.KeepDraft {
+		if err := dryRunExit(ctx, flags, "gmail.scheduled.cancel", map[string]any{
+			"draft_id":   draftID,
+			"keep_draft": true,
+		}); err != nil {
+			return err
+		}
+	} else if err := confirmDestructive(ctx, flags, fmt.Sprintf("cancel scheduled send and delete gmail draft %s", draftID)); err != nil {
+		return err
+	}
+
+	// Re-check under the schedule lock: a running sender may have sent the
+	// draft while we were waiting for confirmation.
+	lock, err := lockGmailSchedule(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+	if item, err = loadGmailScheduled(dir, draftID); err != nil {
+		return err
+	}
+	if item.Status == gmailScheduledSent || item.Status == gmailScheduledCanceled {
+		return usagef("scheduled send %s is already %s", draftID, item.Status)
+	}
+
+	if !c.KeepDraft {
+		svc, err := newGmailService(ctx, account)
+		if err != nil {
+			return err
+		}
+		if err := svc.Users.Drafts.Delete("me", draftID).Context(ctx).Do(); err != nil && !isNotFoundAPIError(err) {
+			return err
+		}
+	}
+
+	item.Status = gmailScheduledCanceled
+	if err := saveGmailScheduled(dir, item); err != nil {
+		return err
+	}
+	return writeResult(ctx, u,
+		kv("canceled", true),
+		kv("draftId", draftID),
+		kv("draftDeleted", !c.KeepDraft),
+	)
+}
+
+type GmailScheduledRunCmd struct {
+	Daemon   bool          `name:"daemon" help:"Keep running and send drafts as they come due"`
+	Interval time.Duration `name:"interval" help:"How often --daemon checks the schedule" default:"1m"`
+}
+
+func (c *GmailScheduledRunCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if c.Daemon && c.Interval <= 0 {
+		return usage("--interval must be > 0")
+	}
+	dir, err := gmailScheduleDir(account)
+	if err != nil {
+		return err
+	}
+
+	if flags != nil && flags.DryRun {
+		items, listErr := listGmailScheduled(dir)
+		if listErr != nil {
+			return listErr
+		}
+		now := time.Now().UnixMilli()
+		due := []string{}
+		for _, item := range items {
+			if item.Status == gmailScheduledPending && item.SendAtMs <= now {
+				due = append(due, item.DraftID)
+			}
+		}
+		return dryRunExit(ctx, flags, "gmail.scheduled.run", map[string]any{"due": due})
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	if c.Daemon {
+		return c.runDaemon(ctx, u, svc, dir)
+	}
+
+	results := []gmailScheduledResult{}
+	if err := sendDueGmailScheduled(ctx, svc, dir, time.Now(), func(r gmailScheduledResult) error {
+		results = append(results, r)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status != gmailScheduledSent {
+			failed++
+		}
+	}
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"results": results}); err != nil {
+			return err
+		}
+	} else if len(results) == 0 {
+		u.Err().Println("No scheduled sends due")
+	} else {
+		w, flush := tableWriter(ctx)
+		fmt.Fprintln(w, "DRAFT_ID\tSTATUS\tMESSAGE_ID\tERROR")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.DraftID, r.Status, r.MessageID, sanitizeTab(r.Error))
+		}
+		flush()
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d scheduled sends failed", failed, len(results))
+	}
+	return nil
+}
+
+// runDaemon reports outcomes as they happen (NDJSON with --json) and only
+// stops when ctx is canceled.
+func (c *GmailScheduledRunCmd) runDaemon(ctx context.Context, u *ui.UI, svc *gmail.Service, dir string) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	report := func(r gmailScheduledResult) error {
+		if outfmt.IsJSON(ctx) {
+			return enc.Encode(r)
+		}
+		switch r.Status {
+		case gmailScheduledSent:
+			u.Out().Printf("%s\t%s\t%s", r.Status, r.DraftID, r.MessageID)
+		default:
+			u.Out().Printf("%s\t%s\t%s", r.Status, r.DraftID, sanitizeTab(r.Error))
+		}
+		return nil
+	}
+
+	u.Err().Printf("scheduled: checking every %s", c.Interval)
+	for {
+		if err := sendDueGmailScheduled(ctx, svc, dir, time.Now(), report); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			u.Err().Printf("scheduled: run failed: %v", err)
+		}
+		if err := gmailScheduledWait(ctx, c.Interval); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+type gmailScheduledTestServer struct {
+	mu      sync.Mutex
+	created int
+	sent    []string
+	deleted []string
+}
+
+func newGmailScheduledTestServer(t *testing.T) *gmailScheduledTestServer {
+	t.Helper()
+	setupGmailWatchOutboxTest(t)
+
+	s := &gmailScheduledTestServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		switch {
+		case path == "/settings/sendAs" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"sendAs": []map[string]any{}})
+		case path == "/drafts" && r.Method == http.MethodPost:
+			var draft gmail.Draft
+			_ = json.NewDecoder(r.Body).Decode(&draft)
+			if draft.Message == nil || draft.Message.Raw == "" {
+				http.Error(w, "missing raw", http.StatusBadRequest)
+				return
+			}
+			s.created++
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "r1", "message": map[string]any{"id": "m0", "threadId": "t0"}})
+		case path == "/drafts/send" && r.Method == http.MethodPost:
+			var draft gmail.Draft
+			_ = json.NewDecoder(r.Body).Decode(&draft)
+			switch draft.Id {
+			case "gone":
+				http.Error(w, `{"error":{"code":404,"message":"not found"}}`, http.StatusNotFound)
+				return
+			case "flaky":
+				http.Error(w, `{"error":{"code":500,"message":"backend"}}`, http.StatusInternalServerError)
+				return
+			}
+			s.sent = append(s.sent, draft.Id)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "sent-" + draft.Id, "threadId": "t0"})
+		case strings.HasPrefix(path, "/drafts/") && r.Method == http.MethodDelete:
+			s.deleted = append(s.deleted, strings.TrimPrefix(path, "/drafts/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	stubGmailService(t, srv)
+	return s
+}
+
+func scheduleGmailTestItem(t *testing.T, draftID string, sendAt time.Time) string {
+	t.Helper()
+	dir, err := gmailScheduleDir("a@b.com")
+	if err != nil {
+		t.Fatalf("dir: %v", err)
+	}
+	if err := saveGmailScheduled(dir, &gmailScheduledSend{
+		DraftID:  draftID,
+		Account:  "a@b.com",
+		SendAtMs: sendAt.UnixMilli(),
+		Subject:  "S " + draftID,
+		Status:   gmailScheduledPending,
+	}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	return dir
+}
+
+func runGmailScheduledCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out string
+	var runErr error
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			runErr = Execute(append([]string{"--json", "--account", "a@b.com", "gmail"}, args...))
+		})
+	})
+	return out, runErr
+}
+
+func TestGmailSendAt_CreatesDraftAndSchedules(t *testing.T) {
+	srv := newGmailScheduledTestServer(t)
+
+	out, err := runGmailScheduledCmd(t, "send", "--to", "x@example.com", "--subject", "Later", "--body", "hi", "--at", "in 2h")
+	if err != nil {
+		t.Fatalf("send --at: %v", err)
+	}
+	var resp struct {
+		DraftID string `json:"draftId"`
+		SendAt  string `json:"sendAt"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("json: %v (%q)", err, out)
+	}
+	if resp.DraftID != "r1" || resp.SendAt == "" || srv.created != 1 || len(srv.sent) != 0 {
+		t.Fatalf("expected a draft and no send: resp=%+v created=%d sent=%v", resp, srv.created, srv.sent)
+	}
+
+	dir, err := gmailScheduleDir("a@b.com")
+	if err != nil {
+		t.Fatalf("dir: %v", err)
+	}
+	item, err := loadGmailScheduled(dir, "r1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	wantAt := time.Now().Add(2 * time.Hour)
+	if item.Status != gmailScheduledPending || item.Subject != "Later" || strings.Join(item.To, ",") != "x@example.com" ||
+		item.SendAtMs < wantAt.Add(-time.Minute).UnixMilli() || item.SendAtMs > wantAt.UnixMilli() {
+		t.Fatalf("unexpected schedule entry: %+v", item)
+	}
+
+	// Not due yet: run is a no-op.
+	out, err = runGmailScheduledCmd(t, "scheduled", "run")
+	if err != nil || !strings.Contains(out, `"results": []`) || len(srv.sent) != 0 {
+		t.Fatalf("expected nothing due: err=%v out=%q sent=%v", err, out, srv.sent)
+	}
+
+	if _, err := runGmailScheduledCmd(t, "send", "--to", "x@example.com", "--subject", "S", "--body", "b", "--at", "2001-01-01T09:00:00Z"); err == nil {
+		t.Fatalf("expected past --at to be rejected")
+	}
+	if _, err := runGmailScheduledCmd(t, "send", "--to", "x@example.com", "--subject", "S", "--body-html", "<p>b</p>", "--track", "--at", "in 1h"); err == nil {
+		t.Fatalf("expected --at with --track to be rejected")
+	}
+}
+
+func TestGmailScheduledRun_SendsDueAndReportsFailures(t *testing.T) {
+	srv := newGmailScheduledTestServer(t)
+	now := time.Now()
+	dir := scheduleGmailTestItem(t, "due", now.Add(-time.Minute))
+	scheduleGmailTestItem(t, "gone", now.Add(-2*time.Minute))
+	scheduleGmailTestItem(t, "flaky", now.Add(-3*time.Minute))
+	scheduleGmailTestItem(t, "later", now.Add(time.Hour))
+
+	out, err := runGmailScheduledCmd(t, "scheduled", "run")
+	if err == nil || !strings.Contains(err.Error(), "2 of 3 scheduled sends failed") {
+		t.Fatalf("expected partial failure, got %v", err)
+	}
+	var resp struct {
+		Results []gmailScheduledResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("json: %v (%q)", err, out)
+	}
+	statuses := map[string]string{}
+	for _, r := range resp.Results {
+		statuses[r.DraftID] = r.Status
+	}
+	if statuses["due"] != gmailScheduledSent || statuses["gone"] != gmailScheduledFailed || statuses["flaky"] != gmailScheduledRetry || len(statuses) != 3 {
+		t.Fatalf("unexpected results: %+v", resp.Results)
+	}
+	if strings.Join(srv.sent, ",") != "due" {
+		t.Fatalf("unexpected sends: %v", srv.sent)
+	}
+
+	due, _ := loadGmailScheduled(dir, "due")
+	flaky, _ := loadGmailScheduled(dir, "flaky")
+	if due.Status != gmailScheduledSent || due.MessageID != "sent-due" || flaky.Status != gmailScheduledPending || flaky.Attempts != 1 {
+		t.Fatalf("unexpected stored state: due=%+v flaky=%+v", due, flaky)
+	}
+
+	// Transient failures give up after gmailScheduledMaxAttempts.
+	for i := 1; i < gmailScheduledMaxAttempts; i++ {
+		_, _ = runGmailScheduledCmd(t, "scheduled", "run")
+	}
+	flaky, _ = loadGmailScheduled(dir, "flaky")
+	if flaky.Status != gmailScheduledFailed || flaky.Attempts != gmailScheduledMaxAttempts {
+		t.Fatalf("expected flaky to fail permanently: %+v", flaky)
+	}
+
+	out, err = runGmailScheduledCmd(t, "scheduled", "list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var list struct {
+		Scheduled []gmailScheduledSend `json:"scheduled"`
+	}
+	if err := json.Unmarshal([]byte(out), &list); err != nil {
+		t.Fatalf("json: %v (%q)", err, out)
+	}
+	ids := make([]string, 0, len(list.Scheduled))
+	for _, item := range list.Scheduled {
+		ids = append(ids, item.DraftID)
+	}
+	if strings.Join(ids, ",") != "flaky,gone,later" {
+		t.Fatalf("list should hide sent entries and order by send time: %v", ids)
+	}
+}
+
+func TestGmailScheduledRun_WaitsForConcurrentRun(t *testing.T) {
+	srv := newGmailScheduledTestServer(t)
+	dir := scheduleGmailTestItem(t, "due", time.Now().Add(-time.Minute))
+	svc, err := newGmailService(context.Background(), "a@b.com")
+	if err != nil {
+		t.Fatalf("service: %v", err)
+	}
+
+	// Another run holds the schedule and sends the draft meanwhile.
+	lock, err := lockGmailSchedule(dir)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	var results []gmailScheduledResult
+	done := make(chan error, 1)
+	go func() {
+		done <- sendDueGmailScheduled(context.Background(), svc, dir, time.Now(), func(r gmailScheduledResult) error {
+			results = append(results, r)
+			return nil
+		})
+	}()
+	item, _ := loadGmailScheduled(dir, "due")
+	item.Status = gmailScheduledSent
+	item.MessageID = "sent-elsewhere"
+	if err := saveGmailScheduled(dir, item); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	_ = lock.Unlock()
+
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(results) != 0 || len(srv.sent) != 0 {
+		t.Fatalf("draft must not be sent twice: results=%+v sent=%v", results, srv.sent)
+	}
+	if item, _ = loadGmailScheduled(dir, "due"); item.Status != gmailScheduledSent || item.MessageID != "sent-elsewhere" {
+		t.Fatalf("stored state was overwritten: %+v", item)
+	}
+}
+
+func TestGmailScheduledRun_Daemon(t *testing.T) {
+	srv := newGmailScheduledTestServer(t)
+	scheduleGmailTestItem(t, "due", time.Now().Add(-time.Minute))
+
+	origWait := gmailScheduledWait
+	t.Cleanup(func() { gmailScheduledWait = origWait })
+	waits := 0
+	gmailScheduledWait = func(context.Context, time.Duration) error {
+		waits++
+		if waits == 1 {
+			scheduleGmailTestItem(t, "next", time.Now().Add(-time.Second))
+			return nil
+		}
+		return context.Canceled
+	}
+
+	out, err := runGmailScheduledCmd(t, "scheduled", "run", "--daemon", "--interval", "1s")
+	if err != nil {
+		t.Fatalf("daemon: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"draftId":"due"`) || !strings.Contains(lines[1], `"draftId":"next"`) {
+		t.Fatalf("expected one NDJSON line per send, got %q", out)
+	}
+	if strings.Join(srv.sent, ",") != "due,next" {
+		t.Fatalf("unexpected sends: %v", srv.sent)
+	}
+}
+
+func TestGmailScheduledCancel(t *testing.T) {
+	srv := newGmailScheduledTestServer(t)
+	dir := scheduleGmailTestItem(t, "d1", time.Now().Add(time.Hour))
+	scheduleGmailTestItem(t, "d2", time.Now().Add(time.Hour))
+
+	if _, err := runGmailScheduledCmd(t, "scheduled", "cancel", "d1", "--force"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if _, err := runGmailScheduledCmd(t, "scheduled", "cancel", "d2", "--keep-draft"); err != nil {
+		t.Fatalf("cancel --keep-draft: %v", err)
+	}
+	if strings.Join(srv.deleted, ",") != "d1" {
+		t.Fatalf("only d1 should be deleted, got %v", srv.deleted)
+	}
+	for _, id := range []string{"d1", "d2"} {
+		if item, _ := loadGmailScheduled(dir, id); item.Status != gmailScheduledCanceled {
+			t.Fatalf("%s not canceled: %+v", id, item)
+		}
+	}
+	if _, err := runGmailScheduledCmd(t, "scheduled", "cancel", "d1", "--force"); err == nil {
+		t.Fatalf("expected canceling twice to fail")
+	}
+	if _, err := runGmailScheduledCmd(t, "scheduled", "cancel", "../x", "--force"); err == nil {
+		t.Fatalf("expected path-like draft IDs to be rejected")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/mail"
 	"os"
 	"strings"
+	"time"
 
 	"google.golang.org/api/gmail/v1"
 
@@ -22,9 +23,10 @@ type GmailSendCmd struct {
 	Cc               string   `name:"cc" help:"CC recipients (comma-separated)"`
 	Bcc              string   `name:"bcc" help:"BCC recipients (comma-separated)"`
 	Subject          string   `name:"subject" help:"Subject (required)"`
-	Body             string   `name:"body" help:"Body (plain text; required unless --body-html is set)"`
-	BodyFile         string   `name:"body-file" help:"Body file path (plain text; '-' for stdin)"`
+	Body             string   `name:"body" help:"Body (plain text; required unless --body-html or --body-markdown is set)"`
+	BodyFile         string   `name:"body-file" help:"Body file path (plain text, or Markdown for .md/.markdown; '-' for stdin)"`
 	BodyHTML         string   `name:"body-html" help:"Body (HTML; optional)"`
+	BodyMarkdown     string   `name:"body-markdown" help:"Body (Markdown; sent as HTML with a plain-text alternative, local images inlined)"`
 	ReplyToMessageID string   `name:"reply-to-message-id" aliases:"in-reply-to" help:"Reply to Gmail message ID (sets In-Reply-To/References and thread)"`
 	ThreadID         string   `name:"thread-id" help:"Reply within a Gmail thread (uses latest message for headers)"`
 	ReplyAll         bool     `name:"reply-all" help:"Auto-populate recipients from original message (requires --reply-to-message-id or --thread-id)"`
@@ -34,6 +36,8 @@ type GmailSendCmd struct {
 	Track            bool     `name:"track" help:"Enable open tracking (requires tracking setup)"`
 	TrackSplit       bool     `name:"track-split" help:"Send tracked messages separately per recipient"`
 	Quote            bool     `name:"quote" help:"Include quoted original message in reply (requires --reply-to-message-id or --thread-id)"`
+	Signature        bool     `name:"signature" help:"Append the send-as signature (of --from or the account) to the text and HTML parts"`
+	At               string   `name:"at" help:"Schedule instead of sending now: creates a draft sent later by 'gmail scheduled run' (e.g. 'tomorrow 9am', 'in 2h', RFC3339)"`
 }
 
 type sendBatch struct {
@@ -68,10 +72,11 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	replyToMessageID := normalizeGmailMessageID(c.ReplyToMessageID)
 	threadID := normalizeGmailThreadID(c.ThreadID)
 
-	body, err := resolveBodyInput(c.Body, c.BodyFile)
+	composed, err := resolveComposeBody(c.Body, c.BodyFile, c.BodyHTML, c.BodyMarkdown)
 	if err != nil {
 		return err
 	}
+	body, bodyHTML := composed.Text, composed.HTML
 
 	if replyToMessageID != "" && threadID != "" {
 		return usage("use only one of --reply-to-message-id or --thread-id")
@@ -94,14 +99,24 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	if strings.TrimSpace(c.Subject) == "" {
 		return usage("required: --subject")
 	}
-	if strings.TrimSpace(body) == "" && strings.TrimSpace(c.BodyHTML) == "" {
-		return usage("required: --body, --body-file, or --body-html")
+	if strings.TrimSpace(body) == "" && strings.TrimSpace(bodyHTML) == "" {
+		return usage("required: --body, --body-file, --body-html, or --body-markdown")
 	}
 	if c.TrackSplit && !c.Track {
 		return usage("--track-split requires --track")
 	}
-	if c.Track && strings.TrimSpace(c.BodyHTML) == "" {
-		return fmt.Errorf("--track requires --body-html (pixel must be in HTML)")
+	if c.Track && strings.TrimSpace(bodyHTML) == "" {
+		return fmt.Errorf("--track requires --body-html or --body-markdown (pixel must be in HTML)")
+	}
+	var sendAt time.Time
+	if strings.TrimSpace(c.At) != "" {
+		if c.Track {
+			return usage("--at cannot be combined with --track")
+		}
+		sendAt, err = parseGmailSendAt(c.At, time.Now())
+		if err != nil {
+			return err
+		}
 	}
 
 	attachPaths := make([]string, 0, len(c.Attach))
@@ -124,10 +139,13 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		"reply_to":            strings.TrimSpace(c.ReplyTo),
 		"from":                strings.TrimSpace(c.From),
 		"body_len":            len(strings.TrimSpace(body)),
-		"body_html_len":       len(strings.TrimSpace(c.BodyHTML)),
+		"body_html_len":       len(strings.TrimSpace(bodyHTML)),
 		"attachments":         attachPaths,
+		"inline_images":       mailAttachmentPaths(composed.Inline),
 		"track":               c.Track,
 		"track_split":         c.TrackSplit,
+		"signature":           c.Signature,
+		"send_at":             formatScheduledSendAt(sendAt),
 	}); dryRunErr != nil {
 		return dryRunErr
 	}
@@ -142,48 +160,9 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	sendAsList, sendAsListErr := listSendAs(ctx, svc)
-
-	// Determine the From address
-	fromAddr := account
-	sendingEmail := account // The email we're sending from (without display name)
-	if fromEmail := strings.TrimSpace(c.From); fromEmail != "" {
-		// Validate that this is a configured and verified send-as alias.
-		var sa *gmail.SendAs
-		if sendAsListErr == nil {
-			sa = findSendAsByEmail(sendAsList, fromEmail)
-			if sa == nil {
-				return fmt.Errorf("invalid --from address %q: not found in send-as settings", fromEmail)
-			}
-		} else {
-			// Fallback: preserve legacy behavior if we cannot list settings.
-			var getErr error
-			sa, getErr = svc.Users.Settings.SendAs.Get("me", fromEmail).Context(ctx).Do()
-			if getErr != nil {
-				return fmt.Errorf("invalid --from address %q: %w", fromEmail, getErr)
-			}
-		}
-
-		if sa.VerificationStatus != gmailVerificationAccepted {
-			return fmt.Errorf("--from address %q is not verified (status: %s)", fromEmail, sa.VerificationStatus)
-		}
-
-		sendingEmail = fromEmail
-		fromAddr = fromEmail
-
-		if displayName := strings.TrimSpace(sa.DisplayName); displayName != "" {
-			fromAddr = displayName + " <" + fromEmail + ">"
-		}
-	} else {
-		// No --from specified: best-effort look up the primary account's display name.
-		displayName := ""
-		if sendAsListErr == nil {
-			displayName = primaryDisplayNameFromSendAsList(sendAsList, account)
-		}
-		if displayName != "" {
-			fromAddr = displayName + " <" + account + ">"
-		}
-		// If lookup fails, we just use the plain email address (no error)
+	fromAddr, sendingEmail, err := resolveSendFrom(ctx, svc, account, c.From)
+	if err != nil {
+		return err
 	}
 
 	// Fetch reply info (includes recipient headers for reply-all, and body for quoting)
@@ -192,7 +171,15 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
-	body, htmlBody := applyQuoteToBodies(body, c.BodyHTML, c.Quote, replyInfo)
+	if c.Signature {
+		signature, sigErr := fetchSendAsSignature(ctx, svc, sendingEmail)
+		if sigErr != nil {
+			return sigErr
+		}
+		body, bodyHTML = appendSignature(body, bodyHTML, signature)
+	}
+
+	body, htmlBody := applyQuoteToBodies(body, bodyHTML, c.Quote, replyInfo)
 
 	// Determine recipients
 	var toRecipients, ccRecipients []string
@@ -216,10 +203,11 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 
 	bccRecipients := splitCSV(c.Bcc)
 
-	atts := make([]mailAttachment, 0, len(attachPaths))
+	atts := make([]mailAttachment, 0, len(attachPaths)+len(composed.Inline))
 	for _, p := range attachPaths {
 		atts = append(atts, mailAttachment{Path: p})
 	}
+	atts = append(atts, composed.Inline...)
 
 	var trackingCfg *tracking.Config
 	if c.Track {
@@ -230,7 +218,7 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	batches := buildSendBatches(toRecipients, ccRecipients, bccRecipients, c.Track, c.TrackSplit)
-	results, err := sendGmailBatches(ctx, svc, sendMessageOptions{
+	opts := sendMessageOptions{
 		FromAddr:    fromAddr,
 		ReplyTo:     c.ReplyTo,
 		Subject:     c.Subject,
@@ -240,7 +228,11 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		Attachments: atts,
 		Track:       c.Track,
 		TrackingCfg: trackingCfg,
-	}, batches)
+	}
+	if !sendAt.IsZero() {
+		return scheduleGmailSend(ctx, u, svc, account, opts, batches[0], sendAt)
+	}
+	results, err := sendGmailBatches(ctx, svc, opts, batches)
 	if err != nil {
 		return err
 	}
@@ -248,6 +240,55 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	return writeSendResults(ctx, u, fromAddr, results)
 }
 
+// resolveSendFrom returns the From header (with display name when known) and
+// the bare sending address. A non-empty from must be a verified send-as alias.
+func resolveSendFrom(ctx context.Context, svc *gmail.Service, account, from string) (string, string, error) {
+	sendAsList, sendAsListErr := listSendAs(ctx, svc)
+
+	fromAddr := account
+	sendingEmail := account // The email we're sending from (without display name)
+	if fromEmail := strings.TrimSpace(from); fromEmail != "" {
+		// Validate that this is a configured and verified send-as alias.
+		var sa *gmail.SendAs
+		if sendAsListErr == nil {
+			sa = findSendAsByEmail(sendAsList, fromEmail)
+			if sa == nil {
+				return "", "", fmt.Errorf("invalid --from address %q: not found in send-as settings", fromEmail)
+			}
+		} else {
+			// Fallback: preserve legacy behavior if we cannot list settings.
+			var getErr error
+			sa, getErr = svc.Users.Settings.SendAs.Get("me", fromEmail).Context(ctx).Do()
+			if getErr != nil {
+				return "", "", fmt.Errorf("invalid --from address %q: %w", fromEmail, getErr)
+			}
+		}
+
+		if sa.VerificationStatus != gmailVerificationAccepted {
+			return "", "", fmt.Errorf("--from address %q is not verified (status: %s)", fromEmail, sa.VerificationStatus)
+		}
+
+		sendingEmail = fromEmail
+		fromAddr = fromEmail
+
+		if displayName := strings.TrimSpace(sa.DisplayName); displayName != "" {
+			fromAddr = displayName + " <" + fromEmail + ">"
+		}
+	} else {
+		// No --from specified: best-effort look up the primary account's display name.
+		displayName := ""
+		if sendAsListErr == nil {
+			displayName = primaryDisplayNameFromSendAsList(sendAsList, account)
+		}
+		if displayName != "" {
+			fromAddr = displayName + " <" + account + ">"
+		}
+		// If lookup fails, we just use the plain email address (no error)
+	}
+
+	return fromAddr, sendingEmail, nil
+}
+
 func (c *GmailSendCmd) resolveTrackingConfig(account string, toRecipients, ccRecipients, bccRecipients []string, htmlBody string) (*tracking.Config, error) {
 	totalRecipients := len(toRecipients) + len(ccRecipients) + len(bccRecipients)
 	if totalRecipients != 1 && !c.TrackSplit {
@@ -347,11 +388,6 @@ func buildSendBatches(toRecipients, ccRecipients, bccRecipients []string, track,
 }
 
 func sendGmailBatches(ctx context.Context, svc *gmail.Service, opts sendMessageOptions, batches []sendBatch) ([]sendResult, error) {
-	reply := replyInfo{}
-	if opts.ReplyInfo != nil {
-		reply = *opts.ReplyInfo
-	}
-
 	results := make([]sendResult, 0, len(batches))
 	for _, batch := range batches {
 		htmlBody := opts.BodyHTML
@@ -372,30 +408,11 @@ func sendGmailBatches(ctx context.Context, svc *gmail.Service, opts sendMessageO
 			htmlBody = injectTrackingPixelHTML(htmlBody, pixelHTML)
 		}
 
-		raw, err := buildRFC822(mailOptions{
-			From:        opts.FromAddr,
-			To:          batch.To,
-			Cc:          batch.Cc,
-			Bcc:         batch.Bcc,
-			ReplyTo:     opts.ReplyTo,
-			Subject:     opts.Subject,
-			Body:        opts.Body,
-			BodyHTML:    htmlBody,
-			InReplyTo:   reply.InReplyTo,
-			References:  reply.References,
-			Attachments: opts.Attachments,
-		}, nil)
+		msg, err := buildSendMessage(opts, batch, htmlBody)
 		if err != nil {
 			return nil, err
 		}
 
-		msg := &gmail.Message{
-			Raw: base64.RawURLEncoding.EncodeToString(raw),
-		}
-		if reply.ThreadID != "" {
-			msg.ThreadId = reply.ThreadID
-		}
-
 		sent, err := svc.Users.Messages.Send("me", msg).Context(ctx).Do()
 		if err != nil {
 			return nil, err
@@ -416,6 +433,40 @@ func sendGmailBatches(ctx context.Context, svc *gmail.Service, opts sendMessageO
 	return results, nil
 }
 
+// buildSendMessage renders one batch as a raw Gmail message, threaded onto
+// the reply target when there is one.
+func buildSendMessage(opts sendMessageOptions, batch sendBatch, htmlBody string) (*gmail.Message, error) {
+	reply := replyInfo{}
+	if opts.ReplyInfo != nil {
+		reply = *opts.ReplyInfo
+	}
+
+	raw, err := buildRFC822(mailOptions{
+		From:        opts.FromAddr,
+		To:          batch.To,
+		Cc:          batch.Cc,
+		Bcc:         batch.Bcc,
+		ReplyTo:     opts.ReplyTo,
+		Subject:     opts.Subject,
+		Body:        opts.Body,
+		BodyHTML:    htmlBody,
+		InReplyTo:   reply.InReplyTo,
+		References:  reply.References,
+		Attachments: opts.Attachments,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &gmail.Message{
+		Raw: base64.RawURLEncoding.EncodeToString(raw),
+	}
+	if reply.ThreadID != "" {
+		msg.ThreadId = reply.ThreadID
+	}
+	return msg, nil
+}
+
 func writeSendResults(ctx context.Context, u *ui.UI, fromAddr string, results []sendResult) error {
 	if outfmt.IsJSON(ctx) {
 		if len(results) == 1 {
@@ -492,14 +543,20 @@ func firstRecipient(toRecipients, ccRecipients, bccRecipients []string) string {
 }
 
 func injectTrackingPixelHTML(htmlBody, pixelHTML string) string {
+	return appendToHTMLBody(htmlBody, pixelHTML)
+}
+
+// appendToHTMLBody inserts fragment at the end of the document body
+// (before </body> or </html> when present).
+func appendToHTMLBody(htmlBody, fragment string) string {
 	lower := strings.ToLower(htmlBody)
 	if i := strings.LastIndex(lower, "</body>"); i != -1 {
-		return htmlBody[:i] + pixelHTML + htmlBody[i:]
+		return htmlBody[:i] + fragment + htmlBody[i:]
 	}
 	if i := strings.LastIndex(lower, "</html>"); i != -1 {
-		return htmlBody[:i] + pixelHTML + htmlBody[i:]
+		return htmlBody[:i] + fragment + htmlBody[i:]
 	}
-	return htmlBody + pixelHTML
+	return htmlBody + fragment
 }
 
 // buildReplyAllRecipients constructs To and Cc lists for a reply-all.
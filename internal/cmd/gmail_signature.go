@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/htmltext"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailSignatureCmd struct {
+	Get GmailSignatureGetCmd `cmd:"" name:"get" aliases:"show" help:"Show the signature of a send-as address"`
+	Set GmailSignatureSetCmd `cmd:"" name:"set" aliases:"update" help:"Set the signature of a send-as address"`
+}
+
+type GmailSignatureGetCmd struct {
+	Email string `arg:"" name:"email" optional:"" help:"Send-as email (default: account)"`
+	HTML  bool   `name:"html" help:"Print the stored HTML instead of rendered text"`
+}
+
+func (c *GmailSignatureGetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	email := signatureEmail(c.Email, account)
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	sa, err := svc.Users.Settings.SendAs.Get("me", email).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"email":     sa.SendAsEmail,
+			"signature": sa.Signature,
+			"text":      htmltext.Text(sa.Signature),
+		})
+	}
+	if strings.TrimSpace(sa.Signature) == "" {
+		u.Err().Printf("No signature set for %s", sa.SendAsEmail)
+		return nil
+	}
+	if c.HTML {
+		u.Out().Println(sa.Signature)
+		return nil
+	}
+	u.Out().Println(htmltext.Text(sa.Signature))
+	return nil
+}
+
+type GmailSignatureSetCmd struct {
+	Email    string `arg:"" name:"email" optional:"" help:"Send-as email (default: account)"`
+	HTML     string `name:"html" help:"Signature as HTML"`
+	Markdown string `name:"markdown" aliases:"md" help:"Signature as Markdown (rendered to HTML)"`
+	File     string `name:"file" help:"Read the signature from a file (.md/.markdown is Markdown, anything else HTML; '-' for stdin)"`
+	Clear    bool   `name:"clear" help:"Remove the signature"`
+}
+
+func (c *GmailSignatureSetCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	email := signatureEmail(c.Email, account)
+
+	signature, err := c.resolveSignature()
+	if err != nil {
+		return err
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.signature.set", map[string]any{
+		"email":     email,
+		"signature": signature,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	current, err := svc.Users.Settings.SendAs.Get("me", email).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	current.Signature = signature
+	current.ForceSendFields = append(current.ForceSendFields, "Signature")
+	updated, err := svc.Users.Settings.SendAs.Update("me", email, current).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"email":     updated.SendAsEmail,
+			"signature": updated.Signature,
+		})
+	}
+	if signature == "" {
+		u.Out().Printf("Cleared signature for %s", updated.SendAsEmail)
+		return nil
+	}
+	u.Out().Printf("Updated signature for %s", updated.SendAsEmail)
+	return nil
+}
+
+// resolveSignature returns the signature HTML from exactly one of --html,
+// --markdown, --file or --clear.
+func (c *GmailSignatureSetCmd) resolveSignature() (string, error) {
+	sources := 0
+	for _, set := range []bool{c.HTML != "", c.Markdown != "", strings.TrimSpace(c.File) != "", c.Clear} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return "", usage("specify exactly one of --html, --markdown, --file, or --clear")
+	}
+
+	if c.Clear {
+		return "", nil
+	}
+	if c.HTML != "" {
+		return strings.TrimSpace(c.HTML), nil
+	}
+
+	content, markdown := c.Markdown, true
+	if c.Markdown == "" {
+		var err error
+		content, err = resolveBodyInput("", c.File)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(content) == "" {
+			return "", usage("signature file is empty (use --clear to remove the signature)")
+		}
+		markdown = isMarkdownPath(c.File)
+	}
+	if !markdown {
+		return strings.TrimSpace(content), nil
+	}
+	rendered, err := renderMarkdownFragment(content)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(rendered), nil
+}
+
+func signatureEmail(email, account string) string {
+	if email = strings.TrimSpace(email); email != "" {
+		return email
+	}
+	return account
+}
+
+// fetchSendAsSignature returns the HTML signature of the send-as address
+// that mail is sent from.
+func fetchSendAsSignature(ctx context.Context, svc *gmail.Service, email string) (string, error) {
+	sa, err := svc.Users.Settings.SendAs.Get("me", email).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("fetch signature for %s: %w", email, err)
+	}
+	return strings.TrimSpace(sa.Signature), nil
+}
+
+// appendSignature adds the signature to the text part (after the usual
+// "-- " delimiter) and to the HTML part, whichever are present.
+func appendSignature(textBody, htmlBody, signatureHTML string) (string, string) {
+	if strings.TrimSpace(signatureHTML) == "" {
+		return textBody, htmlBody
+	}
+	if strings.TrimSpace(textBody) != "" {
+		textBody = strings.TrimRight(textBody, "\r\n") + "\n\n-- \n" + htmltext.Text(signatureHTML) + "\n"
+	}
+	if strings.TrimSpace(htmlBody) != "" {
+		htmlBody = appendToHTMLBody(htmlBody, "<br><div class=\"gmail_signature\">-- <br>"+signatureHTML+"</div>")
+	}
+	return textBody, htmlBody
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+type gmailSignatureTestServer struct {
+	mu        sync.Mutex
+	signature string
+	raw       string
+}
+
+func newGmailSignatureTestServer(t *testing.T, signature string) *gmailSignatureTestServer {
+	t.Helper()
+
+	s := &gmailSignatureTestServer{signature: signature}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		sendAs := func() map[string]any {
+			return map[string]any{"sendAsEmail": "a@b.com", "isPrimary": true, "verificationStatus": "accepted", "signature": s.signature}
+		}
+		switch {
+		case path == "/settings/sendAs" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"sendAs": []map[string]any{sendAs()}})
+		case path == "/settings/sendAs/a@b.com" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(sendAs())
+		case path == "/settings/sendAs/a@b.com" && r.Method == http.MethodPut:
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			sig, ok := body["signature"].(string)
+			if !ok {
+				http.Error(w, "signature not sent", http.StatusBadRequest)
+				return
+			}
+			s.signature = sig
+			_ = json.NewEncoder(w).Encode(sendAs())
+		case path == "/messages/send" && r.Method == http.MethodPost:
+			var msg gmail.Message
+			_ = json.NewDecoder(r.Body).Decode(&msg)
+			s.raw = msg.Raw
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1", "threadId": "t1"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	stubGmailService(t, srv)
+	return s
+}
+
+func runGmailSignatureCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out string
+	var runErr error
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			runErr = Execute(append([]string{"--account", "a@b.com", "gmail"}, args...))
+		})
+	})
+	return out, runErr
+}
+
+func TestGmailSignatureSetAndGet(t *testing.T) {
+	srv := newGmailSignatureTestServer(t, "")
+
+	if _, err := runGmailSignatureCmd(t, "settings", "signature", "set", "--markdown", "**Ann Lee**\nBuilder at [Example](https://example.com)"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	want := "<p><strong>Ann Lee</strong><br>\nBuilder at <a href=\"https://example.com\">Example</a></p>"
+	if srv.signature != want {
+		t.Fatalf("unexpected stored signature %q", srv.signature)
+	}
+
+	out, err := runGmailSignatureCmd(t, "settings", "signature", "get")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if strings.TrimSpace(out) != "Ann Lee\nBuilder at Example [1]\n\n[1]: https://example.com" {
+		t.Fatalf("unexpected text output %q", out)
+	}
+
+	if _, err := runGmailSignatureCmd(t, "settings", "signature", "set", "--clear"); err != nil || srv.signature != "" {
+		t.Fatalf("clear: err=%v signature=%q", err, srv.signature)
+	}
+	if _, err := runGmailSignatureCmd(t, "settings", "signature", "set", "--html", "<b>x</b>", "--clear"); err == nil {
+		t.Fatalf("expected conflicting sources to be rejected")
+	}
+	if _, err := runGmailSignatureCmd(t, "settings", "signature", "set", "--markdown", "![logo](logo.png)"); err == nil {
+		t.Fatalf("expected local signature images to be rejected")
+	}
+}
+
+func TestGmailSend_SignatureAppendsToBothParts(t *testing.T) {
+	srv := newGmailSignatureTestServer(t, `<div>Ann Lee<br><a href="https://example.com">example.com</a></div>`)
+
+	if _, err := runGmailSignatureCmd(t, "send", "--to", "x@example.com", "--subject", "Hi", "--body", "Hello", "--body-html", "<html><body><p>Hello</p></body></html>", "--signature"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(srv.raw)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	msg := string(decoded)
+	if !strings.Contains(msg, "Hello\r\n\r\n-- \r\nAnn Lee\r\nexample.com\r\n") {
+		t.Fatalf("text part missing signature:\n%s", msg)
+	}
+	if !strings.Contains(msg, `<p>Hello</p><br><div class="gmail_signature">-- <br><div>Ann Lee<br><a href="https://example.com">example.com</a></div></div></body></html>`) {
+		t.Fatalf("html part missing signature:\n%s", msg)
+	}
+}
@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/timeparse"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailStatsCmd struct {
+	Query    string `name:"query" short:"q" help:"Gmail search query to analyze (default: all mail)"`
+	Since    string `name:"since" help:"Only messages newer than this (e.g. 90d, 6m, 1y, 2026-01-01; empty for all time)" default:"30d"`
+	By       string `name:"by" help:"Groupings (comma-separated): sender,domain,label,list,day,week" default:"sender,domain,label,list,week"`
+	Top      int    `name:"top" help:"Rows per grouping (time groupings are not limited)" default:"10"`
+	Max      int    `name:"max" aliases:"limit" help:"Max messages to scan (0 = no limit)" default:"5000"`
+	Suggest  bool   `name:"suggest" help:"Suggest a filter and the List-Unsubscribe link for top senders and lists"`
+	Timezone string `name:"timezone" short:"z" help:"Timezone for day/week buckets (IANA name). Default: local"`
+}
+
+// gmailStatsBucket is one row of a grouping.
+type gmailStatsBucket struct {
+	Key         string `json:"key"`
+	Count       int    `json:"count"`
+	Bytes       int64  `json:"bytes"`
+	Unsubscribe string `json:"unsubscribe,omitempty"`
+	Filter      string `json:"filter,omitempty"`
+}
+
+// gmailStatsMessage is the metadata stats needs from one message.
+type gmailStatsMessage struct {
+	From        string
+	ListID      string
+	Unsubscribe string
+	Labels      []string
+	Size        int64
+	Time        time.Time
+}
+
+var gmailStatsGroupings = []string{"sender", "domain", "label", "list", "day", "week"}
+
+var gmailStatsRelativePattern = regexp.MustCompile(`^(\d+)([dmy])$`)
+
+func (c *GmailStatsCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+
+	groupings, err := parseGmailStatsGroupings(c.By)
+	if err != nil {
+		return err
+	}
+	if c.Top <= 0 {
+		return usage("--top must be > 0")
+	}
+	if c.Max < 0 {
+		return usage("--max must be >= 0")
+	}
+	loc, err := resolveOutputLocation(c.Timezone, false)
+	if err != nil {
+		return err
+	}
+	query, err := gmailStatsQuery(c.Query, c.Since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	ids, truncated, err := listGmailStatsMessageIDs(ctx, svc, query, c.Max)
+	if err != nil {
+		return err
+	}
+	idToName := map[string]string{}
+	if groupings["label"] {
+		idToName, err = fetchLabelIDToName(svc)
+		if err != nil {
+			return err
+		}
+	}
+	messages, err := fetchGmailStatsMessages(ctx, svc, ids, idToName)
+	if err != nil {
+		return err
+	}
+
+	var totalBytes int64
+	for _, m := range messages {
+		totalBytes += m.Size
+	}
+	groups := aggregateGmailStats(messages, groupings, loc, c.Top, c.Suggest)
+
+	if outfmt.IsJSON(ctx) {
+		payload := map[string]any{
+			"query":      query,
+			"scanned":    len(messages),
+			"truncated":  truncated,
+			"totalBytes": totalBytes,
+		}
+		for _, name := range gmailStatsGroupings {
+			if groupings[name] {
+				payload[gmailStatsJSONKey(name)] = groups[name]
+			}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, payload)
+	}
+
+	u.Err().Printf("Scanned %d messages (%s) for %q", len(messages), formatBytes(totalBytes), query)
+	if truncated {
+		u.Err().Printf("Stopped at --max %d; narrow the query or raise --max for complete numbers", c.Max)
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	first := true
+	for _, name := range gmailStatsGroupings {
+		if !groupings[name] || len(groups[name]) == 0 {
+			continue
+		}
+		if !first {
+			u.Out().Println("")
+		}
+		first = false
+		w, flush := tableWriter(ctx)
+		fmt.Fprintf(w, "%s\tCOUNT\tSIZE\n", strings.ToUpper(name))
+		for _, b := range groups[name] {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", sanitizeTab(b.Key), b.Count, formatBytes(b.Bytes))
+		}
+		flush()
+	}
+	if c.Suggest {
+		printGmailStatsSuggestions(ctx, u, groups)
+	}
+	return nil
+}
+
+func printGmailStatsSuggestions(ctx context.Context, u *ui.UI, groups map[string][]gmailStatsBucket) {
+	var rows []gmailStatsBucket
+	for _, name := range []string{"sender", "list"} {
+		rows = append(rows, groups[name]...)
+	}
+	if len(rows) == 0 {
+		return
+	}
+	u.Out().Println("")
+	w, flush := tableWriter(ctx)
+	defer flush()
+	fmt.Fprintln(w, "SOURCE\tUNSUBSCRIBE\tFILTER")
+	for _, b := range rows {
+		unsubscribe := b.Unsubscribe
+		if unsubscribe == "" {
+			unsubscribe = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", sanitizeTab(b.Key), sanitizeTab(unsubscribe), b.Filter)
+	}
+}
+
+func gmailStatsJSONKey(grouping string) string {
+	switch grouping {
+	case "sender":
+		return "senders"
+	case "domain":
+		return "domains"
+	case "label":
+		return "labels"
+	case "list":
+		return "lists"
+	case "day":
+		return "days"
+	default:
+		return "weeks"
+	}
+}
+
+func parseGmailStatsGroupings(value string) (map[string]bool, error) {
+	out := map[string]bool{}
+	for _, part := range splitCSV(value) {
+		part = strings.ToLower(part)
+		switch part {
+		case "senders", "from":
+			part = "sender"
+		case "domains":
+			part = "domain"
+		case "labels":
+			part = "label"
+		case "lists", "list-id":
+			part = "list"
+		case "days":
+			part = "day"
+		case "weeks":
+			part = "week"
+		}
+		known := false
+		for _, g := range gmailStatsGroupings {
+			if g == part {
+				known = true
+			}
+		}
+		if !known {
+			return nil, usagef("unknown --by grouping %q (use %s)", part, strings.Join(gmailStatsGroupings, ","))
+		}
+		out[part] = true
+	}
+	if len(out) == 0 {
+		return nil, usage("--by needs at least one grouping")
+	}
+	return out, nil
+}
+
+// gmailStatsQuery combines --query and --since. Relative values (90d, 6m,
+// 1y) map to Gmail's newer_than:, anything else to after:<unix seconds>.
+func gmailStatsQuery(query, since string, now time.Time) (string, error) {
+	query = strings.TrimSpace(query)
+	since = strings.TrimSpace(since)
+	if since == "" {
+		return query, nil
+	}
+	var clause string
+	if gmailStatsRelativePattern.MatchString(strings.ToLower(since)) {
+		clause = "newer_than:" + strings.ToLower(since)
+	} else {
+		parsed, err := timeparse.ParseSince(since, now, time.Local)
+		if err != nil {
+			return "", usagef("invalid --since %q (use e.g. 90d, 6m, 1y, 24h or a date)", since)
+		}
+		clause = "after:" + strconv.FormatInt(parsed.Time.Unix(), 10)
+	}
+	return strings.TrimSpace(query + " " + clause), nil
+}
+
+func listGmailStatsMessageIDs(ctx context.Context, svc *gmail.Service, query string, maxMessages int) ([]string, bool, error) {
+	var ids []string
+	pageToken := ""
+	for {
+		call := svc.Users.Messages.List("me").
+			MaxResults(500).
+			Fields("messages(id),nextPageToken").
+			Context(ctx)
+		if query != "" {
+			call = call.Q(query)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, false, err
+		}
+		for _, m := range resp.Messages {
+			if m == nil || m.Id == "" {
+				continue
+			}
+			if maxMessages > 0 && len(ids) >= maxMessages {
+				return ids, true, nil
+			}
+			ids = append(ids, m.Id)
+		}
+		if resp.NextPageToken == "" || resp.NextPageToken == pageToken {
+			return ids, false, nil
+		}
+		pageToken = resp.NextPageToken
+	}
+}
+
+func fetchGmailStatsMessages(ctx context.Context, svc *gmail.Service, ids []string, idToName map[string]string) ([]gmailStatsMessage, error) {
+	const maxConcurrency = 10
+	out := make([]gmailStatsMessage, len(ids))
+	errs := make([]error, len(ids))
+
+	// A fixed pool of workers keeps the goroutine count bounded even when
+	// --max 0 scans the whole mailbox.
+	jobs := make(chan int)
+	fetch := func(idx int) {
+		messageID := ids[idx]
+		msg, err := svc.Users.Messages.Get("me", messageID).
+			Format("metadata").
+			MetadataHeaders("From", "List-Id", "List-Unsubscribe").
+			Fields("id,labelIds,internalDate,sizeEstimate,payload(headers)").
+			Context(ctx).
+			Do()
+		if err != nil {
+			errs[idx] = fmt.Errorf("message %s: %w", messageID, err)
+			return
+		}
+		labels := make([]string, 0, len(msg.LabelIds))
+		for _, lid := range msg.LabelIds {
+			if name, ok := idToName[lid]; ok {
+				labels = append(labels, name)
+			} else {
+				labels = append(labels, lid)
+			}
+		}
+		out[idx] = gmailStatsMessage{
+			From:        headerValue(msg.Payload, "From"),
+			ListID:      normalizeListID(headerValue(msg.Payload, "List-Id")),
+			Unsubscribe: bestUnsubscribeLink(msg.Payload),
+			Labels:      labels,
+			Size:        msg.SizeEstimate,
+			Time:        time.UnixMilli(msg.InternalDate),
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(maxConcurrency, len(ids)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				fetch(idx)
+			}
+		}()
+	}
+	for i := range ids {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// normalizeListID returns the list identifier from a List-Id header
+// ("Weekly news <news.example.com>" -> "news.example.com").
+func normalizeListID(value string) string {
+	value = strings.TrimSpace(value)
+	if open := strings.LastIndex(value, "<"); open >= 0 {
+		if end := strings.Index(value[open:], ">"); end > 0 {
+			return strings.ToLower(strings.TrimSpace(value[open+1 : open+end]))
+		}
+	}
+	return strings.ToLower(value)
+}
+
+func gmailStatsSender(from string) string {
+	if addr, err := mail.ParseAddress(from); err == nil {
+		return strings.ToLower(addr.Address)
+	}
+	return strings.ToLower(strings.TrimSpace(from))
+}
+
+func aggregateGmailStats(messages []gmailStatsMessage, groupings map[string]bool, loc *time.Location, top int, suggest bool) map[string][]gmailStatsBucket {
+	counts := map[string]map[string]*gmailStatsBucket{}
+	add := func(group, key string, m gmailStatsMessage) {
+		if key == "" {
+			return
+		}
+		if counts[group] == nil {
+			counts[group] = map[string]*gmailStatsBucket{}
+		}
+		b := counts[group][key]
+		if b == nil {
+			b = &gmailStatsBucket{Key: key}
+			counts[group][key] = b
+		}
+		b.Count++
+		b.Bytes += m.Size
+		if suggest && b.Unsubscribe == "" && (group == "sender" || group == "list") {
+			b.Unsubscribe = m.Unsubscribe
+		}
+	}
+
+	for _, m := range messages {
+		sender := gmailStatsSender(m.From)
+		add("sender", sender, m)
+		if at := strings.LastIndex(sender, "@"); at >= 0 {
+			add("domain", sender[at+1:], m)
+		}
+		for _, label := range m.Labels {
+			add("label", label, m)
+		}
+		add("list", m.ListID, m)
+		if !m.Time.IsZero() && m.Time.Unix() > 0 {
+			day := m.Time.In(loc)
+			add("day", day.Format("2006-01-02"), m)
+			weekStart := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+			add("week", weekStart.Format("2006-01-02"), m)
+		}
+	}
+
+	out := map[string][]gmailStatsBucket{}
+	for group := range groupings {
+		buckets := make([]gmailStatsBucket, 0, len(counts[group]))
+		for _, b := range counts[group] {
+			buckets = append(buckets, *b)
+		}
+		if group == "day" || group == "week" {
+			sort.Slice(buckets, func(i, j int) bool { return buckets[i].Key < buckets[j].Key })
+			out[group] = buckets
+			continue
+		}
+		sort.Slice(buckets, func(i, j int) bool {
+			if buckets[i].Count != buckets[j].Count {
+				return buckets[i].Count > buckets[j].Count
+			}
+			if buckets[i].Bytes != buckets[j].Bytes {
+				return buckets[i].Bytes > buckets[j].Bytes
+			}
+			return buckets[i].Key < buckets[j].Key
+		})
+		if len(buckets) > top {
+			buckets = buckets[:top]
+		}
+		if suggest {
+			for i := range buckets {
+				switch group {
+				case "sender":
+					buckets[i].Filter = "gog gmail settings filters create --from " + shellQuoteArg(buckets[i].Key) + " --archive"
+				case "list":
+					buckets[i].Filter = "gog gmail settings filters create --query " + shellQuoteArg("list:"+buckets[i].Key) + " --archive"
+				}
+			}
+		}
+		out[group] = buckets
+	}
+	return out
+}
+
+// shellQuoteArg quotes s for a POSIX shell when it contains anything beyond
+// a conservative set of safe characters.
+func shellQuoteArg(s string) string {
+	safe := s != ""
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newGmailStatsTestService(t *testing.T) *[]string {
+	t.Helper()
+
+	day := func(s string) string {
+		ts, _ := time.Parse(time.RFC3339, s)
+		return strconv.FormatInt(ts.UnixMilli(), 10)
+	}
+	messages := map[string]map[string]any{
+		"m1": {"from": "News <news@shop.example>", "list": "Shop deals <deals.shop.example>", "unsub": "<mailto:u@shop.example>, <https://shop.example/u>", "labels": []string{"INBOX", "Label_1"}, "size": 1000, "at": day("2026-03-02T10:00:00Z")},
+		"m2": {"from": "news@shop.example", "list": "<deals.shop.example>", "labels": []string{"INBOX"}, "size": 3000, "at": day("2026-03-04T10:00:00Z")},
+		"m3": {"from": "Ann <ann@work.example>", "labels": []string{"INBOX", "IMPORTANT"}, "size": 500, "at": day("2026-03-10T10:00:00Z")},
+	}
+	var queries []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		switch {
+		case path == "/messages" && r.Method == http.MethodGet:
+			queries = append(queries, r.URL.Query().Get("q"))
+			if r.URL.Query().Get("pageToken") == "" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"messages": []map[string]any{{"id": "m1"}, {"id": "m2"}}, "nextPageToken": "p2"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"messages": []map[string]any{{"id": "m3"}}})
+		case path == "/labels":
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{{"id": "INBOX", "name": "INBOX"}, {"id": "Label_1", "name": "Deals"}, {"id": "IMPORTANT", "name": "IMPORTANT"}}})
+		case strings.HasPrefix(path, "/messages/"):
+			m, ok := messages[strings.TrimPrefix(path, "/messages/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			headers := []map[string]any{{"name": "From", "value": m["from"]}}
+			if v, ok := m["list"]; ok {
+				headers = append(headers, map[string]any{"name": "List-Id", "value": v})
+			}
+			if v, ok := m["unsub"]; ok {
+				headers = append(headers, map[string]any{"name": "List-Unsubscribe", "value": v})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":           strings.TrimPrefix(path, "/messages/"),
+				"labelIds":     m["labels"],
+				"sizeEstimate": m["size"],
+				"internalDate": m["at"],
+				"payload":      map[string]any{"headers": headers},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	stubGmailService(t, srv)
+	return &queries
+}
+
+func TestGmailStats_JSON(t *testing.T) {
+	queries := newGmailStatsTestService(t)
+
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "gmail", "stats", "--query", "in:inbox", "--since", "90d", "--by", "sender,domain,label,list,week", "--top", "2", "--suggest", "--timezone", "UTC"}); err != nil {
+				t.Fatalf("stats: %v", err)
+			}
+		})
+	})
+
+	var resp struct {
+		Query      string             `json:"query"`
+		Scanned    int                `json:"scanned"`
+		TotalBytes int64              `json:"totalBytes"`
+		Senders    []gmailStatsBucket `json:"senders"`
+		Domains    []gmailStatsBucket `json:"domains"`
+		Labels     []gmailStatsBucket `json:"labels"`
+		Lists      []gmailStatsBucket `json:"lists"`
+		Weeks      []gmailStatsBucket `json:"weeks"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("json: %v (%q)", err, out)
+	}
+	if resp.Query != "in:inbox newer_than:90d" || (*queries)[0] != resp.Query || resp.Scanned != 3 || resp.TotalBytes != 4500 {
+		t.Fatalf("unexpected summary: %+v", resp)
+	}
+	top := resp.Senders[0]
+	if len(resp.Senders) != 2 || top.Key != "news@shop.example" || top.Count != 2 || top.Bytes != 4000 ||
+		top.Unsubscribe != "https://shop.example/u" || top.Filter != "gog gmail settings filters create --from news@shop.example --archive" {
+		t.Fatalf("unexpected senders: %+v", resp.Senders)
+	}
+	if resp.Domains[0].Key != "shop.example" || len(resp.Labels) != 2 || resp.Labels[0].Key != "INBOX" || resp.Labels[0].Count != 3 || resp.Labels[1].Key != "Deals" {
+		t.Fatalf("unexpected domains/labels: %+v %+v", resp.Domains, resp.Labels)
+	}
+	if len(resp.Lists) != 1 || resp.Lists[0].Key != "deals.shop.example" || resp.Lists[0].Filter != "gog gmail settings filters create --query list:deals.shop.example --archive" {
+		t.Fatalf("unexpected lists: %+v", resp.Lists)
+	}
+	if len(resp.Weeks) != 2 || resp.Weeks[0].Key != "2026-03-02" || resp.Weeks[0].Count != 2 || resp.Weeks[1].Key != "2026-03-09" {
+		t.Fatalf("unexpected weeks: %+v", resp.Weeks)
+	}
+}
+
+func TestGmailStatsQuery(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		query, since, want string
+	}{
+		{query: "", since: "", want: ""},
+		{query: "in:inbox", since: "6M", want: "in:inbox newer_than:6m"},
+		{query: "", since: "24h", want: "after:1773057600"},
+		{query: "from:x", since: "2026-01-01", want: "from:x after:1767225600"},
+	}
+	for _, tc := range cases {
+		got, err := gmailStatsQuery(tc.query, tc.since, now)
+		if err != nil || got != tc.want {
+			t.Fatalf("gmailStatsQuery(%q, %q) = %q, %v; want %q", tc.query, tc.since, got, err, tc.want)
+		}
+	}
+	if _, err := gmailStatsQuery("", "soon", now); err == nil {
+		t.Fatalf("expected invalid --since to fail")
+	}
+	if _, err := parseGmailStatsGroupings("sender,hour"); err == nil {
+		t.Fatalf("expected unknown grouping to fail")
+	}
+}
+
+func TestShellQuoteArg(t *testing.T) {
+	if got := shellQuoteArg("a@b.com"); got != "a@b.com" {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := shellQuoteArg("it's here"); got != `'it'\''s here'` {
+		t.Fatalf("unexpected %q", got)
+	}
+}
@@ -25,3 +25,20 @@ func newGmailServiceForTest(t *testing.T, h http.HandlerFunc) (*gmail.Service, f
 	}
 	return svc, srv.Close
 }
+
+func stubGmailService(t *testing.T, srv *httptest.Server) {
+	t.Helper()
+
+	origNew := newGmailService
+	t.Cleanup(func() { newGmailService = origNew })
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	newGmailService = func(context.Context, string) (*gmail.Service, error) { return svc, nil }
+}
@@ -12,51 +12,70 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"golang.org/x/net/html/charset"
 	"google.golang.org/api/gmail/v1"
 
 	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/htmltext"
 	"github.com/steipete/gogcli/internal/outfmt"
 	"github.com/steipete/gogcli/internal/ui"
 )
 
-// HTML stripping patterns for cleaner text output.
-var (
-	// Remove script blocks entirely (including content)
-	scriptPattern = regexp.MustCompile(`(?is)<script[^>]*>.*?</script>`)
-	// Remove style blocks entirely (including content)
-	stylePattern = regexp.MustCompile(`(?is)<style[^>]*>.*?</style>`)
-	// Remove all HTML tags
-	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
-	// Collapse multiple whitespace/newlines
-	whitespacePattern = regexp.MustCompile(`\s+`)
+// Values for --body-format.
+const (
+	gmailBodyFormatPlain    = "plain"
+	gmailBodyFormatMarkdown = "markdown"
+	gmailBodyFormatHTML     = "html"
 )
 
+// renderMessageBody returns a message body in the requested format. Plain
+// text prefers the text/plain part; Markdown and HTML prefer the HTML part.
+// HTML-only messages are rendered rather than shown as markup.
+func renderMessageBody(p *gmail.MessagePart, format string) string {
+	switch format {
+	case gmailBodyFormatMarkdown, gmailBodyFormatHTML:
+		htmlBody := findPartBody(p, "text/html")
+		if htmlBody == "" {
+			if body, isHTML := bestBodyForDisplay(p); isHTML {
+				htmlBody = body
+			} else {
+				return body
+			}
+		}
+		if format == gmailBodyFormatHTML {
+			return htmlBody
+		}
+		return htmltext.Markdown(htmlBody)
+	default:
+		body, isHTML := bestBodyForDisplay(p)
+		if isHTML {
+			return htmltext.Text(body)
+		}
+		return body
+	}
+}
+
+// stripHTMLTags renders HTML as a single line of text for previews. Link
+// footnotes are left out so they do not crowd the visible text.
 func stripHTMLTags(s string) string {
-	// First remove script and style blocks entirely
-	s = scriptPattern.ReplaceAllString(s, "")
-	s = stylePattern.ReplaceAllString(s, "")
-	// Then remove remaining HTML tags
-	s = htmlTagPattern.ReplaceAllString(s, " ")
-	// Collapse whitespace
-	s = whitespacePattern.ReplaceAllString(s, " ")
-	return strings.TrimSpace(s)
+	return strings.Join(strings.Fields(htmltext.Preview(s)), " ")
 }
 
 type GmailThreadCmd struct {
 	Get         GmailThreadGetCmd         `cmd:"" name:"get" aliases:"info,show" default:"withargs" help:"Get a thread with all messages (optionally download attachments)"`
+	Export      GmailThreadExportCmd      `cmd:"" name:"export" help:"Export a conversation to Markdown, HTML, or .eml files with its attachments"`
 	Modify      GmailThreadModifyCmd      `cmd:"" name:"modify" aliases:"update,edit,set" help:"Modify labels on all messages in a thread"`
 	Attachments GmailThreadAttachmentsCmd `cmd:"" name:"attachments" aliases:"files" help:"List all attachments in a thread"`
 }
 
 type GmailThreadGetCmd struct {
-	ThreadID  string        `arg:"" name:"threadId" help:"Thread ID"`
-	Download  bool          `name:"download" help:"Download attachments"`
-	Full      bool          `name:"full" help:"Show full message bodies"`
-	OutputDir OutputDirFlag `embed:""`
+	ThreadID   string        `arg:"" name:"threadId" help:"Thread ID"`
+	Download   bool          `name:"download" help:"Download attachments"`
+	Full       bool          `name:"full" help:"Show full message bodies"`
+	BodyFormat string        `name:"body-format" help:"Body rendering: plain|markdown|html" default:"plain" enum:"plain,markdown,html"`
+	OutputDir  OutputDirFlag `embed:""`
 }
 
 func (c *GmailThreadGetCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -134,13 +153,7 @@ func (c *GmailThreadGetCmd) Run(ctx context.Context, flags *RootFlags) error {
 		u.Out().Printf("Date: %s", headerValue(msg.Payload, "Date"))
 		u.Out().Println("")
 
-		body, isHTML := bestBodyForDisplay(msg.Payload)
-		if body != "" {
-			cleanBody := body
-			if isHTML {
-				// Strip HTML tags for cleaner text output
-				cleanBody = stripHTMLTags(body)
-			}
+		if cleanBody := renderMessageBody(msg.Payload, c.BodyFormat); cleanBody != "" {
 			// Limit body preview to avoid overwhelming output
 			// Use runes to avoid breaking multi-byte UTF-8 characters
 			runes := []rune(cleanBody)
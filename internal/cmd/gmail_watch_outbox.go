@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/filelock"
+)
+
+const (
+	gmailHookSignatureHeader = "X-Gog-Signature"
+	gmailHookDeliveryHeader  = "X-Gog-Delivery"
+	gmailHookAttemptHeader   = "X-Gog-Attempt"
+
+	gmailHookQueuePending   = "pending"
+	gmailHookQueueDelivered = "delivered"
+	gmailHookQueueDead      = "dead"
+
+	defaultHookMaxAttempts = 10
+	gmailHookRetryBase     = 5 * time.Second
+	gmailHookRetryMax      = 10 * time.Minute
+	gmailHookOutboxPoll    = 2 * time.Second
+	gmailHookDeliveredKeep = 200
+)
+
+// gmailHookDelivery is one queued webhook POST. The payload is stored exactly
+// as it was first marshaled so retries and replays sign identical bytes.
+type gmailHookDelivery struct {
+	ID              string          `json:"id"`
+	Sink            string          `json:"sink,omitempty"`
+	CreatedAtMs     int64           `json:"createdAtMs"`
+	Attempts        int             `json:"attempts"`
+	NextAttemptAtMs int64           `json:"nextAttemptAtMs,omitempty"`
+	LastAttemptAtMs int64           `json:"lastAttemptAtMs,omitempty"`
+	LastError       string          `json:"lastError,omitempty"`
+	DeliveredAtMs   int64           `json:"deliveredAtMs,omitempty"`
+	Payload         json.RawMessage `json:"payload"`
+}
+
+// gmailHookOutbox keeps hook deliveries on disk next to the watch state:
+// pending/ holds deliveries waiting for (re)try, delivered/ keeps the most
+// recent successes for replay and dead/ collects deliveries that ran out of
+// attempts. Anything that sends deliveries holds the outbox lock (see lock),
+// so a running watch and `watch replay` never deliver the same entry twice.
+type gmailHookOutbox struct {
+	dir         string
+	maxAttempts int
+	mu          sync.Mutex
+	now         func() time.Time
+}
+
+func gmailHookOutboxDir(account string) (string, error) {
+	dir, err := config.EnsureGmailWatchDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sanitizeAccountForPath(account)+".outbox"), nil
+}
+
+func newGmailHookOutbox(account string, maxAttempts int) (*gmailHookOutbox, error) {
+	dir, err := gmailHookOutboxDir(account)
+	if err != nil {
+		return nil, err
+	}
+	for _, queue := range []string{gmailHookQueuePending, gmailHookQueueDelivered, gmailHookQueueDead} {
+		if err := os.MkdirAll(filepath.Join(dir, queue), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultHookMaxAttempts
+	}
+	return &gmailHookOutbox{dir: dir, maxAttempts: maxAttempts, now: time.Now}, nil
+}
+
+// lock serializes delivery within this process and against other gog
+// processes using the same outbox; the returned func releases it.
+func (o *gmailHookOutbox) lock() (func(), error) {
+	o.mu.Lock()
+	fl, err := filelock.Acquire(filepath.Join(o.dir, "outbox.lock"))
+	if err != nil {
+		o.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		_ = fl.Unlock()
+		o.mu.Unlock()
+	}, nil
+}
+
+func (o *gmailHookOutbox) path(queue, id string) string {
+	return filepath.Join(o.dir, queue, id+".json")
+}
+
+func (o *gmailHookOutbox) enqueue(sink string, payload []byte) (gmailHookDelivery, error) {
+	now := o.now()
+	id, err := newGmailHookDeliveryID(now)
+	if err != nil {
+		return gmailHookDelivery{}, err
+	}
+	d := gmailHookDelivery{
+		ID:              id,
+		Sink:            sink,
+		CreatedAtMs:     now.UnixMilli(),
+		NextAttemptAtMs: now.UnixMilli(),
+		Payload:         json.RawMessage(payload),
+	}
+	// No lock: a new entry is a new file, renamed into place atomically, so
+	// queueing never waits behind a slow delivery.
+	return d, o.write(gmailHookQueuePending, d)
+}
+
+// write stores a delivery via a temp file + rename so a crash never leaves a
+// half-written entry behind. Entries are not indented: that would reformat
+// the raw payload and change the bytes that get signed.
+func (o *gmailHookOutbox) write(queue string, d gmailHookDelivery) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	dest := o.path(queue, d.ID)
+	tmp := dest + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+func (o *gmailHookOutbox) move(d gmailHookDelivery, from, to string) error {
+	if err := o.write(to, d); err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+	if err := os.Remove(o.path(from, d.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// list returns the deliveries of a queue, oldest first.
+func (o *gmailHookOutbox) list(queue string) ([]gmailHookDelivery, error) {
+	entries, err := os.ReadDir(filepath.Join(o.dir, queue))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	out := make([]gmailHookDelivery, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(o.dir, queue, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var d gmailHookDelivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", queue, entry.Name(), err)
+		}
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (o *gmailHookOutbox) prune(queue string, keep int) error {
+	items, err := o.list(queue)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(items)-keep; i++ {
+		if err := os.Remove(o.path(queue, items[i].ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// flush attempts every pending delivery that is due. Failures are rescheduled
+// with exponential backoff until maxAttempts, then moved to the dead queue.
+func (o *gmailHookOutbox) flush(ctx context.Context, send func(context.Context, gmailHookDelivery) error) (delivered, failed int, err error) {
+	unlock, err := o.lock()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+
+	items, err := o.list(gmailHookQueuePending)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, d := range items {
+		if ctx.Err() != nil {
+			return delivered, failed, ctx.Err()
+		}
+		now := o.now()
+		if d.NextAttemptAtMs > now.UnixMilli() {
+			continue
+		}
+		d.Attempts++
+		d.LastAttemptAtMs = now.UnixMilli()
+		sendErr := send(ctx, d)
+		if sendErr == nil {
+			d.LastError = ""
+			d.NextAttemptAtMs = 0
+			d.DeliveredAtMs = o.now().UnixMilli()
+			if err := o.move(d, gmailHookQueuePending, gmailHookQueueDelivered); err != nil {
+				return delivered, failed, err
+			}
+			delivered++
+			continue
+		}
+		failed++
+		d.LastError = sendErr.Error()
+		if d.Attempts >= o.maxAttempts {
+			d.NextAttemptAtMs = 0
+			if err := o.move(d, gmailHookQueuePending, gmailHookQueueDead); err != nil {
+				return delivered, failed, err
+			}
+			continue
+		}
+		d.NextAttemptAtMs = now.Add(gmailHookBackoff(d.Attempts)).UnixMilli()
+		if err := o.write(gmailHookQueuePending, d); err != nil {
+			return delivered, failed, err
+		}
+	}
+	if delivered > 0 {
+		if err := o.prune(gmailHookQueueDelivered, gmailHookDeliveredKeep); err != nil {
+			return delivered, failed, err
+		}
+	}
+	return delivered, failed, nil
+}
+
+func gmailHookBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := gmailHookRetryBase
+	for i := 1; i < attempts && delay < gmailHookRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > gmailHookRetryMax {
+		delay = gmailHookRetryMax
+	}
+	return delay
+}
+
+// newGmailHookDeliveryID returns a lexically time-ordered ID.
+func newGmailHookDeliveryID(now time.Time) (string, error) {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%013d-%s", now.UnixMilli(), hex.EncodeToString(b[:])), nil
+}
+
+// signGmailHookPayload returns the X-Gog-Signature value: a unix timestamp
+// and an HMAC-SHA256 over "<timestamp>.<body>", hex encoded.
+func signGmailHookPayload(secret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", ts)
+	_, _ = mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
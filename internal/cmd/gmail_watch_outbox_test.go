@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func setupGmailWatchOutboxTest(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg-config"))
+}
+
+func TestGmailHookOutbox_RetriesThenDeadLetters(t *testing.T) {
+	setupGmailWatchOutboxTest(t)
+	outbox, err := newGmailHookOutbox("a@b.com", 2)
+	if err != nil {
+		t.Fatalf("outbox: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	outbox.now = func() time.Time { return now }
+
+	if _, err := outbox.enqueue("", []byte(`{"historyId":"1"}`)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	calls := 0
+	failing := func(context.Context, gmailHookDelivery) error {
+		calls++
+		return errors.New("boom")
+	}
+
+	if _, failed, err := outbox.flush(context.Background(), failing); err != nil || failed != 1 {
+		t.Fatalf("first flush: failed=%d err=%v", failed, err)
+	}
+	pending, _ := outbox.list(gmailHookQueuePending)
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].LastError != "boom" || pending[0].NextAttemptAtMs != now.Add(gmailHookRetryBase).UnixMilli() {
+		t.Fatalf("unexpected pending entry: %+v", pending)
+	}
+
+	// Not yet due: no attempt.
+	_, _, _ = outbox.flush(context.Background(), failing)
+	if calls != 1 {
+		t.Fatalf("expected backoff to hold the retry, got %d calls", calls)
+	}
+
+	now = now.Add(gmailHookRetryBase)
+	_, _, _ = outbox.flush(context.Background(), failing)
+	pending, _ = outbox.list(gmailHookQueuePending)
+	dead, _ := outbox.list(gmailHookQueueDead)
+	if calls != 2 || len(pending) != 0 || len(dead) != 1 || dead[0].Attempts != 2 {
+		t.Fatalf("expected dead letter after max attempts: calls=%d pending=%d dead=%+v", calls, len(pending), dead)
+	}
+	if string(dead[0].Payload) != `{"historyId":"1"}` {
+		t.Fatalf("payload must be kept verbatim: %s", dead[0].Payload)
+	}
+}
+
+func TestGmailHookBackoff(t *testing.T) {
+	if got := gmailHookBackoff(1); got != gmailHookRetryBase {
+		t.Fatalf("attempt 1: %v", got)
+	}
+	if got := gmailHookBackoff(3); got != 4*gmailHookRetryBase {
+		t.Fatalf("attempt 3: %v", got)
+	}
+	if got := gmailHookBackoff(30); got != gmailHookRetryMax {
+		t.Fatalf("attempt 30: %v", got)
+	}
+}
+
+type gmailHookTestReceiver struct {
+	mu     sync.Mutex
+	fail   bool
+	bodies []string
+	sigs   []string
+	ids    []string
+}
+
+func newGmailHookTestReceiver(t *testing.T) (*gmailHookTestReceiver, *httptest.Server) {
+	t.Helper()
+	recv := &gmailHookTestReceiver{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recv.mu.Lock()
+		defer recv.mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		if recv.fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		recv.bodies = append(recv.bodies, string(body))
+		recv.sigs = append(recv.sigs, r.Header.Get(gmailHookSignatureHeader))
+		recv.ids = append(recv.ids, r.Header.Get(gmailHookDeliveryHeader))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return recv, srv
+}
+
+func (r *gmailHookTestReceiver) setFail(fail bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fail = fail
+}
+
+func verifyGmailHookSignature(t *testing.T, secret, header, body string) {
+	t.Helper()
+	parts := strings.Split(header, ",")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "t=") || !strings.HasPrefix(parts[1], "v1=") {
+		t.Fatalf("malformed signature header %q", header)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strings.TrimPrefix(parts[0], "t=") + "." + body))
+	if strings.TrimPrefix(parts[1], "v1=") != hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("signature mismatch for %q", header)
+	}
+}
+
+func TestGmailWatchServer_QueueHookSignsAndKeepsFailures(t *testing.T) {
+	setupGmailWatchOutboxTest(t)
+	recv, hookSrv := newGmailHookTestReceiver(t)
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	outbox, err := newGmailHookOutbox("a@b.com", 0)
+	if err != nil {
+		t.Fatalf("outbox: %v", err)
+	}
+	s := &gmailWatchServer{
+		cfg:        gmailWatchServeConfig{Account: "a@b.com", HookURL: hookSrv.URL, HookSecret: "s3cret"},
+		store:      store,
+		hookClient: hookSrv.Client(),
+		outbox:     outbox,
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+
+	recv.setFail(true)
+	if err := s.queueHook(context.Background(), &gmailHookPayload{Source: "gmail", Account: "a@b.com", HistoryID: "7"}); err != nil {
+		t.Fatalf("queueHook: %v", err)
+	}
+	// Queueing only persists the payload; the outbox loop makes the attempt.
+	pending, _ := outbox.list(gmailHookQueuePending)
+	if len(pending) != 1 || pending[0].Attempts != 0 || len(recv.bodies) != 0 {
+		t.Fatalf("queueHook must not deliver: %+v", pending)
+	}
+	s.flushOutbox(context.Background())
+	pending, _ = outbox.list(gmailHookQueuePending)
+	if len(pending) != 1 || pending[0].Attempts != 1 {
+		t.Fatalf("failed delivery must stay queued: %+v", pending)
+	}
+	if state := store.Get(); state.LastDeliveryStatus != gmailWatchStatusHTTPError {
+		t.Fatalf("unexpected delivery status: %q", state.LastDeliveryStatus)
+	}
+
+	// The retry goes out once the receiver recovers and the backoff elapses.
+	recv.setFail(false)
+	outbox.now = func() time.Time { return time.Now().Add(time.Hour) }
+	s.flushOutbox(context.Background())
+	delivered, _ := outbox.list(gmailHookQueueDelivered)
+	if len(delivered) != 1 || len(recv.bodies) != 1 {
+		t.Fatalf("expected one delivery, got delivered=%d received=%d", len(delivered), len(recv.bodies))
+	}
+	if recv.ids[0] != pending[0].ID {
+		t.Fatalf("delivery header %q, want %q", recv.ids[0], pending[0].ID)
+	}
+	verifyGmailHookSignature(t, "s3cret", recv.sigs[0], recv.bodies[0])
+}
+
+func TestGmailWatchServer_OutboxLockIsShared(t *testing.T) {
+	setupGmailWatchOutboxTest(t)
+	recv, hookSrv := newGmailHookTestReceiver(t)
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	outbox, err := newGmailHookOutbox("a@b.com", 0)
+	if err != nil {
+		t.Fatalf("outbox: %v", err)
+	}
+	s := &gmailWatchServer{
+		cfg:        gmailWatchServeConfig{Account: "a@b.com", HookURL: hookSrv.URL},
+		store:      store,
+		hookClient: hookSrv.Client(),
+		outbox:     outbox,
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+
+	// Another process (e.g. `watch replay`) holds the outbox lock.
+	other, err := newGmailHookOutbox("a@b.com", 0)
+	if err != nil {
+		t.Fatalf("outbox: %v", err)
+	}
+	unlock, err := other.lock()
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+
+	queued := make(chan error, 1)
+	go func() {
+		queued <- s.queueHook(context.Background(), &gmailHookPayload{Source: "gmail", Account: "a@b.com", HistoryID: "9"})
+	}()
+	select {
+	case err := <-queued:
+		if err != nil {
+			t.Fatalf("queueHook: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("queueHook must not wait for the outbox lock")
+	}
+
+	flushed := make(chan struct{})
+	go func() {
+		s.flushOutbox(context.Background())
+		close(flushed)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	recv.mu.Lock()
+	early := len(recv.bodies)
+	recv.mu.Unlock()
+	if early != 0 {
+		t.Fatalf("flush delivered while another process held the outbox lock")
+	}
+	unlock()
+	<-flushed
+	if len(recv.bodies) != 1 {
+		t.Fatalf("expected delivery after the lock was released, got %d", len(recv.bodies))
+	}
+}
+
+func TestGmailWatchReplayCmd_RedeliversDeadLetters(t *testing.T) {
+	setupGmailWatchOutboxTest(t)
+	recv, hookSrv := newGmailHookTestReceiver(t)
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := store.Update(func(s *gmailWatchState) error {
+		s.Account = "a@b.com"
+		s.Hook = &gmailWatchHook{URL: hookSrv.URL, Secret: "s3cret"}
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	outbox, err := newGmailHookOutbox("a@b.com", 0)
+	if err != nil {
+		t.Fatalf("outbox: %v", err)
+	}
+	for _, id := range []string{"0000000000001-aa", "0000000000002-bb"} {
+		d := gmailHookDelivery{ID: id, Attempts: 10, LastError: "hook status 503", Payload: json.RawMessage(`{"id":"` + id + `"}`)}
+		if err := outbox.write(gmailHookQueueDead, d); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute([]string{"--json", "--account", "a@b.com", "gmail", "watch", "replay", "--id", "0000000000002-bb"}); err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+		})
+	})
+	var got struct {
+		Replayed   int                     `json:"replayed"`
+		Deliveries []gmailHookReplayResult `json:"deliveries"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("unmarshal: %v (out=%q)", err, out)
+	}
+	if got.Replayed != 1 || got.Deliveries[0].ID != "0000000000002-bb" || got.Deliveries[0].Status != "delivered" {
+		t.Fatalf("unexpected replay: %+v", got)
+	}
+	if len(recv.bodies) != 1 || recv.bodies[0] != `{"id":"0000000000002-bb"}` {
+		t.Fatalf("unexpected hook bodies: %v", recv.bodies)
+	}
+	verifyGmailHookSignature(t, "s3cret", recv.sigs[0], recv.bodies[0])
+
+	dead, _ := outbox.list(gmailHookQueueDead)
+	delivered, _ := outbox.list(gmailHookQueueDelivered)
+	if len(dead) != 1 || dead[0].ID != "0000000000001-aa" || len(delivered) != 1 || delivered[0].LastError != "" {
+		t.Fatalf("unexpected queues: dead=%+v delivered=%+v", dead, delivered)
+	}
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var newPubsubService = googleapi.NewPubsub
+
+// gmailWatchPullWait sleeps between polls; tests replace it to stop the loop.
+var gmailWatchPullWait = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+type GmailWatchPullCmd struct {
+	Subscription string        `name:"subscription" help:"Pub/Sub pull subscription (projects/.../subscriptions/...); without it, poll Gmail history"`
+	Interval     time.Duration `name:"interval" help:"History polling interval (and retry delay after errors)" default:"30s"`
+	MaxMessages  int64         `name:"max-messages" help:"Pub/Sub messages per pull" default:"10"`
+	Once         bool          `name:"once" help:"Process what is pending and exit"`
+	GmailWatchDeliveryFlags
+}
+
+func (c *GmailWatchPullCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if c.Interval <= 0 {
+		return usage("--interval must be > 0")
+	}
+	if c.MaxMessages <= 0 {
+		return usage("--max-messages must be > 0")
+	}
+	sub := strings.TrimSpace(c.Subscription)
+	if sub != "" && !strings.HasPrefix(sub, "projects/") {
+		return usage("--subscription must be projects/<project>/subscriptions/<name>")
+	}
+
+	store, err := loadOrSeedGmailWatchStore(ctx, account)
+	if err != nil {
+		return err
+	}
+	server, err := c.newServer(ctx, kctx, flags, account, store)
+	if err != nil {
+		return err
+	}
+	stop := server.startOutbox(ctx)
+	defer stop()
+
+	// Without hooks, payloads are streamed to stdout as NDJSON.
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	emit := func(payload *gmailHookPayload) error { return enc.Encode(payload) }
+
+	var psvc *pubsub.Service
+	if sub != "" {
+		psvc, err = newPubsubService(ctx)
+		if err != nil {
+			return err
+		}
+		u.Err().Printf("watch: pulling %s", sub)
+	} else {
+		u.Err().Printf("watch: polling history every %s", c.Interval)
+	}
+
+	for {
+		var pulled int
+		var pollErr error
+		if psvc != nil {
+			pulled, pollErr = server.pullPubsub(ctx, psvc, sub, c.MaxMessages, c.Once, emit)
+		} else {
+			pollErr = server.pollHistory(ctx, emit)
+		}
+		if pollErr != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if c.Once {
+				return pollErr
+			}
+			server.warnf("watch: pull failed: %v", pollErr)
+		}
+		if c.Once && (psvc == nil || pulled == 0) {
+			if server.outbox != nil {
+				server.flushOutbox(ctx)
+			}
+			return nil
+		}
+		// Pub/Sub pulls long-poll themselves; only wait between history polls
+		// and after errors.
+		if psvc != nil && pollErr == nil {
+			continue
+		}
+		if err := gmailWatchPullWait(ctx, c.Interval); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// loadOrSeedGmailWatchStore loads the watch state, or starts one at the
+// mailbox's current historyId when `watch start` was never run.
+func loadOrSeedGmailWatchStore(ctx context.Context, account string) (*gmailWatchStore, error) {
+	store, err := newGmailWatchStore(account)
+	if err != nil {
+		return nil, err
+	}
+	if _, statErr := os.Stat(store.path); statErr == nil {
+		return loadGmailWatchStore(account)
+	}
+	svc, err := newGmailService(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := svc.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Update(func(s *gmailWatchState) error {
+		s.Account = account
+		s.HistoryID = formatHistoryID(profile.HistoryId)
+		s.UpdatedAtMs = time.Now().UnixMilli()
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// pullPubsub pulls one batch from the subscription and processes it like
+// pushed notifications. Messages are acked once handled; failures stay
+// unacked so Pub/Sub redelivers them.
+func (s *gmailWatchServer) pullPubsub(ctx context.Context, psvc *pubsub.Service, sub string, maxMessages int64, returnImmediately bool, emit func(*gmailHookPayload) error) (int, error) {
+	resp, err := psvc.Projects.Subscriptions.Pull(sub, &pubsub.PullRequest{
+		MaxMessages:       maxMessages,
+		ReturnImmediately: returnImmediately,
+	}).Context(ctx).Do()
+	if err != nil {
+		return 0, err
+	}
+	ackIDs := make([]string, 0, len(resp.ReceivedMessages))
+	for _, received := range resp.ReceivedMessages {
+		if received == nil || received.Message == nil {
+			continue
+		}
+		envelope := &pubsubPushEnvelope{Subscription: sub}
+		envelope.Message.Data = received.Message.Data
+		envelope.Message.MessageID = received.Message.MessageId
+		envelope.Message.PublishTime = received.Message.PublishTime
+		envelope.Message.Attributes = received.Message.Attributes
+		payload, decodeErr := decodeGmailPushPayload(envelope)
+		if decodeErr != nil {
+			// Redelivery will not fix a malformed message.
+			s.warnf("watch: invalid push data: %v", decodeErr)
+			ackIDs = append(ackIDs, received.AckId)
+			continue
+		}
+		if err := s.handlePulled(ctx, payload, emit); err != nil {
+			s.warnf("watch: handle push failed: %v", err)
+			continue
+		}
+		ackIDs = append(ackIDs, received.AckId)
+	}
+	if len(ackIDs) > 0 {
+		if _, err := psvc.Projects.Subscriptions.Acknowledge(sub, &pubsub.AcknowledgeRequest{AckIds: ackIDs}).Context(ctx).Do(); err != nil {
+			return len(resp.ReceivedMessages), err
+		}
+	}
+	return len(resp.ReceivedMessages), nil
+}
+
+// pollHistory turns the mailbox's current historyId into a synthetic push,
+// so unchanged mailboxes cost a single getProfile call.
+func (s *gmailWatchServer) pollHistory(ctx context.Context, emit func(*gmailHookPayload) error) error {
+	svc, err := s.newService(ctx, s.cfg.Account)
+	if err != nil {
+		return err
+	}
+	profile, err := svc.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	return s.handlePulled(ctx, gmailPushPayload{HistoryID: formatHistoryID(profile.HistoryId)}, emit)
+}
+
+func (s *gmailWatchServer) handlePulled(ctx context.Context, payload gmailPushPayload, emit func(*gmailHookPayload) error) error {
+	if payload.EmailAddress != "" && !strings.EqualFold(payload.EmailAddress, s.cfg.Account) {
+		s.warnf("watch: ignoring push for %s", payload.EmailAddress)
+		return nil
+	}
+	result, err := s.handlePush(ctx, payload)
+	if err != nil {
+		if errors.Is(err, errNoNewMessages) {
+			return nil
+		}
+		return err
+	}
+	// History moved without new messages (label changes, deletions).
+	if result == nil || len(result.Messages) == 0 {
+		return nil
+	}
+	if !s.hasHooks() {
+		return emit(result)
+	}
+	s.deliver(ctx, result)
+	return nil
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+)
+
+type gmailWatchPullTestServer struct {
+	mu            sync.Mutex
+	historyStarts []string
+	pulls         int
+	acked         []string
+}
+
+func newGmailWatchPullTestServer(t *testing.T, profileHistoryID string) *gmailWatchPullTestServer {
+	t.Helper()
+	setupGmailWatchOutboxTest(t)
+	origPubsub := newPubsubService
+	t.Cleanup(func() { newPubsubService = origPubsub })
+
+	s := &gmailWatchPullTestServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me")
+		switch {
+		case path == "/profile":
+			_ = json.NewEncoder(w).Encode(map[string]any{"emailAddress": "a@b.com", "historyId": profileHistoryID})
+		case path == "/history":
+			s.historyStarts = append(s.historyStarts, r.URL.Query().Get("startHistoryId"))
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "105",
+				"history": []map[string]any{
+					{"messagesAdded": []map[string]any{{"message": map[string]any{"id": "m1"}}, {"message": map[string]any{"id": "m2"}}}},
+				},
+			})
+		case path == "/messages/m1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": "m1", "threadId": "t1", "labelIds": []string{"INBOX"}, "snippet": "hi",
+				"payload": map[string]any{"headers": []map[string]any{{"name": "Subject", "value": "Hello"}}},
+			})
+		case path == "/messages/m2":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m2", "threadId": "t2", "labelIds": []string{"SPAM"}})
+		case r.URL.Path == "/v1/projects/p/subscriptions/s:pull":
+			s.pulls++
+			if s.pulls > 1 {
+				_ = json.NewEncoder(w).Encode(map[string]any{})
+				return
+			}
+			data := base64.StdEncoding.EncodeToString([]byte(`{"emailAddress":"a@b.com","historyId":105}`))
+			_ = json.NewEncoder(w).Encode(map[string]any{"receivedMessages": []map[string]any{
+				{"ackId": "ack-1", "message": map[string]any{"data": data, "messageId": "ps-1"}},
+			}})
+		case r.URL.Path == "/v1/projects/p/subscriptions/s:acknowledge":
+			var req pubsub.AcknowledgeRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			s.acked = append(s.acked, req.AckIds...)
+			_ = json.NewEncoder(w).Encode(map[string]any{})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	stubGmailService(t, srv)
+	psvc, err := pubsub.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("pubsub.NewService: %v", err)
+	}
+	newPubsubService = func(context.Context) (*pubsub.Service, error) { return psvc, nil }
+	return s
+}
+
+func seedGmailWatchHistory(t *testing.T, historyID string) *gmailWatchStore {
+	t.Helper()
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if err := store.Update(func(s *gmailWatchState) error {
+		s.Account = "a@b.com"
+		s.HistoryID = historyID
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return store
+}
+
+func runGmailWatchPull(t *testing.T, args ...string) []gmailHookPayload {
+	t.Helper()
+	var out string
+	_ = captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := Execute(append([]string{"--account", "a@b.com", "gmail", "watch", "pull", "--once"}, args...)); err != nil {
+				t.Fatalf("pull: %v", err)
+			}
+		})
+	})
+	var payloads []gmailHookPayload
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		var p gmailHookPayload
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			t.Fatalf("bad NDJSON line %q: %v", scanner.Text(), err)
+		}
+		payloads = append(payloads, p)
+	}
+	return payloads
+}
+
+func TestGmailWatchPull_PollsHistory(t *testing.T) {
+	srv := newGmailWatchPullTestServer(t, "105")
+	seedGmailWatchHistory(t, "100")
+
+	payloads := runGmailWatchPull(t)
+	if len(payloads) != 1 || len(payloads[0].Messages) != 1 || payloads[0].Messages[0].ID != "m1" || payloads[0].HistoryID != "105" {
+		t.Fatalf("unexpected payloads (SPAM must be excluded): %+v", payloads)
+	}
+	if strings.Join(srv.historyStarts, ",") != "100" {
+		t.Fatalf("unexpected history calls: %v", srv.historyStarts)
+	}
+
+	// The cursor advanced, so an unchanged mailbox only costs a profile call.
+	if payloads := runGmailWatchPull(t); len(payloads) != 0 || len(srv.historyStarts) != 1 {
+		t.Fatalf("expected no work on second poll: payloads=%v history=%v", payloads, srv.historyStarts)
+	}
+}
+
+func TestGmailWatchPull_SeedsStateWithoutWatch(t *testing.T) {
+	srv := newGmailWatchPullTestServer(t, "200")
+
+	if payloads := runGmailWatchPull(t); len(payloads) != 0 || len(srv.historyStarts) != 0 {
+		t.Fatalf("first poll must only seed the cursor: payloads=%v history=%v", payloads, srv.historyStarts)
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := store.Get().HistoryID; got != "200" {
+		t.Fatalf("expected seeded historyId 200, got %q", got)
+	}
+}
+
+func TestGmailWatchPull_PubsubSubscriptionAcks(t *testing.T) {
+	srv := newGmailWatchPullTestServer(t, "105")
+	seedGmailWatchHistory(t, "100")
+
+	payloads := runGmailWatchPull(t, "--subscription", "projects/p/subscriptions/s")
+	if len(payloads) != 1 || payloads[0].Messages[0].ID != "m1" {
+		t.Fatalf("unexpected payloads: %+v", payloads)
+	}
+	if strings.Join(srv.acked, ",") != "ack-1" || srv.pulls != 2 {
+		t.Fatalf("expected ack after processing and a final empty pull: acked=%v pulls=%d", srv.acked, srv.pulls)
+	}
+	store, err := loadGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := store.Get(); got.HistoryID != "105" || got.LastPushMessageID != "ps-1" {
+		t.Fatalf("unexpected state: %+v", got)
+	}
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailWatchReplayCmd struct {
+	From       string   `name:"from" help:"Outbox queue to replay: dead|pending|delivered" enum:"dead,pending,delivered" default:"dead"`
+	IDs        []string `name:"id" help:"Delivery IDs to replay (repeatable, comma-separated; default all in the queue)"`
+	Since      string   `name:"since" help:"Only payloads queued within this duration (e.g. 24h)"`
+	Limit      int      `name:"limit" help:"Max payloads to replay (0 = all)" default:"0"`
+	HookURL    string   `name:"hook-url" help:"Webhook URL (default: stored hook)"`
+	HookToken  string   `name:"hook-token" help:"Webhook bearer token (default: stored hook)"`
+	HookSecret string   `name:"hook-secret" help:"HMAC signing secret (default: stored hook)"`
+	Rules      string   `name:"rules" help:"Rules file defining the sinks payloads were routed to (default: stored --rules)"`
+}
+
+type gmailHookReplayResult struct {
+	ID        string `json:"id"`
+	CreatedAt string `json:"createdAt"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (c *GmailWatchReplayCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if c.Limit < 0 {
+		return usage("--limit must be >= 0")
+	}
+	since, err := parseDurationSeconds(c.Since)
+	if err != nil {
+		return usagef("invalid --since: %v", err)
+	}
+
+	store, err := loadGmailWatchStore(account)
+	if err != nil {
+		return err
+	}
+	state := store.Get()
+	cfg := gmailWatchServeConfig{Account: account}
+	if state.Hook != nil {
+		cfg.HookURL = state.Hook.URL
+		cfg.HookToken = state.Hook.Token
+		cfg.HookSecret = state.Hook.Secret
+	}
+	if strings.TrimSpace(c.HookURL) != "" {
+		cfg.HookURL = strings.TrimSpace(c.HookURL)
+	}
+	if c.HookToken != "" {
+		cfg.HookToken = c.HookToken
+	}
+	if c.HookSecret != "" {
+		cfg.HookSecret = c.HookSecret
+	}
+	var router *gmailWatchRouter
+	rulesPath := strings.TrimSpace(c.Rules)
+	if rulesPath == "" {
+		rulesPath = state.HookRules
+	}
+	if rulesPath != "" {
+		var hook *gmailWatchHook
+		if cfg.HookURL != "" {
+			hook = &gmailWatchHook{URL: cfg.HookURL, Token: cfg.HookToken, Secret: cfg.HookSecret}
+		}
+		if router, err = loadGmailWatchRouter(rulesPath, hook); err != nil {
+			return err
+		}
+	}
+	if cfg.HookURL == "" && router == nil {
+		return usage("no hook configured; pass --hook-url or save one with gmail watch serve --save-hook")
+	}
+
+	outbox, err := newGmailHookOutbox(account, 0)
+	if err != nil {
+		return err
+	}
+	// Hold the outbox lock for the whole replay so a running watch cannot
+	// deliver (or move) the same entries meanwhile.
+	unlock, err := outbox.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	items, err := selectGmailHookDeliveries(outbox, c.From, splitCommaList(strings.Join(c.IDs, ",")), since, c.Limit, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, d := range items {
+		ids = append(ids, d.ID)
+	}
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.watch.replay", map[string]any{
+		"from":       c.From,
+		"url":        cfg.HookURL,
+		"rules":      rulesPath,
+		"deliveries": ids,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+	if len(items) == 0 {
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, os.Stdout, map[string]any{"from": c.From, "replayed": 0, "failed": 0, "deliveries": []gmailHookReplayResult{}})
+		}
+		u.Err().Printf("No %s payloads to replay", c.From)
+		return nil
+	}
+
+	server := &gmailWatchServer{
+		cfg:        cfg,
+		store:      store,
+		router:     router,
+		hookClient: &http.Client{Timeout: defaultHookRequestTimeoutSec * time.Second},
+	}
+	results := make([]gmailHookReplayResult, 0, len(items))
+	failed := 0
+	for _, d := range items {
+		d.Attempts++
+		d.LastAttemptAtMs = time.Now().UnixMilli()
+		res := gmailHookReplayResult{ID: d.ID, CreatedAt: formatUnixMillis(d.CreatedAtMs), Status: "delivered"}
+		if sendErr := server.deliverHook(ctx, d); sendErr != nil {
+			failed++
+			res.Status = "failed"
+			res.Error = sendErr.Error()
+			d.LastError = sendErr.Error()
+			if err := outbox.write(c.From, d); err != nil {
+				return err
+			}
+		} else {
+			d.LastError = ""
+			d.NextAttemptAtMs = 0
+			d.DeliveredAtMs = time.Now().UnixMilli()
+			if err := outbox.move(d, c.From, gmailHookQueueDelivered); err != nil {
+				return err
+			}
+		}
+		results = append(results, res)
+	}
+	if err := outbox.prune(gmailHookQueueDelivered, gmailHookDeliveredKeep); err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, os.Stdout, map[string]any{
+			"from":       c.From,
+			"replayed":   len(results) - failed,
+			"failed":     failed,
+			"deliveries": results,
+		}); err != nil {
+			return err
+		}
+	} else {
+		w, flush := tableWriter(ctx)
+		fmt.Fprintln(w, "ID\tCREATED\tSTATUS\tERROR")
+		for _, res := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", res.ID, res.CreatedAt, res.Status, res.Error)
+		}
+		flush()
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d deliveries failed", failed, len(results))
+	}
+	return nil
+}
+
+func selectGmailHookDeliveries(outbox *gmailHookOutbox, queue string, ids []string, since time.Duration, limit int, now time.Time) ([]gmailHookDelivery, error) {
+	items, err := outbox.list(queue)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		byID := make(map[string]gmailHookDelivery, len(items))
+		for _, d := range items {
+			byID[d.ID] = d
+		}
+		selected := make([]gmailHookDelivery, 0, len(ids))
+		for _, id := range ids {
+			d, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("delivery %s not found in %s queue", id, queue)
+			}
+			selected = append(selected, d)
+		}
+		items = selected
+	}
+	if since > 0 {
+		cutoff := now.Add(-since).UnixMilli()
+		kept := items[:0]
+		for _, d := range items {
+			if d.CreatedAtMs >= cutoff {
+				kept = append(kept, d)
+			}
+		}
+		items = kept
+	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
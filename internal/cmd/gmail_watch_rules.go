@@ -0,0 +1,448 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/steipete/gogcli/internal/config"
+)
+
+const (
+	gmailWatchSinkWebhook = "webhook"
+	gmailWatchSinkCommand = "command"
+	gmailWatchSinkFile    = "file"
+
+	// gmailWatchHookSinkName is the sink name rules use for --hook-url (or the
+	// stored hook).
+	gmailWatchHookSinkName = "hook"
+
+	defaultWatchCommandTimeout = 30 * time.Second
+)
+
+// gmailWatchRulesFile is the on-disk format of `gmail watch serve --rules`.
+// YAML is the documented format; JSON parses too.
+type gmailWatchRulesFile struct {
+	Sinks   map[string]gmailWatchSink `yaml:"sinks"`
+	Rules   []gmailWatchRule          `yaml:"rules"`
+	Default []string                  `yaml:"default"`
+}
+
+type gmailWatchSink struct {
+	Type    string   `yaml:"type"`
+	URL     string   `yaml:"url"`
+	Token   string   `yaml:"token"`
+	Secret  string   `yaml:"secret"`
+	Command []string `yaml:"command"`
+	Path    string   `yaml:"path"`
+	Timeout string   `yaml:"timeout"`
+
+	timeout time.Duration
+}
+
+type gmailWatchRule struct {
+	Name    string   `yaml:"name"`
+	Labels  []string `yaml:"labels"`
+	From    string   `yaml:"from"`
+	To      string   `yaml:"to"`
+	Subject string   `yaml:"subject"`
+	Query   string   `yaml:"query"`
+	Sinks   []string `yaml:"sinks"`
+	Stop    bool     `yaml:"stop"`
+
+	subject *regexp.Regexp
+	query   []gmailWatchQueryTerm
+}
+
+// gmailWatchQueryTerm is one predicate of a rule's Gmail-like query. Values
+// are lowercased; label terms compare label IDs case-insensitively.
+type gmailWatchQueryTerm struct {
+	field  string
+	value  string
+	negate bool
+}
+
+type gmailWatchRoute struct {
+	Sink    string
+	Payload *gmailHookPayload
+}
+
+type gmailWatchRouter struct {
+	sinks    map[string]gmailWatchSink
+	rules    []gmailWatchRule
+	defaults []string
+}
+
+func loadGmailWatchRouter(path string, hook *gmailWatchHook) (*gmailWatchRouter, error) {
+	expanded, err := config.ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(expanded) //nolint:gosec // user-provided rules file
+	if err != nil {
+		return nil, err
+	}
+	var file gmailWatchRulesFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("rules %s: %w", path, err)
+	}
+	router, err := newGmailWatchRouter(file, hook)
+	if err != nil {
+		return nil, fmt.Errorf("rules %s: %w", path, err)
+	}
+	return router, nil
+}
+
+func newGmailWatchRouter(file gmailWatchRulesFile, hook *gmailWatchHook) (*gmailWatchRouter, error) {
+	r := &gmailWatchRouter{sinks: make(map[string]gmailWatchSink, len(file.Sinks)+1)}
+	for name, sink := range file.Sinks {
+		if strings.TrimSpace(name) == "" {
+			return nil, errors.New("sink with empty name")
+		}
+		if err := sink.validate(); err != nil {
+			return nil, fmt.Errorf("sink %s: %w", name, err)
+		}
+		r.sinks[name] = sink
+	}
+	if hook != nil {
+		if _, ok := r.sinks[gmailWatchHookSinkName]; ok {
+			return nil, fmt.Errorf("sink name %q is reserved for --hook-url", gmailWatchHookSinkName)
+		}
+		r.sinks[gmailWatchHookSinkName] = gmailWatchSink{Type: gmailWatchSinkWebhook, URL: hook.URL, Token: hook.Token, Secret: hook.Secret}
+	}
+	if len(r.sinks) == 0 {
+		return nil, errors.New("no sinks defined")
+	}
+
+	for i, rule := range file.Rules {
+		ref := rule.Name
+		if ref == "" {
+			ref = fmt.Sprintf("#%d", i+1)
+		}
+		if len(rule.Sinks) == 0 {
+			return nil, fmt.Errorf("rule %s: sinks required", ref)
+		}
+		if err := r.checkSinks(rule.Sinks); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", ref, err)
+		}
+		if rule.Subject != "" {
+			re, err := regexp.Compile(rule.Subject)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: subject: %w", ref, err)
+			}
+			rule.subject = re
+		}
+		terms, err := parseGmailWatchQuery(rule.Query)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: query: %w", ref, err)
+		}
+		rule.query = terms
+		rule.From = strings.ToLower(strings.TrimSpace(rule.From))
+		rule.To = strings.ToLower(strings.TrimSpace(rule.To))
+		r.rules = append(r.rules, rule)
+	}
+	if err := r.checkSinks(file.Default); err != nil {
+		return nil, fmt.Errorf("default: %w", err)
+	}
+	r.defaults = file.Default
+	return r, nil
+}
+
+func (r *gmailWatchRouter) checkSinks(names []string) error {
+	for _, name := range names {
+		if _, ok := r.sinks[name]; !ok {
+			return fmt.Errorf("unknown sink %q", name)
+		}
+	}
+	return nil
+}
+
+func (s *gmailWatchSink) validate() error {
+	switch s.Type {
+	case gmailWatchSinkWebhook:
+		if strings.TrimSpace(s.URL) == "" {
+			return errors.New("url required")
+		}
+	case gmailWatchSinkCommand:
+		if len(s.Command) == 0 || strings.TrimSpace(s.Command[0]) == "" {
+			return errors.New("command required")
+		}
+	case gmailWatchSinkFile:
+		expanded, err := config.ExpandPath(strings.TrimSpace(s.Path))
+		if err != nil {
+			return err
+		}
+		if expanded == "" {
+			return errors.New("path required")
+		}
+		s.Path = expanded
+	default:
+		return fmt.Errorf("type must be %s, %s or %s", gmailWatchSinkWebhook, gmailWatchSinkCommand, gmailWatchSinkFile)
+	}
+	timeout, err := parseDurationSeconds(s.Timeout)
+	if err != nil {
+		return fmt.Errorf("timeout: %w", err)
+	}
+	s.timeout = timeout
+	return nil
+}
+
+// usesLabels reports whether any rule compares labels, which is when label
+// names need to be resolved to IDs.
+func (r *gmailWatchRouter) usesLabels() bool {
+	for _, rule := range r.rules {
+		if len(rule.Labels) > 0 {
+			return true
+		}
+		for _, term := range rule.query {
+			if term.field == "label" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveLabels maps label names in rules to IDs using a lowercased
+// name/ID -> ID map (see fetchLabelNameToID); unknown values are kept as-is.
+func (r *gmailWatchRouter) resolveLabels(nameToID map[string]string) {
+	resolve := func(v string) string {
+		if id, ok := nameToID[strings.ToLower(strings.TrimSpace(v))]; ok {
+			return id
+		}
+		return v
+	}
+	for i := range r.rules {
+		rule := &r.rules[i]
+		for j, label := range rule.Labels {
+			rule.Labels[j] = resolve(label)
+		}
+		for j, term := range rule.query {
+			if term.field == "label" {
+				rule.query[j].value = strings.ToLower(resolve(term.value))
+			}
+		}
+	}
+}
+
+// route splits a payload per sink. Rules are evaluated in order for every
+// message; a matching rule with stop: true ends evaluation for that message.
+// Messages no rule matched go to the default sinks.
+func (r *gmailWatchRouter) route(payload *gmailHookPayload) []gmailWatchRoute {
+	perSink := make(map[string][]gmailHookMessage)
+	order := make([]string, 0, len(r.sinks))
+	for _, msg := range payload.Messages {
+		for _, name := range r.targets(msg) {
+			if _, ok := perSink[name]; !ok {
+				order = append(order, name)
+			}
+			perSink[name] = append(perSink[name], msg)
+		}
+	}
+	routes := make([]gmailWatchRoute, 0, len(order))
+	for _, name := range order {
+		routed := *payload
+		routed.Messages = perSink[name]
+		routes = append(routes, gmailWatchRoute{Sink: name, Payload: &routed})
+	}
+	return routes
+}
+
+func (r *gmailWatchRouter) targets(msg gmailHookMessage) []string {
+	var out []string
+	matched := false
+	for _, rule := range r.rules {
+		if !rule.matches(msg) {
+			continue
+		}
+		matched = true
+		for _, name := range rule.Sinks {
+			if !containsFold(out, name) {
+				out = append(out, name)
+			}
+		}
+		if rule.Stop {
+			break
+		}
+	}
+	if !matched {
+		return r.defaults
+	}
+	return out
+}
+
+func (rule *gmailWatchRule) matches(msg gmailHookMessage) bool {
+	if len(rule.Labels) > 0 {
+		found := false
+		for _, label := range rule.Labels {
+			if containsFold(msg.Labels, label) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if rule.From != "" && !strings.Contains(strings.ToLower(msg.From), rule.From) {
+		return false
+	}
+	if rule.To != "" && !strings.Contains(strings.ToLower(msg.To), rule.To) {
+		return false
+	}
+	if rule.subject != nil && !rule.subject.MatchString(msg.Subject) {
+		return false
+	}
+	for _, term := range rule.query {
+		if term.matches(msg) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t gmailWatchQueryTerm) matches(msg gmailHookMessage) bool {
+	has := func(field string) bool { return strings.Contains(strings.ToLower(field), t.value) }
+	switch t.field {
+	case "from":
+		return has(msg.From)
+	case "to":
+		return has(msg.To)
+	case "subject":
+		return has(msg.Subject)
+	case "label":
+		return containsFold(msg.Labels, t.value)
+	default:
+		return has(msg.From) || has(msg.Subject) || has(msg.Snippet) || has(msg.Body)
+	}
+}
+
+// parseGmailWatchQuery parses a small subset of Gmail search syntax:
+// from:, to:, subject:, label:, is:unread|read|starred|important,
+// in:inbox|spam|trash, bare words and "quoted phrases", each optionally
+// negated with a leading '-'. Terms are ANDed.
+func parseGmailWatchQuery(query string) ([]gmailWatchQueryTerm, error) {
+	tokens, err := splitGmailWatchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	terms := make([]gmailWatchQueryTerm, 0, len(tokens))
+	for _, token := range tokens {
+		term := gmailWatchQueryTerm{}
+		if strings.HasPrefix(token, "-") && len(token) > 1 {
+			term.negate = true
+			token = token[1:]
+		}
+		field, value, ok := strings.Cut(token, ":")
+		if !ok || strings.HasPrefix(token, `"`) {
+			term.value = strings.ToLower(strings.Trim(token, `"`))
+			terms = append(terms, term)
+			continue
+		}
+		value = strings.ToLower(strings.Trim(value, `"`))
+		if value == "" {
+			return nil, fmt.Errorf("empty value for %s:", field)
+		}
+		switch strings.ToLower(field) {
+		case "from", "to", "subject", "label":
+			term.field = strings.ToLower(field)
+			term.value = value
+		case "is":
+			term.field = "label"
+			switch value {
+			case "unread", "starred", "important":
+				term.value = value
+			case "read":
+				term.value = "unread"
+				term.negate = !term.negate
+			default:
+				return nil, fmt.Errorf("unsupported term %q", token)
+			}
+		case "in":
+			if value != "inbox" && value != "spam" && value != "trash" {
+				return nil, fmt.Errorf("unsupported term %q", token)
+			}
+			term.field = "label"
+			term.value = value
+		default:
+			return nil, fmt.Errorf("unsupported term %q", token)
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+func splitGmailWatchQuery(query string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuote := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			cur.WriteRune(r)
+		case (r == ' ' || r == '\t' || r == '\n') && !inQuote:
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, errors.New("unterminated quote")
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+// runGmailWatchCommandSink runs a command sink with the payload on stdin.
+// The delivery ID and sink name are passed as GOG_DELIVERY_ID and GOG_SINK.
+func runGmailWatchCommandSink(ctx context.Context, name string, sink gmailWatchSink, d gmailHookDelivery) error {
+	timeout := sink.timeout
+	if timeout <= 0 {
+		timeout = defaultWatchCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, sink.Command[0], sink.Command[1:]...) //nolint:gosec // command comes from the user's rules file
+	cmd.Stdin = bytes.NewReader(d.Payload)
+	cmd.Env = append(os.Environ(), "GOG_DELIVERY_ID="+d.ID, "GOG_SINK="+name)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// appendGmailWatchFileSink appends the payload as one NDJSON line.
+func appendGmailWatchFileSink(sink gmailWatchSink, d gmailHookDelivery) error {
+	f, err := os.OpenFile(sink.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(bytes.TrimSpace(d.Payload), '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeGmailWatchRules(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func routedSinks(routes []gmailWatchRoute) map[string][]string {
+	out := make(map[string][]string, len(routes))
+	for _, route := range routes {
+		for _, msg := range route.Payload.Messages {
+			out[route.Sink] = append(out[route.Sink], msg.ID)
+		}
+	}
+	return out
+}
+
+func TestGmailWatchRouter_Route(t *testing.T) {
+	path := writeGmailWatchRules(t, `
+sinks:
+  archive:
+    type: file
+    path: /tmp/gog-watch-test.ndjson
+  notify:
+    type: command
+    command: [notify-send, gmail]
+rules:
+  - name: invoices
+    subject: "(?i)invoice"
+    labels: [Billing]
+    sinks: [hook, archive]
+    stop: true
+  - name: boss
+    from: "@boss.example.com"
+    sinks: [notify]
+  - name: unread-not-newsletters
+    query: 'is:unread -from:news "launch plan"'
+    sinks: [notify, archive]
+default: [archive]
+`)
+	router, err := loadGmailWatchRouter(path, &gmailWatchHook{URL: "http://127.0.0.1/hook"})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !router.usesLabels() {
+		t.Fatalf("expected label rules")
+	}
+	router.resolveLabels(map[string]string{"billing": "Label_7", "unread": "UNREAD"})
+
+	payload := &gmailHookPayload{Source: "gmail", Account: "a@b.com", HistoryID: "9", Messages: []gmailHookMessage{
+		{ID: "m1", From: "Ann <ann@boss.example.com>", Subject: "Invoice 42", Labels: []string{"INBOX", "Label_7"}},
+		{ID: "m2", From: "Ann <ann@boss.example.com>", Subject: "Hello", Snippet: "about the Launch Plan", Labels: []string{"UNREAD"}},
+		{ID: "m3", From: "news@list.example.com", Subject: "Launch plan digest", Labels: []string{"UNREAD"}},
+	}}
+	got := routedSinks(router.route(payload))
+	want := map[string]string{"hook": "m1", "archive": "m1,m2,m3", "notify": "m2"}
+	for sink, ids := range want {
+		if strings.Join(got[sink], ",") != ids {
+			t.Fatalf("sink %s got %v, want %s (all=%v)", sink, got[sink], ids, got)
+		}
+	}
+}
+
+func TestGmailWatchRouter_Validation(t *testing.T) {
+	cases := map[string]string{
+		"unknown sink":   "sinks:\n  a: {type: file, path: /tmp/x}\nrules:\n  - sinks: [b]\n",
+		"bad type":       "sinks:\n  a: {type: email}\n",
+		"bad regex":      "sinks:\n  a: {type: file, path: /tmp/x}\nrules:\n  - subject: '('\n    sinks: [a]\n",
+		"bad query":      "sinks:\n  a: {type: file, path: /tmp/x}\nrules:\n  - query: 'has:attachment'\n    sinks: [a]\n",
+		"unknown field":  "sinks:\n  a: {type: file, path: /tmp/x, url: x, retries: 3}\n",
+		"reserved hook":  "sinks:\n  hook: {type: file, path: /tmp/x}\n",
+		"no sinks":       "rules: []\n",
+		"missing target": "sinks:\n  a: {type: webhook}\n",
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			var hook *gmailWatchHook
+			if name == "reserved hook" {
+				hook = &gmailWatchHook{URL: "http://x"}
+			}
+			if _, err := loadGmailWatchRouter(writeGmailWatchRules(t, content), hook); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
+
+func TestGmailWatchServer_RoutesToFileAndCommandSinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("command sink test uses sh")
+	}
+	setupGmailWatchOutboxTest(t)
+	dir := t.TempDir()
+	eventsPath := filepath.Join(dir, "events.ndjson")
+	cmdOut := filepath.Join(dir, "cmd.out")
+	rules := writeGmailWatchRules(t, `
+sinks:
+  events: {type: file, path: `+eventsPath+`}
+  script: {type: command, command: [sh, -c, 'printf "%s " "$GOG_SINK" >> `+cmdOut+`; cat >> `+cmdOut+`']}
+rules:
+  - query: label:INBOX
+    sinks: [events, script]
+`)
+	router, err := loadGmailWatchRouter(rules, nil)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	store, err := newGmailWatchStore("a@b.com")
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	outbox, err := newGmailHookOutbox("a@b.com", 0)
+	if err != nil {
+		t.Fatalf("outbox: %v", err)
+	}
+	s := &gmailWatchServer{
+		cfg:    gmailWatchServeConfig{Account: "a@b.com"},
+		store:  store,
+		outbox: outbox,
+		router: router,
+		logf:   func(string, ...any) {},
+		warnf:  func(string, ...any) {},
+	}
+	payload := &gmailHookPayload{Source: "gmail", Account: "a@b.com", HistoryID: "5", Messages: []gmailHookMessage{
+		{ID: "in", Labels: []string{"INBOX"}},
+		{ID: "out", Labels: []string{"Label_1"}},
+	}}
+	if err := s.queueHook(context.Background(), payload); err != nil {
+		t.Fatalf("queueHook: %v", err)
+	}
+	s.flushOutbox(context.Background())
+
+	data, err := os.ReadFile(eventsPath)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	var got gmailHookPayload
+	if err := json.Unmarshal(data, &got); err != nil || !strings.HasSuffix(string(data), "}\n") {
+		t.Fatalf("expected one NDJSON line, got %q (%v)", data, err)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].ID != "in" {
+		t.Fatalf("unexpected routed payload: %+v", got)
+	}
+	out, err := os.ReadFile(cmdOut)
+	if err != nil {
+		t.Fatalf("command output: %v", err)
+	}
+	if !strings.HasPrefix(string(out), "script {") || !strings.Contains(string(out), `"id":"in"`) {
+		t.Fatalf("unexpected command output: %q", out)
+	}
+	delivered, _ := outbox.list(gmailHookQueueDelivered)
+	if len(delivered) != 2 || delivered[0].Sink == "" {
+		t.Fatalf("expected two delivered entries with sinks, got %+v", delivered)
+	}
+	if state := store.Get(); state.LastDeliveryStatus != "ok" {
+		t.Fatalf("unexpected delivery status %q", state.LastDeliveryStatus)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +32,9 @@ type gmailWatchServer struct {
 	validator       *idtoken.Validator
 	newService      func(context.Context, string) (*gmail.Service, error)
 	hookClient      *http.Client
+	outbox          *gmailHookOutbox
+	outboxWake      chan struct{}
+	router          *gmailWatchRouter
 	excludeLabelIDs map[string]struct{}
 	logf            func(string, ...any)
 	warnf           func(string, ...any)
@@ -84,7 +88,7 @@ func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if s.cfg.HookURL == "" {
+	if !s.hasHooks() {
 		if s.cfg.AllowNoHook {
 			_ = json.NewEncoder(w).Encode(result)
 			return
@@ -93,12 +97,28 @@ func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.sendHook(r.Context(), result); err != nil {
+	s.deliver(r.Context(), result)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *gmailWatchServer) hasHooks() bool {
+	return s.cfg.HookURL != "" || s.router != nil
+}
+
+// deliver hands a payload to the outbox (or straight to the hooks when there
+// is none). Delivery failures are logged, never returned: the history cursor
+// has already advanced.
+func (s *gmailWatchServer) deliver(ctx context.Context, result *gmailHookPayload) {
+	if s.outbox != nil {
+		queueErr := s.queueHook(ctx, result)
+		if queueErr == nil {
+			return
+		}
+		s.warnf("watch: hook outbox failed, sending directly: %v", queueErr)
+	}
+	if err := s.sendHook(ctx, result); err != nil {
 		s.warnf("watch: hook failed: %v", err)
-		w.WriteHeader(http.StatusOK)
-		return
 	}
-	w.WriteHeader(http.StatusOK)
 }
 
 func (s *gmailWatchServer) authorize(r *http.Request) bool {
@@ -321,7 +341,7 @@ func (s *gmailWatchServer) fetchMessages(ctx context.Context, svc *gmail.Service
 			Labels:   msg.LabelIds,
 		}
 		if s.cfg.IncludeBody {
-			body := bestBodyText(msg.Payload)
+			body := renderMessageBody(msg.Payload, gmailBodyFormatPlain)
 			item.Body, item.BodyTruncated = truncateUTF8Bytes(body, s.cfg.MaxBodyBytes)
 		}
 		messages = append(messages, item)
@@ -346,45 +366,171 @@ func (s *gmailWatchServer) isExcludedLabel(labelIDs []string) bool {
 }
 
 func (s *gmailWatchServer) sendHook(ctx context.Context, payload *gmailHookPayload) error {
-	data, err := json.Marshal(payload)
+	var errs []error
+	for _, route := range s.routes(payload) {
+		data, err := json.Marshal(route.Payload)
+		if err != nil {
+			return err
+		}
+		if err := s.deliverHook(ctx, gmailHookDelivery{Sink: route.Sink, Attempts: 1, Payload: data}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// queueHook persists the payload in the outbox and wakes the outbox loop,
+// which makes the first attempt; a failed or interrupted delivery is retried
+// instead of lost. It never waits for the hook itself, so a slow receiver
+// cannot hold up the Pub/Sub ack.
+func (s *gmailWatchServer) queueHook(_ context.Context, payload *gmailHookPayload) error {
+	for _, route := range s.routes(payload) {
+		data, err := json.Marshal(route.Payload)
+		if err != nil {
+			return err
+		}
+		if _, err := s.outbox.enqueue(route.Sink, data); err != nil {
+			return err
+		}
+	}
+	select {
+	case s.outboxWake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// routes splits a payload across rule sinks, or targets the single hook when
+// no rules are configured.
+func (s *gmailWatchServer) routes(payload *gmailHookPayload) []gmailWatchRoute {
+	if s.router == nil {
+		return []gmailWatchRoute{{Payload: payload}}
+	}
+	routes := s.router.route(payload)
+	if len(routes) == 0 && s.cfg.VerboseOutput {
+		s.logf("watch: no rule matched historyId=%s", payload.HistoryID)
+	}
+	return routes
+}
+
+func (s *gmailWatchServer) flushOutbox(ctx context.Context) {
+	delivered, failed, err := s.outbox.flush(ctx, s.deliverHook)
 	if err != nil {
+		s.warnf("watch: hook outbox: %v", err)
+		return
+	}
+	if failed > 0 {
+		s.warnf("watch: hook delivery failed for %d payload(s); will retry", failed)
+	} else if delivered > 0 && s.cfg.VerboseOutput {
+		s.logf("watch: delivered %d hook payload(s)", delivered)
+	}
+}
+
+// startOutbox runs the outbox retry loop in the background, if there is an
+// outbox; the returned func stops it.
+func (s *gmailWatchServer) startOutbox(ctx context.Context) func() {
+	if s.outbox == nil {
+		return func() {}
+	}
+	if s.outboxWake == nil {
+		s.outboxWake = make(chan struct{}, 1)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go s.runOutbox(ctx)
+	return cancel
+}
+
+// runOutbox retries pending deliveries until ctx is cancelled.
+func (s *gmailWatchServer) runOutbox(ctx context.Context) {
+	s.flushOutbox(ctx)
+	ticker := time.NewTicker(gmailHookOutboxPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flushOutbox(ctx)
+		case <-s.outboxWake:
+			s.flushOutbox(ctx)
+		}
+	}
+}
+
+// deliverHook sends one delivery to its sink; deliveries without a sink go to
+// the configured hook URL.
+func (s *gmailWatchServer) deliverHook(ctx context.Context, d gmailHookDelivery) error {
+	target := gmailWatchSink{Type: gmailWatchSinkWebhook, URL: s.cfg.HookURL, Token: s.cfg.HookToken, Secret: s.cfg.HookSecret}
+	if d.Sink != "" {
+		sink, ok := gmailWatchSink{}, false
+		if s.router != nil {
+			sink, ok = s.router.sinks[d.Sink]
+		}
+		if !ok {
+			return fmt.Errorf("unknown sink %q", d.Sink)
+		}
+		target = sink
+	}
+
+	var err error
+	switch target.Type {
+	case gmailWatchSinkCommand:
+		err = runGmailWatchCommandSink(ctx, d.Sink, target, d)
+	case gmailWatchSinkFile:
+		err = appendGmailWatchFileSink(target, d)
+	default:
+		return s.postHook(ctx, target, d)
+	}
+	if err != nil {
+		s.recordDelivery("error", fmt.Sprintf("%s: %v", d.Sink, err))
 		return err
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.HookURL, bytes.NewReader(data))
+	s.recordDelivery("ok", "")
+	return nil
+}
+
+func (s *gmailWatchServer) postHook(ctx context.Context, target gmailWatchSink, d gmailHookDelivery) error {
+	if target.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, target.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(d.Payload))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if s.cfg.HookToken != "" {
-		req.Header.Set("Authorization", "Bearer "+s.cfg.HookToken)
+	if target.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+target.Token)
+	}
+	if target.Secret != "" {
+		req.Header.Set(gmailHookSignatureHeader, signGmailHookPayload(target.Secret, time.Now().Unix(), d.Payload))
+	}
+	if d.ID != "" {
+		req.Header.Set(gmailHookDeliveryHeader, d.ID)
+		req.Header.Set(gmailHookAttemptHeader, strconv.Itoa(d.Attempts))
 	}
 	resp, err := s.hookClient.Do(req)
 	if err != nil {
-		_ = s.store.Update(func(state *gmailWatchState) error {
-			state.LastDeliveryStatus = "error"
-			state.LastDeliveryAtMs = time.Now().UnixMilli()
-			state.LastDeliveryStatusNote = err.Error()
-			return nil
-		})
+		s.recordDelivery("error", err.Error())
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		_ = s.store.Update(func(state *gmailWatchState) error {
-			state.LastDeliveryStatus = gmailWatchStatusHTTPError
-			state.LastDeliveryAtMs = time.Now().UnixMilli()
-			state.LastDeliveryStatusNote = fmt.Sprintf("status %d", resp.StatusCode)
-			return nil
-		})
+		s.recordDelivery(gmailWatchStatusHTTPError, fmt.Sprintf("status %d", resp.StatusCode))
 		return fmt.Errorf("hook status %d", resp.StatusCode)
 	}
+	s.recordDelivery("ok", "")
+	return nil
+}
+
+func (s *gmailWatchServer) recordDelivery(status, note string) {
 	_ = s.store.Update(func(state *gmailWatchState) error {
-		state.LastDeliveryStatus = "ok"
+		state.LastDeliveryStatus = status
 		state.LastDeliveryAtMs = time.Now().UnixMilli()
-		state.LastDeliveryStatusNote = ""
+		state.LastDeliveryStatusNote = note
 		return nil
 	})
-	return nil
 }
 
 func parsePubSubPush(r *http.Request) (*pubsubPushEnvelope, error) {
@@ -19,6 +19,7 @@ const (
 type gmailWatchHook struct {
 	URL         string `json:"url"`
 	Token       string `json:"token,omitempty"`
+	Secret      string `json:"secret,omitempty"`
 	IncludeBody bool   `json:"includeBody,omitempty"`
 	MaxBytes    int    `json:"maxBytes,omitempty"`
 }
@@ -33,6 +34,7 @@ type gmailWatchState struct {
 	RenewAfterMs           int64           `json:"renewAfterMs,omitempty"`
 	UpdatedAtMs            int64           `json:"updatedAtMs,omitempty"`
 	Hook                   *gmailWatchHook `json:"hook,omitempty"`
+	HookRules              string          `json:"hookRules,omitempty"`
 	LastDeliveryStatus     string          `json:"lastDeliveryStatus,omitempty"`
 	LastDeliveryAtMs       int64           `json:"lastDeliveryAtMs,omitempty"`
 	LastDeliveryStatusNote string          `json:"lastDeliveryStatusNote,omitempty"`
@@ -50,6 +52,7 @@ type gmailWatchServeConfig struct {
 	SharedToken   string
 	HookURL       string
 	HookToken     string
+	HookSecret    string
 	IncludeBody   bool
 	MaxBodyBytes  int
 	ExcludeLabels []string
@@ -135,6 +135,117 @@ func GmailWatchDir() (string, error) {
 	return filepath.Join(dir, "state", "gmail-watch"), nil
 }
 
+func GmailExportDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "gmail-export"), nil
+}
+
+func GmailImportDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "gmail-import"), nil
+}
+
+func GmailMergeDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "gmail-merge"), nil
+}
+
+func GmailScheduleDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "gmail-schedule"), nil
+}
+
+func CalendarMirrorDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "calendar-mirror"), nil
+}
+
+func DriveSyncDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "drive-sync"), nil
+}
+
+func EnsureDriveSyncDir() (string, error) {
+	dir, err := DriveSyncDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure drive sync dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+func DriveUploadsDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "drive-uploads"), nil
+}
+
+func EnsureDriveUploadsDir() (string, error) {
+	dir, err := DriveUploadsDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure drive uploads dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+func DriveChangesDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "state", "drive-changes"), nil
+}
+
+func EnsureDriveChangesDir() (string, error) {
+	dir, err := DriveChangesDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure drive changes dir: %w", err)
+	}
+
+	return dir, nil
+}
+
 func KeepServiceAccountPath(email string) (string, error) {
 	dir, err := Dir()
 	if err != nil {
@@ -241,6 +352,71 @@ func EnsureGmailWatchDir() (string, error) {
 	return dir, nil
 }
 
+func EnsureGmailExportDir() (string, error) {
+	dir, err := GmailExportDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail export dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+func EnsureGmailImportDir() (string, error) {
+	dir, err := GmailImportDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail import dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+func EnsureGmailMergeDir() (string, error) {
+	dir, err := GmailMergeDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail merge dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+func EnsureGmailScheduleDir() (string, error) {
+	dir, err := GmailScheduleDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure gmail schedule dir: %w", err)
+	}
+
+	return dir, nil
+}
+
+func EnsureCalendarMirrorDir() (string, error) {
+	dir, err := CalendarMirrorDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("ensure calendar mirror dir: %w", err)
+	}
+
+	return dir, nil
+}
+
 // ExpandPath expands ~ at the beginning of a path to the user's home directory.
 // This is needed because ~ is a shell feature and is not expanded when paths
 // are quoted (e.g., --out "~/Downloads/file.pdf").
@@ -53,6 +53,78 @@ func TestDerivedPaths(t *testing.T) {
 	if !strings.HasPrefix(downloadsDir, base) {
 		t.Fatalf("expected downloads dir under %q, got %q", base, downloadsDir)
 	}
+
+	syncDir, err := DriveSyncDir()
+	if err != nil {
+		t.Fatalf("DriveSyncDir: %v", err)
+	}
+
+	if !strings.HasPrefix(syncDir, base) {
+		t.Fatalf("expected drive sync dir under %q, got %q", base, syncDir)
+	}
+
+	uploadsDir, err := DriveUploadsDir()
+	if err != nil {
+		t.Fatalf("DriveUploadsDir: %v", err)
+	}
+
+	if !strings.HasPrefix(uploadsDir, base) {
+		t.Fatalf("expected drive uploads dir under %q, got %q", base, uploadsDir)
+	}
+
+	changesDir, err := DriveChangesDir()
+	if err != nil {
+		t.Fatalf("DriveChangesDir: %v", err)
+	}
+
+	if !strings.HasPrefix(changesDir, base) {
+		t.Fatalf("expected drive changes dir under %q, got %q", base, changesDir)
+	}
+
+	exportDir, err := GmailExportDir()
+	if err != nil {
+		t.Fatalf("GmailExportDir: %v", err)
+	}
+
+	if !strings.HasPrefix(exportDir, base) {
+		t.Fatalf("expected gmail export dir under %q, got %q", base, exportDir)
+	}
+
+	importDir, err := GmailImportDir()
+	if err != nil {
+		t.Fatalf("GmailImportDir: %v", err)
+	}
+
+	if !strings.HasPrefix(importDir, base) {
+		t.Fatalf("expected gmail import dir under %q, got %q", base, importDir)
+	}
+
+	mergeDir, err := GmailMergeDir()
+	if err != nil {
+		t.Fatalf("GmailMergeDir: %v", err)
+	}
+
+	if !strings.HasPrefix(mergeDir, base) {
+		t.Fatalf("expected gmail merge dir under %q, got %q", base, mergeDir)
+	}
+
+	scheduleDir, err := GmailScheduleDir()
+	if err != nil {
+		t.Fatalf("GmailScheduleDir: %v", err)
+	}
+
+	if !strings.HasPrefix(scheduleDir, base) {
+		t.Fatalf("expected gmail schedule dir under %q, got %q", base, scheduleDir)
+	}
+
+	mirrorDir, err := CalendarMirrorDir()
+	if err != nil {
+		t.Fatalf("CalendarMirrorDir: %v", err)
+	}
+
+	if !strings.HasPrefix(mirrorDir, base) {
+		t.Fatalf("expected calendar mirror dir under %q, got %q", base, mirrorDir)
+	}
 }
 
 func TestKeepServiceAccountLegacyPathMore(t *testing.T) {
@@ -0,0 +1,59 @@
+// Package filelock provides exclusive advisory locks on lock files, so
+// separate gog processes can serialize work on shared state. The operating
+// system drops the lock when the holder exits, so a crash never leaves a
+// stale lock behind.
+package filelock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrLocked is returned by TryLock when another process holds the lock.
+var ErrLocked = errors.New("lock is held by another process")
+
+// Lock is a held lock; call Unlock to release it.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire blocks until it holds the exclusive lock on path, creating the file
+// (and its directory) if needed.
+func Acquire(path string) (*Lock, error) {
+	return acquire(path, true)
+}
+
+// TryLock takes the lock on path if it is free and returns ErrLocked otherwise.
+func TryLock(path string) (*Lock, error) {
+	return acquire(path, false)
+}
+
+func acquire(path string, wait bool) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600) //nolint:gosec // caller-owned state path
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f, wait); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &Lock{f: f}, nil
+}
+
+// Unlock releases the lock. The lock file itself is left in place.
+func (l *Lock) Unlock() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	unlockErr := unlockFile(l.f)
+	closeErr := l.f.Close()
+	l.f = nil
+	if unlockErr != nil {
+		return unlockErr
+	}
+	return closeErr
+}
@@ -0,0 +1,30 @@
+package filelock
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "x.lock")
+
+	held, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if _, err := TryLock(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked while held, got %v", err)
+	}
+	if err := held.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	again, err := TryLock(path)
+	if err != nil {
+		t.Fatalf("TryLock after release: %v", err)
+	}
+	if err := again.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+}
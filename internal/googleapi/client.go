@@ -95,6 +95,28 @@ func optionsForAccount(ctx context.Context, service googleauth.Service, email st
 func optionsForAccountScopes(ctx context.Context, serviceLabel string, email string, scopes []string) ([]option.ClientOption, error) {
 	slog.Debug("creating client options with custom scopes", "serviceLabel", serviceLabel, "email", email)
 
+	c, err := httpClientForAccountScopes(ctx, serviceLabel, email, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("client options with custom scopes created successfully", "serviceLabel", serviceLabel, "email", email)
+
+	return []option.ClientOption{option.WithHTTPClient(c)}, nil
+}
+
+// httpClientForAccount returns the authenticated (and retrying) HTTP client that
+// backs the API services, for endpoints the generated clients don't cover.
+func httpClientForAccount(ctx context.Context, service googleauth.Service, email string) (*http.Client, error) {
+	scopes, err := googleauth.Scopes(service)
+	if err != nil {
+		return nil, fmt.Errorf("resolve scopes: %w", err)
+	}
+
+	return httpClientForAccountScopes(ctx, string(service), email, scopes)
+}
+
+func httpClientForAccountScopes(ctx context.Context, serviceLabel string, email string, scopes []string) (*http.Client, error) {
 	var creds config.ClientCredentials
 
 	var ts oauth2.TokenSource
@@ -128,14 +150,11 @@ func optionsForAccountScopes(ctx context.Context, serviceLabel string, email str
 		Source: ts,
 		Base:   baseTransport,
 	})
-	c := &http.Client{
+
+	return &http.Client{
 		Transport: retryTransport,
 		Timeout:   defaultHTTPTimeout,
-	}
-
-	slog.Debug("client options with custom scopes created successfully", "serviceLabel", serviceLabel, "email", email)
-
-	return []option.ClientOption{option.WithHTTPClient(c)}, nil
+	}, nil
 }
 
 func newBaseTransport() *http.Transport {
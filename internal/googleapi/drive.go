@@ -3,6 +3,7 @@ package googleapi
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"google.golang.org/api/drive/v3"
 
@@ -18,3 +19,14 @@ func NewDrive(ctx context.Context, email string) (*drive.Service, error) {
 		return svc, nil
 	}
 }
+
+// NewDriveHTTPClient returns an authenticated HTTP client with Drive scopes, for
+// Drive endpoints that are only exposed as URLs (e.g. revision export links).
+func NewDriveHTTPClient(ctx context.Context, email string) (*http.Client, error) {
+	c, err := httpClientForAccount(ctx, googleauth.ServiceDrive, email)
+	if err != nil {
+		return nil, fmt.Errorf("drive http client: %w", err)
+	}
+
+	return c, nil
+}
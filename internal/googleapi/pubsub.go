@@ -0,0 +1,36 @@
+package googleapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+)
+
+// NewPubsub uses Application Default Credentials rather than a stored account:
+// the subscription lives in the GCP project that owns the Gmail watch topic.
+// The client has no overall timeout so long-polling pulls are bounded by ctx.
+func NewPubsub(ctx context.Context) (*pubsub.Service, error) {
+	ts, err := google.DefaultTokenSource(ctx, pubsub.PubsubScope)
+	if err != nil {
+		return nil, fmt.Errorf("pubsub credentials (run gcloud auth application-default login): %w", err)
+	}
+
+	client := &http.Client{
+		Transport: NewRetryTransport(&oauth2.Transport{
+			Source: ts,
+			Base:   newBaseTransport(),
+		}),
+	}
+
+	svc, err := pubsub.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		return nil, fmt.Errorf("create pubsub service: %w", err)
+	}
+
+	return svc, nil
+}
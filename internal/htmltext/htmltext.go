@@ -0,0 +1,592 @@
+// Package htmltext renders HTML email bodies as readable plain text or
+// Markdown: block elements become paragraphs, lists and data tables keep
+// their shape, links become numbered footnotes, and quoted replies become
+// "> " blockquotes.
+package htmltext
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Text renders src as plain text.
+func Text(src string) string {
+	return render(src, false, true)
+}
+
+// Markdown renders src as Markdown, with links as reference-style footnotes.
+func Markdown(src string) string {
+	return render(src, true, true)
+}
+
+// Preview renders src as plain text without link markers or footnotes, for
+// short previews where only the readable text matters.
+func Preview(src string) string {
+	return render(src, false, false)
+}
+
+func render(src string, markdown, footnotes bool) string {
+	if strings.TrimSpace(src) == "" {
+		return ""
+	}
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		return strings.TrimSpace(src)
+	}
+	r := &renderer{markdown: markdown, footnotes: footnotes, linkIndex: map[string]int{}}
+	out := joinPieces(r.collect(doc), false)
+
+	if len(r.links) > 0 {
+		var b strings.Builder
+		b.WriteString(out)
+		b.WriteString("\n\n")
+		for i, link := range r.links {
+			fmt.Fprintf(&b, "[%d]: %s\n", i+1, link)
+		}
+		out = b.String()
+	}
+	return tidy(out)
+}
+
+type renderer struct {
+	markdown  bool
+	footnotes bool
+	links     []string
+	linkIndex map[string]int
+}
+
+// piece is a rendered block. Paragraphs are separated by a blank line;
+// plain lines (from <div> and <br>-style markup) by a single newline.
+type piece struct {
+	text string
+	para bool
+}
+
+func joinPieces(pieces []piece, tight bool) string {
+	var b strings.Builder
+	var prev *piece
+	for i := range pieces {
+		p := &pieces[i]
+		if strings.TrimSpace(p.text) == "" {
+			continue
+		}
+		if prev != nil {
+			if tight || (!prev.para && !p.para) {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(p.text)
+		prev = p
+	}
+	return b.String()
+}
+
+var skippedElements = map[string]bool{
+	"head": true, "script": true, "style": true, "title": true, "meta": true,
+	"link": true, "noscript": true, "template": true, "svg": true, "button": true,
+}
+
+// Containers are transparent: their children are laid out as if inline in
+// the parent, one line per block.
+var containerElements = map[string]bool{
+	"html": true, "body": true, "div": true, "section": true, "article": true,
+	"main": true, "header": true, "footer": true, "nav": true, "aside": true,
+	"center": true, "form": true, "address": true, "figure": true, "figcaption": true,
+	"tbody": true, "thead": true, "tfoot": true, "tr": true, "td": true, "th": true,
+	"table": true, "details": true, "summary": true, "fieldset": true,
+}
+
+var paragraphElements = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "dl": true, "pre": true, "blockquote": true, "hr": true,
+}
+
+func isBlock(n *html.Node) bool {
+	return n.Type == html.ElementNode && (containerElements[n.Data] || paragraphElements[n.Data] || n.Data == "li" || n.Data == "dt" || n.Data == "dd")
+}
+
+func containsBlock(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if isBlock(c) || (c.Type == html.ElementNode && containsBlock(c)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHidden(n *html.Node) bool {
+	if _, ok := attr(n, "hidden"); ok {
+		return true
+	}
+	style, _ := attr(n, "style")
+	style = strings.ToLower(strings.ReplaceAll(style, " ", ""))
+	return strings.Contains(style, "display:none") || strings.Contains(style, "mso-hide:all")
+}
+
+// collect lays out the children of n as pieces, accumulating inline content
+// into lines between blocks.
+func (r *renderer) collect(n *html.Node) []piece {
+	var pieces []piece
+	var line strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(collapseInline(line.String())); text != "" {
+			pieces = append(pieces, piece{text: text})
+		}
+		line.Reset()
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			line.WriteString(r.text(c.Data))
+			continue
+		}
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if skippedElements[c.Data] || isHidden(c) {
+			continue
+		}
+		switch {
+		case c.Data == "table" && isDataTable(c):
+			flush()
+			pieces = append(pieces, piece{text: r.table(c), para: true})
+		case paragraphElements[c.Data]:
+			flush()
+			pieces = append(pieces, piece{text: r.paragraph(c), para: true})
+		case containerElements[c.Data] || c.Data == "li" || c.Data == "dt" || c.Data == "dd":
+			flush()
+			pieces = append(pieces, r.collect(c)...)
+		case containsBlock(c):
+			// Inline element wrapping blocks (e.g. <a><div>..</div></a>).
+			flush()
+			pieces = append(pieces, r.collect(c)...)
+		default:
+			line.WriteString(r.inline(c))
+		}
+	}
+	flush()
+	return pieces
+}
+
+func (r *renderer) paragraph(n *html.Node) string {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := strings.TrimSpace(collapseInline(r.inlineChildren(n)))
+		text = strings.ReplaceAll(text, "\n", " ")
+		if r.markdown && text != "" {
+			level, _ := strconv.Atoi(n.Data[1:])
+			return strings.Repeat("#", level) + " " + text
+		}
+		return text
+	case "ul", "ol":
+		return r.list(n, n.Data == "ol")
+	case "dl":
+		return joinPieces(r.collect(n), true)
+	case "pre":
+		text := strings.Trim(textContent(n), "\n")
+		if r.markdown {
+			return "```\n" + text + "\n```"
+		}
+		return text
+	case "blockquote":
+		return quote(joinPieces(r.collect(n), false))
+	case "hr":
+		return "---"
+	default:
+		return joinPieces(r.collect(n), false)
+	}
+}
+
+func quote(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			lines[i] = ">"
+			continue
+		}
+		lines[i] = "> " + l
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *renderer) list(n *html.Node, ordered bool) string {
+	num := 1
+	if v, ok := attr(n, "start"); ok {
+		if start, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			num = start
+		}
+	}
+	var items []string
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || isHidden(c) {
+			continue
+		}
+		var body string
+		switch c.Data {
+		case "li":
+			body = joinPieces(r.collect(c), true)
+		case "ul", "ol":
+			// Nested list placed directly in the list (invalid but common).
+			body = r.list(c, c.Data == "ol")
+			if len(items) > 0 {
+				items[len(items)-1] += "\n" + indent(body, "  ")
+				continue
+			}
+		default:
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+		pad := strings.Repeat(" ", len(marker))
+		items = append(items, marker+strings.TrimPrefix(indent(body, pad), pad))
+	}
+	return strings.Join(items, "\n")
+}
+
+func indent(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = prefix + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isDataTable tells real tables from the layout tables newsletters are built
+// from: no nested tables or block content, and at least two columns.
+func isDataTable(n *html.Node) bool {
+	rows := tableRows(n)
+	if len(rows) < 2 {
+		return false
+	}
+	maxCols := 0
+	for _, row := range rows {
+		maxCols = max(maxCols, len(row))
+		for _, cell := range row {
+			if hasDescendant(cell, func(d *html.Node) bool {
+				return d.Data == "table" || (isBlock(d) && d.Data != "p")
+			}) {
+				return false
+			}
+		}
+	}
+	return maxCols >= 2
+}
+
+func tableRows(table *html.Node) [][]*html.Node {
+	var rows [][]*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.Data {
+			case "tr":
+				var cells []*html.Node
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+						cells = append(cells, cell)
+					}
+				}
+				rows = append(rows, cells)
+			case "thead", "tbody", "tfoot":
+				walk(c)
+			}
+		}
+	}
+	walk(table)
+	return rows
+}
+
+func (r *renderer) table(n *html.Node) string {
+	var grid [][]string
+	cols := 0
+	for _, row := range tableRows(n) {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			text := strings.TrimSpace(collapseInline(joinPieces(r.collect(cell), true)))
+			text = strings.ReplaceAll(text, "\n", " ")
+			if r.markdown {
+				text = strings.ReplaceAll(text, "|", `\|`)
+			}
+			cells = append(cells, text)
+		}
+		cols = max(cols, len(cells))
+		grid = append(grid, cells)
+	}
+
+	widths := make([]int, cols)
+	for _, row := range grid {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell), 3)
+		}
+	}
+	pad := func(s string, w int) string {
+		return s + strings.Repeat(" ", w-utf8.RuneCountInString(s))
+	}
+
+	lines := make([]string, 0, len(grid)+1)
+	for i, row := range grid {
+		cells := make([]string, cols)
+		for j := range cells {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			cells[j] = pad(cell, widths[j])
+		}
+		if r.markdown {
+			lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+			if i == 0 {
+				seps := make([]string, cols)
+				for j := range seps {
+					seps[j] = strings.Repeat("-", widths[j])
+				}
+				lines = append(lines, "| "+strings.Join(seps, " | ")+" |")
+			}
+			continue
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *renderer) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.TextNode:
+			b.WriteString(r.text(c.Data))
+		case html.ElementNode:
+			if skippedElements[c.Data] || isHidden(c) {
+				continue
+			}
+			if isBlock(c) {
+				b.WriteString(" " + joinPieces(r.collect(c), true) + " ")
+				continue
+			}
+			b.WriteString(r.inline(c))
+		}
+	}
+	return b.String()
+}
+
+func (r *renderer) inline(n *html.Node) string {
+	switch n.Data {
+	case "br":
+		return "\n"
+	case "img":
+		return r.image(n)
+	case "a":
+		return r.link(n)
+	case "b", "strong":
+		return r.wrap(n, "**")
+	case "i", "em":
+		return r.wrap(n, "*")
+	case "s", "strike", "del":
+		return r.wrap(n, "~~")
+	case "code", "kbd", "samp", "tt":
+		if r.markdown {
+			text := textContent(n)
+			if strings.TrimSpace(text) == "" {
+				return text
+			}
+			return "`" + strings.ReplaceAll(text, "`", "") + "`"
+		}
+		return textContent(n)
+	default:
+		return r.inlineChildren(n)
+	}
+}
+
+// wrap adds Markdown emphasis markers inside any surrounding whitespace, so
+// "<b>bold </b>" does not become "**bold **".
+func (r *renderer) wrap(n *html.Node, marker string) string {
+	text := r.inlineChildren(n)
+	if !r.markdown || strings.TrimSpace(text) == "" {
+		return text
+	}
+	trimmed := strings.TrimSpace(text)
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + marker + trimmed + marker + trail
+}
+
+func (r *renderer) image(n *html.Node) string {
+	alt, _ := attr(n, "alt")
+	alt = strings.TrimSpace(collapseInline(alt))
+	if !r.markdown {
+		return alt
+	}
+	src, _ := attr(n, "src")
+	src = strings.TrimSpace(src)
+	w, _ := attr(n, "width")
+	h, _ := attr(n, "height")
+	if src == "" || strings.HasPrefix(src, "data:") || w == "1" || h == "1" || w == "0" || h == "0" {
+		return alt
+	}
+	return "![" + escapeMarkdown(alt) + "](" + markdownTarget(src) + ")"
+}
+
+func (r *renderer) link(n *html.Node) string {
+	text := r.inlineChildren(n)
+	href, _ := attr(n, "href")
+	href = strings.TrimSpace(href)
+	lowerHref := strings.ToLower(href)
+	if !r.footnotes || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(lowerHref, "javascript:") {
+		return text
+	}
+	label := strings.TrimSpace(collapseInline(text))
+	if label == "" {
+		return text
+	}
+	// Links that spell out their own target need no footnote.
+	bare := strings.TrimPrefix(strings.TrimPrefix(lowerHref, "mailto:"), "tel:")
+	plainLabel := strings.ToLower(strings.NewReplacer(`\_`, "_", `\*`, "*").Replace(label))
+	if plainLabel == bare || plainLabel == strings.TrimSuffix(bare, "/") ||
+		strings.TrimPrefix(strings.TrimPrefix(bare, "https://"), "http://") == strings.TrimSuffix(plainLabel, "/") {
+		return text
+	}
+
+	idx, ok := r.linkIndex[href]
+	if !ok {
+		r.links = append(r.links, href)
+		idx = len(r.links)
+		r.linkIndex[href] = idx
+	}
+	lead := text[:len(text)-len(strings.TrimLeft(text, " \t\n"))]
+	trail := text[len(strings.TrimRight(text, " \t\n")):]
+	if r.markdown {
+		return fmt.Sprintf("%s[%s][%d]%s", lead, label, idx, trail)
+	}
+	return fmt.Sprintf("%s%s [%d]%s", lead, label, idx, trail)
+}
+
+var invisibleReplacer = strings.NewReplacer(
+	"\u00a0", " ", // nbsp
+	"\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "", "\u034f", "", // zero-width preheader padding
+)
+
+// text collapses whitespace in a text node, keeping a single space at either
+// edge so adjacent inline elements stay separated.
+func (r *renderer) text(s string) string {
+	s = invisibleReplacer.Replace(s)
+	if strings.TrimSpace(s) == "" {
+		if s == "" {
+			return ""
+		}
+		return " "
+	}
+	lead, trail := "", ""
+	if isSpace(s[0]) {
+		lead = " "
+	}
+	if isSpace(s[len(s)-1]) {
+		trail = " "
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if r.markdown {
+		s = escapeMarkdown(s)
+	}
+	return lead + s + trail
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r' || b == '\f'
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func markdownTarget(url string) string {
+	if strings.ContainsAny(url, " ()") {
+		return "<" + url + ">"
+	}
+	return url
+}
+
+// collapseInline squeezes spaces within each line and drops empty lines
+// produced by stray <br>s at the edges.
+func collapseInline(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.Join(strings.Fields(l), " ")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// tidy trims trailing spaces and squeezes runs of blank lines.
+func tidy(s string) string {
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	blank := 0
+	for _, l := range lines {
+		l = strings.TrimRight(l, " \t")
+		if strings.TrimSpace(strings.TrimLeft(l, ">")) == "" && strings.HasPrefix(l, ">") {
+			l = ">"
+		}
+		if l == "" {
+			blank++
+			if blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, l)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && n.Data == "br" {
+			b.WriteString("\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func hasDescendant(n *html.Node, match func(*html.Node) bool) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && (match(c) || hasDescendant(c, match)) {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,85 @@
+package htmltext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "empty", input: "", want: ""},
+		{name: "plain", input: "plain text", want: "plain text"},
+		{name: "entities", input: "<p>Tom &amp; Jerry&nbsp;&lt;3 &quot;ok&quot;</p>", want: `Tom & Jerry <3 "ok"`},
+		{name: "script and style", input: "<html><head><style>p{}</style><title>x</title></head><body><script>bad()</script><p>Hi there</p></body></html>", want: "Hi there"},
+		{name: "paragraphs", input: "<p>hello</p>   <p>world</p>", want: "hello\n\nworld"},
+		{name: "div lines", input: "<div>one</div><div>two</div><div><br></div><div>three</div>", want: "one\ntwo\nthree"},
+		{name: "br", input: "a<br>b<br/>c", want: "a\nb\nc"},
+		{name: "inline spacing", input: "<p>Hello <b>World</b>,<span> again</span></p>", want: "Hello World, again"},
+		{name: "hidden preheader", input: `<div style="display: none">preview text</div><p>Body</p>`, want: "Body"},
+		{name: "lists", input: "<ul><li>a</li><li>b<ul><li>c</li></ul></li></ul><ol start=3><li>x</li><li>y</li></ol>", want: "- a\n- b\n  - c\n\n3. x\n4. y"},
+		{name: "blockquote", input: "<p>Sure.</p><blockquote><p>Can you?</p><p>Thanks</p></blockquote>", want: "Sure.\n\n> Can you?\n>\n> Thanks"},
+		{name: "nested quote", input: "<blockquote>outer<blockquote>inner</blockquote></blockquote>", want: "> outer\n>\n> > inner"},
+		{name: "links", input: `<p>Read <a href="https://x.test/a">the docs</a> or <a href="https://x.test/a">here</a>, mail <a href="mailto:a@b.c">a@b.c</a>, see <a href="https://y.test/">https://y.test/</a></p>`, want: "Read the docs [1] or here [1], mail a@b.c, see https://y.test/\n\n[1]: https://x.test/a"},
+		{name: "data table", input: "<table><tr><th>Item</th><th>Qty</th></tr><tr><td>Apples</td><td>3</td></tr></table>", want: "Item    Qty\nApples  3"},
+		{name: "layout table", input: "<table><tr><td><div>Logo</div></td></tr><tr><td><p>Welcome!</p><p>Second</p></td></tr></table>", want: "Logo\n\nWelcome!\n\nSecond"},
+		{name: "pre", input: "<pre>  x := 1\n  y := 2</pre>", want: "x := 1\n  y := 2"},
+		{name: "zero width", input: "<p>a\u200b\u200cb&#xFEFF;</p>", want: "ab"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Text(tc.input); got != tc.want {
+				t.Fatalf("Text(%q)\n got: %q\nwant: %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	input := `<h2>Weekly <em>update</em></h2>
+<p>Hello <strong>team</strong>, see <a href="https://x.test/report">the report</a>.</p>
+<ul><li>snake_case item</li><li><code>go test</code></li></ul>
+<table><thead><tr><th>Name</th><th>Status</th></tr></thead><tbody><tr><td>API</td><td>ok | green</td></tr></tbody></table>
+<img src="https://x.test/chart.png" alt="chart"><img src="https://x.test/pixel.gif" width="1" height="1">
+<div class="gmail_quote">On Mon, Ann wrote:<blockquote>Earlier <b>text</b></blockquote></div>`
+	want := strings.Join([]string{
+		"## Weekly *update*",
+		"",
+		"Hello **team**, see [the report][1].",
+		"",
+		"- snake\\_case item",
+		"- `go test`",
+		"",
+		"| Name | Status      |",
+		"| ---- | ----------- |",
+		"| API  | ok \\| green |",
+		"",
+		"![chart](https://x.test/chart.png)",
+		"On Mon, Ann wrote:",
+		"",
+		"> Earlier **text**",
+		"",
+		"[1]: https://x.test/report",
+	}, "\n")
+	if got := Markdown(input); got != want {
+		t.Fatalf("Markdown mismatch\n got:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+
+	input := `<p>Read <a href="https://x.test/a">the docs</a> today.</p><p>Thanks</p>`
+	if got, want := Preview(input), "Read the docs today.\n\nThanks"; got != want {
+		t.Fatalf("Preview(%q)\n got: %q\nwant: %q", input, got, want)
+	}
+}
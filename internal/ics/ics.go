@@ -0,0 +1,494 @@
+// Package ics reads and writes iCalendar (RFC 5545) data: content lines with
+// folding, parameters and text escaping, nested components, and the DATE,
+// DATE-TIME and DURATION value types.
+package ics
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateLayout      = "20060102"
+	localTimeLayout = "20060102T150405"
+	utcTimeLayout   = "20060102T150405Z"
+
+	// maxLineOctets is the folding limit for content lines, excluding CRLF.
+	maxLineOctets = 75
+)
+
+// Param is a single property parameter such as TZID=Europe/Berlin.
+type Param struct {
+	Name  string
+	Value string
+}
+
+// Property is a content line: NAME;PARAM=VALUE:value. Value is stored as it
+// appears on the wire; use Text and Component.AddText for TEXT values.
+type Property struct {
+	Name   string
+	Params []Param
+	Value  string
+}
+
+// Param returns the value of the named parameter, or "".
+func (p *Property) Param(name string) string {
+	for _, param := range p.Params {
+		if strings.EqualFold(param.Name, name) {
+			return param.Value
+		}
+	}
+	return ""
+}
+
+// String formats the property as an unfolded content line.
+func (p *Property) String() string {
+	return formatProperty(p)
+}
+
+// Text returns the value with TEXT escapes removed.
+func (p *Property) Text() string {
+	return UnescapeText(p.Value)
+}
+
+// Time parses a DATE or DATE-TIME value. Floating times and unknown TZIDs
+// are interpreted in loc. dateOnly reports a DATE value.
+func (p *Property) Time(loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	value := strings.TrimSpace(p.Value)
+	if loc == nil {
+		loc = time.UTC
+	}
+	if strings.EqualFold(p.Param("VALUE"), "DATE") || len(value) == len(dateLayout) {
+		t, err = time.ParseInLocation(dateLayout, value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(utcTimeLayout, value)
+		return t, false, err
+	}
+	if tzid := strings.TrimPrefix(p.Param("TZID"), "/"); tzid != "" {
+		if tz, loadErr := time.LoadLocation(tzid); loadErr == nil {
+			loc = tz
+		}
+	}
+	t, err = time.ParseInLocation(localTimeLayout, value, loc)
+	return t, false, err
+}
+
+// Component is a BEGIN/END block such as VCALENDAR, VEVENT or VALARM.
+type Component struct {
+	Name       string
+	Properties []*Property
+	Children   []*Component
+}
+
+// NewComponent returns an empty component with the given name.
+func NewComponent(name string) *Component {
+	return &Component{Name: strings.ToUpper(name)}
+}
+
+// Get returns the first property with the given name, or nil.
+func (c *Component) Get(name string) *Property {
+	for _, p := range c.Properties {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// GetAll returns every property with the given name.
+func (c *Component) GetAll(name string) []*Property {
+	var out []*Property
+	for _, p := range c.Properties {
+		if strings.EqualFold(p.Name, name) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Text returns the unescaped TEXT value of the first property with the given
+// name, or "".
+func (c *Component) Text(name string) string {
+	if p := c.Get(name); p != nil {
+		return p.Text()
+	}
+	return ""
+}
+
+// Add appends a property with a raw (already escaped) value.
+func (c *Component) Add(name, value string, params ...Param) *Property {
+	p := &Property{Name: strings.ToUpper(name), Params: params, Value: value}
+	c.Properties = append(c.Properties, p)
+	return p
+}
+
+// AddText appends a TEXT property, escaping the value. Empty values are
+// skipped.
+func (c *Component) AddText(name, text string, params ...Param) {
+	if text == "" {
+		return
+	}
+	c.Add(name, EscapeText(text), params...)
+}
+
+// Components returns the direct children with the given name.
+func (c *Component) Components(name string) []*Component {
+	var out []*Component
+	for _, child := range c.Children {
+		if strings.EqualFold(child.Name, name) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
+
+// Parse reads iCalendar data and returns its top-level components, usually a
+// single VCALENDAR.
+func Parse(r io.Reader) ([]*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		roots []*Component
+		stack []*Component
+	)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := ParseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch prop.Name {
+		case "BEGIN":
+			stack = append(stack, NewComponent(prop.Value))
+		case "END":
+			if len(stack) == 0 || !strings.EqualFold(stack[len(stack)-1].Name, prop.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, prop.Value)
+			}
+			done := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				roots = append(roots, done)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, done)
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property %s outside of a component", i+1, prop.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("no iCalendar components found")
+	}
+	return roots, nil
+}
+
+// unfold joins continuation lines (starting with a space or tab) onto the
+// previous line. Both CRLF and bare LF line endings are accepted.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && line != "" && (line[0] == ' ' || line[0] == '\t') {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// ParseProperty parses a single unfolded content line, such as a recurrence
+// rule stored by Google Calendar.
+func ParseProperty(line string) (*Property, error) {
+	// The name and parameters end at the first colon outside quotes.
+	inQuote := false
+	colon := -1
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inQuote = !inQuote
+		case ':':
+			if !inQuote {
+				colon = i
+			}
+		}
+		if colon >= 0 {
+			break
+		}
+	}
+	if colon < 0 {
+		return nil, fmt.Errorf("invalid content line %q", truncate(line))
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := splitUnquoted(head, ';')
+	name := strings.ToUpper(strings.TrimSpace(parts[0]))
+	if name == "" {
+		return nil, fmt.Errorf("missing property name in %q", truncate(line))
+	}
+	prop := &Property{Name: name, Value: value}
+	for _, part := range parts[1:] {
+		pname, pvalue, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid parameter %q", part)
+		}
+		prop.Params = append(prop.Params, Param{
+			Name:  strings.ToUpper(strings.TrimSpace(pname)),
+			Value: strings.ReplaceAll(pvalue, `"`, ""),
+		})
+	}
+	return prop, nil
+}
+
+func splitUnquoted(s string, sep byte) []string {
+	var (
+		parts   []string
+		inQuote bool
+		start   int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuote = !inQuote
+		case sep:
+			if !inQuote {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func truncate(s string) string {
+	if len(s) > 40 {
+		return s[:40] + "..."
+	}
+	return s
+}
+
+// Encode writes the component tree with CRLF line endings, folding lines
+// longer than 75 octets.
+func Encode(w io.Writer, c *Component) error {
+	var buf bytes.Buffer
+	encodeComponent(&buf, c)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func encodeComponent(buf *bytes.Buffer, c *Component) {
+	writeFolded(buf, "BEGIN:"+c.Name)
+	for _, p := range c.Properties {
+		writeFolded(buf, p.String())
+	}
+	for _, child := range c.Children {
+		encodeComponent(buf, child)
+	}
+	writeFolded(buf, "END:"+c.Name)
+}
+
+func formatProperty(p *Property) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	for _, param := range p.Params {
+		b.WriteByte(';')
+		b.WriteString(param.Name)
+		b.WriteByte('=')
+		if strings.ContainsAny(param.Value, ":;,") {
+			b.WriteString(`"` + strings.ReplaceAll(param.Value, `"`, "") + `"`)
+		} else {
+			b.WriteString(param.Value)
+		}
+	}
+	b.WriteByte(':')
+	b.WriteString(p.Value)
+	return b.String()
+}
+
+func writeFolded(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// EscapeText escapes a TEXT value.
+func EscapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// UnescapeText reverses EscapeText.
+func UnescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// FormatDate formats t as a DATE value.
+func FormatDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// FormatUTC formats t as a UTC DATE-TIME value.
+func FormatUTC(t time.Time) string {
+	return t.UTC().Format(utcTimeLayout)
+}
+
+// FormatLocal formats t as a local DATE-TIME value, for use with TZID.
+func FormatLocal(t time.Time) string {
+	return t.Format(localTimeLayout)
+}
+
+// ParseDuration parses a DURATION value such as -PT15M or P1DT2H.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	orig := s
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	s = s[1:]
+
+	var (
+		total  time.Duration
+		inTime bool
+		num    int
+		digits bool
+	)
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num = num*10 + int(r-'0')
+			digits = true
+			continue
+		case r == 'T':
+			if inTime || digits {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		var unit time.Duration
+		switch {
+		case r == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case r == 'H' && inTime:
+			unit = time.Hour
+		case r == 'M' && inTime:
+			unit = time.Minute
+		case r == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		total += time.Duration(num) * unit
+		num, digits = 0, false
+	}
+	if digits {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	return sign * total, nil
+}
+
+// FormatDuration formats d as a DURATION value (whole minutes or seconds).
+func FormatDuration(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+	if d == 0 {
+		b.WriteString("T0S")
+		return b.String()
+	}
+	if days := d / (24 * time.Hour); days > 0 && d%(24*time.Hour) == 0 {
+		if days%7 == 0 {
+			fmt.Fprintf(&b, "%dW", days/7)
+		} else {
+			fmt.Fprintf(&b, "%dD", days)
+		}
+		return b.String()
+	}
+	if days := d / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+		d -= days * 24 * time.Hour
+	}
+	b.WriteByte('T')
+	if h := d / time.Hour; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+		d -= m * time.Minute
+	}
+	if s := d / time.Second; s > 0 {
+		fmt.Fprintf(&b, "%dS", s)
+	}
+	return b.String()
+}
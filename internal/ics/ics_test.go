@@ -0,0 +1,134 @@
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseUnfoldsAndNests(t *testing.T) {
+	src := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:abc@example.com\r\n" +
+		"SUMMARY:Planning\\, Q3\r\n" +
+		"DESCRIPTION:Line one\\nline\r\n  two\r\n" +
+		"ATTENDEE;CN=\"Doe, Jane\";PARTSTAT=ACCEPTED:mailto:jane@example.com\r\n" +
+		"DTSTART;TZID=Europe/Berlin:20300107T100000\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"TRIGGER:-PT15M\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	roots, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(roots) != 1 || roots[0].Name != "VCALENDAR" {
+		t.Fatalf("unexpected roots: %+v", roots)
+	}
+	events := roots[0].Components("VEVENT")
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	ev := events[0]
+	if got := ev.Text("SUMMARY"); got != "Planning, Q3" {
+		t.Fatalf("summary = %q", got)
+	}
+	if got := ev.Text("DESCRIPTION"); got != "Line one\nline two" {
+		t.Fatalf("description = %q", got)
+	}
+	att := ev.Get("ATTENDEE")
+	if att.Param("CN") != "Doe, Jane" || att.Param("partstat") != "ACCEPTED" || att.Value != "mailto:jane@example.com" {
+		t.Fatalf("unexpected attendee: %+v", att)
+	}
+	start, dateOnly, err := ev.Get("DTSTART").Time(time.UTC)
+	if err != nil || dateOnly {
+		t.Fatalf("DTSTART: %v %v", err, dateOnly)
+	}
+	if start.Location().String() != "Europe/Berlin" || start.Hour() != 10 {
+		t.Fatalf("unexpected start: %s", start)
+	}
+	if len(ev.Components("VALARM")) != 1 {
+		t.Fatalf("expected nested VALARM")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"BEGIN:VCALENDAR\r\nEND:VEVENT\r\n",
+		"BEGIN:VCALENDAR\r\n",
+		"SUMMARY:x\r\n",
+		"BEGIN:VCALENDAR\r\nnocolon\r\nEND:VCALENDAR\r\n",
+	} {
+		if _, err := Parse(strings.NewReader(src)); err == nil {
+			t.Fatalf("expected error for %q", src)
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	cal := NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	ev := NewComponent("VEVENT")
+	long := strings.Repeat("\u00e9t\u00e9 ", 30)
+	ev.AddText("SUMMARY", long)
+	ev.AddText("LOCATION", "")
+	ev.Add("ATTENDEE", "mailto:a@example.com", Param{Name: "CN", Value: "Doe, Jane"})
+	cal.Children = append(cal.Children, ev)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, cal); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("line not folded (%d octets): %q", len(line), line)
+		}
+	}
+	if !strings.Contains(buf.String(), `ATTENDEE;CN="Doe, Jane":mailto:a@example.com`) {
+		t.Fatalf("expected quoted parameter, got:\n%s", buf.String())
+	}
+
+	roots, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	got := roots[0].Components("VEVENT")[0]
+	if got.Text("SUMMARY") != long {
+		t.Fatalf("summary did not round-trip: %q", got.Text("SUMMARY"))
+	}
+	if got.Get("LOCATION") != nil {
+		t.Fatalf("empty text property should be skipped")
+	}
+}
+
+func TestDurations(t *testing.T) {
+	tests := map[string]time.Duration{
+		"-PT15M":   -15 * time.Minute,
+		"PT1H30M":  90 * time.Minute,
+		"P1D":      24 * time.Hour,
+		"P1W":      7 * 24 * time.Hour,
+		"P1DT2H":   26 * time.Hour,
+		"+PT0S":    0,
+		"-P2DT10M": -(48*time.Hour + 10*time.Minute),
+	}
+	for in, want := range tests {
+		got, err := ParseDuration(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+		back, err := ParseDuration(FormatDuration(want))
+		if err != nil || back != want {
+			t.Fatalf("FormatDuration(%v) = %q did not round-trip", want, FormatDuration(want))
+		}
+	}
+	for _, bad := range []string{"", "P", "PT", "15M", "PT1D", "P1H"} {
+		if _, err := ParseDuration(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
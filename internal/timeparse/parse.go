@@ -16,6 +16,8 @@ var (
 	ErrInvalidTimeExpr    = errors.New("invalid time expression")
 	ErrEmptySince         = errors.New("empty since value")
 	ErrInvalidSince       = errors.New("invalid since value")
+	ErrEmptyAt            = errors.New("empty time")
+	ErrInvalidAt          = errors.New("invalid time")
 	ErrInvalidDateLayouts = errors.New("invalid date format")
 )
 
@@ -124,6 +126,92 @@ func ParseRangeExpr(expr string, now time.Time, loc *time.Location) (time.Time,
 	return time.Time{}, fmt.Errorf("%w: %q (try: 2026-01-05, today, tomorrow, monday)", ErrInvalidTimeExpr, expr)
 }
 
+// ParseAt parses a point in time such as a scheduled send time.
+// Supported: everything ParseRangeExpr accepts, relative durations
+// ("in 2h", "+90m"), a clock on its own ("9am", "17:30" = today), and a day
+// followed by a clock ("tomorrow 9am", "next friday at 14:00",
+// "2026-03-01 9:30pm").
+func ParseAt(expr string, now time.Time, loc *time.Location) (time.Time, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return time.Time{}, ErrEmptyAt
+	}
+
+	if loc == nil {
+		loc = time.Local
+	}
+
+	now = now.In(loc)
+	exprLower := strings.ToLower(expr)
+
+	if rest, ok := strings.CutPrefix(exprLower, "in "); ok {
+		if d, err := time.ParseDuration(strings.ReplaceAll(rest, " ", "")); err == nil && d >= 0 {
+			return now.Add(d), nil
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(exprLower, "+"); ok {
+		if d, err := time.ParseDuration(rest); err == nil {
+			return now.Add(d), nil
+		}
+	}
+
+	if hour, minute, ok := parseClock(strings.ReplaceAll(exprLower, " ", "")); ok {
+		return atClock(now, hour, minute), nil
+	}
+
+	if t, err := ParseRangeExpr(expr, now, loc); err == nil {
+		return t, nil
+	}
+
+	// "<day> [at] <clock>": the clock is the last word, or the last two when
+	// written as "9 am".
+	fields := strings.Fields(exprLower)
+	for n := 1; n <= 2 && n < len(fields); n++ {
+		hour, minute, ok := parseClock(strings.Join(fields[len(fields)-n:], ""))
+		if !ok {
+			continue
+		}
+
+		dayFields := fields[:len(fields)-n]
+		if len(dayFields) > 1 && dayFields[len(dayFields)-1] == "at" {
+			dayFields = dayFields[:len(dayFields)-1]
+		}
+
+		day, err := ParseRangeExpr(strings.Join(dayFields, " "), now, loc)
+		if err != nil {
+			break
+		}
+
+		return atClock(day, hour, minute), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q (try: tomorrow 9am, monday 14:30, in 2h, 2026-01-05T09:00)", ErrInvalidAt, expr)
+}
+
+// parseClock parses 12-hour ("9am", "9:30pm") and 24-hour ("14:30") clock
+// times, plus "noon" and "midnight".
+func parseClock(value string) (int, int, bool) {
+	switch value {
+	case "noon":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+
+	for _, layout := range []string{"3pm", "3:04pm", "15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Hour(), t.Minute(), true
+		}
+	}
+
+	return 0, 0, false
+}
+
+func atClock(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
+
 // ParseSince parses --since values for tracking style queries.
 // Supported: duration (24h), date (YYYY-MM-DD), RFC3339(+nano), and
 // local datetime layouts.
@@ -136,6 +136,52 @@ func TestParseRangeExpr(t *testing.T) {
 	}
 }
 
+//nolint:wsl_v5
+func TestParseAt(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("Offset", -5*3600)
+	now := time.Date(2026, 2, 13, 15, 45, 0, 0, loc)
+	testCases := []struct {
+		name    string
+		value   string
+		wantErr bool
+		want    time.Time
+	}{
+		{name: "tomorrow 9am", value: "tomorrow 9am", want: time.Date(2026, 2, 14, 9, 0, 0, 0, loc)},
+		{name: "spaced meridiem", value: "Tomorrow 9 AM", want: time.Date(2026, 2, 14, 9, 0, 0, 0, loc)},
+		{name: "weekday at", value: "next friday at 14:30", want: time.Date(2026, 2, 20, 14, 30, 0, 0, loc)},
+		{name: "date pm", value: "2026-03-01 9:30pm", want: time.Date(2026, 3, 1, 21, 30, 0, 0, loc)},
+		{name: "clock only", value: "5pm", want: time.Date(2026, 2, 13, 17, 0, 0, 0, loc)},
+		{name: "noon", value: "monday noon", want: time.Date(2026, 2, 16, 12, 0, 0, 0, loc)},
+		{name: "in duration", value: "in 2h 30m", want: now.Add(150 * time.Minute)},
+		{name: "plus duration", value: "+90m", want: now.Add(90 * time.Minute)},
+		{name: "rfc3339", value: "2026-02-14T09:00:00Z", want: time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC)},
+		{name: "bad clock", value: "tomorrow 25pm", wantErr: true},
+		{name: "bad day", value: "someday 9am", wantErr: true},
+		{name: "empty", value: " ", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseAt(tc.value, now, loc)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAt: %v", err)
+			}
+			if !got.Equal(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
 //nolint:wsl_v5
 func TestParseSince(t *testing.T) {
 	t.Parallel()